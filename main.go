@@ -10,6 +10,7 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -18,13 +19,19 @@ import (
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/couchbase/couchbase-exporter/pkg/cli"
 	"github.com/couchbase/couchbase-exporter/pkg/collectors"
 	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/dashboards"
 	"github.com/couchbase/couchbase-exporter/pkg/handlers"
 	"github.com/couchbase/couchbase-exporter/pkg/log"
 	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/rules"
 	"github.com/couchbase/couchbase-exporter/pkg/util"
 	"github.com/couchbase/couchbase-exporter/pkg/version"
 
@@ -36,32 +43,94 @@ const (
 	certAndKeyError = "please specify both cert and key arguments"
 	caAppendError   = "failed to append CA"
 	x509Error       = "failed to create X509 KeyPair"
+	certAuthError   = "certificate authentication needs a CA, client certificate and client key"
+
+	sidecarInitInterval = 5 * time.Second
+
+	// authBackoffRefreshes is how many refresh intervals requests rejected
+	// by Couchbase Server are held back for before they are tried again.
+	authBackoffRefreshes = 5
 )
 
 var (
-	exporterConfig = new(objects.ExporterConfig)
-	couchAddr      *string
-	couchPort      *string
-	userFlag       *string
-	passFlag       *string
-	svrAddr        *string
-	svrPort        *string
-	refreshTime    *string
-	tokenFlag      *string
-	cert           *string
-	key            *string
-	ca             *string
-	clientCert     *string
-	clientKey      *string
-	logLevel       *string
-	logJSON        *bool
-	backOffLimit   *string
-	configFile     *string
-	defaultConfig  *bool
-	panics         = 0
-	errCertAndKey  = fmt.Errorf(certAndKeyError)
-	errCaAppend    = fmt.Errorf(caAppendError)
-	errX509        = fmt.Errorf(x509Error)
+	exporterConfig   = new(objects.ExporterConfig)
+	couchAddr        *string
+	couchPort        *string
+	userFlag         *string
+	passFlag         *string
+	authMode         *string
+	tokenFile        *string
+	spnegoCommand    *string
+	svrAddr          *string
+	svrPort          *string
+	refreshTime      *string
+	maxIdleConns     *string
+	requestTimeout   *string
+	requestIDs       *bool
+	connMaxLifetime  *string
+	labelCacheTTL    *string
+	allowedCIDRs     *string
+	maxRequests      *string
+	adminTokenFile   *string
+	tokenFlag        *string
+	cert             *string
+	key              *string
+	ca               *string
+	clientCert       *string
+	clientKey        *string
+	fips             *bool
+	logLevel         *string
+	logThrottle      *string
+	logJSON          *bool
+	backOffLimit     *string
+	configFile       *string
+	defaultConfig    *bool
+	snapshotFile     *string
+	snapshotMaxAge   *string
+	textfilePath     *string
+	recordDir        *string
+	replayDir        *string
+	faultLatency     *string
+	faultErrorRate   *string
+	faultMalformed   *string
+	captureEndpoints *string
+	captureWindow    *string
+	nodeStripPort    *bool
+	nodeHostnameForm *string
+	compat           *string
+	metricLint       *string
+	metricNames      *string
+	slowQueries      *bool
+	preparedStmts    *bool
+	hotKeys          *bool
+	hotKeysTop       *string
+	kvConnections    *bool
+	clientErrors     *bool
+	serviceProbes    *bool
+	slowBuckets      *string
+	health           *bool
+	clusterRole      *string
+	suppressStandby  *bool
+	deltaExport      *bool
+	seriesLimit      *string
+	clusterMode      *bool
+	nodeName         *string
+	perNodeScope     *string
+	waitRebalance    *bool
+	undefinedSamples *string
+	windowAggregates *bool
+	exemplars        *bool
+	sidecar          *bool
+	capellaAPIKey    *string
+	ownerLabelsFile  *string
+	staticLabels     = labelFlags{}
+	listenAddresses  = stringFlags{}
+	panics           = 0
+	clusterGatherers = prometheus.Gatherers{}
+	errCertAndKey    = fmt.Errorf(certAndKeyError)
+	errCaAppend      = fmt.Errorf(caAppendError)
+	errX509          = fmt.Errorf(x509Error)
+	errCertAuth      = fmt.Errorf(certAuthError)
 )
 
 func init() {
@@ -69,10 +138,22 @@ func init() {
 	couchPort = flag.String("couchbase-port", "", "The port where Couchbase Server is running.")
 	userFlag = flag.String("couchbase-username", "", "Couchbase Server Username. Overridden by env-var COUCHBASE_USER if set.")
 	passFlag = flag.String("couchbase-password", "", "Plaintext Couchbase Server Password. Recommended to pass value via env-ver COUCHBASE_PASS. Overridden by aforementioned env-var.")
+	authMode = flag.String("couchbase-auth", "", "how requests to Couchbase Server are authenticated (basic/bearer/certificate/spnego)")
+	tokenFile = flag.String("couchbase-token-file", "", "file holding the bearer token for Couchbase Server, read again for every request. The token may instead be passed via env-var COUCHBASE_TOKEN")
+	spnegoCommand = flag.String("couchbase-spnego-command", "", "command printing the base64 SPNEGO token for the service principal HTTP@<host> it is given as its last argument, run for every request with -couchbase-auth spnego")
 
 	svrAddr = flag.String("server-address", "", "The address to host the server on, default all interfaces")
 	svrPort = flag.String("server-port", "", "The port to host the server on")
 	refreshTime = flag.String("per-node-refresh", "", "How frequently to collect per_node_bucket_stats collector in seconds")
+	maxIdleConns = flag.String("max-idle-conns-per-host", "", "number of idle connections to keep open to each Couchbase Server node")
+	connMaxLifetime = flag.String("conn-max-lifetime", "", "seconds after which idle connections to Couchbase Server are closed, so that its hostnames are resolved again. Never closed if 0")
+	requestTimeout = flag.String("request-timeout", "", "seconds a request to Couchbase Server may take before it is abandoned. Only bounded by the refresh interval if 0")
+	requestIDs = flag.Bool("request-ids", false, "if set to true, every request to Couchbase Server is sent with an X-Request-ID of its own, logged at debug level and quoted in errors")
+	labelCacheTTL = flag.String("label-cache-ttl", "", "seconds the cluster name, node hostname and UUIDs are cached for before they are requested again")
+
+	allowedCIDRs = flag.String("web.allowed-cidrs", "", "comma separated networks, such as 10.0.0.0/8, that may request /metrics. All if empty")
+	maxRequests = flag.String("web.max-requests", "", "maximum number of concurrent requests for /metrics, any more are refused. Unlimited if 0")
+	adminTokenFile = flag.String("web.admin-token-file", "", "file holding the bearer token that allows access to the admin API, which is disabled if empty")
 
 	tokenFlag = flag.String("token", "", "bearer token that allows access to /metrics")
 	cert = flag.String("cert", "", "certificate file for exporter in order to serve metrics over TLS")
@@ -80,27 +161,136 @@ func init() {
 	ca = flag.String("ca", "", "PKI certificate authority file")
 	clientCert = flag.String("client-cert", "", "client certificate file to authenticate this client with couchbase-server")
 	clientKey = flag.String("client-key", "", "client private key file to authenticate this client with couchbase-server")
+	fips = flag.Bool("fips", false, "if set to true, TLS is restricted to TLS 1.2 with FIPS approved cipher suites and curves, and certificates signed with MD5 or SHA-1 are refused. Always set in builds with the fips tag")
 	logLevel = flag.String("log-level", "", "log level (debug/info/warn/error)")
+	logThrottle = flag.String("log-throttle", "", "seconds an identical warning or error is not logged again for, after which it is logged with the number of times it was repeated. Disabled if 0")
 	logJSON = flag.Bool("log-json", true, "if set to true, logs will be JSON formatted")
 
-	backOffLimit = flag.String("backofflimit", "", "number of retries after panicking before exiting")
+	backOffLimit = flag.String("backoff-limit", "", "number of retries after panicking before exiting")
+	flag.StringVar(backOffLimit, "backofflimit", "", "deprecated, use -backoff-limit")
 	configFile = flag.String("config", "", "The location of the PE configuration. Overridden by env-var COUCHBASE_CONFIG_FILE if set.")
 	defaultConfig = flag.Bool("print-config", false, "Outputs the config file with CLI and ENV var override to stdout")
+	snapshotFile = flag.String("snapshot-file", "", "file to persist the last collected per node and bucket stats to, restored on startup. Disabled if empty")
+	snapshotMaxAge = flag.String("snapshot-max-age", "", "maximum age in seconds of a snapshot that will be restored on startup")
+	textfilePath = flag.String("textfile-path", "", "write metrics to this .prom file for node_exporter's textfile collector instead of serving /metrics")
+	recordDir = flag.String("record-dir", "", "directory to save the responses from Couchbase Server to, in a directory for each refresh")
+	replayDir = flag.String("replay-dir", "", "serve metrics from the responses saved with -record-dir to this directory instead of from Couchbase Server")
+	faultLatency = flag.String("dev.fault-latency", "", "milliseconds to delay every request to Couchbase Server by, for testing only")
+	faultErrorRate = flag.String("dev.fault-error-rate", "", "fraction of requests to Couchbase Server to fail with a 503, for testing only")
+	faultMalformed = flag.String("dev.fault-malformed-rate", "", "fraction of responses from Couchbase Server to truncate into malformed JSON, for testing only")
+	captureEndpoints = flag.String("debug.capture-endpoints", "", "regular expression matching the paths of the Couchbase Server endpoints whose request and response bodies are logged, redacted and truncated, for diagnosing responses that fail to parse")
+	captureWindow = flag.String("debug.capture-window", "", "seconds the bodies of the endpoints matching -debug.capture-endpoints are logged for after starting")
+	nodeStripPort = flag.Bool("node-strip-port", false, "if set to true, the port is removed from node labels")
+	nodeHostnameForm = flag.String("node-hostname-form", "", "rewrite node labels as short hostnames (short) or fully qualified domain names (fqdn)")
+
+	compat = flag.String("compat", "", "emit metrics under the names used by another exporter (couchbase/blakelead)")
+	slowQueries = flag.Bool("slow-queries", false, "if set to true, the query service's log of completed requests is read to count slow queries by statement")
+	hotKeys = flag.Bool("hot-keys", false, "if set to true, the most frequently accessed document keys of every bucket are read from the bucket stats")
+	hotKeysTop = flag.String("hot-keys-top", "", "number of the hottest keys of each bucket to export, all those sampled if 0")
+	kvConnections = flag.Bool("kv-connections", false, "if set to true, the data service's connection stats of every node are read from the stats API of Couchbase Server 7")
+	clientErrors = flag.Bool("client-errors", false, "if set to true, the errors the data service returns to client SDKs are read for every node from the stats API of Couchbase Server 7")
+	serviceProbes = flag.Bool("service-probes", false, "if set to true, the port of every service of every node is probed each scrape and reported as couchbase_service_up")
+	slowBuckets = flag.String("slow-bucket-threshold", "", "seconds requesting the stats of a bucket may keep taking before the bucket is collected less often and reported as degraded. Never if 0")
+	clusterRole = flag.String("cluster-role", "", "role of the cluster (primary/standby), labelling every series of the cluster with cluster_role")
+	suppressStandby = flag.Bool("suppress-on-standby", false, "if set to true, the derived metrics alerts are built on, such as couchbase_health_status, are not exported for standby clusters")
+	deltaExport = flag.Bool("delta-export", false, "if set to true, only the series that changed since the last scrape of /metrics/delta are served on it, with a heartbeat, for clusters scraped over constrained links")
+	health = flag.Bool("health", false, "if set to true, couchbase_health_status grades the kv, index, query and xdcr components as ok, warning or critical by the configured thresholds")
+	preparedStmts = flag.Bool("prepared-statements", false, "if set to true, the plan cache of every query node is read to report prepared statements, cache hits and misses and plan invalidations")
+	seriesLimit = flag.String("series-limit", "", "maximum number of series of each metric for each cluster, any more are dropped. Unlimited if 0")
+	metricNames = flag.String("metric-names", "", "names /metrics is served with: legacy, corrected (as served on /metrics/v2) or both while dashboards and alerts are migrated")
+	metricLint = flag.String("metric-lint", "", "check metric names against the Prometheus conventions when registering collectors: off, warn or strict (refuses to start)")
+	capellaAPIKey = flag.String("capella-api-key", "", "secret of the Capella API key used to read the configured Capella clusters. Overridden by env-var CAPELLA_API_KEY if set.")
+	sidecar = flag.Bool("sidecar", false, "if set to true, connection settings are detected from the operator managed pod and collection waits for the node to be initialized")
+	waitRebalance = flag.Bool("wait-for-rebalance", false, "if set to true, per node bucket stats are not collected until the cluster has been rebalanced")
+	undefinedSamples = flag.String("undefined-samples", "", "how per node bucket stats whose latest sample is not a number are exported (skip/nan/zero)")
+	windowAggregates = flag.Bool("window-aggregates", false, "if set to true, the minimum, average and maximum over the sample window are exported for the bucket stats configured to aggregate")
+	exemplars = flag.Bool("exemplars", false, "if set to true, exemplars are attached to counters served in the OpenMetrics format")
+	clusterMode = flag.Bool("cluster-mode", false, "if set to true, per node bucket stats are collected for every node in the cluster, the same as -per-node-scope all")
+	perNodeScope = flag.String("per-node-scope", "", "which nodes per node bucket stats are collected for, the node the exporter connects to (self), every node that serves each bucket (all) or self when running beside the node and all otherwise (auto)")
+	nodeName = flag.String("node-name", "", "hostname of the node to collect per node bucket stats for, rather than the node the exporter connects to")
+	ownerLabelsFile = flag.String("owner-labels-file", "", "JSON file mapping bucket names to labels, such as the owning team, to add to the metrics of each bucket")
+
+	flag.Var(staticLabels, "label", "name=value label to add to every exported metric, may be repeated")
+	flag.Var(&listenAddresses, "web.listen-address", "host:port or unix:///path/to.sock to serve on instead of the server address and port, may be repeated")
+}
+
+// stringFlags collects the values of a repeated flag.
+type stringFlags []string
+
+func (s *stringFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringFlags) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func (s *stringFlags) IsRepeatable() bool {
+	return true
+}
+
+// labelFlags collects repeated -label name=value flags.
+type labelFlags map[string]string
+
+func (l labelFlags) String() string {
+	pairs := []string{}
+	for name, value := range l {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	return strings.Join(pairs, ",")
+}
+
+func (l labelFlags) Set(pair string) error {
+	name, value, err := util.ParseStaticLabel(pair)
+	if err != nil {
+		return err
+	}
+
+	l[name] = value
+
+	return nil
+}
+
+func (l labelFlags) IsRepeatable() bool {
+	return true
 }
 
 func main() {
-	flag.Parse()
+	app := cli.App{
+		Name:    "couchbase-exporter",
+		Default: "serve",
+		Commands: []cli.Command{
+			{Name: "serve", Summary: "collect metrics from Couchbase Server and serve them, the default", Run: runServe},
+			{Name: "version", Summary: "print the version of the exporter", Run: runVersion},
+			{Name: "check-config", Summary: "check the configuration file and flags of serve without connecting to Couchbase Server", Run: runCheckConfig},
+			{Name: "list-metrics", Summary: "list every metric the configuration exports", Run: runListMetrics},
+			{Name: "dashboards", Summary: "generate Grafana dashboards for the configuration", Run: runDashboards},
+			{Name: "rules", Summary: "generate Prometheus alerting rules for the configuration", Run: runRules},
+		},
+	}
+
+	os.Exit(app.Run(os.Args[1:]))
+}
+
+// loadConfig parses the flags of serve and returns the configuration file, or
+// the defaults, overridden by the flags and environment variables.
+func loadConfig(args []string) (*objects.ExporterConfig, error) {
+	if err := cli.Parse(flag.CommandLine, args); err != nil {
+		return nil, err
+	}
 
 	// Load config from file, or load up defaults.
 	exporterConfig, err := config.New(*configFile)
 	if err != nil {
-		log.Error("Error loading config file.")
-		os.Exit(1)
+		return nil, fmt.Errorf("error loading config file: %w", err)
 	}
 
 	// Get Logging settings and initialize log level.
 	exporterConfig.SetOrDefaultLogJSON(*logJSON)
 	exporterConfig.SetOrDefaultLogLevel(*logLevel)
+	exporterConfig.SetOrDefaultLogThrottle(*logThrottle)
 
 	if exporterConfig.LogLevel != "" {
 		log.SetLevel(exporterConfig.LogLevel)
@@ -110,14 +300,27 @@ func main() {
 		log.SetFormat("json")
 	}
 
+	log.SetThrottle(time.Duration(exporterConfig.LogThrottle) * time.Second)
+
 	// Override defaults with values from CLI.
 	exporterConfig.SetOrDefaultCouchAddress(*couchAddr)
 	exporterConfig.SetOrDefaultCouchPort(*couchPort)
 	exporterConfig.SetOrDefaultCouchUser(*userFlag)
 	exporterConfig.SetOrDefaultCouchPassword(*passFlag)
+	exporterConfig.SetOrDefaultCouchAuth(*authMode, *tokenFile)
+	exporterConfig.SetOrDefaultSPNEGOCommand(*spnegoCommand)
 	exporterConfig.SetOrDefaultServerAddress(*svrAddr)
 	exporterConfig.SetOrDefaultServerPort(*svrPort)
+	exporterConfig.SetOrDefaultListenAddresses(listenAddresses)
+	exporterConfig.SetOrDefaultAllowedCIDRs(*allowedCIDRs)
+	exporterConfig.SetOrDefaultMaxRequests(*maxRequests)
+	exporterConfig.SetOrDefaultAdminTokenFile(*adminTokenFile)
 	exporterConfig.SetOrDefaultRefreshRate(*refreshTime)
+	exporterConfig.SetOrDefaultMaxIdleConnsPerHost(*maxIdleConns)
+	exporterConfig.SetOrDefaultConnMaxLifetime(*connMaxLifetime)
+	exporterConfig.SetOrDefaultRequestTimeout(*requestTimeout)
+	exporterConfig.SetOrDefaultRequestIDs(*requestIDs)
+	exporterConfig.SetOrDefaultLabelCacheTTL(*labelCacheTTL)
 	exporterConfig.SetOrDefaultBackoffLimit(*backOffLimit)
 	exporterConfig.SetOrDefaultToken(*tokenFlag)
 	exporterConfig.SetOrDefaultCa(*ca)
@@ -125,6 +328,79 @@ func main() {
 	exporterConfig.SetOrDefaultKey(*key)
 	exporterConfig.SetOrDefaultClientCertificate(*clientCert)
 	exporterConfig.SetOrDefaultClientKey(*clientKey)
+	exporterConfig.SetOrDefaultFIPS(*fips)
+	exporterConfig.SetOrDefaultSnapshotFile(*snapshotFile)
+	exporterConfig.SetOrDefaultSnapshotMaxAge(*snapshotMaxAge)
+	exporterConfig.SetOrDefaultTextfilePath(*textfilePath)
+	exporterConfig.SetOrDefaultRecordDir(*recordDir)
+	exporterConfig.SetOrDefaultReplayDir(*replayDir)
+	exporterConfig.SetOrDefaultFaults(*faultLatency, *faultErrorRate, *faultMalformed)
+	exporterConfig.SetOrDefaultDebugCaptureEndpoints(*captureEndpoints)
+	exporterConfig.SetOrDefaultDebugCaptureWindow(*captureWindow)
+	exporterConfig.SetOrDefaultNodeStripPort(*nodeStripPort)
+	exporterConfig.SetOrDefaultNodeHostnameForm(*nodeHostnameForm)
+	exporterConfig.SetOrDefaultLabels(staticLabels)
+	exporterConfig.SetOrDefaultOwnerLabelsFile(*ownerLabelsFile)
+	exporterConfig.SetOrDefaultCompat(*compat)
+	exporterConfig.SetOrDefaultMetricLint(*metricLint)
+	exporterConfig.SetOrDefaultMetricNames(*metricNames)
+	exporterConfig.SetOrDefaultSeriesLimit(*seriesLimit)
+	exporterConfig.SetOrDefaultSlowQueries(*slowQueries)
+	exporterConfig.SetOrDefaultPreparedStatements(*preparedStmts)
+	exporterConfig.SetOrDefaultHotKeys(*hotKeys)
+	exporterConfig.SetOrDefaultHotKeysTop(*hotKeysTop)
+	exporterConfig.SetOrDefaultKVConnections(*kvConnections)
+	exporterConfig.SetOrDefaultClientErrors(*clientErrors)
+	exporterConfig.SetOrDefaultServiceProbes(*serviceProbes)
+	exporterConfig.SetOrDefaultSlowBucketThreshold(*slowBuckets)
+	exporterConfig.SetOrDefaultHealth(*health)
+	exporterConfig.SetOrDefaultClusterRole(*clusterRole)
+	exporterConfig.SetOrDefaultSuppressOnStandby(*suppressStandby)
+	exporterConfig.SetOrDefaultDelta(*deltaExport)
+	exporterConfig.SetOrDefaultClusterMode(*clusterMode)
+	exporterConfig.SetOrDefaultNodeName(*nodeName)
+	exporterConfig.SetOrDefaultPerNodeScope(*perNodeScope)
+	exporterConfig.SetOrDefaultWaitForRebalance(*waitRebalance)
+	exporterConfig.SetOrDefaultUndefinedSamples(*undefinedSamples)
+	exporterConfig.SetOrDefaultWindowAggregates(*windowAggregates)
+	exporterConfig.SetOrDefaultExemplars(*exemplars)
+	exporterConfig.SetOrDefaultSidecar(*sidecar)
+	exporterConfig.SetOrDefaultCapellaAPIKey(*capellaAPIKey)
+
+	if err := util.ValidateStaticLabels(exporterConfig.Labels); err != nil {
+		return nil, err
+	}
+
+	if _, err := exporterGatherer(exporterConfig, exporterConfig.MetricNames); err != nil {
+		return nil, err
+	}
+
+	if err := exporterConfig.ValidateClusters(); err != nil {
+		return nil, err
+	}
+
+	if err := exporterConfig.DebugCapture.Validate(); err != nil {
+		return nil, err
+	}
+
+	if exporterConfig.RequestTimeout > exporterConfig.RefreshRate {
+		log.Warn("the request timeout of %ds is longer than the refresh interval of %ds, so slow requests are abandoned at the refresh interval",
+			exporterConfig.RequestTimeout, exporterConfig.RefreshRate)
+	}
+
+	return exporterConfig, nil
+}
+
+// runServe implements the serve command, collecting metrics from Couchbase
+// Server and serving them until the exporter is stopped.
+func runServe(args []string) int {
+	exporterConfig, err := loadConfig(args)
+	if err != nil {
+		log.Error("%s", err)
+		writeToTerminationLog(err)
+
+		return 1
+	}
 
 	// This is if we want to dump the config to stdout to generate a configuration file.
 	if *defaultConfig {
@@ -132,11 +408,13 @@ func main() {
 		if err != nil {
 			log.Error("Error generating Json config file.  Exiting")
 			writeToTerminationLog(err)
-			os.Exit(1)
+
+			return 1
 		}
 
 		os.Stdout.WriteString(string(c))
-		os.Exit(0)
+
+		return 0
 	}
 
 	log.Info("Starting %s: %s", version.Application, version.WithBuildNumberAndRevision())
@@ -144,47 +422,535 @@ func main() {
 
 	log.Info("Couchbase Address:  %s:%v", exporterConfig.CouchbaseAddress, exporterConfig.CouchbasePort)
 
+	util.SetFIPS(exporterConfig.FIPS)
+
+	if util.FIPSEnabled() {
+		log.Info("FIPS mode: TLS restricted to FIPS approved algorithms")
+	}
+
 	log.Info("Starting metrics collection...")
 
-	client, err := createClient(exporterConfig)
+	// the capture is shared by the clients of every cluster, so that the admin
+	// API starts and stops it for all of them.
+	capture, err := util.NewCapture(exporterConfig.DebugCapture)
 	if err != nil {
 		log.Error("%s", err)
 		writeToTerminationLog(err)
 		os.Exit(1)
 	}
 
-	labelManager := util.NewLabelManager(client, 600*time.Second)
+	client, err := createClient(exporterConfig, capture)
+	if err != nil {
+		log.Error("%s", err)
+		writeToTerminationLog(err)
+		os.Exit(1)
+	}
 
-	log.Info("Registering Collectors...")
+	if exporterConfig.Sidecar.Enabled {
+		log.Info("Waiting for the node to be initialized...")
 
-	prometheus.MustRegister(collectors.NewNodesCollector(client, exporterConfig.Collectors.Node, labelManager))
-	prometheus.MustRegister(collectors.NewBucketInfoCollector(client, exporterConfig.Collectors.BucketInfo, labelManager))
-	prometheus.MustRegister(collectors.NewTaskCollector(client, exporterConfig.Collectors.Task, labelManager))
+		retries := exporterConfig.Sidecar.InitTimeout / int(sidecarInitInterval.Seconds())
+		if retries < 1 {
+			retries = 1
+		}
 
-	prometheus.MustRegister(collectors.NewQueryCollector(client, exporterConfig.Collectors.Query, labelManager))
-	prometheus.MustRegister(collectors.NewIndexCollector(client, exporterConfig.Collectors.Index, labelManager))
-	prometheus.MustRegister(collectors.NewFTSCollector(client, exporterConfig.Collectors.Search, labelManager))
-	prometheus.MustRegister(collectors.NewCbasCollector(client, exporterConfig.Collectors.Analytics, labelManager))
-	prometheus.MustRegister(collectors.NewEventingCollector(client, exporterConfig.Collectors.Eventing, labelManager))
+		if err := util.WaitForNodeInit(context.Background(), client, sidecarInitInterval, retries); err != nil {
+			log.Error("%s", err)
+			writeToTerminationLog(err)
+			os.Exit(1)
+		}
+	}
 
-	// moved to a goroutine to improve startup time.
 	// Create my cycle controller with refreshrate (seconds) in milliseconds
 	cycle := util.NewCycleController(exporterConfig.RefreshRate * 1000)
-	perNodeBucketStatCollector := collectors.NewPerNodeBucketStatsCollector(client, exporterConfig.Collectors.PerNodeBucketStats, labelManager)
-	prometheus.MustRegister(&perNodeBucketStatCollector)
-	
-	bucketStatCollector := collectors.NewBucketStatsCollector(client, exporterConfig.Collectors.BucketStats, labelManager)
-	prometheus.MustRegister(&bucketStatCollector)
-	
-	cycle.Subscribe(&perNodeBucketStatCollector)
-	cycle.Subscribe(&bucketStatCollector)
+	collectorSwitch := collectors.NewCollectorSwitch()
+	registerer := util.NewLintingRegisterer(prometheus.DefaultRegisterer, exporterConfig.MetricLint)
+
+	enabledCollectors, snapshotters, err := collectCluster(client, exporterConfig, objects.ClusterConfig{}, prometheus.DefaultRegisterer, cycle, collectorSwitch)
+	if err != nil {
+		log.Error("%s", err)
+		writeToTerminationLog(err)
+		os.Exit(1)
+	}
+
+	if exporterConfig.Capella.Enabled() {
+		capellaClient := util.NewCapellaClient(exporterConfig.Capella.URL, exporterConfig.Capella.OrganizationID, exporterConfig.Capella.APIKey)
+		capellaCollector := collectors.NewCapellaCollector(capellaClient, exporterConfig.Capella.Clusters, exporterConfig.Collectors.Capella,
+			util.NewLabelManagerWithHostnames(client, exporterConfig.LabelCacheDuration(), util.NewHostnameNormalizer(exporterConfig.NodeHostnames)))
+
+		capellaCollector = collectors.WithDeadline("", exporterConfig.Collectors.Capella.Name, capellaCollector, exporterConfig.CollectorDeadline())
+		if err := registerer.Register(collectorSwitch.Collector("", exporterConfig.Collectors.Capella.Name, capellaCollector)); err != nil {
+			log.Error("failed to register the %s collector: %s", exporterConfig.Collectors.Capella.Name, err)
+			writeToTerminationLog(err)
+			os.Exit(1)
+		}
+
+		enabledCollectors = append(enabledCollectors, exporterConfig.Collectors.Capella.Name)
+	}
+
+	// every other cluster is collected into a registry of its own, as the
+	// collectors of each describe the same metrics, and on a cycle of its own,
+	// so that a slow cluster does not hold up the others.
+	clusterTargets := []handlers.Target{}
+	clusterCycles := []util.CycleController{}
+
+	for _, cluster := range exporterConfig.Clusters {
+		clusterConfig := exporterConfig.ForCluster(cluster)
+
+		log.Info("Couchbase Address of cluster %s:  %s:%v", cluster.Name, clusterConfig.CouchbaseAddress, clusterConfig.CouchbasePort)
+
+		clusterClient, err := createClient(clusterConfig, capture)
+		if err != nil {
+			err = fmt.Errorf("cluster %s: %w", cluster.Name, err)
+			log.Error("%s", err)
+			writeToTerminationLog(err)
+			os.Exit(1)
+		}
+
+		registry := prometheus.NewRegistry()
+		clusterCycle := util.NewClusterCycleController(cluster.Name, exporterConfig.RefreshRate*1000)
+
+		_, clusterSnapshotters, err := collectCluster(clusterClient, clusterConfig, cluster, registry, clusterCycle, collectorSwitch)
+		if err != nil {
+			err = fmt.Errorf("cluster %s: %w", cluster.Name, err)
+			log.Error("%s", err)
+			writeToTerminationLog(err)
+			os.Exit(1)
+		}
+
+		snapshotters = append(snapshotters, clusterSnapshotters...)
+		clusterCycles = append(clusterCycles, clusterCycle)
+
+		clusterGatherers = append(clusterGatherers, util.NewClusterRoleGatherer(registry, clusterConfig.ClusterRole.Role))
+		clusterTargets = append(clusterTargets, clusterTarget(clusterClient, clusterConfig))
+	}
+
+	// restore the last known values before the first cycle, then keep the snapshot
+	// up to date at the end of every cycle.
+	if exporterConfig.SnapshotFile != "" {
+		snapshotWriter := collectors.NewSnapshotWriter(exporterConfig.SnapshotFile, time.Duration(exporterConfig.SnapshotMaxAge)*time.Second,
+			snapshotters...)
+
+		if err := snapshotWriter.Restore(); err != nil {
+			log.Warn("Not restoring metrics snapshot: %s", err)
+		}
+
+		cycle.Subscribe(snapshotWriter)
+	}
+
+	for _, clusterCycle := range clusterCycles {
+		clusterCycle.Start()
+	}
+
+	// in textfile mode we never listen on a port, the file is rewritten at the end
+	// of every cycle instead.
+	if exporterConfig.TextfilePath != "" {
+		gatherer, _ := exporterGatherer(exporterConfig, exporterConfig.MetricNames)
+
+		textfileWriter, err := util.NewTextfileWriter(exporterConfig.TextfilePath, gatherer)
+		if err != nil {
+			log.Error("%s", err)
+			writeToTerminationLog(err)
+			os.Exit(1)
+		}
+
+		cycle.Subscribe(textfileWriter)
+		cycle.Start()
+
+		if err := util.NotifySystemd("READY=1"); err != nil {
+			log.Warn("%s", err)
+		}
+
+		log.Info("Writing metrics to %s every %d seconds", exporterConfig.TextfilePath, exporterConfig.RefreshRate)
+
+		select {}
+	}
+
 	cycle.Start()
 
 	log.Info("Serving all exposed endpoints...")
 
+	info := exporterInfo(client, exporterConfig, enabledCollectors)
+	info.Targets = append(info.Targets, clusterTargets...)
+
 	for {
-		serveHandlers(client, exporterConfig)
+		serveHandlers(client, exporterConfig, info, collectorSwitch, capture)
+	}
+}
+
+// clusterTarget describes the cluster client connects to for the landing
+// page.
+func clusterTarget(client util.Client, exporterConfig *objects.ExporterConfig) handlers.Target {
+	target := handlers.Target{URL: strings.TrimSuffix(client.URL(""), "/"), Auth: exporterConfig.CouchbaseAuth.Mode}
+	if target.Auth == objects.AuthModeBasic {
+		target.User = exporterConfig.CouchbaseUser
 	}
+
+	return target
+}
+
+// collectCluster registers the collectors of a cluster with registerer, and
+// subscribes those that collect in the background to cycle.  It returns the
+// names of the collectors enabled and those that can be snapshotted.
+func collectCluster(cbClient util.Client, exporterConfig *objects.ExporterConfig, cluster objects.ClusterConfig, registerer prometheus.Registerer,
+	cycle util.CycleController, collectorSwitch *collectors.CollectorSwitch) ([]string, []collectors.Snapshotter, error) {
+	client, err := util.NewBucketFilterClient(cbClient, exporterConfig.Buckets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labelManager := util.NewLabelManagerWithHostnames(client, exporterConfig.LabelCacheDuration(), util.NewHostnameNormalizer(exporterConfig.NodeHostnames))
+
+	log.Info("Checking user permissions...")
+
+	permissions := collectors.ProbePermissions(client, exporterConfig.CouchbaseUser, cluster.Name, &exporterConfig.Collectors, exporterConfig.CollectorDeadline())
+
+	log.Info("Registering Collectors...")
+
+	enabledCollectors := []string{}
+	snapshotters := []collectors.Snapshotter{}
+	registerer = util.NewLintingRegisterer(registerer, exporterConfig.MetricLint)
+
+	// a collector is collected if the user may read its endpoints and the
+	// cluster does not disable it.
+	enabled := func(config *objects.CollectorConfig) bool {
+		return permissions.Enabled(config) && (config == nil || cluster.CollectorEnabled(config.Name))
+	}
+
+	var registerErr error
+
+	registerCollector := func(name string, collector prometheus.Collector) {
+		collector = collectors.WithDeadline(cluster.Name, name, collector, exporterConfig.CollectorDeadline())
+		if err := registerer.Register(collectorSwitch.Collector(cluster.Name, name, collector)); err != nil && registerErr == nil {
+			registerErr = fmt.Errorf("failed to register the %s collector: %w", name, err)
+		}
+	}
+
+	register := func(config *objects.CollectorConfig, collector prometheus.Collector) {
+		if enabled(config) {
+			registerCollector(config.Name, collector)
+
+			enabledCollectors = append(enabledCollectors, config.Name)
+		}
+	}
+
+	register(exporterConfig.Collectors.Node, collectors.NewNodesCollector(client, exporterConfig.Collectors.Node, labelManager))
+	register(exporterConfig.Collectors.BucketInfo, collectors.NewBucketInfoCollector(client, exporterConfig.Collectors.BucketInfo, labelManager))
+	register(exporterConfig.Collectors.Task, collectors.NewTaskCollector(client, exporterConfig.Collectors.Task, labelManager))
+
+	register(exporterConfig.Collectors.Query, collectors.NewQueryCollector(client, exporterConfig.Collectors.Query, labelManager))
+	register(exporterConfig.Collectors.Index, collectors.NewIndexCollector(client, exporterConfig.Collectors.Index, labelManager))
+	register(exporterConfig.Collectors.Search, collectors.NewFTSCollector(client, exporterConfig.Collectors.Search, labelManager))
+	register(exporterConfig.Collectors.Analytics, collectors.NewCbasCollector(client, exporterConfig.Collectors.Analytics, labelManager))
+	register(exporterConfig.Collectors.Eventing, collectors.NewEventingCollector(client, exporterConfig.Collectors.Eventing, labelManager))
+	register(exporterConfig.Collectors.Alerts, collectors.NewAlertsCollector(client, exporterConfig.Collectors.Alerts, labelManager))
+	register(exporterConfig.Collectors.Audit, collectors.NewAuditCollector(client, exporterConfig.Collectors.Audit, labelManager))
+	register(exporterConfig.Collectors.Security, collectors.NewSecurityCollector(client, exporterConfig.Collectors.Security, labelManager))
+	register(exporterConfig.Collectors.Backup, collectors.NewBackupCollector(client, exporterConfig.Collectors.Backup, labelManager))
+	register(exporterConfig.Collectors.Views, collectors.NewViewsCollector(client, exporterConfig.Collectors.Views, labelManager))
+	register(exporterConfig.Collectors.Rollup, collectors.NewRollupCollector(client, exporterConfig.Collectors.Rollup, labelManager))
+
+	if exporterConfig.SlowQueries.Enabled {
+		register(exporterConfig.Collectors.SlowQueries,
+			collectors.NewSlowQueriesCollector(client, exporterConfig.SlowQueries.Buckets, exporterConfig.Collectors.SlowQueries, labelManager))
+	}
+
+	if exporterConfig.PreparedStatements {
+		register(exporterConfig.Collectors.Prepared, collectors.NewPreparedCollector(client, exporterConfig.Collectors.Prepared, labelManager))
+	}
+
+	if exporterConfig.HotKeys.Enabled {
+		register(exporterConfig.Collectors.HotKeys,
+			collectors.NewHotKeysCollector(client, exporterConfig.HotKeys.Top, exporterConfig.Collectors.HotKeys, labelManager))
+	}
+
+	if exporterConfig.KVConnections {
+		register(exporterConfig.Collectors.KVConnections, collectors.NewKVConnectionsCollector(client, exporterConfig.Collectors.KVConnections, labelManager))
+	}
+
+	if exporterConfig.ClientErrors {
+		register(exporterConfig.Collectors.ClientErrors, collectors.NewClientErrorsCollector(client, exporterConfig.Collectors.ClientErrors, labelManager))
+	}
+
+	if exporterConfig.ServiceProbes.Enabled && cluster.CollectorEnabled(objects.ServiceProbesCollector) {
+		prober := util.NewServiceProber(cbClient, time.Duration(exporterConfig.ServiceProbes.Timeout)*time.Second)
+		registerCollector(objects.ServiceProbesCollector, collectors.NewServiceProbesCollector(client, prober, labelManager))
+
+		enabledCollectors = append(enabledCollectors, objects.ServiceProbesCollector)
+	}
+
+	if exporterConfig.Credentials.Check {
+		cycle.Subscribe(collectors.NewCredentialsCheck(client, exporterConfig.Credentials))
+	}
+
+	if enabled(exporterConfig.Collectors.PerNodeBucketStats) {
+		perNodeBucketStatCollector := collectors.NewPerNodeBucketStatsCollector(client, exporterConfig.Collectors.PerNodeBucketStats, labelManager)
+		perNodeBucketStatCollector.SetCluster(cluster.Name)
+		perNodeBucketStatCollector.SetClusterMode(exporterConfig.CollectAllNodes())
+		perNodeBucketStatCollector.SetNodeName(exporterConfig.NodeName)
+		perNodeBucketStatCollector.SetWaitForRebalance(exporterConfig.WaitForRebalance)
+		perNodeBucketStatCollector.SetUndefinedSamples(exporterConfig.UndefinedSamples)
+		perNodeBucketStatCollector.SetExemplars(exporterConfig.Exemplars)
+
+		if err := perNodeBucketStatCollector.SetBucketPriority(exporterConfig.BucketPriority); err != nil {
+			return nil, nil, err
+		}
+
+		perNodeBucketStatCollector.SetSlowBuckets(exporterConfig.SlowBuckets)
+
+		registerCollector(exporterConfig.Collectors.PerNodeBucketStats.Name, &perNodeBucketStatCollector)
+		cycle.Subscribe(collectorSwitch.Worker(cluster.Name, exporterConfig.Collectors.PerNodeBucketStats.Name,
+			collectors.WorkerWithDeadline(cluster.Name, exporterConfig.Collectors.PerNodeBucketStats.Name, &perNodeBucketStatCollector,
+				exporterConfig.CollectorDeadline())))
+
+		enabledCollectors = append(enabledCollectors, exporterConfig.Collectors.PerNodeBucketStats.Name)
+
+		snapshotters = append(snapshotters, &perNodeBucketStatCollector)
+	}
+
+	if enabled(exporterConfig.Collectors.BucketStats) {
+		bucketStatCollector := collectors.NewBucketStatsCollector(client, exporterConfig.Collectors.BucketStats, labelManager)
+		bucketStatCollector.SetCluster(cluster.Name)
+		bucketStatCollector.SetWindowAggregates(exporterConfig.WindowAggregates)
+
+		if err := bucketStatCollector.SetBucketPriority(exporterConfig.BucketPriority); err != nil {
+			return nil, nil, err
+		}
+
+		bucketStatCollector.SetSlowBuckets(exporterConfig.SlowBuckets)
+
+		registerCollector(exporterConfig.Collectors.BucketStats.Name, &bucketStatCollector)
+		cycle.Subscribe(collectorSwitch.Worker(cluster.Name, exporterConfig.Collectors.BucketStats.Name,
+			collectors.WorkerWithDeadline(cluster.Name, exporterConfig.Collectors.BucketStats.Name, &bucketStatCollector,
+				exporterConfig.CollectorDeadline())))
+
+		enabledCollectors = append(enabledCollectors, exporterConfig.Collectors.BucketStats.Name)
+
+		snapshotters = append(snapshotters, &bucketStatCollector)
+	}
+
+	return enabledCollectors, snapshotters, registerErr
+}
+
+// exporterInfo describes the exporter for the landing page, leaving out any
+// credentials.
+func exporterInfo(client util.Client, exporterConfig *objects.ExporterConfig, enabledCollectors []string) *handlers.ExporterInfo {
+	targets := []handlers.Target{clusterTarget(client, exporterConfig)}
+
+	for _, cluster := range exporterConfig.Capella.Clusters {
+		targets = append(targets, handlers.Target{
+			URL: fmt.Sprintf("%s/v4/organizations/%s/projects/%s/clusters/%s", strings.TrimSuffix(exporterConfig.Capella.URL, "/"),
+				exporterConfig.Capella.OrganizationID, cluster.ProjectID, cluster.ClusterID),
+			Auth: objects.AuthModeBearer,
+		})
+	}
+
+	enabled := map[string]bool{}
+	for _, name := range enabledCollectors {
+		enabled[name] = true
+	}
+
+	catalog := []objects.CatalogEntry{}
+
+	for _, entry := range exporterConfig.Collectors.Catalog() {
+		if enabled[entry.Collector] || entry.Collector == objects.SelfMetricsCollector {
+			catalog = append(catalog, entry)
+		}
+	}
+
+	return &handlers.ExporterInfo{
+		Version:    version.WithBuildNumberAndRevision(),
+		Collectors: enabledCollectors,
+		Targets:    targets,
+		Catalog:    catalog,
+	}
+}
+
+// runVersion implements the version command.
+func runVersion(args []string) int {
+	flags := flag.NewFlagSet("version", flag.ExitOnError)
+
+	if err := cli.Parse(flags, args); err != nil {
+		log.Error("%s", err)
+		return 1
+	}
+
+	fmt.Printf("%s %s\n", version.Application, version.WithBuildNumberAndRevision())
+
+	return 0
+}
+
+// runCheckConfig implements the check-config command, taking the flags of
+// serve and reporting whether the exporter would start with them.
+func runCheckConfig(args []string) int {
+	exporterConfig, err := loadConfig(args)
+	if err != nil {
+		log.Error("%s", err)
+		return 1
+	}
+
+	if _, err := util.NewAllowlistHandler(exporterConfig.AllowedCIDRs, http.NotFoundHandler()); err != nil {
+		log.Error("%s", err)
+		return 1
+	}
+
+	if exporterConfig.Certificate != "" || exporterConfig.Key != "" {
+		if _, err := tls.LoadX509KeyPair(exporterConfig.Certificate, exporterConfig.Key); err != nil {
+			log.Error("invalid certificate and key: %s", err)
+			return 1
+		}
+	}
+
+	fmt.Println("configuration is valid")
+
+	return 0
+}
+
+// runDashboards implements the dashboards subcommand, writing one Grafana
+// dashboard per file named after the metrics the given config would export.
+func runDashboards(args []string) int {
+	flags := flag.NewFlagSet("dashboards", flag.ExitOnError)
+	dashboardConfig := flags.String("config", "", "The location of the PE configuration, so that dashboards use any renamed metrics")
+	outputDir := flags.String("output-dir", ".", "directory to write the generated dashboards to")
+
+	if err := cli.Parse(flags, args); err != nil {
+		log.Error("%s", err)
+		return 1
+	}
+
+	exporterConfig, err := config.New(*dashboardConfig)
+	if err != nil {
+		log.Error("Error loading config file.")
+		return 1
+	}
+
+	for name, dashboard := range dashboards.Generate(exporterConfig) {
+		c, err := json.MarshalIndent(dashboard, "", "  ")
+		if err != nil {
+			log.Error("Error generating dashboard %s: %s", name, err)
+			return 1
+		}
+
+		path := filepath.Join(*outputDir, name+".json")
+		if err := ioutil.WriteFile(path, c, 0644); err != nil {
+			log.Error("Error writing dashboard %s: %s", path, err)
+			return 1
+		}
+
+		fmt.Println(path)
+	}
+
+	return 0
+}
+
+// runRules implements the rules subcommand, emitting Prometheus alerting rules
+// either as a plain rule file or wrapped in a prometheus-operator PrometheusRule.
+func runRules(args []string) int {
+	defaults := rules.DefaultThresholds()
+
+	flags := flag.NewFlagSet("rules", flag.ExitOnError)
+	rulesConfig := flags.String("config", "", "The location of the PE configuration, so that rules use any renamed metrics")
+	format := flags.String("format", "rules", "output format, either rules or prometheusrule")
+	name := flags.String("name", "couchbase-exporter-rules", "metadata name of the generated PrometheusRule")
+	output := flags.String("output", "", "file to write the rules to, defaults to stdout")
+	nodeDownFor := flags.Duration("node-down-for", defaults.NodeDownFor, "how long a node must be unhealthy before alerting")
+	residentRatio := flags.Float64("resident-ratio", defaults.ResidentRatio, "active resident ratio percentage below which to alert")
+	diskQueue := flags.Float64("disk-queue", defaults.DiskQueue, "disk write queue length above which growth is alerted on")
+	rebalanceStuckFor := flags.Duration("rebalance-stuck-for", defaults.RebalanceStuckFor, "how long rebalance progress may remain unchanged before alerting")
+
+	if err := cli.Parse(flags, args); err != nil {
+		log.Error("%s", err)
+		return 1
+	}
+
+	exporterConfig, err := config.New(*rulesConfig)
+	if err != nil {
+		log.Error("Error loading config file.")
+		return 1
+	}
+
+	ruleFile := rules.Generate(exporterConfig, rules.Thresholds{
+		NodeDownFor:       *nodeDownFor,
+		ResidentRatio:     *residentRatio,
+		DiskQueue:         *diskQueue,
+		RebalanceStuckFor: *rebalanceStuckFor,
+	})
+
+	var doc interface{}
+
+	switch *format {
+	case "rules":
+		doc = ruleFile
+	case "prometheusrule":
+		doc = ruleFile.AsPrometheusRule(*name)
+	default:
+		log.Error("Unknown rules format %s", *format)
+		return 1
+	}
+
+	c, err := rules.Marshal(doc)
+	if err != nil {
+		log.Error("Error generating rules: %s", err)
+		return 1
+	}
+
+	if *output == "" {
+		fmt.Print(string(c))
+		return 0
+	}
+
+	if err := ioutil.WriteFile(*output, c, 0644); err != nil {
+		log.Error("Error writing rules %s: %s", *output, err)
+		return 1
+	}
+
+	return 0
+}
+
+// runListMetrics implements the list-metrics subcommand, printing every metric
+// the given config would export along with where its value comes from.
+func runListMetrics(args []string) int {
+	flags := flag.NewFlagSet("list-metrics", flag.ExitOnError)
+	listConfig := flags.String("config", "", "The location of the PE configuration, so that the list reflects renamed and disabled metrics")
+	format := flags.String("format", "text", "output format, either text or json")
+
+	if err := cli.Parse(flags, args); err != nil {
+		log.Error("%s", err)
+		return 1
+	}
+
+	exporterConfig, err := config.New(*listConfig)
+	if err != nil {
+		log.Error("Error loading config file.")
+		return 1
+	}
+
+	catalog := exporterConfig.Collectors.Catalog()
+
+	switch *format {
+	case "text":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tLABELS\tENDPOINT\tHELP")
+
+		for _, entry := range catalog {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Name, entry.Type, strings.Join(entry.Labels, ","), entry.Endpoint, entry.Help)
+		}
+
+		_ = w.Flush()
+	case "json":
+		c, err := json.MarshalIndent(catalog, "", "  ")
+		if err != nil {
+			log.Error("Error generating metrics list: %s", err)
+			return 1
+		}
+
+		fmt.Println(string(c))
+	default:
+		log.Error("Unknown metrics list format %s", *format)
+		return 1
+	}
+
+	return 0
 }
 
 func writeToTerminationLog(mainErr error) {
@@ -208,8 +974,58 @@ func writeToTerminationLog(mainErr error) {
 	}
 }
 
+// exporterGatherer labels the series of each cluster with its role, limits the
+// series of each metric, guards their values, adds the health summary, drops
+// the metrics suppressed on standbys, then applies the given legacy or
+// corrected metric names, the naming scheme, the configured relabel rules and
+// the static labels to everything registered.
+func exporterGatherer(exporterConfig *objects.ExporterConfig, metricNames string) (prometheus.Gatherer, error) {
+	rules, err := objects.CompatRules(exporterConfig.Compat)
+	if err != nil {
+		return nil, err
+	}
+
+	top := util.NewClusterRoleGatherer(prometheus.DefaultGatherer, exporterConfig.ClusterRole.Role)
+	limited := util.NewSeriesLimitGatherer(append(prometheus.Gatherers{top}, clusterGatherers...), exporterConfig.SeriesLimit)
+
+	guarded, err := util.NewValueGuardGatherer(limited, exporterConfig.ValueGuards)
+	if err != nil {
+		return nil, err
+	}
+
+	derived, err := util.NewDerivedGatherer(guarded, exporterConfig.DerivedMetrics)
+	if err != nil {
+		return nil, err
+	}
+
+	health, err := util.NewHealthGatherer(derived, exporterConfig.Health)
+	if err != nil {
+		return nil, err
+	}
+
+	standby := util.NewStandbyGatherer(health, exporterConfig.ClusterRole)
+
+	named, err := util.NewNamingGatherer(standby, metricNames)
+	if err != nil {
+		return nil, err
+	}
+
+	owned, err := util.NewOwnerLabelGatherer(named, exporterConfig.OwnerLabelsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	gatherer, err := util.NewRelabelGatherer(owned, append(rules, exporterConfig.Relabel...))
+	if err != nil {
+		return nil, err
+	}
+
+	return util.NewStaticLabelGatherer(gatherer, exporterConfig.Labels), nil
+}
+
 // serve all endpoints registered on the HTTP server.
-func serveHandlers(client util.Client, exporterConfig *objects.ExporterConfig) {
+func serveHandlers(client util.Client, exporterConfig *objects.ExporterConfig, info *handlers.ExporterInfo, collectorSwitch *collectors.CollectorSwitch,
+	capture *util.Capture) {
 	defer func() {
 		if r := recover(); r != nil {
 			log.Warn("Recovered in serveHandlers(): %s", r)
@@ -224,14 +1040,94 @@ func serveHandlers(client util.Client, exporterConfig *objects.ExporterConfig) {
 		handler.TokenLocation = exporterConfig.Token
 	}
 
-	handler.ServeMux.Handle("/metrics", promhttp.Handler())
+	gatherer, _ := exporterGatherer(exporterConfig, exporterConfig.MetricNames)
+
+	// the landing page reports how the last scrape of /metrics went.
+	info.Scrapes = util.NewScrapeStatusGatherer(gatherer)
+	metricsHandler := util.NewLimitHandler(exporterConfig.MaxRequests, util.NewMetricsHandler(info.Scrapes))
+
+	metricsHandler, err := util.NewAllowlistHandler(exporterConfig.AllowedCIDRs, metricsHandler)
+	if err != nil {
+		log.Error("%s", err)
+		os.Exit(1)
+	}
+
+	handler.ServeMux.Handle("/metrics", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, metricsHandler))
+
+	// the corrected metric names are always served on /metrics/v2, so that
+	// dashboards and alerts can be migrated before /metrics is switched.
+	correctedGatherer, _ := exporterGatherer(exporterConfig, objects.MetricNamesCorrected)
+	correctedHandler := util.NewLimitHandler(exporterConfig.MaxRequests, util.NewMetricsHandler(correctedGatherer))
+
+	correctedHandler, err = util.NewAllowlistHandler(exporterConfig.AllowedCIDRs, correctedHandler)
+	if err != nil {
+		log.Error("%s", err)
+		os.Exit(1)
+	}
+
+	handler.ServeMux.Handle("/metrics/v2", promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, correctedHandler))
+
+	// the delta keeps the series served by its last scrape, so it has a
+	// gatherer of its own.
+	if exporterConfig.Delta.Enabled {
+		deltaGatherer := util.NewDeltaGatherer(gatherer, time.Duration(exporterConfig.Delta.FullInterval)*time.Second)
+		deltaHandler := util.NewLimitHandler(exporterConfig.MaxRequests, util.NewMetricsHandler(deltaGatherer))
+
+		deltaHandler, err = util.NewAllowlistHandler(exporterConfig.AllowedCIDRs, deltaHandler)
+		if err != nil {
+			log.Error("%s", err)
+			os.Exit(1)
+		}
+
+		handler.ServeMux.Handle(objects.DeltaPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, deltaHandler))
+	}
 
 	handler.ServeMux.HandleFunc("/readiness-probe", handlers.Readyz(client))
+	handler.ServeMux.HandleFunc("/healthz", handlers.Healthz())
+	handler.ServeMux.HandleFunc("/debug", handlers.Debug(info))
+
+	// the snapshot holds every series, so it is restricted like /metrics.
+	snapshotHandler, err := util.NewAllowlistHandler(exporterConfig.AllowedCIDRs, handlers.Snapshot(info))
+	if err != nil {
+		log.Error("%s", err)
+		os.Exit(1)
+	}
 
-	metricsServer := fmt.Sprintf("%v:%v", exporterConfig.ServerAddress, exporterConfig.ServerPort)
-	log.Info("starting server on %s", metricsServer)
+	handler.ServeMux.Handle(handlers.SnapshotPath, snapshotHandler)
+	handler.ServeMux.HandleFunc("/", handlers.Landing(info))
 
-	util.Serve(metricsServer, handler, exporterConfig.Certificate, exporterConfig.Key)
+	// sockets passed by systemd take the place of the configured addresses.
+	listeners := util.ActivatedSockets()
+	if len(listeners) == 0 {
+		listeners = exporterConfig.Listeners()
+	}
+
+	log.Info("starting server on %s", strings.Join(listeners, ", "))
+
+	util.ServeAll(listeners, adminHandlers(handler, exporterConfig, collectorSwitch, capture), exporterConfig.Certificate, exporterConfig.Key)
+}
+
+// adminHandlers serves the admin API and the recent logs beside handler, if
+// an admin token is configured.  The admin API has its own token, so it is
+// kept out of the handler that checks the token for /metrics.
+func adminHandlers(handler http.Handler, exporterConfig *objects.ExporterConfig, collectorSwitch *collectors.CollectorSwitch,
+	capture *util.Capture) http.Handler {
+	if exporterConfig.AdminTokenFile == "" {
+		return handler
+	}
+
+	admin := http.NewServeMux()
+	admin.HandleFunc(handlers.AdminCollectorsPath, handlers.AdminCollectors(collectorSwitch))
+	admin.HandleFunc(handlers.AdminCollectorsPath+"/", handlers.AdminCollectors(collectorSwitch))
+	admin.HandleFunc(handlers.AdminCapturePath, handlers.AdminCapture(capture))
+	admin.HandleFunc(handlers.AdminCapturePath+"/", handlers.AdminCapture(capture))
+
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	mux.Handle("/admin/", util.NewTokenHandler(exporterConfig.AdminTokenFile, admin))
+	mux.Handle(handlers.LogsPath, util.NewTokenHandler(exporterConfig.AdminTokenFile, handlers.Logs()))
+
+	return mux
 }
 
 func setTLSClientConfig(exporterConfig objects.ExporterConfig, tlsConfig *tls.Config) error {
@@ -261,13 +1157,19 @@ func setTLSClientConfig(exporterConfig objects.ExporterConfig, tlsConfig *tls.Co
 		return fmt.Errorf("%w", keypairError)
 	}
 
+	if util.FIPSEnabled() {
+		if err := util.CheckFIPSKeyPair(cert); err != nil {
+			return fmt.Errorf("client cert: %w", err)
+		}
+	}
+
 	tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
 
 	return nil
 }
 
 // create and config client connection to Couchbase Server.
-func createClient(exporterConfig *objects.ExporterConfig) (util.Client, error) {
+func createClient(exporterConfig *objects.ExporterConfig, capture *util.Capture) (util.Client, error) {
 	// Default to nil.
 	var tlsClientConfig = tls.Config{
 		RootCAs: x509.NewCertPool(),
@@ -275,6 +1177,10 @@ func createClient(exporterConfig *objects.ExporterConfig) (util.Client, error) {
 
 	var client util.Client
 
+	if err := exporterConfig.CouchbaseAuth.Validate(); err != nil {
+		return client, err
+	}
+
 	// Default to insecure.
 	scheme := "http"
 
@@ -293,10 +1199,54 @@ func createClient(exporterConfig *objects.ExporterConfig) (util.Client, error) {
 		return client, certError
 	}
 
+	if exporterConfig.CouchbaseAuth.Mode == objects.AuthModeCertificate && scheme != "https" {
+		return client, errCertAuth
+	}
+
 	couchFullAddress := fmt.Sprintf("%v://%v", scheme, exporterConfig.CouchbaseAddress)
 	log.Info("dial CB Server at %s:%d", couchFullAddress, exporterConfig.CouchbasePort)
 
-	client = util.NewClient(couchFullAddress, exporterConfig.CouchbasePort, exporterConfig.CouchbaseUser, exporterConfig.CouchbasePassword, &tlsClientConfig)
+	// every collector shares the one client, and so the one connection pool.
+	pool := util.NewTransport(&tlsClientConfig, exporterConfig.MaxIdleConnsPerHost)
+	go util.RecycleConnections(context.Background(), pool, time.Duration(exporterConfig.ConnMaxLifetime)*time.Second)
+
+	var transport http.RoundTripper = util.NewTraceTransport(pool)
+
+	refresh := time.Duration(exporterConfig.RefreshRate) * time.Second
+
+	if exporterConfig.ReplayDir != "" {
+		log.Info("replaying the responses recorded in %s", exporterConfig.ReplayDir)
+
+		replay, err := util.NewReplayTransport(exporterConfig.ReplayDir, refresh)
+		if err != nil {
+			return client, err
+		}
+
+		transport = replay
+	} else if exporterConfig.RecordDir != "" {
+		log.Info("recording the responses from Couchbase Server in %s", exporterConfig.RecordDir)
+
+		transport = util.NewRecordingTransport(exporterConfig.RecordDir, refresh, transport)
+	}
+
+	// faults are injected outside of any recording, so that it holds the real
+	// responses.
+	if exporterConfig.Faults.Enabled() {
+		log.Warn("injecting faults into requests to Couchbase Server: %+v", exporterConfig.Faults)
+
+		transport = util.NewFaultTransport(exporterConfig.Faults, transport)
+	}
+
+	// the capture is always in place, as it may be started through the admin
+	// API, and inside the request IDs so that it logs them.
+	transport = util.NewCaptureTransport(capture, transport)
+
+	if exporterConfig.RequestIDs {
+		transport = util.NewRequestIDTransport(transport)
+	}
+
+	client = util.NewClientWithAuth(couchFullAddress, exporterConfig.CouchbasePort, exporterConfig.CouchbaseAuth, exporterConfig.CouchbaseUser,
+		exporterConfig.CouchbasePassword, transport).WithTimeout(exporterConfig.RequestTimeoutDuration()).WithAuthBackoff(authBackoffRefreshes * refresh)
 
 	return client, nil
 }
@@ -0,0 +1,156 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// EnvPrefix is the prefix of the environment variables flags are read from.
+const EnvPrefix = "CB_EXPORTER_"
+
+// Command is a subcommand, run with the arguments that follow its name and
+// returning the exit code.
+type Command struct {
+	Name    string
+	Summary string
+	Run     func(args []string) int
+}
+
+// App dispatches to its commands by the first argument.
+type App struct {
+	Name     string
+	Commands []Command
+	// Default is the command run when the first argument is a flag or there
+	// are none, so that the exporter can still be started without a command.
+	Default string
+	Output  io.Writer
+}
+
+// Run runs the command named by the first argument and returns its exit code.
+func (a *App) Run(args []string) int {
+	name := a.Default
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+
+	switch name {
+	case "help":
+		a.usage()
+		return 0
+	case "":
+		a.usage()
+		return 2
+	}
+
+	for _, command := range a.Commands {
+		if command.Name == name {
+			return command.Run(args)
+		}
+	}
+
+	fmt.Fprintf(a.output(), "unknown command %q\n\n", name)
+	a.usage()
+
+	return 2
+}
+
+func (a *App) usage() {
+	w := tabwriter.NewWriter(a.output(), 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", a.Name)
+
+	for _, command := range a.Commands {
+		fmt.Fprintf(w, "  %s\t%s\n", command.Name, command.Summary)
+	}
+
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the flags of a command.  Flags may also be set with\nenvironment variables, such as -log-level with %s.\n", a.Name, EnvName("log-level"))
+
+	_ = w.Flush()
+}
+
+func (a *App) output() io.Writer {
+	if a.Output != nil {
+		return a.Output
+	}
+
+	return os.Stderr
+}
+
+// EnvName returns the environment variable a flag is read from.
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(flagName))
+}
+
+// Repeatable is implemented by the values of flags that may be given more than
+// once, which are set once for each comma separated value of their
+// environment variable.
+type Repeatable interface {
+	flag.Value
+	IsRepeatable() bool
+}
+
+// Parse parses the arguments, then sets every flag that was not given from
+// its environment variable, so that flags take precedence over the
+// environment.  The usage of each flag names its environment variable.
+func Parse(flags *flag.FlagSet, args []string) error {
+	flags.VisitAll(func(f *flag.Flag) {
+		env := "[$" + EnvName(f.Name) + "]"
+		if !strings.HasSuffix(f.Usage, env) {
+			f.Usage += " " + env
+		}
+	})
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return BindEnv(flags)
+}
+
+// BindEnv sets every flag that was not given on the command line from its
+// environment variable, if set.
+func BindEnv(flags *flag.FlagSet) error {
+	given := map[string]bool{}
+	flags.Visit(func(f *flag.Flag) {
+		given[f.Name] = true
+	})
+
+	var err error
+
+	flags.VisitAll(func(f *flag.Flag) {
+		if err != nil || given[f.Name] {
+			return
+		}
+
+		value, ok := os.LookupEnv(EnvName(f.Name))
+		if !ok {
+			return
+		}
+
+		values := []string{value}
+		if r, ok := f.Value.(Repeatable); ok && r.IsRepeatable() {
+			values = strings.Split(value, ",")
+		}
+
+		for _, v := range values {
+			if setErr := flags.Set(f.Name, strings.TrimSpace(v)); setErr != nil {
+				err = fmt.Errorf("invalid value %q for %s: %w", value, EnvName(f.Name), setErr)
+				return
+			}
+		}
+	})
+
+	return err
+}
@@ -0,0 +1,159 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type alertsCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+func NewAlertsCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetAlertsCollectorDefaultConfig()
+	}
+
+	return &alertsCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *alertsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *alertsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *alertsCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting alerts metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	nodes, err := c.m.client.Nodes(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape alerts")
+
+		return
+	}
+
+	if value, ok := c.config.Lookup(objects.UIAlerts); ok {
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			float64(len(nodes.Alerts)),
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+
+	if value, ok := c.config.Lookup(objects.UIAlertInfo); ok {
+		// the same alert may be raised more than once, but can only be
+		// exported once.
+		seen := map[string]bool{}
+
+		for _, alert := range nodes.Alerts {
+			if seen[alert.Msg] {
+				continue
+			}
+
+			seen[alert.Msg] = true
+			ctx.Message = alert.Msg
+
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.GaugeValue,
+				1,
+				c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+		}
+	}
+
+	if value, ok := c.config.Lookup(objects.Events); ok {
+		c.collectEvents(reqCtx, ch, value, ctx)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+// collectEvents counts the system event log by severity.  The log only exists
+// from Couchbase Server 7.1, so failing to read it does not mark the collector
+// as down.
+func (c *alertsCollector) collectEvents(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext) {
+	events, err := c.m.client.Events(reqCtx)
+	if err != nil {
+		log.Debug("system event log unavailable: %s", err)
+		return
+	}
+
+	counts := map[string]float64{}
+	for _, event := range events.Events {
+		counts[event.Severity]++
+	}
+
+	for severity, count := range counts {
+		ctx.Severity = severity
+
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			count,
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+}
@@ -0,0 +1,143 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type auditCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+func NewAuditCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetAuditCollectorDefaultConfig()
+	}
+
+	return &auditCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *auditCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *auditCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *auditCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting audit metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	settings, err := c.m.client.AuditSettings(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape audit settings")
+
+		return
+	}
+
+	values := map[string]float64{
+		objects.AuditEnabled:        boolToFloat64(settings.AuditdEnabled),
+		objects.AuditRotateInterval: settings.RotateInterval,
+		objects.AuditRotateSize:     settings.RotateSize,
+		objects.AuditDisabledEvents: float64(len(settings.Disabled)),
+	}
+
+	for key, stat := range values {
+		if value, ok := c.config.Lookup(key); ok {
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.GaugeValue,
+				stat,
+				c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+		}
+	}
+
+	if value, ok := c.config.Lookup(objects.AuditDroppedEvents); ok {
+		c.collectDroppedEvents(reqCtx, ch, value, ctx)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+// collectDroppedEvents reads the dropped events counter from the stats API,
+// which only exists from Couchbase Server 7, so failing to read it does not
+// mark the collector as down.
+func (c *auditCollector) collectDroppedEvents(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext) {
+	stats, err := c.m.client.StatsRange(reqCtx, objects.AuditDroppedEventsStat)
+	if err != nil {
+		log.Debug("audit dropped events unavailable: %s", err)
+		return
+	}
+
+	dropped, ok := stats.Last()
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.CounterValue,
+		dropped,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
@@ -0,0 +1,216 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type backupCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+func NewBackupCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetBackupCollectorDefaultConfig()
+	}
+
+	return &backupCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *backupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *backupCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *backupCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting backup metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	currentNode, err := c.m.client.GetCurrentNode(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape backup metrics")
+
+		return
+	}
+
+	// the backup service API is only served on nodes running the service.
+	if contains(currentNode.Services, objects.BackupServiceName) {
+		repositories, err := c.m.client.BackupRepositories(reqCtx)
+		if err != nil {
+			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+			log.Error("failed to scrape backup repositories")
+
+			return
+		}
+
+		for _, repository := range repositories {
+			ctx.Repository = repository.ID
+			ctx.Plan = repository.PlanName
+
+			if err := c.collectRepository(reqCtx, ch, repository, ctx); err != nil {
+				ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+				log.Error("failed to scrape backup repository %s: %s", repository.ID, err)
+
+				return
+			}
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+func (c *backupCollector) collectRepository(reqCtx context.Context, ch chan<- prometheus.Metric, repository objects.BackupRepository, ctx util.MetricContext) error {
+	if value, ok := c.config.Lookup(objects.BackupRepositorySize); ok {
+		info, err := c.m.client.BackupRepositoryInfo(reqCtx, repository.ID)
+		if err != nil {
+			return err
+		}
+
+		c.send(ch, value, info.Size, ctx)
+	}
+
+	tasks, err := c.m.client.BackupTaskHistory(reqCtx, repository.ID)
+	if err != nil {
+		return err
+	}
+
+	var lastSuccess time.Time
+
+	failed := 0
+	latest := map[string]objects.BackupTask{}
+	latestEnd := map[string]time.Time{}
+
+	for _, task := range tasks {
+		if task.Status == objects.BackupTaskStatusFailed {
+			failed++
+		}
+
+		if task.Status != objects.BackupTaskStatusDone {
+			continue
+		}
+
+		end, err := time.Parse(time.RFC3339, task.End)
+		if err != nil {
+			continue
+		}
+
+		if task.Type == objects.BackupTaskTypeBackup && end.After(lastSuccess) {
+			lastSuccess = end
+		}
+
+		if end.After(latestEnd[task.Type]) {
+			latest[task.Type] = task
+			latestEnd[task.Type] = end
+		}
+	}
+
+	if value, ok := c.config.Lookup(objects.BackupFailedTasks); ok {
+		c.send(ch, value, float64(failed), ctx)
+	}
+
+	if value, ok := c.config.Lookup(objects.BackupLastSuccess); ok && !lastSuccess.IsZero() {
+		c.send(ch, value, float64(lastSuccess.Unix()), ctx)
+	}
+
+	if value, ok := c.config.Lookup(objects.BackupTaskDuration); ok {
+		for taskType, task := range latest {
+			duration, ok := taskDuration(task)
+			if !ok {
+				continue
+			}
+
+			ctx.TaskType = strings.ToLower(taskType)
+			c.send(ch, value, duration.Seconds(), ctx)
+		}
+	}
+
+	return nil
+}
+
+func (c *backupCollector) send(ch chan<- prometheus.Metric, value objects.MetricInfo, stat float64, ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
+
+func taskDuration(task objects.BackupTask) (time.Duration, bool) {
+	start, err := time.Parse(time.RFC3339, task.Start)
+	if err != nil {
+		return 0, false
+	}
+
+	end, err := time.Parse(time.RFC3339, task.End)
+	if err != nil {
+		return 0, false
+	}
+
+	return end.Sub(start), true
+}
@@ -0,0 +1,152 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var pendingBucketsVec = objects.NewExporterGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: objects.ExporterNamespace,
+		Name:      "pending_bucket_collections",
+		Help:      "Number of buckets due in the collection in progress that it is yet to start on, or that the last collection did not get to",
+	},
+	[]string{"collector", objects.ClusterLabel})
+
+var degradedBucketsVec = objects.NewExporterGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: objects.ExporterNamespace,
+		Name:      "bucket_degraded",
+		Help:      "Whether the stats of the bucket are collected less often, as requesting them has kept being slow",
+	},
+	[]string{"collector", objects.ClusterLabel, objects.BucketLabel})
+
+// bucketSchedule decides which buckets are collected in each collection from
+// their priorities and how slow their stats have been to request, and tracks
+// how many of them are yet to be collected.
+type bucketSchedule struct {
+	due        func(bucket string, collection int) bool
+	collection int
+	pending    prometheus.Gauge
+	slow       objects.SlowBucketsConfig
+	slowRuns   map[string]int
+	// degraded holds the collection each degraded bucket was degraded in.
+	degraded map[string]int
+}
+
+func newBucketSchedule() *bucketSchedule {
+	return &bucketSchedule{
+		due:        func(string, int) bool { return true },
+		collection: -1,
+		slowRuns:   map[string]int{},
+		degraded:   map[string]int{},
+	}
+}
+
+// set replaces the priorities the buckets are collected by.
+func (s *bucketSchedule) set(priority objects.BucketPriority) error {
+	due, err := priority.Schedule()
+	if err != nil {
+		return err
+	}
+
+	s.due = due
+
+	return nil
+}
+
+// setSlow replaces when buckets are degraded for being slow.
+func (s *bucketSchedule) setSlow(slow objects.SlowBucketsConfig) {
+	if slow.After < 1 {
+		slow.After = objects.DefaultSlowBucketsAfter
+	}
+
+	if slow.Every < 1 {
+		slow.Every = objects.DefaultSlowBucketsEvery
+	}
+
+	s.slow = slow
+}
+
+// next starts the next collection.
+func (s *bucketSchedule) next() {
+	s.collection++
+}
+
+// bucketDue returns whether the bucket is collected in this collection.
+func (s *bucketSchedule) bucketDue(bucket string) bool {
+	if since, ok := s.degraded[bucket]; ok && (s.collection-since)%s.slow.Every != 0 {
+		return false
+	}
+
+	return s.due(bucket, s.collection)
+}
+
+// observe degrades the bucket once requesting its stats for the named
+// collector of the cluster has taken longer than the threshold in enough
+// collections in a row, and restores it as soon as a request is quick again.
+func (s *bucketSchedule) observe(collector, cluster, bucket string, took time.Duration) {
+	if s.slow.Threshold <= 0 {
+		return
+	}
+
+	if took.Seconds() <= s.slow.Threshold {
+		delete(s.slowRuns, bucket)
+
+		if _, ok := s.degraded[bucket]; ok {
+			delete(s.degraded, bucket)
+			log.Info("stats of bucket %s took %s, collecting it every collection again", bucket, took)
+		}
+
+		degradedBucketsVec.WithLabelValues(collector, cluster, bucket).Set(0)
+
+		return
+	}
+
+	s.slowRuns[bucket]++
+
+	if _, ok := s.degraded[bucket]; ok || s.slowRuns[bucket] < s.slow.After {
+		return
+	}
+
+	s.degraded[bucket] = s.collection
+	degradedBucketsVec.WithLabelValues(collector, cluster, bucket).Set(1)
+
+	log.Warn("stats of bucket %s took over %gs in %d collections in a row, collecting it only every %d collections",
+		bucket, s.slow.Threshold, s.slowRuns[bucket], s.slow.Every)
+}
+
+// queue counts the buckets due in this collection as pending for the named
+// collector of the cluster.
+func (s *bucketSchedule) queue(collector, cluster string, buckets []objects.BucketInfo) {
+	s.pending = pendingBucketsVec.WithLabelValues(collector, cluster)
+
+	due := 0
+
+	for _, bucket := range buckets {
+		if s.bucketDue(bucket.Name) {
+			due++
+		}
+	}
+
+	s.pending.Set(float64(due))
+}
+
+// started counts a due bucket as no longer pending once its collection starts.
+func (s *bucketSchedule) started() {
+	if s.pending != nil {
+		s.pending.Dec()
+	}
+}
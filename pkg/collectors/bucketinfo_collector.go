@@ -10,6 +10,7 @@
 package collectors
 
 import (
+	"context"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -36,6 +37,11 @@ func (c *bucketInfoCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (c *bucketInfoCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *bucketInfoCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -43,7 +49,7 @@ func (c *bucketInfoCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting bucketinfo metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -52,7 +58,7 @@ func (c *bucketInfoCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	buckets, err := c.m.client.Buckets()
+	buckets, err := c.m.client.Buckets(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -64,16 +70,23 @@ func (c *bucketInfoCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, bucket := range buckets {
 		log.Debug("Collecting %s bucket metrics...", bucket.Name)
 
-		ctx, _ = c.m.labelManger.GetMetricContext(bucket.Name, "")
+		ctx, _ = c.m.labelManger.GetMetricContext(reqCtx, bucket.Name, "")
+		ctx.BucketUUID = bucket.UUID
+		ctx.BucketType = bucket.BucketType
 
 		for key, value := range c.config.Metrics {
 			log.Debug("Collecting for metric %s.", value.Name)
 
 			if value.Enabled {
+				stat := bucket.BucketBasicStats[key]
+				if key == objects.BucketMaxTTL {
+					stat = float64(bucket.MaxTTL)
+				}
+
 				ch <- prometheus.MustNewConstMetric(
 					value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
 					prometheus.GaugeValue,
-					bucket.BucketBasicStats[key],
+					stat,
 					c.m.labelManger.GetLabelValues(value.Labels, ctx)...,
 				)
 			}
@@ -12,17 +12,17 @@
 package collectors
 
 import (
+	"context"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
 	"github.com/couchbase/couchbase-exporter/pkg/objects"
 	"github.com/couchbase/couchbase-exporter/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 var (
-	bucketUpVec = promauto.NewGaugeVec(
+	bucketUpVec = objects.NewExporterGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace:   "bucketstats",
 			Subsystem:   "",
@@ -31,7 +31,7 @@ var (
 			ConstLabels: nil,
 		},
 		[]string{objects.ClusterLabel})
-	bucketScrapeVec = promauto.NewGaugeVec(
+	bucketScrapeVec = objects.NewExporterGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace:   "bucketstats",
 			Subsystem:   "",
@@ -50,6 +50,11 @@ type BucketStatsCollector struct {
 	up             *prometheus.GaugeVec
 	scrapeDuration *prometheus.GaugeVec
 	labelManger    util.CbLabelManager
+	cluster        string
+	aggregate      bool
+	statKeys       statKeys
+	gauges         *gaugeCache
+	schedule       *bucketSchedule
 	// This is for TESTING purposes only.
 	// By default bucketStatsCollector implements and uses itself to
 	// fulfill this functionality.
@@ -57,12 +62,19 @@ type BucketStatsCollector struct {
 }
 
 func (c *BucketStatsCollector) SetGaugeVec(vec prometheus.GaugeVec, stat float64, labelValues ...string) {
-	vec.WithLabelValues(labelValues...).Set(stat)
+	c.gauges.set(vec, stat, labelValues...)
+}
+
+// SetCluster names the cluster the collector collects from, as it is named in
+// the configuration, to tell its snapshot and the exporter's own metrics from
+// those of the other clusters.  The top level cluster has no name.
+func (c *BucketStatsCollector) SetCluster(name string) {
+	c.cluster = name
 }
 
 // Implements Worker interface for CycleController.
-func (c *BucketStatsCollector) DoWork() {
-	c.CollectMetrics()
+func (c *BucketStatsCollector) DoWork(reqCtx context.Context) {
+	c.CollectMetrics(reqCtx)
 }
 
 func last(stats []float64) float64 {
@@ -81,34 +93,87 @@ func min(x, y float64) float64 {
 	return x
 }
 
-func (c *BucketStatsCollector) setMetric(metric objects.MetricInfo, samples map[string][]float64, ctx util.MetricContext) {
+// SetWindowAggregates makes the collector export the minimum, average and
+// maximum over the sample window of the metrics configured to aggregate.
+func (c *BucketStatsCollector) SetWindowAggregates(enabled bool) {
+	c.aggregate = enabled
+}
+
+// SetBucketPriority makes the collector collect the buckets of low priority
+// only every so many collections.
+func (c *BucketStatsCollector) SetBucketPriority(priority objects.BucketPriority) error {
+	return c.schedule.set(priority)
+}
+
+// SetSlowBuckets makes the collector collect the buckets whose stats keep
+// being slow to request less often.
+func (c *BucketStatsCollector) SetSlowBuckets(slow objects.SlowBucketsConfig) {
+	c.schedule.setSlow(slow)
+}
+
+// bucketStatValue converts a sample of the named stat to the unit it is
+// exported in.
+func bucketStatValue(name string, stat float64) float64 {
+	switch name {
+	case "avg_bg_wait_time":
+		// comes across as microseconds.  Convert
+		return stat / 1000000
+	case "ep_cache_miss_rate":
+		return min(stat, 100)
+	default:
+		return stat
+	}
+}
+
+func (c *BucketStatsCollector) setMetric(metric objects.MetricInfo, samples map[string][]float64, ctx util.MetricContext, labels *labelValueCache) {
 	if !metric.Enabled {
 		return
 	}
 
 	promMetric, ok := c.metrics[metric.Name]
+	if !objects.BucketStatApplies(ctx.BucketType, metric.Name) {
+		if ok {
+			c.gauges.delete(*promMetric, labels.get(metric.Labels)...)
+		}
+
+		return
+	}
+
 	if !ok {
 		promMetric = metric.GetPrometheusGaugeVec(c.registry, c.config.Namespace, c.config.Subsystem)
 		c.metrics[metric.Name] = promMetric
 	}
 
-	switch metric.Name {
-	case "avg_bg_wait_time":
-		// comes across as microseconds.  Convert
-		c.Setter.SetGaugeVec(*promMetric, last(samples[metric.Name])/1000000, c.labelManger.GetLabelValues(metric.Labels, ctx)...)
-	case "ep_cache_miss_rate":
-		c.Setter.SetGaugeVec(*promMetric, min(last(samples[metric.Name]), 100), c.labelManger.GetLabelValues(metric.Labels, ctx)...)
-	default:
-		c.Setter.SetGaugeVec(*promMetric, last(samples[metric.Name]), c.labelManger.GetLabelValues(metric.Labels, ctx)...)
+	labelValues := labels.get(metric.Labels)
+	c.Setter.SetGaugeVec(*promMetric, bucketStatValue(metric.Name, last(samples[metric.Name])), labelValues...)
+
+	if !c.aggregate || !metric.Aggregate || len(samples[metric.Name]) == 0 {
+		return
+	}
+
+	for _, aggregate := range windowAggregates {
+		info := aggregate.metric(metric)
+
+		vec, ok := c.metrics[info.Name]
+		if !ok {
+			vec = info.GetPrometheusGaugeVec(c.registry, c.config.Namespace, c.config.Subsystem)
+			c.metrics[info.Name] = vec
+		}
+
+		c.Setter.SetGaugeVec(*vec, bucketStatValue(metric.Name, aggregate.fn(samples[metric.Name])), labelValues...)
 	}
 }
 
-func (c *BucketStatsCollector) CollectMetrics() {
+// CollectMetrics collects the stats of every bucket, abandoning the requests
+// in flight when reqCtx is done.
+func (c *BucketStatsCollector) CollectMetrics(reqCtx context.Context) {
 	start := time.Now()
 
 	log.Info("Begin collecting bucketstats metrics...")
 
-	ctx, err := c.labelManger.GetBasicMetricContext()
+	c.schedule.next()
+
+	ctx, err := c.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		c.Setter.SetGaugeVec(*c.up, 0, objects.ClusterLabel)
 		log.Error("%s", err)
@@ -116,7 +181,7 @@ func (c *BucketStatsCollector) CollectMetrics() {
 		return
 	}
 
-	buckets, err := c.client.Buckets()
+	buckets, err := c.client.Buckets(reqCtx)
 	if err != nil {
 		c.Setter.SetGaugeVec(*c.up, 0, objects.ClusterLabel)
 		log.Error("failed to scrape buckets")
@@ -124,12 +189,29 @@ func (c *BucketStatsCollector) CollectMetrics() {
 		return
 	}
 
+	c.schedule.queue(c.config.Name, ctx.ClusterName, buckets)
+
 	for _, bucket := range buckets {
+		if reqCtx.Err() != nil {
+			c.Setter.SetGaugeVec(*c.up, 0, objects.ClusterLabel)
+			log.Warn("bucket stats collection stopped before bucket %s: %s", bucket.Name, reqCtx.Err())
+
+			return
+		}
+
+		if !c.schedule.bucketDue(bucket.Name) {
+			continue
+		}
+
+		c.schedule.started()
+
 		log.Debug("Collecting %s bucket stats metrics...", bucket.Name)
 
-		ctx, _ := c.labelManger.GetMetricContext(bucket.Name, "")
+		ctx, _ := c.labelManger.GetMetricContext(reqCtx, bucket.Name, "")
+		ctx.BucketType = bucket.BucketType
 
-		stats, err := c.client.BucketStats(bucket.Name)
+		bucketStart := time.Now()
+		stats, err := c.client.BucketStats(reqCtx, bucket.Name)
 
 		if err != nil {
 			c.Setter.SetGaugeVec(*c.up, 0, objects.ClusterLabel)
@@ -138,20 +220,42 @@ func (c *BucketStatsCollector) CollectMetrics() {
 			return
 		}
 
-		for _, value := range c.config.Metrics {
-			log.Debug("Collecting bucket stats: %s", value.Name)
+		observeBucketScrape(c.config.Name, bucket.Name, bucketStart, len(stats.Op.Samples))
+		c.schedule.observe(c.config.Name, ctx.ClusterName, bucket.Name, time.Since(bucketStart))
+		observeSampleAge(c.config.Name, stats.Op.Samples)
+		addReplicaSkew(stats.Op.Samples, bucket.ReplicaNumber)
+		c.statKeys.observe(c.config.Name, bucket.BucketType, stats.Op.Samples)
+
+		labels := newLabelValueCache(c.labelManger, ctx)
 
+		for _, value := range c.config.Metrics {
 			if value.Enabled {
-				c.setMetric(value, stats.Op.Samples, ctx)
+				c.setMetric(value, stats.Op.Samples, ctx, labels)
 			}
 		}
 	}
 
 	c.Setter.SetGaugeVec(*c.up, 1, objects.ClusterLabel)
 	c.Setter.SetGaugeVec(*c.scrapeDuration, time.Since(start).Seconds(), ctx.ClusterName)
+	markSnapshotFresh(c.SnapshotKey())
 	log.Info("Bucket stats is complete Duration: %v", time.Since(start))
 }
 
+// Implements Snapshotter interface.
+func (c *BucketStatsCollector) SnapshotKey() SnapshotKey {
+	return SnapshotKey{Cluster: c.cluster, Collector: c.config.Name}
+}
+
+// Implements Snapshotter interface.
+func (c *BucketStatsCollector) Snapshot() []objects.MetricSample {
+	return snapshotGaugeVecs(c.metrics)
+}
+
+// Implements Snapshotter interface.
+func (c *BucketStatsCollector) Restore(samples []objects.MetricSample) {
+	restoreGaugeVecs(c.config, c.registry, c.metrics, samples)
+}
+
 func (c *BucketStatsCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, metric := range c.metrics {
 		metric.Collect(ch)
@@ -185,6 +289,9 @@ func NewBucketStatsCollector(client util.CbClient, config *objects.CollectorConf
 		registry:       prometheus.NewRegistry(),
 		config:         config,
 		metrics:        map[string]*prometheus.GaugeVec{},
+		statKeys:       newStatKeys(config),
+		gauges:         newGaugeCache(),
+		schedule:       newBucketSchedule(),
 	}
 
 	collector.Setter = collector
@@ -0,0 +1,177 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	bytesPerMiB = 1 << 20
+	bytesPerGiB = 1 << 30
+)
+
+type capellaCollector struct {
+	m        MetaCollector
+	client   util.CapellaClient
+	clusters []objects.CapellaClusterRef
+	config   *objects.CollectorConfig
+}
+
+// NewCapellaCollector creates a collector for the given Capella clusters.
+// Capella clusters are not reached through the cluster manager, so the label
+// manager is only used to build label values.
+func NewCapellaCollector(client util.CapellaClient, clusters []objects.CapellaClusterRef, config *objects.CollectorConfig,
+	labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetCapellaCollectorDefaultConfig()
+	}
+
+	return &capellaCollector{
+		m: MetaCollector{
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		client:   client,
+		clusters: clusters,
+		config:   config,
+	}
+}
+
+// Describe all metrics.
+func (c *capellaCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *capellaCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	log.Info("Collecting Capella metrics...")
+
+	for _, ref := range c.clusters {
+		c.collectCluster(ch, ref)
+	}
+}
+
+// collectCluster reports the metrics of a single cluster, labelled with the
+// name of the cluster, or its ID if the cluster cannot be read.
+func (c *capellaCollector) collectCluster(ch chan<- prometheus.Metric, ref objects.CapellaClusterRef) {
+	start := time.Now()
+
+	cluster, err := c.client.Cluster(ref.ProjectID, ref.ClusterID)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ref.ClusterID)
+
+		log.Error("failed to scrape Capella cluster %s: %s", ref.ClusterID, err)
+
+		return
+	}
+
+	ctx := util.MetricContext{
+		ClusterName: cluster.Name,
+		ClusterUUID: cluster.ID,
+	}
+
+	if value, ok := c.config.Lookup(objects.CapellaClusterHealthy); ok {
+		c.send(ch, value, boolToFloat64(cluster.Healthy()), ctx)
+	}
+
+	if value, ok := c.config.Lookup(objects.CapellaClusterNodes); ok {
+		c.send(ch, value, float64(cluster.Nodes()), ctx)
+	}
+
+	for _, group := range cluster.ServiceGroups {
+		groupCtx := ctx
+		groupCtx.Services = group.ServiceNames()
+
+		if value, ok := c.config.Lookup(objects.CapellaServiceGroupNodes); ok {
+			c.send(ch, value, float64(group.NumOfNodes), groupCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.CapellaServiceGroupNodeCPU); ok {
+			c.send(ch, value, float64(group.Node.Compute.CPU), groupCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.CapellaServiceGroupNodeRAM); ok {
+			c.send(ch, value, float64(group.Node.Compute.RAM)*bytesPerGiB, groupCtx)
+		}
+	}
+
+	buckets, err := c.client.Buckets(ref.ProjectID, ref.ClusterID)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape buckets of Capella cluster %s: %s", cluster.Name, err)
+
+		return
+	}
+
+	for _, bucket := range buckets {
+		bucketCtx := ctx
+		bucketCtx.BucketName = bucket.Name
+
+		if value, ok := c.config.Lookup(objects.CapellaBucketItems); ok {
+			c.send(ch, value, float64(bucket.Stats.ItemCount), bucketCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.CapellaBucketOpsPerSecond); ok {
+			c.send(ch, value, float64(bucket.Stats.OpsPerSecond), bucketCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.CapellaBucketDiskUsed); ok {
+			c.send(ch, value, float64(bucket.Stats.DiskUsedInMib)*bytesPerMiB, bucketCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.CapellaBucketMemoryUsed); ok {
+			c.send(ch, value, float64(bucket.Stats.MemoryUsedInMib)*bytesPerMiB, bucketCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.CapellaBucketMemoryAllocated); ok {
+			c.send(ch, value, float64(bucket.MemoryAllocationInMb)*bytesPerMiB, bucketCtx)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+func (c *capellaCollector) send(ch chan<- prometheus.Metric, value objects.MetricInfo, stat float64, ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
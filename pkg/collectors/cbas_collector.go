@@ -10,6 +10,7 @@
 package collectors
 
 import (
+	"context"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -64,6 +65,11 @@ func (c *cbasCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect all metrics.
 func (c *cbasCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *cbasCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -71,7 +77,7 @@ func (c *cbasCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting cbas metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -80,7 +86,7 @@ func (c *cbasCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	cbas, err := c.m.client.Cbas()
+	cbas, err := c.m.client.Cbas(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -89,8 +95,10 @@ func (c *cbasCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	for _, value := range c.config.Metrics {
-		if value.Enabled {
+	observeSampleAge(c.config.Name, cbas.Op.Samples)
+
+	for key, value := range c.config.Metrics {
+		if value.Enabled && !objects.CbasNodeMetrics[key] {
 			ch <- prometheus.MustNewConstMetric(
 				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
 				prometheus.GaugeValue,
@@ -100,6 +108,107 @@ func (c *cbasCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
+	currentNode, err := c.m.client.GetCurrentNode(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape cbas stats")
+
+		return
+	}
+
+	if contains(currentNode.Services, "cbas") {
+		if err := c.collectIngestion(reqCtx, ch, ctx); err != nil {
+			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+			log.Error("%s", err)
+
+			return
+		}
+
+		if value, ok := c.config.Lookup(objects.CbasFailedRecords); ok {
+			c.collectFailedRecords(reqCtx, ch, value, ctx)
+		}
+	}
+
 	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
 	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
 }
+
+// collectIngestion reports the state of each link, and the ingestion progress
+// of each of the link's datasets, labelled with their scope qualified names.
+func (c *cbasCollector) collectIngestion(reqCtx context.Context, ch chan<- prometheus.Metric, ctx util.MetricContext) error {
+	connected, connectedOk := c.config.Lookup(objects.CbasLinkConnected)
+	processed, processedOk := c.config.Lookup(objects.CbasDatasetItemsProcessed)
+	progress, progressOk := c.config.Lookup(objects.CbasDatasetProgress)
+	lag, lagOk := c.config.Lookup(objects.CbasDatasetTimeLag)
+
+	if !connectedOk && !processedOk && !progressOk && !lagOk {
+		return nil
+	}
+
+	ingestion, err := c.m.client.AnalyticsIngestion(reqCtx)
+	if err != nil {
+		return err
+	}
+
+	send := func(value objects.MetricInfo, valueType prometheus.ValueType, val float64, ctx util.MetricContext) {
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			valueType,
+			val,
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+
+	for _, link := range ingestion.Links {
+		ctx.Link = link.QualifiedName()
+
+		if connectedOk {
+			send(connected, prometheus.GaugeValue, boolToFloat64(link.Connected()), ctx)
+		}
+
+		for _, state := range link.State {
+			for _, scope := range state.Scopes {
+				for _, collection := range scope.Collections {
+					ctx.Dataset = scope.Name + "." + collection.Name
+
+					if processedOk {
+						send(processed, prometheus.CounterValue, state.ItemsProcessed, ctx)
+					}
+
+					if progressOk {
+						send(progress, prometheus.GaugeValue, state.Progress, ctx)
+					}
+
+					if lagOk {
+						send(lag, prometheus.GaugeValue, state.TimeLag/1000, ctx)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// collectFailedRecords reads the failed records counter from the stats API,
+// which only exists from Couchbase Server 7, so failing to read it does not
+// mark the collector as down.
+func (c *cbasCollector) collectFailedRecords(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext) {
+	stats, err := c.m.client.StatsRange(reqCtx, objects.CbasFailedRecordsStat)
+	if err != nil {
+		log.Debug("analytics failed records unavailable: %s", err)
+		return
+	}
+
+	failed, ok := stats.Last()
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.CounterValue,
+		failed,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
@@ -0,0 +1,124 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type clientErrorsCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+// NewClientErrorsCollector creates a collector for the errors the data service
+// of every node returns to client SDKs, read from the Couchbase Server 7 stats
+// API.  They are exported together, with consistent names, so that the SLOs
+// of applications can be written against them.
+func NewClientErrorsCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetClientErrorsCollectorDefaultConfig()
+	}
+
+	return &clientErrorsCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *clientErrorsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *clientErrorsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *clientErrorsCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting client error metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	for key, stat := range objects.ClientErrorStats {
+		value, ok := c.config.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		stats, err := c.m.client.NodeStatsRange(reqCtx, stat)
+		if err != nil {
+			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+			log.Error("failed to scrape client error stats: %s", err)
+
+			return
+		}
+
+		// every error is counted since memcached started.
+		for _, series := range stats.LastBySeries() {
+			seriesCtx := ctx
+			seriesCtx.NodeHostname = series.Node
+			seriesCtx.BucketName = series.Bucket
+
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.CounterValue,
+				series.Value,
+				c.m.labelManger.GetLabelValues(value.Labels, seriesCtx)...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
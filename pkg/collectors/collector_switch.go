@@ -0,0 +1,156 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const unknownCollector string = "unknown collector"
+
+var ErrUnknownCollector = fmt.Errorf(unknownCollector)
+
+// CollectorSwitch lets collectors be disabled and enabled again while the
+// exporter runs.  A disabled collector neither reports its metrics nor makes
+// any requests to Couchbase Server.  Collectors are switched by name, for
+// every cluster they collect from.  Nothing is persisted, so every collector
+// is enabled again on restart.
+type CollectorSwitch struct {
+	mu      sync.RWMutex
+	enabled map[string]bool
+	// clusters lists the clusters each collector collects from, as they are
+	// named in the configuration.
+	clusters map[string][]string
+}
+
+func NewCollectorSwitch() *CollectorSwitch {
+	return &CollectorSwitch{enabled: map[string]bool{}, clusters: map[string][]string{}}
+}
+
+// Collector adds the named collector of cluster to the switch, returning a
+// collector that only collects while it is enabled.
+func (s *CollectorSwitch) Collector(cluster, name string, collector prometheus.Collector) prometheus.Collector {
+	s.add(cluster, name)
+
+	return &switchedCollector{name: name, collector: collector, enabled: s.Enabled}
+}
+
+// Worker adds the named collector of cluster to the switch, returning a
+// worker that only does its work while the collector is enabled.
+func (s *CollectorSwitch) Worker(cluster, name string, worker util.Worker) util.Worker {
+	s.add(cluster, name)
+
+	return &switchedWorker{name: name, worker: worker, enabled: s.Enabled}
+}
+
+func (s *CollectorSwitch) add(cluster, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.enabled[name]; !ok {
+		s.enabled[name] = true
+	}
+
+	for _, existing := range s.clusters[name] {
+		if existing == cluster {
+			return
+		}
+	}
+
+	s.clusters[name] = append(s.clusters[name], cluster)
+	collectorEnabledVec.WithLabelValues(cluster, name).Set(boolToFloat64(s.enabled[name]))
+}
+
+// Enabled reports whether the named collector is enabled.
+func (s *CollectorSwitch) Enabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.enabled[name]
+}
+
+// Set enables or disables the named collector, ignoring case.
+func (s *CollectorSwitch) Set(name string, enabled bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for existing := range s.enabled {
+		if !strings.EqualFold(existing, name) {
+			continue
+		}
+
+		if s.enabled[existing] != enabled {
+			log.Info("collector %s enabled: %v", existing, enabled)
+		}
+
+		s.enabled[existing] = enabled
+
+		for _, cluster := range s.clusters[existing] {
+			collectorEnabledVec.WithLabelValues(cluster, existing).Set(boolToFloat64(enabled))
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("%w %q", ErrUnknownCollector, name)
+}
+
+// States returns whether each collector is enabled, by name.
+func (s *CollectorSwitch) States() map[string]bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	states := make(map[string]bool, len(s.enabled))
+	for name, enabled := range s.enabled {
+		states[name] = enabled
+	}
+
+	return states
+}
+
+type switchedCollector struct {
+	name      string
+	collector prometheus.Collector
+	enabled   func(string) bool
+}
+
+func (c *switchedCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
+
+func (c *switchedCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.enabled(c.name) {
+		c.collector.Collect(ch)
+	}
+}
+
+type switchedWorker struct {
+	name    string
+	worker  util.Worker
+	enabled func(string) bool
+}
+
+// WorkerName implements util.NamedWorker.
+func (w *switchedWorker) WorkerName() string {
+	return w.name
+}
+
+func (w *switchedWorker) DoWork(ctx context.Context) {
+	if w.enabled(w.name) {
+		w.worker.DoWork(ctx)
+	}
+}
@@ -0,0 +1,123 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const day = 24 * time.Hour
+
+var (
+	credentialsValidGauge = objects.NewExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "credentials_valid",
+			Help:      "1 if Couchbase Server accepted the credentials of the exporter at the latest check that reached it, 0 if it rejected them",
+		})
+	credentialsExpiryGauge = objects.NewExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "credentials_expiry_timestamp_seconds",
+			Help:      "Time the credentials of the exporter expire, in seconds since the epoch, if known",
+		})
+)
+
+// CredentialsCheck requests /whoami every cycle, so that credentials that
+// have been revoked or have expired show up in a single metric rather than
+// as every collector failing, and warns ahead of a known expiry.
+type CredentialsCheck struct {
+	client util.CbClient
+	config objects.CredentialsConfig
+	// Now is for TESTING purposes only.
+	Now func() time.Time
+}
+
+func NewCredentialsCheck(client util.CbClient, config objects.CredentialsConfig) *CredentialsCheck {
+	return &CredentialsCheck{
+		client: client,
+		config: config,
+		Now:    time.Now,
+	}
+}
+
+// Implements Worker interface for CycleController.
+func (c *CredentialsCheck) DoWork(reqCtx context.Context) {
+	c.Check(reqCtx)
+}
+
+// Check requests /whoami and reports whether the credentials were accepted
+// and, if known, when they expire.
+func (c *CredentialsCheck) Check(reqCtx context.Context) {
+	who, err := c.client.WhoAmI(reqCtx)
+	if errors.Is(err, util.ErrUnauthorized) {
+		log.Error("credentials check failed: %s", err)
+		credentialsValidGauge.Set(0)
+
+		return
+	}
+
+	// a cluster that cannot be reached says nothing about the credentials, so
+	// the result of the last check stands.
+	if err != nil {
+		log.Warn("unable to check the credentials: %s", err)
+
+		return
+	}
+
+	credentialsValidGauge.Set(1)
+
+	expiry, ok := c.expiry(who)
+	if !ok {
+		return
+	}
+
+	credentialsExpiryGauge.Set(float64(expiry.Unix()))
+
+	remaining := expiry.Sub(c.Now())
+	if remaining < time.Duration(c.config.WarnBefore)*day {
+		log.Warn("the credentials of user %s (%s) expire at %s, in %s. Renew them before collection stops.",
+			who.ID, who.Domain, expiry.Format(time.RFC3339), remaining.Round(time.Minute))
+	}
+}
+
+// expiry returns when the credentials expire, either as configured or from
+// when the password of a local user was last changed and the maximum age of
+// passwords.
+func (c *CredentialsCheck) expiry(who objects.WhoAmI) (time.Time, bool) {
+	if c.config.ExpiresAt != "" {
+		expiry, err := time.Parse(time.RFC3339, c.config.ExpiresAt)
+		if err != nil {
+			log.Error("invalid credentials expiry %q: %s", c.config.ExpiresAt, err)
+			return time.Time{}, false
+		}
+
+		return expiry, true
+	}
+
+	if c.config.PasswordMaxAge <= 0 || who.PasswordChangeDate == "" {
+		return time.Time{}, false
+	}
+
+	changed, err := time.Parse(time.RFC3339, who.PasswordChangeDate)
+	if err != nil {
+		log.Debug("unable to parse password change date %q: %s", who.PasswordChangeDate, err)
+		return time.Time{}, false
+	}
+
+	return changed.Add(time.Duration(c.config.PasswordMaxAge) * day), true
+}
@@ -0,0 +1,111 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// deadNodeBackoff is how long a node is first skipped for after its stats
+	// could not be retrieved, doubling after each failure up to
+	// maxDeadNodeBackoff.
+	deadNodeBackoff    = 30 * time.Second
+	maxDeadNodeBackoff = 10 * time.Minute
+)
+
+// ErrNodeSkipped is returned instead of requesting the stats of a node that
+// is being skipped.
+var ErrNodeSkipped = errors.New("node skipped after failing to retrieve its stats")
+
+var nodeSkippedVec = objects.NewExporterGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: objects.ExporterNamespace,
+		Name:      "node_skipped",
+		Help:      "1 while the stats of the node are not requested because they could not be retrieved, 0 once they are requested again",
+	},
+	[]string{objects.NodeLabel})
+
+// deadNodes skips the nodes whose stats could not be retrieved, for every
+// bucket, for an interval that doubles after each failure, rather than
+// waiting for an unreachable node to time out for every bucket in every
+// collection.  It is safe to use from the requests made in parallel.
+type deadNodes struct {
+	mutex sync.Mutex
+	nodes map[string]*deadNode
+}
+
+type deadNode struct {
+	failures int
+	until    time.Time
+}
+
+func newDeadNodes() *deadNodes {
+	return &deadNodes{nodes: map[string]*deadNode{}}
+}
+
+// skip returns whether the node is still being skipped at now.
+func (d *deadNodes) skip(node string, now time.Time) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	n, ok := d.nodes[node]
+
+	return ok && now.Before(n.until)
+}
+
+// failed records that the stats of the node could not be retrieved at now,
+// and returns how long it will be skipped for.  A node that fails again while
+// it is already skipped, because its requests were in flight, is not skipped
+// for longer.
+func (d *deadNodes) failed(node string, now time.Time) time.Duration {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	n, ok := d.nodes[node]
+	if !ok {
+		n = &deadNode{}
+		d.nodes[node] = n
+	}
+
+	if now.Before(n.until) {
+		return n.until.Sub(now)
+	}
+
+	backoff := deadNodeBackoff << n.failures
+	if backoff >= maxDeadNodeBackoff {
+		backoff = maxDeadNodeBackoff
+	} else {
+		n.failures++
+	}
+
+	n.until = now.Add(backoff)
+	nodeSkippedVec.WithLabelValues(node).Set(1)
+
+	return backoff
+}
+
+// reachable forgets the failures of the node once its stats are retrieved.
+func (d *deadNodes) reachable(node string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if _, ok := d.nodes[node]; !ok {
+		return
+	}
+
+	delete(d.nodes, node)
+	nodeSkippedVec.WithLabelValues(node).Set(0)
+}
@@ -0,0 +1,171 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	collectorTimeoutVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "collector_timeout_total",
+			Help:      "Number of collections abandoned at the collector's deadline, exposing only what was collected by then",
+		},
+		[]string{"cluster", "collector"})
+)
+
+// ContextCollector is a collector whose requests to Couchbase Server are
+// abandoned once the context it collects with is done.
+type ContextCollector interface {
+	prometheus.Collector
+	CollectContext(ctx context.Context, ch chan<- prometheus.Metric)
+}
+
+// WithDeadline returns a collector that exposes whatever the collector has
+// collected once the deadline has passed, rather than holding up the scrape
+// of every other collector.  A ContextCollector collects with a context that
+// is done at the deadline, so that its requests still in flight are
+// abandoned.  Any other collector carries on in the background, and what it
+// collects later is discarded.  Until an abandoned collection has finished
+// the collector is not collected again, and exposes nothing.  The collector is
+// counted under the cluster it collects from, as it is named in the
+// configuration.  A deadline of zero returns the collector as it is.
+func WithDeadline(cluster, name string, collector prometheus.Collector, deadline time.Duration) prometheus.Collector {
+	if deadline <= 0 {
+		return collector
+	}
+
+	return &deadlineCollector{cluster: cluster, name: name, collector: collector, deadline: deadline}
+}
+
+// WorkerWithDeadline returns a worker whose work is given a context that is
+// done at the deadline, so that the requests for stats still in flight are
+// abandoned and the values already collected are kept.  A deadline of zero
+// returns the worker as it is.
+func WorkerWithDeadline(cluster, name string, worker util.Worker, deadline time.Duration) util.Worker {
+	if deadline <= 0 {
+		return worker
+	}
+
+	return &deadlineWorker{cluster: cluster, name: name, worker: worker, deadline: deadline}
+}
+
+type deadlineCollector struct {
+	cluster   string
+	name      string
+	collector prometheus.Collector
+	deadline  time.Duration
+
+	mu sync.Mutex
+	// collecting is set while a collection runs, including one abandoned at
+	// the deadline.
+	collecting bool
+}
+
+func (c *deadlineCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
+
+func (c *deadlineCollector) Collect(ch chan<- prometheus.Metric) {
+	if !c.start() {
+		log.Warn("%s collector is still finishing a collection abandoned at its deadline, skipping it", c.name)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.deadline)
+	metrics := make(chan prometheus.Metric)
+
+	go func() {
+		defer c.finish()
+		defer cancel()
+
+		if collector, ok := c.collector.(ContextCollector); ok {
+			collector.CollectContext(ctx, metrics)
+		} else {
+			c.collector.Collect(metrics)
+		}
+
+		close(metrics)
+	}()
+
+	timer := time.NewTimer(c.deadline)
+	defer timer.Stop()
+
+	for {
+		select {
+		case m, ok := <-metrics:
+			if !ok {
+				return
+			}
+
+			ch <- m
+		case <-timer.C:
+			collectorTimeoutVec.WithLabelValues(c.cluster, c.name).Inc()
+			log.Warn("%s collector did not finish within %s, exposing what was collected", c.name, c.deadline)
+
+			go func() {
+				for range metrics {
+				}
+			}()
+
+			return
+		}
+	}
+}
+
+// start reports whether a collection may start, marking it as running if so.
+func (c *deadlineCollector) start() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.collecting {
+		return false
+	}
+
+	c.collecting = true
+
+	return true
+}
+
+func (c *deadlineCollector) finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.collecting = false
+}
+
+type deadlineWorker struct {
+	cluster  string
+	name     string
+	worker   util.Worker
+	deadline time.Duration
+}
+
+func (w *deadlineWorker) DoWork(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.deadline)
+	defer cancel()
+
+	w.worker.DoWork(ctx)
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		collectorTimeoutVec.WithLabelValues(w.cluster, w.name).Inc()
+		log.Warn("%s collector did not finish within %s, keeping what was collected", w.name, w.deadline)
+	}
+}
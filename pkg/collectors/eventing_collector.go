@@ -10,6 +10,7 @@
 package collectors
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -67,6 +68,11 @@ func (c *eventingCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect all metrics.
 func (c *eventingCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *eventingCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -74,7 +80,7 @@ func (c *eventingCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting eventing metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -83,7 +89,7 @@ func (c *eventingCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	ev, err := c.m.client.Eventing()
+	ev, err := c.m.client.Eventing(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -92,6 +98,8 @@ func (c *eventingCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	observeSampleAge(c.config.Name, ev.Op.Samples)
+
 	for _, value := range c.config.Metrics {
 		if value.Enabled {
 			sampleName := objects.EventingMetricPrefix
@@ -0,0 +1,155 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"math"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gaugeCache holds the child gauge of every set of label values a collector
+// sets, with the value it was last set to, so that setting a gauge to the
+// value it already has costs a map lookup rather than a lookup of the child
+// under the vector's lock.  With hundreds of stats for every bucket on every
+// node, most of which do not change between cycles, this is most of the work
+// of a cycle.  It is only used by the collector's worker, one cycle at a time.
+type gaugeCache struct {
+	gauges map[gaugeKey]*cachedGauge
+}
+
+type gaugeKey struct {
+	vec  *prometheus.MetricVec
+	hash uint64
+}
+
+type cachedGauge struct {
+	gauge       prometheus.Gauge
+	labelValues []string
+	value       uint64
+}
+
+func newGaugeCache() *gaugeCache {
+	return &gaugeCache{gauges: map[gaugeKey]*cachedGauge{}}
+}
+
+// newGaugeKey hashes the label values with FNV-1a, as the vector does, rather
+// than joining them, so that looking up an unchanged gauge does not allocate.
+func newGaugeKey(vec prometheus.GaugeVec, labelValues []string) gaugeKey {
+	const (
+		offset64  = 14695981039346656037
+		prime64   = 1099511628211
+		separator = 0xff
+	)
+
+	hash := uint64(offset64)
+
+	for _, value := range labelValues {
+		for i := 0; i < len(value); i++ {
+			hash ^= uint64(value[i])
+			hash *= prime64
+		}
+
+		hash ^= separator
+		hash *= prime64
+	}
+
+	return gaugeKey{vec: vec.MetricVec, hash: hash}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// set sets the child of vec with the label values to value, unless it already
+// has that value.  Values are compared bit for bit, so that NaN is unchanged.
+// Label values whose hash collides with those of another child are set on the
+// vector directly.
+func (g *gaugeCache) set(vec prometheus.GaugeVec, value float64, labelValues ...string) {
+	key := newGaugeKey(vec, labelValues)
+	bits := math.Float64bits(value)
+
+	cached, ok := g.gauges[key]
+	if ok && !equalStrings(cached.labelValues, labelValues) {
+		vec.WithLabelValues(labelValues...).Set(value)
+		return
+	}
+
+	if ok && cached.value == bits {
+		return
+	}
+
+	if !ok {
+		cached = &cachedGauge{
+			gauge:       vec.WithLabelValues(labelValues...),
+			labelValues: append([]string(nil), labelValues...),
+		}
+		g.gauges[key] = cached
+	}
+
+	cached.gauge.Set(value)
+	cached.value = bits
+}
+
+// delete deletes the child of vec with the label values, which must be done
+// through the cache so that a later set does not set the deleted child.
+func (g *gaugeCache) delete(vec prometheus.GaugeVec, labelValues ...string) {
+	key := newGaugeKey(vec, labelValues)
+	if cached, ok := g.gauges[key]; ok && equalStrings(cached.labelValues, labelValues) {
+		delete(g.gauges, key)
+	}
+
+	vec.DeleteLabelValues(labelValues...)
+}
+
+// labelValueCache resolves the values of each set of labels for a bucket on a
+// node once, rather than for every one of the bucket's stats, which mostly
+// share the same labels.  The values returned are shared, and must not be
+// modified.
+type labelValueCache struct {
+	manager util.CbLabelManager
+	ctx     util.MetricContext
+	entries []labelValueEntry
+}
+
+type labelValueEntry struct {
+	labels []string
+	values []string
+}
+
+func newLabelValueCache(manager util.CbLabelManager, ctx util.MetricContext) *labelValueCache {
+	return &labelValueCache{manager: manager, ctx: ctx}
+}
+
+// get returns the values of labels.  There are only ever a few different sets
+// of labels, so they are searched in turn.
+func (l *labelValueCache) get(labels []string) []string {
+	for _, entry := range l.entries {
+		if equalStrings(entry.labels, labels) {
+			return entry.values
+		}
+	}
+
+	values := l.manager.GetLabelValues(labels, l.ctx)
+	l.entries = append(l.entries, labelValueEntry{labels: labels, values: values})
+
+	return values
+}
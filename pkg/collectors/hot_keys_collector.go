@@ -0,0 +1,158 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type hotKeysCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+	top    int
+}
+
+// NewHotKeysCollector creates a collector for the most frequently accessed
+// document keys of every bucket, as sampled by the data service and reported
+// with the bucket stats.  Only the top hottest keys of each bucket are
+// exported, or every key reported if top is not positive, so that a hot
+// partition can be traced back to the documents that make it hot.
+func NewHotKeysCollector(client util.CbClient, top int, config *objects.CollectorConfig,
+	labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetHotKeysCollectorDefaultConfig()
+	}
+
+	return &hotKeysCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+		top:    top,
+	}
+}
+
+// Describe all metrics.
+func (c *hotKeysCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *hotKeysCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *hotKeysCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting hot key metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	buckets, err := c.m.client.Buckets(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape buckets")
+
+		return
+	}
+
+	for _, bucket := range buckets {
+		stats, err := c.m.client.BucketStats(reqCtx, bucket.Name)
+		if err != nil {
+			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+			log.Error("failed to scrape hot keys of bucket %s: %s", bucket.Name, err)
+
+			return
+		}
+
+		ctx.BucketName = bucket.Name
+
+		c.collectBucket(ch, stats.HotKeys, ctx)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+func (c *hotKeysCollector) collectBucket(ch chan<- prometheus.Metric, hotKeys []objects.HotKey, ctx util.MetricContext) {
+	// the data service reports its sample in no particular order.
+	sorted := append([]objects.HotKey{}, hotKeys...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Ops > sorted[j].Ops
+	})
+
+	if c.top > 0 && len(sorted) > c.top {
+		sorted = sorted[:c.top]
+	}
+
+	for i, key := range sorted {
+		ctx.DocumentKey = key.Name
+		ctx.Rank = strconv.Itoa(i + 1)
+
+		if value, ok := c.config.Lookup(objects.HotKeyInfo); ok {
+			c.send(ch, value, 1, ctx)
+		}
+
+		if value, ok := c.config.Lookup(objects.HotKeyOps); ok {
+			c.send(ch, value, key.Ops, ctx)
+		}
+	}
+}
+
+func (c *hotKeysCollector) send(ch chan<- prometheus.Metric, value objects.MetricInfo, stat float64, ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
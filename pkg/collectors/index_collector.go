@@ -10,6 +10,7 @@
 package collectors
 
 import (
+	"context"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -65,6 +66,11 @@ func (c *indexCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect all metrics.
 func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *indexCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -72,7 +78,7 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting index metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -81,7 +87,7 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	indexStats, err := c.m.client.Index()
+	indexStats, err := c.m.client.Index(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -90,7 +96,9 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	currentNode, err := c.m.client.GetCurrentNode()
+	observeSampleAge(c.config.Name, indexStats.Op.Samples)
+
+	currentNode, err := c.m.client.GetCurrentNode(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -100,7 +108,7 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	if contains(currentNode.Services, "index") {
-		stats, err := c.m.client.IndexStats()
+		stats, err := c.m.client.IndexStats(reqCtx)
 		if err != nil {
 			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -109,7 +117,24 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 			return
 		}
 
-		for _, value := range c.config.Metrics {
+		for key, value := range c.config.Metrics {
+			if isIndexStatusMetric(key) {
+				continue
+			}
+
+			if stat, ok := objects.IndexerMetrics[value.Name]; ok {
+				if val, ok := stats[objects.IndexerStats][stat].(float64); ok && value.Enabled {
+					ch <- prometheus.MustNewConstMetric(
+						value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+						prometheus.GaugeValue,
+						val,
+						c.m.labelManger.GetLabelValues(value.Labels, ctx)...,
+					)
+				}
+
+				continue
+			}
+
 			if value.Enabled && !contains(value.Labels, objects.KeyspaceLabel) {
 				ch <- prometheus.MustNewConstMetric(
 					value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
@@ -119,8 +144,8 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 				)
 			} else {
 				for key, values := range stats {
-					ctx, _ = c.m.labelManger.GetMetricContext("", key)
-					if key == "indexer" {
+					ctx, _ = c.m.labelManger.GetMetricContext(reqCtx, "", key)
+					if key == objects.IndexerStats {
 						continue
 					}
 
@@ -139,7 +164,11 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 			}
 		}
 	} else {
-		for _, value := range c.config.Metrics {
+		for key, value := range c.config.Metrics {
+			if _, ok := objects.IndexerMetrics[value.Name]; ok || isIndexStatusMetric(key) {
+				continue
+			}
+
 			if value.Enabled && !contains(value.Labels, objects.KeyspaceLabel) {
 				ch <- prometheus.MustNewConstMetric(
 					value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
@@ -151,6 +180,86 @@ func (c *indexCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
+	c.collectIndexStatus(reqCtx, ch, ctx)
+
 	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
 	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
 }
+
+func isIndexStatusMetric(key string) bool {
+	return key == objects.IndexStorageInfo || key == objects.IndexDuplicates
+}
+
+// collectIndexStatus reports the storage mode of every index in the cluster
+// and how many indexes on each keyspace duplicate another, from the index
+// definitions.  The indexes are still reported without them, as reading
+// the definitions needs more privileges than the stats.
+func (c *indexCollector) collectIndexStatus(reqCtx context.Context, ch chan<- prometheus.Metric, ctx util.MetricContext) {
+	storageInfo, storageInfoEnabled := c.config.Lookup(objects.IndexStorageInfo)
+	duplicates, duplicatesEnabled := c.config.Lookup(objects.IndexDuplicates)
+
+	if !storageInfoEnabled && !duplicatesEnabled {
+		return
+	}
+
+	status, err := c.m.client.IndexStatus(reqCtx)
+	if err != nil {
+		log.Error("failed to scrape index status: %s", err)
+		return
+	}
+
+	// replicas of an index are listed separately, and are neither reported
+	// twice nor counted as duplicates.
+	seen := map[string]bool{}
+	signatures := map[string]map[string]int{}
+	keyspaces := []string{}
+
+	for _, index := range status.Indexes {
+		keyspace := index.Keyspace()
+
+		id := keyspace + "\xff" + index.IndexName
+		if seen[id] {
+			continue
+		}
+
+		seen[id] = true
+
+		if _, ok := signatures[keyspace]; !ok {
+			signatures[keyspace] = map[string]int{}
+			keyspaces = append(keyspaces, keyspace)
+		}
+
+		signatures[keyspace][index.Signature()]++
+
+		if storageInfoEnabled {
+			ctx.Keyspace = keyspace
+			ctx.Index = index.IndexName
+			ctx.StorageMode = index.StorageMode
+
+			ch <- prometheus.MustNewConstMetric(
+				storageInfo.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.GaugeValue,
+				1,
+				c.m.labelManger.GetLabelValues(storageInfo.Labels, ctx)...)
+		}
+	}
+
+	if !duplicatesEnabled {
+		return
+	}
+
+	for _, keyspace := range keyspaces {
+		count := 0
+		for _, n := range signatures[keyspace] {
+			count += n - 1
+		}
+
+		ctx.Keyspace = keyspace
+
+		ch <- prometheus.MustNewConstMetric(
+			duplicates.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			float64(count),
+			c.m.labelManger.GetLabelValues(duplicates.Labels, ctx)...)
+	}
+}
@@ -0,0 +1,130 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type kvConnectionsCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+// NewKVConnectionsCollector creates a collector for memcached's connection
+// stats on every data service node, read from the Couchbase Server 7 stats
+// API.  Unlike the bucket scoped curr_connections they count every connection
+// to the node, whichever bucket it selected, and show connections being
+// rejected when the node reaches its connection limit.
+func NewKVConnectionsCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetKVConnectionsCollectorDefaultConfig()
+	}
+
+	return &kvConnectionsCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *kvConnectionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *kvConnectionsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *kvConnectionsCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting KV connection metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	for key, stat := range objects.KVConnectionStats {
+		value, ok := c.config.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		stats, err := c.m.client.NodeStatsRange(reqCtx, stat)
+		if err != nil {
+			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+			log.Error("failed to scrape KV connection stats: %s", err)
+
+			return
+		}
+
+		// connections accepted and rejected are counted since memcached
+		// started.
+		valueType := prometheus.GaugeValue
+		if key == objects.KVConnectionsRejected || key == objects.KVConnectionsTotal {
+			valueType = prometheus.CounterValue
+		}
+
+		for node, stat := range stats.LastByNode() {
+			nodeCtx := ctx
+			nodeCtx.NodeHostname = node
+
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				valueType,
+				stat,
+				c.m.labelManger.GetLabelValues(value.Labels, nodeCtx)...)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
@@ -12,8 +12,10 @@
 package collectors
 
 import (
+	"context"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -30,6 +32,9 @@ const (
 	memoryFree           = "memoryFree"
 	mcdMemoryAllocated   = "mcdMemoryAllocated"
 	mcdMemoryReserved    = "mcdMemoryReserved"
+	serverGroupInfo      = objects.ServerGroupInfo
+	clusterInfo          = objects.ClusterInfo
+	versionInfo          = objects.VersionInfo
 	interestingStats     = "interestingStats"
 	systemStats          = "systemStats"
 	interestingStatsTrim = "interestingstats_"
@@ -114,6 +119,11 @@ func contains(haystack []string, needle string) bool {
 
 // Collect all metrics.
 func (c *nodesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *nodesCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -121,7 +131,7 @@ func (c *nodesCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting nodes metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
@@ -131,7 +141,7 @@ func (c *nodesCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	nodes, err := c.m.client.Nodes()
+	nodes, err := c.m.client.Nodes(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -140,9 +150,15 @@ func (c *nodesCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	groups := c.serverGroups(reqCtx)
+
 	for key, value := range c.config.Metrics {
 		if contains(nodeSpecificStats, key) || strings.HasPrefix(key, interestingStats) || strings.HasPrefix(key, systemStats) {
-			c.addNodeStats(ch, key, value, &nodes)
+			c.addNodeStats(reqCtx, ch, key, value, &nodes, groups)
+		} else if key == clusterInfo {
+			c.addClusterInfo(reqCtx, ch, value, ctx, &nodes)
+		} else if key == objects.ClockSkew {
+			c.addClockSkew(reqCtx, ch, value, ctx, &nodes)
 		} else {
 			ch <- prometheus.MustNewConstMetric(
 				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
@@ -156,6 +172,79 @@ func (c *nodesCollector) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
 }
 
+// addClusterInfo reports the cluster UUID, which identifies the cluster even
+// after it is renamed, along with its edition and version.  The version is
+// that of the node the exporter reads from, while the compatibility version
+// is that of the oldest node, so the two differ part way through an upgrade.
+func (c *nodesCollector) addClusterInfo(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext, nodes *objects.Nodes) {
+	if !value.Enabled {
+		return
+	}
+
+	pools, err := c.m.client.Pools(reqCtx)
+	if err != nil {
+		log.Debug("cluster UUID unavailable: %s", err)
+		return
+	}
+
+	ctx.ClusterUUID = pools.UUID
+	ctx.Edition = pools.Edition()
+	ctx.Version = objects.ServerVersion(pools.ImplementationVersion)
+	ctx.CompatVersion = nodes.CompatVersion()
+
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		1,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
+
+// addClockSkew reports how far the clock of each node is from the exporter's,
+// read from the Date of the node's response, which is only to the second.
+// Nodes that do not answer are left out rather than marking the collector down.
+func (c *nodesCollector) addClockSkew(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext, nodes *objects.Nodes) {
+	if !value.Enabled {
+		return
+	}
+
+	skews := make([]*time.Duration, len(nodes.Nodes))
+
+	var wg sync.WaitGroup
+
+	for i, node := range nodes.Nodes {
+		wg.Add(1)
+
+		go func(i int, hostname string) {
+			defer wg.Done()
+
+			nodeTime, err := c.m.client.NodeTime(reqCtx, hostname)
+			if err != nil {
+				log.Debug("time of node %s unavailable: %s", hostname, err)
+				return
+			}
+
+			skew := nodeTime.Skew()
+			skews[i] = &skew
+		}(i, node.Hostname)
+	}
+
+	wg.Wait()
+
+	for i, node := range nodes.Nodes {
+		if skews[i] == nil {
+			continue
+		}
+
+		ctx.NodeHostname = node.Hostname
+
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			skews[i].Seconds(),
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+}
+
 func getUptimeValue(uptime string, bitSize int) float64 {
 	up, err := strconv.ParseFloat(uptime, bitSize)
 
@@ -168,12 +257,39 @@ func getUptimeValue(uptime string, bitSize int) float64 {
 
 // These are the metrics that we collect per node.  Including metrics with "InterestingStats" and "SystemStats" prefixes.  This list allows us to check
 // metrics to see if we should collect them per node, or not.
-var nodeSpecificStats = []string{healthyState, uptime, clusterMembership, memoryTotal, memoryFree, mcdMemoryAllocated, mcdMemoryReserved}
+var nodeSpecificStats = []string{healthyState, uptime, clusterMembership, memoryTotal, memoryFree, mcdMemoryAllocated, mcdMemoryReserved, serverGroupInfo, versionInfo}
+
+// serverGroups maps each node to its server group, but only when an enabled
+// metric is labelled with it.  Server groups are an Enterprise Edition
+// feature, so failing to read them does not mark the collector as down.
+func (c *nodesCollector) serverGroups(reqCtx context.Context) map[string]string {
+	labelled := false
+
+	for _, value := range c.config.Metrics {
+		if value.Enabled && contains(value.Labels, objects.ServerGroupLabel) {
+			labelled = true
+			break
+		}
+	}
+
+	if !labelled {
+		return nil
+	}
+
+	groups, err := c.m.client.ServerGroups(reqCtx)
+	if err != nil {
+		log.Debug("server groups unavailable: %s", err)
+		return nil
+	}
+
+	return groups.ByHostname()
+}
 
-func (c *nodesCollector) addNodeStats(ch chan<- prometheus.Metric, key string, value objects.MetricInfo, nodes *objects.Nodes) {
+func (c *nodesCollector) addNodeStats(reqCtx context.Context, ch chan<- prometheus.Metric, key string, value objects.MetricInfo, nodes *objects.Nodes, groups map[string]string) {
 	for _, node := range nodes.Nodes {
-		ctx, _ := c.m.labelManger.GetMetricContext("", "")
+		ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, "", "")
 		ctx.NodeHostname = node.Hostname
+		ctx.ServerGroup = groups[node.Hostname]
 		log.Debug("Collecting %s-%s node metrics for metric %s", ctx.ClusterName, ctx.NodeHostname, key)
 
 		switch key {
@@ -220,6 +336,24 @@ func (c *nodesCollector) addNodeStats(ch chan<- prometheus.Metric, key string, v
 				prometheus.CounterValue,
 				node.McdMemoryReserved,
 				c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+		case versionInfo:
+			ctx.Version = objects.ServerVersion(node.Version)
+
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.GaugeValue,
+				1,
+				c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+		case serverGroupInfo:
+			if ctx.ServerGroup == "" {
+				continue
+			}
+
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.GaugeValue,
+				1,
+				c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
 		default:
 			c.handleNonSpecificNodeMetrics(ch, key, value, node, ctx)
 		}
@@ -234,10 +368,18 @@ func (c *nodesCollector) handleNonSpecificNodeMetrics(ch chan<- prometheus.Metri
 			node.InterestingStats[strings.TrimPrefix(value.Name, interestingStatsTrim)],
 			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
 	} else if strings.HasPrefix(key, systemStats) {
+		// older versions of Couchbase Server do not report the steal,
+		// allocation stall and limit fields, which are left out rather than
+		// reported as 0.
+		stat, ok := node.SystemStats[strings.TrimPrefix(value.Name, systemStatsTrim)]
+		if !ok {
+			return
+		}
+
 		ch <- prometheus.MustNewConstMetric(
 			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
 			prometheus.GaugeValue,
-			node.SystemStats[strings.TrimPrefix(value.Name, systemStatsTrim)],
+			stat,
 			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
 	}
 }
@@ -0,0 +1,76 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxResolutionBackoff is the most collections skipped for a bucket whose
+// servers do not include the node.
+const maxResolutionBackoff = 32
+
+var nodeResolutionFailuresVec = objects.NewExporterCounterVec(
+	prometheus.CounterOpts{
+		Namespace: objects.ExporterNamespace,
+		Name:      "node_resolution_failures_total",
+		Help:      "Number of times the node was not among the servers of the bucket, so its per node stats could not be requested",
+	},
+	[]string{objects.BucketLabel, objects.NodeLabel})
+
+// nodeResolution backs off looking for a node among the servers of a bucket
+// that did not include it, doubling the number of collections skipped after
+// each failure, as the node rarely joins the bucket between two collections.
+type nodeResolution struct {
+	failures int
+	skip     int
+}
+
+// resolutionDue returns whether the node should be looked for among the
+// servers of the bucket in this collection, counting down the collections to
+// skip otherwise.
+func (c *PerNodeBucketStatsCollector) resolutionDue(bucket, node string) bool {
+	r, ok := c.resolutions[bucket+"/"+node]
+	if !ok || r.skip == 0 {
+		return true
+	}
+
+	r.skip--
+
+	return false
+}
+
+// resolutionFailed records that the node was not among the servers of the
+// bucket, and returns the number of collections that will be skipped.
+func (c *PerNodeBucketStatsCollector) resolutionFailed(bucket, node string) int {
+	nodeResolutionFailuresVec.WithLabelValues(bucket, node).Inc()
+
+	r, ok := c.resolutions[bucket+"/"+node]
+	if !ok {
+		r = &nodeResolution{}
+		c.resolutions[bucket+"/"+node] = r
+	}
+
+	r.skip = 1 << r.failures
+	if r.skip > maxResolutionBackoff {
+		r.skip = maxResolutionBackoff
+	} else {
+		r.failures++
+	}
+
+	return r.skip
+}
+
+// resolved forgets the failures to find the node among the servers of the
+// bucket.
+func (c *PerNodeBucketStatsCollector) resolved(bucket, node string) {
+	delete(c.resolutions, bucket+"/"+node)
+}
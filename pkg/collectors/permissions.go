@@ -0,0 +1,191 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	roleClusterRead  = "ro_admin or external_stats_reader"
+	roleBucketStats  = "ro_admin, or data_monitoring on every bucket"
+	roleSecurityRead = "ro_admin or security_admin"
+	roleViewsRead    = "ro_admin, or views_reader on every bucket"
+)
+
+var (
+	collectorEnabledVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "collector_enabled",
+			Help:      "1 if the collector is enabled, 0 if it was disabled because the configured user lacks the roles it needs or through the admin API",
+		},
+		[]string{"cluster", "collector"})
+)
+
+// CollectorPermissions records, by collector name, whether the configured user
+// may read the endpoints each collector depends on.
+type CollectorPermissions map[string]bool
+
+// Enabled reports whether the collector should be registered.  Collectors
+// that were not probed are assumed to be permitted.
+func (p CollectorPermissions) Enabled(config *objects.CollectorConfig) bool {
+	if config == nil {
+		return true
+	}
+
+	enabled, ok := p[config.Name]
+
+	return !ok || enabled
+}
+
+type probeFunc func(context.Context, util.CbClient) error
+
+type permissionProbe struct {
+	config *objects.CollectorConfig
+	roles  string
+	probe  probeFunc
+}
+
+// ProbePermissions requests the endpoint behind each collector once, and
+// disables any collector whose endpoint the configured user is forbidden
+// from reading.  Other errors leave the collector enabled, as they are
+// usually transient or mean the service is simply not running.  The probes
+// give up after timeout, leaving the collectors they did not get to enabled.
+// The collectors are reported under the cluster client connects to, as it is
+// named in the configuration.
+func ProbePermissions(client util.CbClient, user, cluster string, collectors *objects.ExporterCollectors,
+	timeout time.Duration) CollectorPermissions {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	permissions := CollectorPermissions{}
+
+	for _, p := range permissionProbes(collectors) {
+		if p.config == nil {
+			continue
+		}
+
+		err := p.probe(ctx, client)
+		enabled := !errors.Is(err, util.ErrForbidden)
+
+		if !enabled {
+			log.Error("User %s is not permitted to read %s, disabling the %s collector. Grant the user %s to enable it.",
+				user, collectors.Endpoint(p.config), p.config.Name, p.roles)
+		}
+
+		permissions[p.config.Name] = enabled
+		collectorEnabledVec.WithLabelValues(cluster, p.config.Name).Set(boolToFloat64(enabled))
+	}
+
+	return permissions
+}
+
+// sharedProbe runs probe the first time it is called and returns its result
+// every time, so collectors reading the same endpoints request them once.
+func sharedProbe(probe probeFunc) probeFunc {
+	var (
+		once sync.Once
+		err  error
+	)
+
+	return func(ctx context.Context, client util.CbClient) error {
+		once.Do(func() {
+			err = probe(ctx, client)
+		})
+
+		return err
+	}
+}
+
+func permissionProbes(c *objects.ExporterCollectors) []permissionProbe {
+	var buckets []objects.BucketInfo
+
+	nodes := sharedProbe(func(ctx context.Context, client util.CbClient) error {
+		_, err := client.Nodes(ctx)
+		return err
+	})
+	listBuckets := sharedProbe(func(ctx context.Context, client util.CbClient) (err error) {
+		buckets, err = client.Buckets(ctx)
+		return err
+	})
+
+	// bucket level roles may permit some buckets and not others, but reading
+	// one bucket is enough to tell a user without them, and reading every
+	// bucket of a large cluster would hold up startup.
+	bucketStats := sharedProbe(func(ctx context.Context, client util.CbClient) error {
+		if err := listBuckets(ctx, client); err != nil || len(buckets) == 0 {
+			return err
+		}
+
+		_, err := client.BucketStats(ctx, buckets[0].Name)
+
+		return err
+	})
+	designDocs := sharedProbe(func(ctx context.Context, client util.CbClient) error {
+		if err := listBuckets(ctx, client); err != nil || len(buckets) == 0 {
+			return err
+		}
+
+		_, err := client.DesignDocs(ctx, buckets[0].Name)
+
+		return err
+	})
+
+	return []permissionProbe{
+		{c.Node, roleClusterRead, nodes},
+		{c.BucketInfo, roleClusterRead, listBuckets},
+		{c.Task, roleClusterRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.Tasks(ctx)
+			return err
+		}},
+		{c.Query, roleClusterRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.Query(ctx)
+			return err
+		}},
+		{c.Index, roleClusterRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.Index(ctx)
+			return err
+		}},
+		{c.Search, roleClusterRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.Fts(ctx)
+			return err
+		}},
+		{c.Analytics, roleClusterRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.Cbas(ctx)
+			return err
+		}},
+		{c.Eventing, roleClusterRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.Eventing(ctx)
+			return err
+		}},
+		{c.Alerts, roleClusterRead, nodes},
+		{c.Rollup, roleClusterRead, listBuckets},
+		{c.Audit, roleSecurityRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.AuditSettings(ctx)
+			return err
+		}},
+		{c.Security, roleSecurityRead, func(ctx context.Context, client util.CbClient) error {
+			_, err := client.SecuritySettings(ctx)
+			return err
+		}},
+		{c.Views, roleViewsRead, designDocs},
+		{c.BucketStats, roleBucketStats, bucketStats},
+		{c.PerNodeBucketStats, roleBucketStats, bucketStats},
+	}
+}
@@ -1,16 +1,18 @@
 package collectors
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
+	"math"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
 	"github.com/couchbase/couchbase-exporter/pkg/objects"
 	"github.com/couchbase/couchbase-exporter/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 const (
@@ -18,11 +20,15 @@ const (
 	notFound         = "node not found"
 	namespace        = "cbpernode_bucketstats"
 	subsystem        = ""
+
+	// maxParallelNodeRequests bounds the per-node stats requests in flight
+	// for a bucket in cluster mode.
+	maxParallelNodeRequests = 8
 )
 
 var (
 	ErrNotFound = fmt.Errorf(notFound)
-	upVec       = promauto.NewGaugeVec(
+	upVec       = objects.NewExporterGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Subsystem:   subsystem,
@@ -31,7 +37,7 @@ var (
 			ConstLabels: nil,
 		},
 		[]string{objects.ClusterLabel})
-	scrapeVec = promauto.NewGaugeVec(
+	scrapeVec = objects.NewExporterGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace:   namespace,
 			Subsystem:   subsystem,
@@ -40,6 +46,22 @@ var (
 			ConstLabels: nil,
 		},
 		[]string{objects.ClusterLabel})
+	balancedVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace:   namespace,
+			Subsystem:   subsystem,
+			Name:        "cluster_balanced",
+			Help:        "1 if the cluster is balanced, 0 while it needs a rebalance or one is in progress",
+			ConstLabels: nil,
+		},
+		[]string{objects.ClusterLabel})
+	unparseableSamplesVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "unparseable_samples_total",
+			Help:      "Number of latest samples of a stat that were not numbers, such as \"undefined\"",
+		},
+		[]string{"stat"})
 )
 
 type PrometheusVecSetter interface {
@@ -53,7 +75,20 @@ type PerNodeBucketStatsCollector struct {
 	client         util.CbClient
 	up             *prometheus.GaugeVec
 	scrapeDuration *prometheus.GaugeVec
+	balanced       *prometheus.GaugeVec
 	labelManger    util.CbLabelManager
+	cluster        string
+	clusterMode    bool
+	nodeName       string
+	waitRebalance  bool
+	rebalanceWait  rebalanceWait
+	resolutions    map[string]*nodeResolution
+	statKeys       statKeys
+	gauges         *gaugeCache
+	schedule       *bucketSchedule
+	deadNodes      *deadNodes
+	undefined      string
+	exemplars      bool
 	// This is for TESTING purposes only.
 	// By default PerNodeBucketStatsCollector implements and uses itself to
 	// fulfill this functionality.
@@ -68,13 +103,79 @@ func NewPerNodeBucketStatsCollector(client util.CbClient, config *objects.Collec
 		config:         config,
 		up:             upVec,
 		scrapeDuration: scrapeVec,
+		balanced:       balancedVec,
 		labelManger:    labelManager,
+		resolutions:    map[string]*nodeResolution{},
+		statKeys:       newStatKeys(config),
+		gauges:         newGaugeCache(),
+		schedule:       newBucketSchedule(),
+		deadNodes:      newDeadNodes(),
 	}
 	collector.Setter = collector
 
 	return *collector
 }
 
+// SetCluster names the cluster the collector collects from, as it is named in
+// the configuration, to tell its snapshot and the exporter's own metrics from
+// those of the other clusters.  The top level cluster has no name.
+func (c *PerNodeBucketStatsCollector) SetCluster(name string) {
+	c.cluster = name
+}
+
+// SetClusterMode makes the collector gather the stats of every node in the
+// cluster, rather than only those of the node the exporter is attached to.
+func (c *PerNodeBucketStatsCollector) SetClusterMode(enabled bool) {
+	c.clusterMode = enabled
+}
+
+// SetNodeName makes the collector gather the stats of the named node rather
+// than those of the node the exporter is attached to, so that a central
+// exporter can cover a node it cannot run beside.  The name is that of a
+// node in /pools/default, with or without its port.  It has no effect in
+// cluster mode, where every node is collected.
+func (c *PerNodeBucketStatsCollector) SetNodeName(name string) {
+	c.nodeName = name
+}
+
+// SetBucketPriority makes the collector collect the buckets of low priority
+// only every so many collections.
+func (c *PerNodeBucketStatsCollector) SetBucketPriority(priority objects.BucketPriority) error {
+	return c.schedule.set(priority)
+}
+
+// SetSlowBuckets makes the collector collect the buckets whose stats keep
+// being slow to request less often.
+func (c *PerNodeBucketStatsCollector) SetSlowBuckets(slow objects.SlowBucketsConfig) {
+	c.schedule.setSlow(slow)
+}
+
+// SetWaitForRebalance makes the collector skip collection until the cluster
+// has been rebalanced, rather than collecting while a rebalance is needed or
+// in progress.
+func (c *PerNodeBucketStatsCollector) SetWaitForRebalance(enabled bool) {
+	c.waitRebalance = enabled
+}
+
+// SetUndefinedSamples sets how samples that are not numbers, such as the
+// "undefined" Couchbase Server returns for some stats, are exported: skipped,
+// or exported as NaN or 0.
+func (c *PerNodeBucketStatsCollector) SetUndefinedSamples(mode string) {
+	switch mode {
+	case objects.UndefinedSamplesSkip, objects.UndefinedSamplesNaN, objects.UndefinedSamplesZero:
+	default:
+		log.Warn("unknown undefined sample handling %q, such samples will be skipped", mode)
+	}
+
+	c.undefined = mode
+}
+
+// SetExemplars makes the collector attach the bucket and node that reported
+// a sample that is not a number to the count of such samples, as an exemplar.
+func (c *PerNodeBucketStatsCollector) SetExemplars(enabled bool) {
+	c.exemplars = enabled
+}
+
 func (c *PerNodeBucketStatsCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, metric := range c.metrics {
 		metric.Collect(ch)
@@ -96,16 +197,21 @@ func (c *PerNodeBucketStatsCollector) Describe(ch chan<- *prometheus.Desc) {
 }
 
 // Implements Worker interface for CycleController.
-func (c *PerNodeBucketStatsCollector) DoWork() {
-	c.CollectMetrics()
+func (c *PerNodeBucketStatsCollector) DoWork(reqCtx context.Context) {
+	c.CollectMetrics(reqCtx)
 }
 
-func (c *PerNodeBucketStatsCollector) CollectMetrics() {
+// CollectMetrics collects the per node stats of every bucket, abandoning the
+// requests in flight when reqCtx is done.
+func (c *PerNodeBucketStatsCollector) CollectMetrics(reqCtx context.Context) {
 	start := time.Now()
 
 	log.Info("Begin collection of Node Stats")
+
+	c.schedule.next()
+
 	// get current node hostname and cache it as we'll need it later when we re-execute
-	ctx, err := c.labelManger.GetBasicMetricContext()
+	ctx, err := c.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		c.Setter.SetGaugeVec(*c.up, 0, objects.ClusterLabel)
 		log.Error("%s", err)
@@ -115,7 +221,7 @@ func (c *PerNodeBucketStatsCollector) CollectMetrics() {
 
 	log.Info("Cluster name is: %s", ctx.ClusterName)
 
-	rebalanced, err := getClusterBalancedStatus(c.client)
+	nodes, err := c.client.Nodes(reqCtx)
 	if err != nil {
 		c.Setter.SetGaugeVec(*c.up, 0, ctx.ClusterName)
 		log.Error("Unable to get rebalance status %s", err)
@@ -123,12 +229,34 @@ func (c *PerNodeBucketStatsCollector) CollectMetrics() {
 		return
 	}
 
-	if !rebalanced {
-		log.Info("Waiting for Rebalance... retrying...")
-		return
+	c.Setter.SetGaugeVec(*c.balanced, boolToFloat64(isBalanced(nodes)), ctx.ClusterName)
+
+	if c.waitRebalance {
+		if !rebalanceSettled(nodes) {
+			waited := c.rebalanceWait.retry(ctx.ClusterName, time.Now())
+			log.Info("Waiting for Rebalance for %s... retrying...", waited.Round(time.Second))
+
+			return
+		}
+
+		c.rebalanceWait.done(ctx.ClusterName)
+	}
+
+	hostname := ""
+
+	if c.nodeName != "" && !c.clusterMode {
+		node, ok := findNode(nodes, c.nodeName)
+		if !ok {
+			c.Setter.SetGaugeVec(*c.up, 0, ctx.ClusterName)
+			log.Error("node %s is not part of the cluster", c.nodeName)
+
+			return
+		}
+
+		hostname = node.Hostname
 	}
 
-	buckets, err := c.client.Buckets()
+	buckets, err := c.client.Buckets(reqCtx)
 	if err != nil {
 		c.Setter.SetGaugeVec(*c.up, 0, ctx.ClusterName)
 		log.Error("Unable to get buckets %s", err)
@@ -136,11 +264,58 @@ func (c *PerNodeBucketStatsCollector) CollectMetrics() {
 		return
 	}
 
+	c.schedule.queue(c.config.Name, ctx.ClusterName, buckets)
+
+	healthy := true
+
 	for _, bucket := range buckets {
+		if reqCtx.Err() != nil {
+			c.Setter.SetGaugeVec(*c.up, 0, ctx.ClusterName)
+			log.Warn("per node bucket stats collection stopped before bucket %s: %s", bucket.Name, reqCtx.Err())
+
+			return
+		}
+
+		if !c.schedule.bucketDue(bucket.Name) {
+			continue
+		}
+
+		c.schedule.started()
+
+		ctx, _ := c.labelManger.GetMetricContext(reqCtx, bucket.Name, "")
+		ctx.BucketType = bucket.BucketType
+
+		bucketStart := time.Now()
+
+		if c.clusterMode {
+			if !c.collectAllNodes(reqCtx, ctx, bucket.ReplicaNumber, bucketStart) {
+				healthy = false
+			}
+
+			continue
+		}
+
+		if hostname != "" {
+			ctx.NodeHostname = hostname
+		}
+
+		if !c.resolutionDue(bucket.Name, ctx.NodeHostname) {
+			healthy = false
+			continue
+		}
+
 		log.Debug("Collecting per-node bucket stats, node=%s, bucket=%s", ctx.NodeHostname, bucket.Name)
 
-		ctx, _ := c.labelManger.GetMetricContext(bucket.Name, "")
-		samples, err := getPerNodeBucketStats(c.client, ctx)
+		samples, err := getPerNodeBucketStats(reqCtx, c.client, ctx)
+
+		if errors.Is(err, ErrNotFound) {
+			skip := c.resolutionFailed(bucket.Name, ctx.NodeHostname)
+			log.Warn("%s, skipping the next %d collections of the bucket", err, skip)
+
+			healthy = false
+
+			continue
+		}
 
 		if err != nil {
 			c.Setter.SetGaugeVec(*c.up, 0, ctx.ClusterName)
@@ -148,79 +323,258 @@ func (c *PerNodeBucketStatsCollector) CollectMetrics() {
 			return
 		}
 
+		c.resolved(bucket.Name, ctx.NodeHostname)
+
+		observeBucketScrape(c.config.Name, bucket.Name, bucketStart, len(samples))
+		c.schedule.observe(c.config.Name, ctx.ClusterName, bucket.Name, time.Since(bucketStart))
+		observeSampleAge(c.config.Name, samples)
+		addReplicaSkew(samples, bucket.ReplicaNumber)
+		c.statKeys.observe(c.config.Name, bucket.BucketType, samples)
+
+		labels := newLabelValueCache(c.labelManger, ctx)
+
 		for _, value := range c.config.Metrics {
-			c.setMetric(value, samples, ctx)
+			c.setMetric(value, samples, ctx, labels)
 		}
+
+		samples.Release()
+	}
+
+	if !healthy {
+		c.Setter.SetGaugeVec(*c.up, 0, ctx.ClusterName)
+		return
 	}
 
 	c.Setter.SetGaugeVec(*c.up, 1, ctx.ClusterName)
 	c.Setter.SetGaugeVec(*c.scrapeDuration, time.Since(start).Seconds(), ctx.ClusterName)
+	markSnapshotFresh(c.SnapshotKey())
 	log.Info("Per node bucket stats is complete Duration: %v", time.Since(start))
 }
 
-func (c *PerNodeBucketStatsCollector) setMetric(metric objects.MetricInfo, samples map[string]interface{}, ctx util.MetricContext) {
+type nodeBucketStats struct {
+	ctx     util.MetricContext
+	samples objects.Samples
+	err     error
+}
+
+// collectAllNodes requests the stats of the context's bucket from every node
+// that serves it in parallel, and sets the metrics of each node that answered.
+// It returns false if the stats of any node could not be retrieved.  The cost
+// of the bucket, which has the given number of replicas, is observed across
+// all of its nodes, from start.
+func (c *PerNodeBucketStatsCollector) collectAllNodes(reqCtx context.Context, ctx util.MetricContext, replicas int, start time.Time) bool {
+	servers, err := c.client.Servers(reqCtx, ctx.BucketName)
+	if err != nil {
+		log.Error("unable to retrieve Servers %s", err)
+		return false
+	}
+
+	results := make(chan nodeBucketStats, len(servers.Servers))
+	limit := make(chan struct{}, maxParallelNodeRequests)
+
+	var wg sync.WaitGroup
+
+	for _, server := range servers.Servers {
+		nodeCtx := ctx
+		nodeCtx.NodeHostname = server.Hostname
+		uri := server.Stats["uri"]
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			if c.deadNodes.skip(nodeCtx.NodeHostname, time.Now()) {
+				results <- nodeBucketStats{ctx: nodeCtx, err: ErrNodeSkipped}
+				return
+			}
+
+			log.Debug("Collecting per-node bucket stats, node=%s, bucket=%s", nodeCtx.NodeHostname, nodeCtx.BucketName)
+
+			var bucketStats objects.PerNodeBucketStats
+			err := c.client.Get(reqCtx, uri, &bucketStats)
+			results <- nodeBucketStats{ctx: nodeCtx, samples: bucketStats.Op.Samples, err: err}
+		}()
+	}
+
+	wg.Wait()
+	close(results)
+
+	ok := true
+	samples := 0
+
+	for result := range results {
+		switch {
+		case errors.Is(result.err, ErrNodeSkipped):
+			log.Debug("skipping the per node stats of bucket %s on node %s", result.ctx.BucketName, result.ctx.NodeHostname)
+
+			ok = false
+
+			continue
+		case result.err != nil && reqCtx.Err() == nil:
+			backoff := c.deadNodes.failed(result.ctx.NodeHostname, time.Now())
+			log.Error("unable to GET PerNodeBucketStats for node %s, skipping it for %s: %s", result.ctx.NodeHostname, backoff, result.err)
+
+			ok = false
+
+			continue
+		case result.err != nil:
+			log.Error("unable to GET PerNodeBucketStats for node %s: %s", result.ctx.NodeHostname, result.err)
+
+			ok = false
+
+			continue
+		}
+
+		c.deadNodes.reachable(result.ctx.NodeHostname)
+
+		samples += len(result.samples)
+		observeSampleAge(c.config.Name, result.samples)
+		addReplicaSkew(result.samples, replicas)
+		c.statKeys.observe(c.config.Name, result.ctx.BucketType, result.samples)
+
+		labels := newLabelValueCache(c.labelManger, result.ctx)
+
+		for _, value := range c.config.Metrics {
+			c.setMetric(value, result.samples, result.ctx, labels)
+		}
+
+		result.samples.Release()
+	}
+
+	observeBucketScrape(c.config.Name, ctx.BucketName, start, samples)
+	c.schedule.observe(c.config.Name, ctx.ClusterName, ctx.BucketName, time.Since(start))
+
+	return ok
+}
+
+// Implements Snapshotter interface.
+func (c *PerNodeBucketStatsCollector) SnapshotKey() SnapshotKey {
+	return SnapshotKey{Cluster: c.cluster, Collector: c.config.Name}
+}
+
+// Implements Snapshotter interface.
+func (c *PerNodeBucketStatsCollector) Snapshot() []objects.MetricSample {
+	return snapshotGaugeVecs(c.metrics)
+}
+
+// Implements Snapshotter interface.
+func (c *PerNodeBucketStatsCollector) Restore(samples []objects.MetricSample) {
+	restoreGaugeVecs(c.config, c.registry, c.metrics, samples)
+}
+
+func (c *PerNodeBucketStatsCollector) setMetric(metric objects.MetricInfo, samples objects.Samples, ctx util.MetricContext, labels *labelValueCache) {
 	if !metric.Enabled {
 		return
 	}
 
-	if mt, ok := c.metrics[metric.Name]; ok {
-		c.Setter.SetGaugeVec(*mt, last(strToFloatArr(fmt.Sprint(samples[metric.Name]))), c.labelManger.GetLabelValues(metric.Labels, ctx)...)
-	} else {
-		mt := metric.GetPrometheusGaugeVec(c.registry, c.config.Namespace, c.config.Subsystem)
-		c.metrics[metric.Name] = mt
-		stats := strToFloatArr(fmt.Sprint(samples[metric.Name]))
-		if len(stats) > 0 {
-			c.Setter.SetGaugeVec(*mt, last(stats), c.labelManger.GetLabelValues(metric.Labels, ctx)...)
+	mt, ok := c.metrics[metric.Name]
+	if !objects.BucketStatApplies(ctx.BucketType, metric.Name) {
+		if ok {
+			c.gauges.delete(*mt, labels.get(metric.Labels)...)
 		}
+
+		return
 	}
+
+	if !ok {
+		mt = metric.GetPrometheusGaugeVec(c.registry, c.config.Namespace, c.config.Subsystem)
+		c.metrics[metric.Name] = mt
+	}
+
+	labelValues := labels.get(metric.Labels)
+
+	stat, ok := c.latestSample(metric.Name, samples[metric.Name], ctx)
+	if !ok {
+		// drop the series rather than leave the previous sample behind.
+		c.gauges.delete(*mt, labelValues...)
+		return
+	}
+
+	c.Setter.SetGaugeVec(*mt, stat, labelValues...)
 }
 
-func getClusterBalancedStatus(c util.CbClient) (bool, error) {
-	node, err := c.Nodes()
-	if err != nil {
-		return false, fmt.Errorf("unable to retrieve nodes, %w", err)
+// findNode returns the node of the cluster with the given hostname, which
+// may leave out the port.
+func findNode(nodes objects.Nodes, name string) (objects.Node, bool) {
+	for _, node := range nodes.Nodes {
+		host, _, err := net.SplitHostPort(node.Hostname)
+		if node.Hostname == name || (err == nil && host == name) {
+			return node, true
+		}
 	}
 
-	return node.Counters[rebalanceSuccess] > 0 || (node.Balanced && node.RebalanceStatus == "none"), nil
+	return objects.Node{}, false
+}
+
+// isBalanced reports whether the cluster is balanced with no rebalance running.
+func isBalanced(nodes objects.Nodes) bool {
+	return nodes.Balanced && nodes.RebalanceStatus == "none"
+}
+
+// rebalanceSettled reports whether collection may go ahead when waiting for
+// rebalances, which it may once any rebalance has ever succeeded.
+func rebalanceSettled(nodes objects.Nodes) bool {
+	return nodes.Counters[rebalanceSuccess] > 0 || isBalanced(nodes)
 }
 
 func (c *PerNodeBucketStatsCollector) SetGaugeVec(vec prometheus.GaugeVec, stat float64, labelValues ...string) {
-	vec.WithLabelValues(labelValues...).Set(stat)
+	c.gauges.set(vec, stat, labelValues...)
 }
 
-func strToFloatArr(floatsStr string) []float64 {
-	floatsStrArr := strings.Split(floatsStr, " ")
+// latestSample returns the most recent of the samples Couchbase Server
+// returned for stat.  A sample that is not a number, such as "undefined", is
+// counted and then skipped, or exported as NaN or 0 as configured.  ok is
+// false if there is no sample to export.
+func (c *PerNodeBucketStatsCollector) latestSample(stat string, samples []float64, ctx util.MetricContext) (float64, bool) {
+	// if the key is omitted from the results (Which we know happens depending on version of CBS), or is null, this is nil.
+	if samples == nil {
+		return 0, true
+	}
 
-	var floatsArr []float64
+	if len(samples) == 0 {
+		return 0, false
+	}
 
-	for _, f := range floatsStrArr {
-		parse := f
+	latest := samples[len(samples)-1]
+	if !math.IsNaN(latest) {
+		return latest, true
+	}
 
-		if strings.HasPrefix(parse, "[") {
-			parse = strings.Replace(parse, "[", "", 1)
-		}
+	c.countUnparseable(stat, ctx)
 
-		if strings.HasSuffix(parse, "]") {
-			parse = strings.Replace(parse, "]", "", 1)
-		}
-		// if the key is omitted from the results (Which we know happens depending on version of CBS), this could be <nil>.
-		if strings.Contains(parse, "<nil>") {
-			parse = "0.0"
-		}
+	switch c.undefined {
+	case objects.UndefinedSamplesNaN:
+		return math.NaN(), true
+	case objects.UndefinedSamplesZero:
+		return 0, true
+	default:
+		log.Debug("skipping sample of %s that is not a number", stat)
+		return 0, false
+	}
+}
 
-		i, err := strconv.ParseFloat(parse, 64)
-		if err == nil {
-			floatsArr = append(floatsArr, i)
-		} else {
-			log.Error("Error parsing %v", f)
-		}
+// countUnparseable counts a sample of stat that was not a number, with the
+// bucket and node that reported it as an exemplar if enabled.
+func (c *PerNodeBucketStatsCollector) countUnparseable(stat string, ctx util.MetricContext) {
+	counter := unparseableSamplesVec.WithLabelValues(stat)
+
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && c.exemplars {
+		adder.AddWithExemplar(1, prometheus.Labels{objects.BucketLabel: ctx.BucketName, objects.NodeLabel: ctx.NodeHostname})
+		return
 	}
 
-	return floatsArr
+	counter.Inc()
 }
 
-func getPerNodeBucketStats(client util.CbClient, ctx util.MetricContext) (map[string]interface{}, error) {
-	url, err := getSpecificNodeBucketStatsURL(client, ctx.BucketName, ctx.NodeHostname)
+func getPerNodeBucketStats(reqCtx context.Context, client util.CbClient, ctx util.MetricContext) (objects.Samples, error) {
+	url, err := getSpecificNodeBucketStatsURL(reqCtx, client, ctx.BucketName, ctx.NodeHostname)
+	if errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
 
 	if err != nil {
 		log.Error("unable to GET PerNodeBucketStats %s", err)
@@ -228,7 +582,7 @@ func getPerNodeBucketStats(client util.CbClient, ctx util.MetricContext) (map[st
 	}
 
 	var bucketStats objects.PerNodeBucketStats
-	err = client.Get(url, &bucketStats)
+	err = client.Get(reqCtx, url, &bucketStats)
 
 	if err != nil {
 		log.Error("unable to GET PerNodeBucketStats %s", err)
@@ -238,21 +592,18 @@ func getPerNodeBucketStats(client util.CbClient, ctx util.MetricContext) (map[st
 	return bucketStats.Op.Samples, nil
 }
 
-func getSpecificNodeBucketStatsURL(client util.CbClient, bucket, node string) (string, error) {
-	servers, err := client.Servers(bucket)
+func getSpecificNodeBucketStatsURL(reqCtx context.Context, client util.CbClient, bucket, node string) (string, error) {
+	servers, err := client.Servers(reqCtx, bucket)
 	if err != nil {
 		log.Error("unable to retrieve Servers %s", err)
 		return "", err
 	}
 
-	correctURI := ""
-
 	for _, server := range servers.Servers {
-		if server.Hostname == node {
-			correctURI = server.Stats["uri"]
-			break
+		if server.Hostname == node && server.Stats["uri"] != "" {
+			return server.Stats["uri"], nil
 		}
 	}
 
-	return correctURI, nil
+	return "", fmt.Errorf("%w: %s is not among the servers of bucket %s", ErrNotFound, node, bucket)
 }
@@ -0,0 +1,194 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type preparedCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+	// plans are the prepared statements the last scrape found, by node and
+	// name.  nil until the first scrape, which only sets the baseline.
+	plans  map[string]map[string]objects.Prepared
+	totals map[string]*preparedTotals
+}
+
+// preparedTotals counts what happened to the plan cache of a query node
+// between scrapes since the exporter started.
+type preparedTotals struct {
+	hits          float64
+	misses        float64
+	invalidations float64
+}
+
+// NewPreparedCollector creates a collector for the plan cache of every query
+// node.  The cache only reports the statements it holds and how often each has
+// been used, so hits, misses and invalidations are counted from the changes
+// between scrapes: uses are hits, statements new to the cache are misses, and
+// statements whose plan was prepared again, as happens after the indexes it
+// used change, are both misses and invalidations.
+func NewPreparedCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetPreparedCollectorDefaultConfig()
+	}
+
+	return &preparedCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+		totals: map[string]*preparedTotals{},
+	}
+}
+
+// Describe all metrics.
+func (c *preparedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *preparedCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *preparedCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting prepared statement metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	prepareds, err := c.m.client.Prepareds(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape prepared statements: %s", err)
+
+		return
+	}
+
+	plans := map[string]map[string]objects.Prepared{}
+
+	for _, prepared := range prepareds {
+		if plans[prepared.Node] == nil {
+			plans[prepared.Node] = map[string]objects.Prepared{}
+		}
+
+		plans[prepared.Node][prepared.Name] = prepared
+
+		if c.totals[prepared.Node] == nil {
+			c.totals[prepared.Node] = &preparedTotals{}
+		}
+
+		if c.plans != nil {
+			c.count(c.totals[prepared.Node], prepared)
+		}
+	}
+
+	c.plans = plans
+
+	for node, totals := range c.totals {
+		nodeCtx := ctx
+		nodeCtx.NodeHostname = node
+
+		if value, ok := c.config.Lookup(objects.PreparedStatements); ok {
+			c.send(ch, value, prometheus.GaugeValue, float64(len(plans[node])), nodeCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.PreparedCacheHits); ok {
+			c.send(ch, value, prometheus.CounterValue, totals.hits, nodeCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.PreparedCacheMisses); ok {
+			c.send(ch, value, prometheus.CounterValue, totals.misses, nodeCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.PreparedInvalidations); ok {
+			c.send(ch, value, prometheus.CounterValue, totals.invalidations, nodeCtx)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+// count adds what happened to the prepared statement since the last scrape to
+// the totals of its node.
+func (c *preparedCollector) count(totals *preparedTotals, prepared objects.Prepared) {
+	previous, ok := c.plans[prepared.Node][prepared.Name]
+
+	switch {
+	case !ok:
+		totals.misses++
+		totals.hits += prepared.Uses
+
+		return
+	case prepared.PlanPreparedTime != previous.PlanPreparedTime:
+		totals.misses++
+		totals.invalidations++
+	}
+
+	// the statement was evicted and prepared again between scrapes if its
+	// uses went down.
+	if prepared.Uses < previous.Uses {
+		totals.hits += prepared.Uses
+	} else {
+		totals.hits += prepared.Uses - previous.Uses
+	}
+}
+
+func (c *preparedCollector) send(ch chan<- prometheus.Metric, value objects.MetricInfo, valueType prometheus.ValueType, stat float64,
+	ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		valueType,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
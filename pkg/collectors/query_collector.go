@@ -10,6 +10,7 @@
 package collectors
 
 import (
+	"context"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -64,6 +65,11 @@ func (c *queryCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect all metrics.
 func (c *queryCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *queryCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -71,7 +77,7 @@ func (c *queryCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting query metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -80,7 +86,7 @@ func (c *queryCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	queryStats, err := c.m.client.Query()
+	queryStats, err := c.m.client.Query(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -89,7 +95,13 @@ func (c *queryCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	observeSampleAge(c.config.Name, queryStats.Op.Samples)
+
 	for _, value := range c.config.Metrics {
+		if _, ok := objects.QueryVitalsMetrics[value.Name]; ok {
+			continue
+		}
+
 		if value.Enabled {
 			ch <- prometheus.MustNewConstMetric(
 				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
@@ -99,6 +111,76 @@ func (c *queryCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 	}
 
+	c.collectVitals(reqCtx, ch, ctx)
+
 	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
 	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
 }
+
+// collectVitals reports the CPU, memory and request costs of the query
+// service of the node the exporter runs against, if it runs one.  Vitals the
+// query service does not report, as older versions do not, are left out.
+func (c *queryCollector) collectVitals(reqCtx context.Context, ch chan<- prometheus.Metric, ctx util.MetricContext) {
+	enabled := false
+	for _, value := range c.config.Metrics {
+		if _, ok := objects.QueryVitalsMetrics[value.Name]; ok && value.Enabled {
+			enabled = true
+		}
+	}
+
+	if !enabled {
+		return
+	}
+
+	currentNode, err := c.m.client.GetCurrentNode(reqCtx)
+	if err != nil {
+		log.Error("failed to scrape query vitals: %s", err)
+		return
+	}
+
+	if !contains(currentNode.Services, objects.QueryService) {
+		return
+	}
+
+	vitals, err := c.m.client.QueryVitals(reqCtx)
+	if err != nil {
+		log.Error("failed to scrape query vitals: %s", err)
+		return
+	}
+
+	for _, value := range c.config.Metrics {
+		vital, ok := objects.QueryVitalsMetrics[value.Name]
+		if !ok || !value.Enabled {
+			continue
+		}
+
+		val, ok := vitalValue(vitals[vital])
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			val,
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...,
+		)
+	}
+}
+
+// vitalValue returns a vital as a number, converting durations to seconds.
+func vitalValue(vital interface{}) (float64, bool) {
+	switch v := vital.(type) {
+	case float64:
+		return v, true
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false
+		}
+
+		return d.Seconds(), true
+	default:
+		return 0, false
+	}
+}
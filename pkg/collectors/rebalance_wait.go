@@ -0,0 +1,63 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	rebalanceWaitVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "rebalance_wait_seconds",
+			Help:      "Time in seconds per node bucket stats have not been collected while waiting for the cluster to be rebalanced, 0 once they are",
+		},
+		[]string{objects.ClusterLabel})
+	rebalanceWaitRetriesVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "rebalance_wait_retries_total",
+			Help:      "Number of times collection of per node bucket stats was skipped to wait for the cluster to be rebalanced",
+		},
+		[]string{objects.ClusterLabel})
+)
+
+// rebalanceWait tracks how long collection of per node bucket stats has been
+// waiting for a rebalance, so that stats missing after the exporter starts
+// can be told apart from stats that failed to collect.
+type rebalanceWait struct {
+	since time.Time
+}
+
+// retry records a collection skipped to wait for a rebalance, and returns
+// how long collection has been waiting.
+func (w *rebalanceWait) retry(cluster string, now time.Time) time.Duration {
+	if w.since.IsZero() {
+		w.since = now
+	}
+
+	waited := now.Sub(w.since)
+
+	rebalanceWaitVec.WithLabelValues(cluster).Set(waited.Seconds())
+	rebalanceWaitRetriesVec.WithLabelValues(cluster).Inc()
+
+	return waited
+}
+
+// done records that collection is no longer waiting.
+func (w *rebalanceWait) done(cluster string) {
+	w.since = time.Time{}
+
+	rebalanceWaitVec.WithLabelValues(cluster).Set(0)
+}
@@ -0,0 +1,47 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+// addReplicaSkew derives the samples of the replica item skew of a bucket
+// with the given number of replicas from its samples of curr_items and
+// vb_replica_curr_items.  The skew is how many items the replica vBuckets are
+// short of holding a copy of every active item for each replica, so it stays
+// near 0 while replication keeps up and widens as it falls behind.  On a
+// single node the active and replica vBuckets are different partitions, so
+// the per node skew is only near 0 when the cluster is balanced.
+//
+// Nothing is derived unless both stats were returned, leaving the skew
+// missing like any other stat.
+func addReplicaSkew(samples map[string][]float64, replicas int) {
+	active, replica := samples[objects.BucketStatsCurrItems], samples[objects.BucketStatsVbReplicaCurrItems]
+	if active == nil || replica == nil {
+		return
+	}
+
+	// the samples of both stats end at the same time, but either may have
+	// fewer of them.
+	n := len(active)
+	if len(replica) < n {
+		n = len(replica)
+	}
+
+	active, replica = active[len(active)-n:], replica[len(replica)-n:]
+	skew := make([]float64, n)
+
+	for i := range skew {
+		skew[i] = active[i]*float64(replicas) - replica[i]
+	}
+
+	samples[objects.BucketStatsVbReplicaCurrItemsSkew] = skew
+}
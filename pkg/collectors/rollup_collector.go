@@ -0,0 +1,157 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type rollupCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+// NewRollupCollector creates a collector for the totals of the whole cluster,
+// summed from the basic stats of every bucket.  Its metrics are only labelled
+// by cluster, for top level dashboards that need not read a series per bucket
+// or node.
+func NewRollupCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetRollupCollectorDefaultConfig()
+	}
+
+	return &rollupCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *rollupCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *rollupCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *rollupCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting cluster rollup metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	buckets, err := c.m.client.Buckets(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape buckets for the cluster rollup: %s", err)
+
+		return
+	}
+
+	totals := map[string]float64{
+		objects.RollupBuckets: float64(len(buckets)),
+	}
+
+	for _, bucket := range buckets {
+		totals[objects.RollupOps] += bucket.BucketBasicStats[objects.OpsPerSec]
+		totals[objects.RollupItems] += bucket.BucketBasicStats[objects.ItemCount]
+		totals[objects.RollupRAMUsed] += bucket.BucketBasicStats[objects.MemUsed]
+		totals[objects.RollupDiskUsed] += bucket.BucketBasicStats[objects.DiskUsed]
+		totals[objects.RollupRAMQuota] += float64(bucket.Quota.RAM)
+	}
+
+	// the cluster is only as safe as its least safe bucket, and a cluster
+	// without buckets has no data to lose, so reports nothing.
+	for i, bucket := range buckets {
+		tolerated := float64(bucket.FailuresTolerated())
+		if i == 0 || tolerated < totals[objects.RollupFailuresTolerated] {
+			totals[objects.RollupFailuresTolerated] = tolerated
+		}
+	}
+
+	for key, total := range totals {
+		if value, ok := c.config.Lookup(key); ok {
+			ch <- prometheus.MustNewConstMetric(
+				value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+				prometheus.GaugeValue,
+				total,
+				c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+		}
+	}
+
+	if value, ok := c.config.Lookup(objects.RollupDiskQuota); ok {
+		c.collectDiskQuota(reqCtx, ch, value, ctx)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+// collectDiskQuota reports the disk available to the data of the cluster,
+// which buckets have no quota of their own for.  It comes from the storage
+// totals of /pools/default, so failing to read it does not mark the collector
+// as down.
+func (c *rollupCollector) collectDiskQuota(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext) {
+	nodes, err := c.m.client.Nodes(reqCtx)
+	if err != nil {
+		log.Debug("storage totals unavailable for the cluster rollup: %s", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		nodes.StorageTotals.Hdd.QuotaTotal,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
@@ -0,0 +1,40 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var sampleAgeVec = objects.NewExporterGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: objects.ExporterNamespace,
+		Name:      "sample_age_seconds",
+		Help:      "Seconds between the newest sample of the most recent stats request of the collector and when it was received, which grows while Couchbase Server stops sampling its stats",
+	},
+	[]string{"collector"})
+
+// observeSampleAge records how old the newest of samples was when it was
+// received, by the timestamps Couchbase Server returns with every request for
+// samples, so that stats Couchbase Server stopped sampling are told apart
+// from stats the exporter failed to read.  Nothing is recorded without
+// timestamps.
+func observeSampleAge(collector string, samples map[string][]float64) {
+	timestamps := samples[timestampStat]
+	if len(timestamps) == 0 {
+		return
+	}
+
+	newest := time.UnixMilli(int64(timestamps[len(timestamps)-1]))
+	sampleAgeVec.WithLabelValues(collector).Set(time.Since(newest).Seconds())
+}
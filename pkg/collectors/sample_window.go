@@ -0,0 +1,71 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+// windowAggregate is an aggregation over the window of samples Couchbase
+// Server returns for a stat, which covers the second by second samples that
+// fall between two scrapes.
+type windowAggregate struct {
+	suffix string
+	help   string
+	fn     func([]float64) float64
+}
+
+var windowAggregates = []windowAggregate{
+	{suffix: "min", help: "minimum", fn: windowMin},
+	{suffix: "avg", help: "average", fn: windowAvg},
+	{suffix: "max", help: "maximum", fn: windowMax},
+}
+
+// metric returns the metric the aggregate of the given metric is exported as,
+// named after it with the aggregate's suffix.
+func (a windowAggregate) metric(metric objects.MetricInfo) objects.MetricInfo {
+	metric.Name += "_" + a.suffix
+	if metric.NameOverride != "" {
+		metric.NameOverride += "_" + a.suffix
+	}
+
+	metric.HelpText += " (" + a.help + " over the sample window)"
+
+	return metric
+}
+
+func windowMin(stats []float64) float64 {
+	result := stats[0]
+	for _, stat := range stats[1:] {
+		result = min(result, stat)
+	}
+
+	return result
+}
+
+func windowMax(stats []float64) float64 {
+	result := stats[0]
+	for _, stat := range stats[1:] {
+		if stat > result {
+			result = stat
+		}
+	}
+
+	return result
+}
+
+func windowAvg(stats []float64) float64 {
+	sum := 0.0
+	for _, stat := range stats {
+		sum += stat
+	}
+
+	return sum / float64(len(stats))
+}
@@ -0,0 +1,41 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	bucketScrapeDurationVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "bucket_scrape_duration_seconds",
+			Help:      "Time in seconds the most recent request for the stats of the bucket took",
+		},
+		[]string{"collector", objects.BucketLabel})
+	bucketScrapeSamplesVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "bucket_scrape_samples",
+			Help:      "Number of stats returned by the most recent request for the stats of the bucket",
+		},
+		[]string{"collector", objects.BucketLabel})
+)
+
+// observeBucketScrape records what reading the stats of a bucket cost, so
+// that slow buckets can be filtered or collected less often.
+func observeBucketScrape(collector, bucket string, start time.Time, samples int) {
+	bucketScrapeDurationVec.WithLabelValues(collector, bucket).Set(time.Since(start).Seconds())
+	bucketScrapeSamplesVec.WithLabelValues(collector, bucket).Set(float64(samples))
+}
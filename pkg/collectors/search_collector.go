@@ -10,6 +10,7 @@
 package collectors
 
 import (
+	"context"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -65,6 +66,11 @@ func (c *ftsCollector) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect all metrics.
 func (c *ftsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *ftsCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -72,7 +78,7 @@ func (c *ftsCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting fts metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -81,7 +87,7 @@ func (c *ftsCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	ftsStats, err := c.m.client.Fts()
+	ftsStats, err := c.m.client.Fts(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -90,6 +96,8 @@ func (c *ftsCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	observeSampleAge(c.config.Name, ftsStats.Op.Samples)
+
 	for _, value := range c.config.Metrics {
 		if value.Enabled {
 			ch <- prometheus.MustNewConstMetric(
@@ -0,0 +1,187 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type securityCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+func NewSecurityCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetSecurityCollectorDefaultConfig()
+	}
+
+	return &securityCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *securityCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *securityCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *securityCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting security metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	nodes, err := c.m.client.Nodes(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape nodes for their encryption")
+
+		return
+	}
+
+	settings, err := c.m.client.SecuritySettings(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape security settings")
+
+		return
+	}
+
+	if value, ok := c.config.Lookup(objects.NodeEncryption); ok {
+		for _, node := range nodes.Nodes {
+			nodeCtx := ctx
+			nodeCtx.NodeHostname = node.Hostname
+
+			c.gauge(ch, value, boolToFloat64(node.NodeEncryption), nodeCtx)
+		}
+	}
+
+	if value, ok := c.config.Lookup(objects.ClusterEncryption); ok {
+		c.gauge(ch, value, boolToFloat64(nodes.ClusterEncryption()), ctx)
+	}
+
+	if value, ok := c.config.Lookup(objects.ClusterEncryptionLevel); ok {
+		levelCtx := ctx
+		levelCtx.Encryption = objects.NoClusterEncryptionLevel
+
+		if nodes.ClusterEncryption() && settings.ClusterEncryptionLevel != "" {
+			levelCtx.Encryption = settings.ClusterEncryptionLevel
+		}
+
+		c.gauge(ch, value, 1, levelCtx)
+	}
+
+	if value, ok := c.config.Lookup(objects.EncryptionAtRest); ok {
+		c.collectEncryptionAtRest(reqCtx, ch, value, ctx)
+	}
+
+	if value, ok := c.config.Lookup(objects.BucketEncryptionAtRest); ok {
+		c.collectBucketEncryptionAtRest(reqCtx, ch, value, ctx)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+func (c *securityCollector) gauge(ch chan<- prometheus.Metric, value objects.MetricInfo, stat float64, ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
+
+// collectEncryptionAtRest reads the encryption of the configuration, logs
+// and audit log, which only exists from Couchbase Server 8, so failing to
+// read it does not mark the collector as down.
+func (c *securityCollector) collectEncryptionAtRest(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext) {
+	settings, err := c.m.client.EncryptionAtRestSettings(reqCtx)
+	if err != nil {
+		log.Debug("encryption at rest settings unavailable: %s", err)
+		return
+	}
+
+	for data, setting := range settings {
+		ctx.EncryptedData = data
+
+		c.gauge(ch, value, boolToFloat64(setting.Enabled()), ctx)
+	}
+}
+
+// collectBucketEncryptionAtRest reports the encryption of each bucket's data,
+// leaving out buckets on versions that do not report it.
+func (c *securityCollector) collectBucketEncryptionAtRest(reqCtx context.Context, ch chan<- prometheus.Metric, value objects.MetricInfo, ctx util.MetricContext) {
+	buckets, err := c.m.client.Buckets(reqCtx)
+	if err != nil {
+		log.Debug("buckets unavailable for their encryption at rest: %s", err)
+		return
+	}
+
+	for _, bucket := range buckets {
+		encrypted, ok := bucket.EncryptedAtRest()
+		if !ok {
+			continue
+		}
+
+		ctx.BucketName = bucket.Name
+
+		c.gauge(ch, value, boolToFloat64(encrypted), ctx)
+	}
+}
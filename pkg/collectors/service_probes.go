@@ -0,0 +1,110 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type serviceProbesCollector struct {
+	client       util.CbClient
+	prober       *util.ServiceProber
+	labelManager util.CbLabelManager
+	serviceUp    *prometheus.Desc
+}
+
+var serviceProbeLabels = []string{objects.ClusterLabel, objects.NodeLabel, objects.ServiceLabel}
+
+// NewServiceProbesCollector creates a collector that probes the port of every
+// service of every node in the cluster each scrape, in parallel, reporting
+// couchbase_service_up.
+func NewServiceProbesCollector(client util.CbClient, prober *util.ServiceProber, labelManager util.CbLabelManager) prometheus.Collector {
+	return &serviceProbesCollector{
+		client:       client,
+		prober:       prober,
+		labelManager: labelManager,
+		serviceUp: prometheus.NewDesc(
+			objects.ServiceUpMetric,
+			"1 while the port of the service on the node answers, 0 when it does not",
+			serviceProbeLabels,
+			nil,
+		),
+	}
+}
+
+// Describe all metrics.
+func (c *serviceProbesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.serviceUp
+}
+
+// Collect all metrics.
+func (c *serviceProbesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *serviceProbesCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	ctx, err := c.labelManager.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		log.Error("%s", err)
+		return
+	}
+
+	nodes, err := c.client.Nodes(reqCtx)
+	if err != nil {
+		log.Error("failed to list the nodes to probe: %s", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+
+	for _, node := range nodes.Nodes {
+		host, _, err := net.SplitHostPort(node.Hostname)
+		if err != nil {
+			host = node.Hostname
+		}
+
+		for _, service := range node.Services {
+			if !c.prober.Probes(service) {
+				continue
+			}
+
+			ctx := ctx
+			ctx.NodeHostname = node.Hostname
+			ctx.Service = service
+
+			wg.Add(1)
+
+			go func(host string, ctx util.MetricContext) {
+				defer wg.Done()
+
+				up := 1.0
+
+				if err := c.prober.Probe(host, ctx.Service); err != nil {
+					log.Warn("%s on %s does not answer: %s", ctx.Service, ctx.NodeHostname, err)
+
+					up = 0
+				}
+
+				ch <- prometheus.MustNewConstMetric(c.serviceUp, prometheus.GaugeValue, up,
+					c.labelManager.GetLabelValues(serviceProbeLabels, ctx)...)
+			}(host, ctx)
+		}
+	}
+
+	wg.Wait()
+}
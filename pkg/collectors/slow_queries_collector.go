@@ -0,0 +1,186 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type slowQueriesCollector struct {
+	m       MetaCollector
+	config  *objects.CollectorConfig
+	buckets []float64
+}
+
+// slowQuery is what the completed requests log holds for a fingerprint.
+type slowQuery struct {
+	statement string
+	counts    []int
+	elapsed   float64
+}
+
+// NewSlowQueriesCollector creates a collector that reads the query service's
+// log of completed requests, which by default holds requests that took longer
+// than a second, and counts the requests of each statement fingerprint by how
+// long they took, so slow queries can be found without logging every query.
+func NewSlowQueriesCollector(client util.CbClient, buckets []float64, config *objects.CollectorConfig,
+	labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetSlowQueriesCollectorDefaultConfig()
+	}
+
+	if len(buckets) == 0 {
+		buckets = objects.DefaultSlowQueryBuckets
+	}
+
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+
+	return &slowQueriesCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config:  config,
+		buckets: sorted,
+	}
+}
+
+// Describe all metrics.
+func (c *slowQueriesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *slowQueriesCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *slowQueriesCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting slow query metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	requests, err := c.m.client.CompletedRequests(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape completed requests: %s", err)
+
+		return
+	}
+
+	for fingerprint, query := range c.fingerprint(requests) {
+		queryCtx := ctx
+		queryCtx.Fingerprint = fingerprint
+		queryCtx.Statement = query.statement
+
+		if value, ok := c.config.Lookup(objects.SlowQueryRequests); ok {
+			// counts are cumulative, so the last, +Inf, is every request.
+			for i, count := range query.counts {
+				queryCtx.Bound = "+Inf"
+				if i < len(c.buckets) {
+					queryCtx.Bound = strconv.FormatFloat(c.buckets[i], 'g', -1, 64)
+				}
+
+				c.send(ch, value, float64(count), queryCtx)
+			}
+		}
+
+		if value, ok := c.config.Lookup(objects.SlowQueryElapsed); ok {
+			c.send(ch, value, query.elapsed, queryCtx)
+		}
+
+		if value, ok := c.config.Lookup(objects.SlowQueryStatement); ok {
+			c.send(ch, value, 1, queryCtx)
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+// fingerprint groups the requests by the fingerprint of their normalized
+// statement, counting each in every bucket it is within.
+func (c *slowQueriesCollector) fingerprint(requests []objects.CompletedRequest) map[string]*slowQuery {
+	queries := map[string]*slowQuery{}
+
+	for _, request := range requests {
+		normalized := objects.NormalizeStatement(request.Statement)
+		fingerprint := objects.Fingerprint(normalized)
+
+		query, ok := queries[fingerprint]
+		if !ok {
+			query = &slowQuery{
+				statement: objects.TruncateStatement(normalized),
+				counts:    make([]int, len(c.buckets)+1),
+			}
+			queries[fingerprint] = query
+		}
+
+		bucket := sort.SearchFloat64s(c.buckets, request.Elapsed)
+		for i := bucket; i < len(query.counts); i++ {
+			query.counts[i]++
+		}
+
+		query.elapsed += request.Elapsed
+	}
+
+	return queries
+}
+
+func (c *slowQueriesCollector) send(ch chan<- prometheus.Metric, value objects.MetricInfo, stat float64, ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
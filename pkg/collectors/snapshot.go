@@ -0,0 +1,218 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+)
+
+const (
+	snapshotTooOld = "snapshot is older than the maximum age"
+)
+
+var (
+	ErrSnapshotTooOld = fmt.Errorf(snapshotTooOld)
+	snapshotStaleVec  = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "snapshot_stale",
+			Help:      "1 while a collector is serving values restored from the on-disk snapshot rather than freshly collected ones",
+		},
+		[]string{"cluster", "collector"})
+	snapshotTimestamp = objects.NewExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "snapshot_restored_timestamp_seconds",
+			Help:      "Unix time at which the restored snapshot was written",
+		})
+)
+
+// Snapshotter is implemented by the cycle driven collectors, whose gauges
+// persist between scrapes and can therefore be saved and restored.
+type Snapshotter interface {
+	SnapshotKey() SnapshotKey
+	Snapshot() []objects.MetricSample
+	Restore([]objects.MetricSample)
+}
+
+// SnapshotKey identifies the samples of a collector in a snapshot by the
+// cluster it collects from, as named in the configuration, and its name.
+type SnapshotKey struct {
+	Cluster   string
+	Collector string
+}
+
+// String returns the key the samples are saved under.  Those of the top level
+// cluster, which has no name, are saved under the name of the collector alone
+// as they were before other clusters could be collected.
+func (k SnapshotKey) String() string {
+	if k.Cluster == "" {
+		return k.Collector
+	}
+
+	return k.Cluster + "/" + k.Collector
+}
+
+// SnapshotWriter implements the Worker interface for CycleController, writing
+// the current values of every registered Snapshotter to disk on each cycle.
+type SnapshotWriter struct {
+	path       string
+	maxAge     time.Duration
+	collectors []Snapshotter
+}
+
+func NewSnapshotWriter(path string, maxAge time.Duration, collectors ...Snapshotter) *SnapshotWriter {
+	return &SnapshotWriter{
+		path:       path,
+		maxAge:     maxAge,
+		collectors: collectors,
+	}
+}
+
+// Implements Worker interface for CycleController.
+func (w *SnapshotWriter) DoWork(_ context.Context) {
+	if err := w.Write(); err != nil {
+		log.Error("unable to write metrics snapshot %s", err)
+	}
+}
+
+// Write persists the snapshot atomically, so a crash mid-write never leaves a
+// truncated file behind for the next start to trip over.
+func (w *SnapshotWriter) Write() error {
+	snapshot := objects.Snapshot{
+		Timestamp:  time.Now().Unix(),
+		Collectors: map[string][]objects.MetricSample{},
+	}
+
+	for _, c := range w.collectors {
+		snapshot.Collectors[c.SnapshotKey().String()] = c.Snapshot()
+	}
+
+	bts, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+
+	return util.WriteFileAtomic(w.path, bts, 0600)
+}
+
+// Restore loads the snapshot from disk and hands each collector its samples.
+// Restored collectors are reported as stale until they next collect.
+func (w *SnapshotWriter) Restore() error {
+	bts, err := ioutil.ReadFile(w.path)
+	if err != nil {
+		return err
+	}
+
+	var snapshot objects.Snapshot
+	if err := json.Unmarshal(bts, &snapshot); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot %s: %w", w.path, err)
+	}
+
+	written := time.Unix(snapshot.Timestamp, 0)
+	if w.maxAge > 0 && time.Since(written) > w.maxAge {
+		return fmt.Errorf("%w: written %v", ErrSnapshotTooOld, written)
+	}
+
+	for _, c := range w.collectors {
+		key := c.SnapshotKey()
+
+		samples, ok := snapshot.Collectors[key.String()]
+		if !ok {
+			continue
+		}
+
+		c.Restore(samples)
+		snapshotStaleVec.WithLabelValues(key.Cluster, key.Collector).Set(1)
+	}
+
+	snapshotTimestamp.Set(float64(snapshot.Timestamp))
+	log.Info("Restored metrics snapshot written at %v", written)
+
+	return nil
+}
+
+func markSnapshotFresh(key SnapshotKey) {
+	snapshotStaleVec.WithLabelValues(key.Cluster, key.Collector).Set(0)
+}
+
+func snapshotGaugeVecs(metrics map[string]*prometheus.GaugeVec) []objects.MetricSample {
+	samples := []objects.MetricSample{}
+
+	for name, vec := range metrics {
+		ch := make(chan prometheus.Metric)
+
+		go func(vec *prometheus.GaugeVec) {
+			vec.Collect(ch)
+			close(ch)
+		}(vec)
+
+		for m := range ch {
+			var metric io_prometheus_client.Metric
+			if err := m.Write(&metric); err != nil {
+				continue
+			}
+
+			labels := map[string]string{}
+			for _, l := range metric.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+
+			samples = append(samples, objects.MetricSample{
+				Name:   name,
+				Labels: labels,
+				Value:  metric.GetGauge().GetValue(),
+			})
+		}
+	}
+
+	return samples
+}
+
+func restoreGaugeVecs(config *objects.CollectorConfig, registry prometheus.Registerer, metrics map[string]*prometheus.GaugeVec, samples []objects.MetricSample) {
+	byName := map[string]objects.MetricInfo{}
+
+	for _, value := range config.Metrics {
+		if value.Enabled {
+			byName[value.Name] = value
+		}
+	}
+
+	for _, sample := range samples {
+		info, ok := byName[sample.Name]
+		if !ok {
+			continue
+		}
+
+		vec, ok := metrics[sample.Name]
+		if !ok {
+			vec = info.GetPrometheusGaugeVec(registry, config.Namespace, config.Subsystem)
+			metrics[sample.Name] = vec
+		}
+
+		gauge, err := vec.GetMetricWith(sample.Labels)
+		if err != nil {
+			log.Debug("discarding snapshot sample for %s: %s", sample.Name, err)
+			continue
+		}
+
+		gauge.Set(sample.Value)
+	}
+}
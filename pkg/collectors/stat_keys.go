@@ -0,0 +1,92 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"sort"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// timestampStat is returned with the samples of every stats request, and is
+// not a stat.
+const timestampStat = "timestamp"
+
+var (
+	unmappedStatKeysVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "unmapped_stat_keys_total",
+			Help:      "Number of times Couchbase Server returned a stat that no metric of the collector is configured for",
+		},
+		[]string{"collector", "stat"})
+	missingStatKeysVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "missing_stat_keys_total",
+			Help:      "Number of times Couchbase Server did not return the stat an enabled metric of the collector is configured for",
+		},
+		[]string{"collector", "stat"})
+)
+
+// statKeys maps the stats Couchbase Server returns to whether the metric
+// configured for each is enabled, so that stats with no metric, and metrics
+// whose stat is misspelled and so never returned, can be counted.
+type statKeys map[string]bool
+
+// newStatKeys maps the stats of the metrics of config, warning of metrics
+// without a stat and of stats with more than one metric, which are usually
+// mistakes in the configuration.
+func newStatKeys(config *objects.CollectorConfig) statKeys {
+	keys := statKeys{}
+	metrics := map[string][]string{}
+
+	if config == nil {
+		return keys
+	}
+
+	for key, value := range config.Metrics {
+		if value.Name == "" {
+			log.Warn("metric %s of the %s collector has no stat name", key, config.Name)
+			continue
+		}
+
+		keys[value.Name] = keys[value.Name] || value.Enabled
+		metrics[value.Name] = append(metrics[value.Name], key)
+	}
+
+	for stat, names := range metrics {
+		if len(names) > 1 {
+			sort.Strings(names)
+			log.Warn("stat %s is exported by more than one metric of the %s collector: %v", stat, config.Name, names)
+		}
+	}
+
+	return keys
+}
+
+// observe counts the stats in samples that have no metric and the stats of
+// enabled metrics that are missing from samples, leaving out the stats
+// buckets of the given type never report.
+func (k statKeys) observe(collector, bucketType string, samples map[string][]float64) {
+	for stat := range samples {
+		if _, ok := k[stat]; !ok && stat != timestampStat {
+			unmappedStatKeysVec.WithLabelValues(collector, stat).Inc()
+		}
+	}
+
+	for stat, enabled := range k {
+		if _, ok := samples[stat]; !ok && enabled && objects.BucketStatApplies(bucketType, stat) {
+			missingStatKeysVec.WithLabelValues(collector, stat).Inc()
+		}
+	}
+}
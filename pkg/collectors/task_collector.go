@@ -12,6 +12,8 @@
 package collectors
 
 import (
+	"context"
+	"strings"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -25,8 +27,15 @@ const (
 	taskBucketCompaction               = "bucket_compaction"
 	taskXdcr                           = "xdcr"
 	taskClusterLogCollection           = "clusterLogsCollection"
+	taskWarmingUp                      = "warming_up"
 	metricRebalancePerNode             = "rebalancePerNode"
+	metricRebalanceIndex               = "rebalanceIndex"
 	metricCompacting                   = "compacting"
+	metricCompactionRunning            = "compactionRunning"
+	metricCompactionLastDuration       = "compactionLastDuration"
+	metricCompactionDocsThreshold      = "compactionDocsThreshold"
+	metricCompactionViewsThreshold     = "compactionViewsThreshold"
+	metricCompactionPurgeInterval      = "compactionPurgeInterval"
 	metricXdcrChangesLeft              = "xdcrChangesLeft"
 	metricXdcrDocsChecked              = "xdcrDocsChecked"
 	metricXdcrDocsWritten              = "xdcrDocsWritten"
@@ -36,12 +45,20 @@ const (
 	metricDocsTransferred              = "progressDocsTransferred"
 	metricDocsActiveVbucketsLeft       = "progressActiveVBucketsLeft"
 	metricDocsTotalReplicaVBucketsLeft = "progressReplicaVBucketsLeft"
+	metricWarmupState                  = "warmupState"
+	metricWarmupEstimatedItems         = "warmupEstimatedItems"
+	metricWarmupItems                  = "warmupItems"
 )
 
 type taskCollector struct {
 	m MetaCollector
 
 	config *objects.CollectorConfig
+
+	// compactionStarts holds when the running compaction of each bucket was
+	// first seen, and lastCompactions how long the last finished one took.
+	compactionStarts map[string]time.Time
+	lastCompactions  map[string]float64
 }
 
 func NewTaskCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
@@ -66,7 +83,9 @@ func NewTaskCollector(client util.CbClient, config *objects.CollectorConfig, lab
 			),
 			labelManger: labelManager,
 		},
-		config: config,
+		config:           config,
+		compactionStarts: map[string]time.Time{},
+		lastCompactions:  map[string]float64{},
 	}
 }
 
@@ -83,23 +102,27 @@ func (c *taskCollector) Describe(ch chan<- *prometheus.Desc) {
 	}
 }
 
-func (c *taskCollector) collectTasks(ch chan<- prometheus.Metric, tasks []objects.Task) map[string]bool {
+func (c *taskCollector) collectTasks(reqCtx context.Context, ch chan<- prometheus.Metric, tasks []objects.Task) map[string]bool {
 	var compactsReported = map[string]bool{}
+	var xdcrStats = map[string]*objects.XdcrStats{}
+	var nodes = &warmupNodes{reqCtx: reqCtx, client: c.m.client}
 
 	for _, task := range tasks {
 		switch task.Type {
 		case taskRebalance:
-			c.addRebalance(ch, task)
+			c.addRebalance(reqCtx, ch, task)
 		case taskBucketCompaction:
 			// XXX: there can be more than one compacting tasks for the same
 			// bucket for now, let's report just the first.
-			c.addBucketCompaction(ch, task, compactsReported[task.Bucket])
+			c.addBucketCompaction(reqCtx, ch, task, compactsReported[task.Bucket])
 			compactsReported[task.Bucket] = true
 		case taskXdcr:
 			log.Debug("found xdcr tasks from %s to %s", task.Source, task.Target)
-			c.addXdcr(ch, task)
+			c.addXdcr(reqCtx, ch, task, xdcrStats)
 		case taskClusterLogCollection:
-			c.addClusterLogCollection(ch, task)
+			c.addClusterLogCollection(reqCtx, ch, task)
+		case taskWarmingUp:
+			c.addWarmup(reqCtx, ch, task, nodes)
 		default:
 			log.Warn("not implemented")
 		}
@@ -107,9 +130,9 @@ func (c *taskCollector) collectTasks(ch chan<- prometheus.Metric, tasks []object
 
 	return compactsReported
 }
-func (c *taskCollector) addBucketCompaction(ch chan<- prometheus.Metric, task objects.Task, compactsReported bool) {
+func (c *taskCollector) addBucketCompaction(reqCtx context.Context, ch chan<- prometheus.Metric, task objects.Task, compactsReported bool) {
 	if cp, ok := c.config.Metrics[metricCompacting]; ok && cp.Enabled && !compactsReported {
-		ctx, _ := c.m.labelManger.GetMetricContext(task.Bucket, "")
+		ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, task.Bucket, "")
 		ch <- prometheus.MustNewConstMetric(
 			cp.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
 			prometheus.GaugeValue,
@@ -118,9 +141,153 @@ func (c *taskCollector) addBucketCompaction(ch chan<- prometheus.Metric, task ob
 	}
 }
 
-func (c *taskCollector) addClusterLogCollection(ch chan<- prometheus.Metric, task objects.Task) {
+// trackCompactions times bucket compactions from the scrape they are first
+// seen running to the scrape they are gone, so their durations are only
+// accurate to within a scrape interval.
+func (c *taskCollector) trackCompactions(running map[string]bool, now time.Time) {
+	for bucket := range running {
+		if _, ok := c.compactionStarts[bucket]; !ok {
+			c.compactionStarts[bucket] = now
+		}
+	}
+
+	for bucket, started := range c.compactionStarts {
+		if !running[bucket] {
+			c.lastCompactions[bucket] = now.Sub(started).Seconds()
+			delete(c.compactionStarts, bucket)
+		}
+	}
+}
+
+// defaultCompaction reads the cluster wide auto-compaction settings the first
+// time a bucket that uses them asks for them.
+type defaultCompaction struct {
+	reqCtx   context.Context
+	client   util.CbClient
+	fetched  bool
+	settings *objects.AutoCompaction
+}
+
+func (d *defaultCompaction) get() (*objects.AutoCompaction, bool) {
+	if !d.fetched {
+		d.fetched = true
+
+		settings, err := d.client.AutoCompaction(d.reqCtx)
+		if err != nil {
+			log.Debug("%s", err)
+		} else {
+			d.settings = &settings
+		}
+	}
+
+	return d.settings, d.settings != nil
+}
+
+// addCompactionState reports whether a bucket is compacting, how long its last
+// compaction took and the fragmentation thresholds that trigger compaction,
+// to compare with its couch_docs_fragmentation and couch_views_fragmentation,
+// and how long its tombstones are kept before they are purged.
+func (c *taskCollector) addCompactionState(reqCtx context.Context, ch chan<- prometheus.Metric, bucket objects.BucketInfo, running bool, defaults *defaultCompaction) {
+	ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, bucket.Name, "")
+
+	send := func(value objects.MetricInfo, val float64) {
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			val,
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+
+	if value, ok := c.config.Lookup(metricCompactionRunning); ok {
+		send(value, boolToFloat64(running))
+	}
+
+	if duration, ok := c.lastCompactions[bucket.Name]; ok {
+		if value, ok := c.config.Lookup(metricCompactionLastDuration); ok {
+			send(value, duration)
+		}
+	}
+
+	docs, docsOk := c.config.Lookup(metricCompactionDocsThreshold)
+	views, viewsOk := c.config.Lookup(metricCompactionViewsThreshold)
+	purge, purgeOk := c.config.Lookup(metricCompactionPurgeInterval)
+
+	if !docsOk && !viewsOk && !purgeOk {
+		return
+	}
+
+	settings, ok := bucket.AutoCompaction()
+	if !ok && bucket.UsesDefaultAutoCompaction() {
+		var cluster *objects.AutoCompaction
+		if cluster, ok = defaults.get(); ok {
+			settings = *cluster
+		}
+	}
+
+	if !ok {
+		return
+	}
+
+	if percent, ok := settings.DatabaseFragmentationThreshold.Percent(); ok && docsOk {
+		send(docs, percent)
+	}
+
+	if percent, ok := settings.ViewFragmentationThreshold.Percent(); ok && viewsOk {
+		send(views, percent)
+	}
+
+	if settings.PurgeInterval > 0 && purgeOk {
+		send(purge, settings.PurgeInterval*day.Seconds())
+	}
+}
+
+// addXdcrStats reports the conflict resolution, filtering and checkpointing
+// stats of a replication.  The XDCR stats of each source bucket are requested
+// at most once per scrape, and failing to read them does not mark the
+// collector as down.
+func (c *taskCollector) addXdcrStats(reqCtx context.Context, ch chan<- prometheus.Metric, task objects.Task, ctx util.MetricContext, xdcrStats map[string]*objects.XdcrStats) {
+	if task.ID == "" || task.Source == "" {
+		return
+	}
+
+	for key, stat := range objects.XdcrStatMetrics {
+		value, ok := c.config.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		stats, fetched := xdcrStats[task.Source]
+		if !fetched {
+			result, err := c.m.client.XdcrStats(reqCtx, task.Source)
+			if err != nil {
+				log.Debug("%s", err)
+			} else {
+				stats = &result
+			}
+
+			xdcrStats[task.Source] = stats
+		}
+
+		if stats == nil {
+			return
+		}
+
+		samples, ok := stats.Replication(task.ID, stat)
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			last(samples),
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+}
+
+func (c *taskCollector) addClusterLogCollection(reqCtx context.Context, ch chan<- prometheus.Metric, task objects.Task) {
 	if clc, ok := c.config.Metrics[taskClusterLogCollection]; ok && clc.Enabled {
-		ctx, _ := c.m.labelManger.GetMetricContext(task.Bucket, "")
+		ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, task.Bucket, "")
 		ch <- prometheus.MustNewConstMetric(
 			clc.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
 			prometheus.GaugeValue,
@@ -129,16 +296,86 @@ func (c *taskCollector) addClusterLogCollection(ch chan<- prometheus.Metric, tas
 	}
 }
 
-func (c *taskCollector) addRebalance(ch chan<- prometheus.Metric, task objects.Task) {
+// warmupNodes resolves the Erlang node names warming_up tasks are reported
+// under to the hostnames nodes are labelled with, reading the nodes of the
+// cluster the first time a task needs them.
+type warmupNodes struct {
+	reqCtx    context.Context
+	client    util.CbClient
+	hostnames map[string]string
+}
+
+func (w *warmupNodes) hostname(otpNode string) string {
+	if w.hostnames == nil {
+		w.hostnames = map[string]string{}
+
+		nodes, err := w.client.Nodes(w.reqCtx)
+		if err != nil {
+			log.Debug("%s", err)
+		}
+
+		for _, node := range nodes.Nodes {
+			w.hostnames[node.OtpNode] = node.Hostname
+		}
+	}
+
+	if hostname, ok := w.hostnames[otpNode]; ok {
+		return hostname
+	}
+
+	// otherwise fall back to the host the node is named after, ns_1@<host>.
+	return otpNode[strings.Index(otpNode, "@")+1:]
+}
+
+// addWarmup reports the progress of a bucket warming up on a node after a
+// restart, while it loads its items from disk and before it serves them.
+func (c *taskCollector) addWarmup(reqCtx context.Context, ch chan<- prometheus.Metric, task objects.Task, nodes *warmupNodes) {
+	ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, task.Bucket, "")
+	ctx.NodeHostname = nodes.hostname(task.Node)
+
+	if state, ok := c.config.Lookup(metricWarmupState); ok {
+		ctx := ctx
+		ctx.State, _ = task.Stats[objects.WarmupState].(string)
+
+		ch <- prometheus.MustNewConstMetric(
+			state.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			1,
+			c.m.labelManger.GetLabelValues(state.Labels, ctx)...)
+	}
+
+	for key, stat := range map[string]string{
+		metricWarmupEstimatedItems: objects.WarmupEstimatedValueCount,
+		metricWarmupItems:          objects.WarmupValueCount,
+	} {
+		value, ok := c.config.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		items, ok := task.WarmupStat(stat)
+		if !ok {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			items,
+			c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+	}
+}
+
+func (c *taskCollector) addRebalance(reqCtx context.Context, ch chan<- prometheus.Metric, task objects.Task) {
 	if rb, ok := c.config.Metrics[taskRebalance]; ok && rb.Enabled {
-		ctx, _ := c.m.labelManger.GetMetricContext(task.Bucket, "")
+		ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, task.Bucket, "")
 
 		ch <- prometheus.MustNewConstMetric(rb.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem), prometheus.GaugeValue, task.Progress, c.m.labelManger.GetLabelValues(rb.Labels, ctx)...)
 	}
 
 	if rbPN, ok := c.config.Metrics[metricRebalancePerNode]; ok && rbPN.Enabled {
 		for node, progress := range task.PerNode {
-			ctx, _ := c.m.labelManger.GetMetricContext(task.Bucket, "")
+			ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, task.Bucket, "")
 			ctx.NodeHostname = node
 			ch <- prometheus.MustNewConstMetric(
 				rbPN.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
@@ -147,10 +384,25 @@ func (c *taskCollector) addRebalance(ch chan<- prometheus.Metric, task objects.T
 				c.m.labelManger.GetLabelValues(rbPN.Labels, ctx)...)
 		}
 	}
+
+	if rbIdx, ok := c.config.Metrics[metricRebalanceIndex]; ok && rbIdx.Enabled && task.StageInfo.Index.Started() {
+		ctx, _ := c.m.labelManger.GetMetricContext(reqCtx, task.Bucket, "")
+		ch <- prometheus.MustNewConstMetric(
+			rbIdx.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+			prometheus.GaugeValue,
+			task.StageInfo.Index.TotalProgress,
+			c.m.labelManger.GetLabelValues(rbIdx.Labels, ctx)...)
+	}
 }
 
-func (c *taskCollector) addXdcr(ch chan<- prometheus.Metric, task objects.Task) {
-	ctx, _ := c.m.labelManger.GetMetricContextWithSourceAndTarget(task.Bucket, "", task.Source, task.Target)
+func (c *taskCollector) addXdcr(reqCtx context.Context, ch chan<- prometheus.Metric, task objects.Task, xdcrStats map[string]*objects.XdcrStats) {
+	bucket := task.Bucket
+	if bucket == "" {
+		// replications are only named by their source bucket.
+		bucket = task.Source
+	}
+
+	ctx, _ := c.m.labelManger.GetMetricContextWithSourceAndTarget(reqCtx, bucket, "", task.Source, task.Target)
 
 	if xcl, ok := c.config.Metrics[metricXdcrChangesLeft]; ok && xcl.Enabled {
 		ch <- prometheus.MustNewConstMetric(
@@ -192,10 +444,12 @@ func (c *taskCollector) addXdcr(ch chan<- prometheus.Metric, task objects.Task)
 			c.m.labelManger.GetLabelValues(xe.Labels, ctx)...)
 	}
 
+	c.addXdcrStats(reqCtx, ch, task, ctx, xdcrStats)
+
 	for _, data := range task.DetailedProgress.PerNode {
 		// for each node grab these specific metrics from the config (if they exist)
 		// then grab their data from the request and dump it into prometheus.
-		ctx, _ := c.m.labelManger.GetMetricContextWithSourceAndTarget(task.DetailedProgress.Bucket, "", task.Source, task.Target)
+		ctx, _ := c.m.labelManger.GetMetricContextWithSourceAndTarget(reqCtx, task.DetailedProgress.Bucket, "", task.Source, task.Target)
 
 		if dt, ok := c.config.Metrics[metricDocsTotal]; ok && dt.Enabled {
 			ch <- prometheus.MustNewConstMetric(
@@ -232,6 +486,11 @@ func (c *taskCollector) addXdcr(ch chan<- prometheus.Metric, task objects.Task)
 }
 
 func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *taskCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
 	c.m.mutex.Lock()
 	defer c.m.mutex.Unlock()
 
@@ -239,7 +498,7 @@ func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
 
 	log.Info("Collecting tasks metrics...")
 
-	ctx, err := c.m.labelManger.GetBasicMetricContext()
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
 
@@ -248,7 +507,7 @@ func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	tasks, err := c.m.client.Tasks()
+	tasks, err := c.m.client.Tasks(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -257,7 +516,7 @@ func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	buckets, err := c.m.client.Buckets()
+	buckets, err := c.m.client.Buckets(reqCtx)
 	if err != nil {
 		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
 
@@ -267,13 +526,19 @@ func (c *taskCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	// nolint: lll
-	compactsReported := c.collectTasks(ch, tasks)
+	compactsReported := c.collectTasks(reqCtx, ch, tasks)
 	// always report the compacting task, even if it is not happening
 	// this is to not break dashboards and make it easier to test alert rule
 	// and etc.
 	compact := c.config.Metrics[metricCompacting]
 
+	c.trackCompactions(compactsReported, start)
+
+	defaults := &defaultCompaction{reqCtx: reqCtx, client: c.m.client}
+
 	for _, bucket := range buckets {
+		c.addCompactionState(reqCtx, ch, bucket, compactsReported[bucket.Name], defaults)
+
 		if _, ok := compactsReported[bucket.Name]; !ok {
 			// nolint: lll
 			ch <- prometheus.MustNewConstMetric(compact.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem), prometheus.GaugeValue, 0, bucket.Name, ctx.ClusterName)
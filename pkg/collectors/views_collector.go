@@ -0,0 +1,220 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package collectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
+)
+
+// maxParallelDesignDocRequests bounds the requests for the design document
+// info and stats of a bucket in flight at once.
+const maxParallelDesignDocRequests = 8
+
+type viewsCollector struct {
+	m      MetaCollector
+	config *objects.CollectorConfig
+}
+
+func NewViewsCollector(client util.CbClient, config *objects.CollectorConfig, labelManager util.CbLabelManager) prometheus.Collector {
+	if config == nil {
+		config = objects.GetViewsCollectorDefaultConfig()
+	}
+
+	return &viewsCollector{
+		m: MetaCollector{
+			client: client,
+			up: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultUptimeMetric),
+				objects.DefaultUptimeMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			scrapeDuration: prometheus.NewDesc(
+				prometheus.BuildFQName(config.Namespace, config.Subsystem, objects.DefaultScrapeDurationMetric),
+				objects.DefaultScrapeDurationMetricHelp,
+				[]string{objects.ClusterLabel},
+				nil,
+			),
+			labelManger: labelManager,
+		},
+		config: config,
+	}
+}
+
+// Describe all metrics.
+func (c *viewsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.m.up
+	ch <- c.m.scrapeDuration
+
+	for _, value := range c.config.Metrics {
+		if !value.Enabled {
+			continue
+		}
+		ch <- value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem)
+	}
+}
+
+// Collect all metrics.
+func (c *viewsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.CollectContext(context.Background(), ch)
+}
+
+// CollectContext implements ContextCollector.
+func (c *viewsCollector) CollectContext(reqCtx context.Context, ch chan<- prometheus.Metric) {
+	c.m.mutex.Lock()
+	defer c.m.mutex.Unlock()
+
+	start := time.Now()
+
+	log.Info("Collecting views metrics...")
+
+	ctx, err := c.m.labelManger.GetBasicMetricContext(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, objects.ClusterLabel)
+
+		log.Error("%v", err)
+
+		return
+	}
+
+	buckets, err := c.m.client.Buckets(reqCtx)
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+		log.Error("failed to scrape buckets")
+
+		return
+	}
+
+	for _, bucket := range buckets {
+		// only Couchbase buckets support views.
+		if bucket.BucketType != objects.CouchbaseBucketType {
+			continue
+		}
+
+		ctx.BucketName = bucket.Name
+
+		if err := c.collectBucket(reqCtx, ch, bucket.Name, ctx); err != nil {
+			ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 0, ctx.ClusterName)
+
+			log.Error("failed to scrape views of bucket %s: %s", bucket.Name, err)
+
+			return
+		}
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.m.up, prometheus.GaugeValue, 1, ctx.ClusterName)
+	ch <- prometheus.MustNewConstMetric(c.m.scrapeDuration, prometheus.GaugeValue, time.Since(start).Seconds(), ctx.ClusterName)
+}
+
+// collectBucket lists the design documents of the bucket, then requests the
+// info of each and the bucket stats at once, as on a distant cluster the time
+// each request spends in flight soon adds up.  The first request to fail
+// cancels the rest, as their results would only be thrown away.
+func (c *viewsCollector) collectBucket(reqCtx context.Context, ch chan<- prometheus.Metric, bucket string, ctx util.MetricContext) error {
+	ddocs, err := c.m.client.DesignDocs(reqCtx, bucket)
+	if err != nil {
+		return err
+	}
+
+	names := ddocs.Names()
+	if len(names) == 0 {
+		return nil
+	}
+
+	// the first request to fail cancels the others.
+	group, reqCtx := errgroup.WithContext(reqCtx)
+	group.SetLimit(maxParallelDesignDocRequests)
+
+	var samples map[string][]float64
+
+	// view accesses are only reported in the bucket stats, keyed by the
+	// signature of the design document's index.
+	if _, ok := c.config.Lookup(objects.ViewsAccesses); ok {
+		group.Go(func() error {
+			stats, err := c.m.client.BucketStats(reqCtx, bucket)
+			if err != nil {
+				return err
+			}
+
+			samples = stats.Op.Samples
+
+			return nil
+		})
+	}
+
+	infos := make([]objects.DesignDocInfo, len(names))
+
+	for i, name := range names {
+		i, name := i, name
+
+		group.Go(func() error {
+			info, err := c.m.client.DesignDocInfo(reqCtx, bucket, name)
+			if err != nil {
+				return err
+			}
+
+			infos[i] = info
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	for i, name := range names {
+
+		info := infos[i]
+		ctx.DesignDoc = name
+
+		if value, ok := c.config.Lookup(objects.ViewsAccesses); ok {
+			if stat, ok := samples[info.AccessesStat()]; ok {
+				c.send(ch, value, last(stat), ctx)
+			}
+		}
+
+		if value, ok := c.config.Lookup(objects.ViewsUpdateDuration); ok {
+			if duration, ok := info.LastUpdateDuration(); ok {
+				c.send(ch, value, duration, ctx)
+			}
+		}
+
+		if value, ok := c.config.Lookup(objects.ViewsDiskSize); ok {
+			c.send(ch, value, info.ViewIndex.DiskSize, ctx)
+		}
+
+		if value, ok := c.config.Lookup(objects.ViewsDataSize); ok {
+			c.send(ch, value, info.ViewIndex.DataSize, ctx)
+		}
+
+		if value, ok := c.config.Lookup(objects.ViewsUpdaterRunning); ok {
+			c.send(ch, value, boolToFloat64(info.ViewIndex.UpdaterRunning), ctx)
+		}
+	}
+
+	return nil
+}
+
+func (c *viewsCollector) send(ch chan<- prometheus.Metric, value objects.MetricInfo, stat float64, ctx util.MetricContext) {
+	ch <- prometheus.MustNewConstMetric(
+		value.GetPrometheusDescription(c.config.Namespace, c.config.Subsystem),
+		prometheus.GaugeValue,
+		stat,
+		c.m.labelManger.GetLabelValues(value.Labels, ctx)...)
+}
@@ -0,0 +1,280 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package dashboards generates Grafana dashboards from the exporter
+// configuration, so that panels always query the names metrics are actually
+// exported under, including any overrides.
+package dashboards
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+const (
+	datasource    = "${DS_PROMETHEUS}"
+	schemaVersion = 27
+	panelWidth    = 12
+	panelHeight   = 8
+)
+
+type Dashboard struct {
+	Inputs        []Input    `json:"__inputs"`
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Refresh       string     `json:"refresh"`
+	Time          TimeRange  `json:"time"`
+	Templating    Templating `json:"templating"`
+	Panels        []Panel    `json:"panels"`
+}
+
+type Input struct {
+	Name     string `json:"name"`
+	Label    string `json:"label"`
+	Type     string `json:"type"`
+	PluginID string `json:"pluginId"`
+}
+
+type TimeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type Templating struct {
+	List []Variable `json:"list"`
+}
+
+type Variable struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	Type       string `json:"type"`
+	Datasource string `json:"datasource"`
+	Query      string `json:"query"`
+	Refresh    int    `json:"refresh"`
+	Multi      bool   `json:"multi"`
+	IncludeAll bool   `json:"includeAll"`
+}
+
+type Panel struct {
+	ID         int      `json:"id"`
+	Type       string   `json:"type"`
+	Title      string   `json:"title"`
+	Datasource string   `json:"datasource"`
+	GridPos    GridPos  `json:"gridPos"`
+	Targets    []Target `json:"targets"`
+}
+
+type GridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type Target struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+	RefID        string `json:"refId"`
+}
+
+// panelSpec describes a panel in terms of a collector and metric key.  Expr is
+// a format string that receives the metric name with its label selector.
+type panelSpec struct {
+	title     string
+	collector *objects.CollectorConfig
+	key       string
+	expr      string
+	legend    string
+}
+
+type dashboardSpec struct {
+	uid       string
+	title     string
+	variables []string
+	panels    []panelSpec
+}
+
+// Generate builds every dashboard, keyed by a file friendly name.  Panels for
+// metrics that are missing or disabled in the configuration are left out.
+func Generate(config *objects.ExporterConfig) map[string]Dashboard {
+	c := config.Collectors
+	dashboards := map[string]Dashboard{}
+
+	for name, spec := range map[string]dashboardSpec{
+		"cluster-overview": {
+			uid:       "cb-cluster-overview",
+			title:     "Couchbase Cluster Overview",
+			variables: []string{objects.ClusterLabel},
+			panels: []panelSpec{
+				{"Healthy Nodes", c.Node, "healthy", "sum(%s)", "healthy"},
+				{"Ops per Second by Bucket", c.BucketInfo, objects.OpsPerSec, "sum by (bucket) (%s)", "{{bucket}}"},
+				{"Memory Used by Bucket", c.BucketInfo, objects.MemUsed, "sum by (bucket) (%s)", "{{bucket}}"},
+				{"Disk Used by Bucket", c.BucketInfo, objects.DiskUsed, "sum by (bucket) (%s)", "{{bucket}}"},
+				{"Items by Bucket", c.BucketInfo, objects.ItemCount, "sum by (bucket) (%s)", "{{bucket}}"},
+				{"Quota Used by Bucket", c.BucketInfo, objects.QuotaPercentUsed, "%s", "{{bucket}}"},
+				{"Rebalance Progress", c.Task, "rebalance", "%s", "rebalance"},
+				{"Query Requests", c.Query, "QueryRequests", "%s", "requests"},
+				{"Index Memory Used", c.Index, "IndexMemoryUsed", "%s", "memory used"},
+			},
+		},
+		"bucket-detail": {
+			uid:       "cb-bucket-detail",
+			title:     "Couchbase Bucket Detail",
+			variables: []string{objects.ClusterLabel, objects.BucketLabel},
+			panels: []panelSpec{
+				{"Ops per Second", c.BucketStats, "Ops", "%s", "{{bucket}}"},
+				{"Gets", c.BucketStats, "CmdGet", "%s", "{{bucket}}"},
+				{"Sets", c.BucketStats, "CmdSet", "%s", "{{bucket}}"},
+				{"Cache Miss Rate", c.BucketStats, "EpCacheMissRate", "%s", "{{bucket}}"},
+				{"Active Resident Ratio", c.BucketStats, "VbActiveResidentItemsRatio", "%s", "{{bucket}}"},
+				{"Disk Write Queue", c.BucketStats, "DiskWriteQueue", "%s", "{{bucket}}"},
+				{"Items", c.BucketStats, "CurrItems", "%s", "{{bucket}}"},
+				{"Connections", c.BucketStats, "CurrConnections", "%s", "{{bucket}}"},
+				{"Temporary OOM Errors", c.BucketStats, "EpTmpOomErrors", "%s", "{{bucket}}"},
+				{"Background Fetch Wait", c.BucketStats, "AvgBgWaitTime", "%s", "{{bucket}}"},
+			},
+		},
+		"per-node-kv": {
+			uid:       "cb-per-node-kv",
+			title:     "Couchbase Per Node KV",
+			variables: []string{objects.ClusterLabel, objects.BucketLabel, objects.NodeLabel},
+			panels: []panelSpec{
+				{"Ops per Second", c.PerNodeBucketStats, "Ops", "%s", "{{node}} {{bucket}}"},
+				{"Gets", c.PerNodeBucketStats, "CmdGet", "%s", "{{node}} {{bucket}}"},
+				{"Sets", c.PerNodeBucketStats, "CmdSet", "%s", "{{node}} {{bucket}}"},
+				{"Active Resident Ratio", c.PerNodeBucketStats, "VbActiveResidentItemsRatio", "%s", "{{node}} {{bucket}}"},
+				{"Disk Write Queue", c.PerNodeBucketStats, "DiskWriteQueue", "%s", "{{node}} {{bucket}}"},
+				{"Background Fetches", c.PerNodeBucketStats, "EpBgFetched", "%s", "{{node}} {{bucket}}"},
+				{"Connections", c.PerNodeBucketStats, "CurrConnections", "%s", "{{node}} {{bucket}}"},
+				{"CPU Utilization", c.Node, "systemStatsCPUUtilizationRate", "%s", "{{node}}"},
+				{"Free Memory", c.Node, "systemStatsMemFree", "%s", "{{node}}"},
+			},
+		},
+		"xdcr": {
+			uid:       "cb-xdcr",
+			title:     "Couchbase XDCR",
+			variables: []string{objects.ClusterLabel, objects.BucketLabel},
+			panels: []panelSpec{
+				{"Changes Left", c.Task, "xdcrChangesLeft", "%s", "{{bucket}} -> {{target}}"},
+				{"Docs Checked", c.Task, "xdcrDocsChecked", "%s", "{{bucket}} -> {{target}}"},
+				{"Docs Written", c.Task, "xdcrDocsWritten", "%s", "{{bucket}} -> {{target}}"},
+				{"Errors", c.Task, "xdcrErrors", "%s", "{{bucket}} -> {{target}}"},
+				{"Paused", c.Task, "xdcrPaused", "%s", "{{bucket}} -> {{target}}"},
+				{"Docs Failed Source Conflict Resolution", c.Task, "xdcrDocsFailedCrSource", "%s", "{{bucket}} -> {{target}}"},
+				{"Docs Filtered", c.Task, "xdcrDocsFiltered", "%s", "{{bucket}} -> {{target}}"},
+				{"Failed Checkpoints", c.Task, "xdcrFailedCheckpoints", "%s", "{{bucket}} -> {{target}}"},
+				{"DCP Items Remaining", c.BucketStats, "EpDcpXdcrItemsRemaining", "%s", "{{bucket}}"},
+			},
+		},
+	} {
+		dashboards[name] = build(spec)
+	}
+
+	return dashboards
+}
+
+func build(spec dashboardSpec) Dashboard {
+	dashboard := Dashboard{
+		Inputs: []Input{{
+			Name:     "DS_PROMETHEUS",
+			Label:    "Prometheus",
+			Type:     "datasource",
+			PluginID: "prometheus",
+		}},
+		UID:           spec.uid,
+		Title:         spec.title,
+		Tags:          []string{"couchbase"},
+		SchemaVersion: schemaVersion,
+		Refresh:       "30s",
+		Time:          TimeRange{From: "now-6h", To: "now"},
+		Templating:    Templating{List: []Variable{}},
+		Panels:        []Panel{},
+	}
+
+	source := ""
+
+	for _, panel := range spec.panels {
+		if panel.collector == nil {
+			continue
+		}
+
+		metric, ok := panel.collector.Lookup(panel.key)
+		if !ok {
+			continue
+		}
+
+		index := len(dashboard.Panels)
+		name := metric.FQName(panel.collector.Namespace, panel.collector.Subsystem)
+		selector := name + labelSelector(objects.GetLabelKeys(metric.Labels), spec.variables)
+
+		if source == "" {
+			source = name
+		}
+
+		dashboard.Panels = append(dashboard.Panels, Panel{
+			ID:         index + 1,
+			Type:       "timeseries",
+			Title:      panel.title,
+			Datasource: datasource,
+			GridPos: GridPos{
+				H: panelHeight,
+				W: panelWidth,
+				X: (index % 2) * panelWidth,
+				Y: (index / 2) * panelHeight,
+			},
+			Targets: []Target{{
+				Expr:         fmt.Sprintf(panel.expr, selector),
+				LegendFormat: panel.legend,
+				RefID:        "A",
+			}},
+		})
+	}
+
+	// template variables are populated from the first panel so they only
+	// offer values that the dashboard can actually display.
+	if source != "" {
+		for _, variable := range spec.variables {
+			dashboard.Templating.List = append(dashboard.Templating.List, Variable{
+				Name:       variable,
+				Label:      strings.ToUpper(variable[:1]) + variable[1:],
+				Type:       "query",
+				Datasource: datasource,
+				Query:      fmt.Sprintf("label_values(%s, %s)", source, variable),
+				Refresh:    2,
+				Multi:      variable != objects.ClusterLabel,
+				IncludeAll: variable != objects.ClusterLabel,
+			})
+		}
+	}
+
+	return dashboard
+}
+
+// labelSelector matches each dashboard variable the metric is labelled with.
+func labelSelector(labels []string, variables []string) string {
+	matchers := []string{}
+
+	for _, variable := range variables {
+		for _, label := range labels {
+			if label == variable {
+				matchers = append(matchers, fmt.Sprintf("%s=~\"$%s\"", label, label))
+			}
+		}
+	}
+
+	if len(matchers) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(matchers, ",") + "}"
+}
@@ -0,0 +1,78 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	httputil "github.com/couchbase/couchbase-exporter/pkg/http/util"
+)
+
+// AdminCollectorsPath is where the admin API lists and toggles collectors.
+const AdminCollectorsPath = "/admin/collectors"
+
+var errUnknownAction = errors.New("unknown action, expected enable or disable")
+
+// AdminCollectors responds to GET /admin/collectors with whether each
+// collector is enabled, and to POST /admin/collectors/<name>/enable or
+// /admin/collectors/<name>/disable by switching the collector.
+func AdminCollectors(collectorSwitch *collectors.CollectorSwitch) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, AdminCollectorsPath), "/")
+
+		if path == "" {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", http.MethodGet)
+				httputil.RespondErr(w, r, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+
+				return
+			}
+
+			httputil.Respond(w, r, collectorSwitch.States(), http.StatusOK)
+
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			httputil.RespondErr(w, r, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		name, action := path, ""
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			name, action = path[:i], path[i+1:]
+		}
+
+		var err error
+
+		switch action {
+		case "enable":
+			err = collectorSwitch.Set(name, true)
+		case "disable":
+			err = collectorSwitch.Set(name, false)
+		default:
+			httputil.RespondErr(w, r, errUnknownAction, http.StatusNotFound)
+			return
+		}
+
+		if err != nil {
+			httputil.RespondErr(w, r, err, http.StatusNotFound)
+			return
+		}
+
+		httputil.Respond(w, r, collectorSwitch.States(), http.StatusOK)
+	}
+}
@@ -0,0 +1,92 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	httputil "github.com/couchbase/couchbase-exporter/pkg/http/util"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+)
+
+// AdminCapturePath is where the admin API starts and stops capturing the
+// bodies of requests to Couchbase Server.
+const AdminCapturePath = "/admin/capture"
+
+var (
+	errUnknownCaptureAction    = errors.New("unknown action, expected start or stop")
+	errMissingCaptureEndpoints = errors.New("endpoints must be given")
+)
+
+// AdminCapture responds to GET /admin/capture with the endpoints being
+// captured and until when, to POST /admin/capture/start?endpoints=<regex> by
+// capturing the matching endpoints for the configured window, or for
+// window=<seconds>, and to POST /admin/capture/stop by stopping the capture.
+func AdminCapture(capture *util.Capture) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		action := strings.Trim(strings.TrimPrefix(r.URL.Path, AdminCapturePath), "/")
+
+		if action == "" {
+			if r.Method != http.MethodGet {
+				w.Header().Set("Allow", http.MethodGet)
+				httputil.RespondErr(w, r, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+
+				return
+			}
+
+			httputil.Respond(w, r, capture.State(), http.StatusOK)
+
+			return
+		}
+
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			httputil.RespondErr(w, r, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		switch action {
+		case "start":
+			endpoints := r.URL.Query().Get("endpoints")
+			if endpoints == "" {
+				httputil.RespondErr(w, r, errMissingCaptureEndpoints, http.StatusBadRequest)
+				return
+			}
+
+			seconds := 0
+
+			if window := r.URL.Query().Get("window"); window != "" {
+				var err error
+				if seconds, err = strconv.Atoi(window); err != nil || seconds < 0 {
+					httputil.RespondErr(w, r, fmt.Errorf("window must be a positive number of seconds, not %q", window), http.StatusBadRequest)
+					return
+				}
+			}
+
+			if err := capture.Start(endpoints, time.Duration(seconds)*time.Second); err != nil {
+				httputil.RespondErr(w, r, err, http.StatusBadRequest)
+				return
+			}
+		case "stop":
+			capture.Stop()
+		default:
+			httputil.RespondErr(w, r, errUnknownCaptureAction, http.StatusNotFound)
+			return
+		}
+
+		httputil.Respond(w, r, capture.State(), http.StatusOK)
+	}
+}
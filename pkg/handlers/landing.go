@@ -0,0 +1,133 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	httputil "github.com/couchbase/couchbase-exporter/pkg/http/util"
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+)
+
+// Target is a cluster the exporter collects from, described without its
+// credentials.
+type Target struct {
+	URL  string `json:"url"`
+	Auth string `json:"auth"`
+	User string `json:"user,omitempty"`
+}
+
+// ExporterInfo describes what a running exporter is configured to do.
+type ExporterInfo struct {
+	Version    string
+	Collectors []string
+	Targets    []Target
+	Catalog    []objects.CatalogEntry
+	Scrapes    *util.ScrapeStatusGatherer
+}
+
+// exporterStatus is the body of /debug, and what the landing page shows.
+type exporterStatus struct {
+	Version    string                 `json:"version"`
+	Collectors []string               `json:"collectors"`
+	Targets    []Target               `json:"targets"`
+	LastScrape *util.ScrapeStatus     `json:"lastScrape"`
+	Metrics    []objects.CatalogEntry `json:"metrics,omitempty"`
+}
+
+func (e *ExporterInfo) status() exporterStatus {
+	status := exporterStatus{
+		Version:    e.Version,
+		Collectors: e.Collectors,
+		Targets:    e.Targets,
+	}
+
+	if e.Scrapes != nil {
+		if last := e.Scrapes.LastScrape(); !last.Time.IsZero() {
+			status.LastScrape = &last
+		}
+	}
+
+	return status
+}
+
+var landingTemplate = template.Must(template.New("landing").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Couchbase Exporter</title></head>
+<body>
+<h1>Couchbase Exporter</h1>
+<p>{{.Version}}</p>
+<ul>
+<li><a href="metrics">Metrics</a></li>
+<li><a href="metrics/v2">Metrics with corrected names</a></li>
+<li><a href="healthz">Health</a></li>
+<li><a href="readiness-probe">Readiness</a></li>
+<li><a href="debug">Debug</a></li>
+<li><a href="snapshot">Snapshot</a></li>
+</ul>
+<h2>Targets</h2>
+<table>
+<tr><th>URL</th><th>Authentication</th><th>User</th></tr>
+{{range .Targets}}<tr><td>{{.URL}}</td><td>{{.Auth}}</td><td>{{.User}}</td></tr>
+{{end}}</table>
+<h2>Collectors</h2>
+<ul>
+{{range .Collectors}}<li>{{.}}</li>
+{{end}}</ul>
+<h2>Last Scrape</h2>
+{{with .LastScrape}}<p>{{.Time.Format "2006-01-02T15:04:05Z07:00"}} took {{printf "%.3f" .DurationSeconds}}s</p>
+{{if .Error}}<p>Error: {{.Error}}</p>
+{{end}}<table>
+<tr><th>Metric</th><th>Up</th></tr>
+{{range $name, $up := .Up}}<tr><td>{{$name}}</td><td>{{$up}}</td></tr>
+{{end}}</table>
+{{else}}<p>The metrics have not been scraped yet.</p>
+{{end}}</body>
+</html>
+`))
+
+// Landing serves a page at / describing the exporter, with links to its other
+// endpoints.
+func Landing(info *ExporterInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+		if err := landingTemplate.Execute(w, info.status()); err != nil {
+			log.Debug("error writing landing page: %v", err)
+		}
+	}
+}
+
+// Debug responds with what the landing page shows, and every metric the
+// enabled collectors export, as JSON.
+func Debug(info *ExporterInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := info.status()
+		status.Metrics = info.Catalog
+
+		httputil.Respond(w, r, status, http.StatusOK)
+	}
+}
+
+// Healthz responds with a 200 while the exporter is able to serve requests,
+// whether or not Couchbase Server is available.
+func Healthz() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		httputil.Respond(w, r, "ok", http.StatusOK)
+	}
+}
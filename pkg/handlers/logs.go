@@ -0,0 +1,58 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	httputil "github.com/couchbase/couchbase-exporter/pkg/http/util"
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+// LogsPath is where the admin API serves the exporter's recent log lines.
+const LogsPath = "/-/logs"
+
+// Logs responds to GET /-/logs with the exporter's most recent log lines,
+// oldest first and with their secrets redacted, for when the container's logs
+// are hard to get at.  ?lines=N limits the response to the last N lines.
+func Logs() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			httputil.RespondErr(w, r, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		n := 0
+
+		if lines := r.URL.Query().Get("lines"); lines != "" {
+			var err error
+			if n, err = strconv.Atoi(lines); err != nil || n < 0 {
+				httputil.RespondErr(w, r, fmt.Errorf("lines must be a positive number, not %q", lines), http.StatusBadRequest)
+				return
+			}
+		}
+
+		recent := log.Recent(n)
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+
+		if len(recent) > 0 {
+			if _, err := w.Write([]byte(strings.Join(recent, "\n") + "\n")); err != nil {
+				log.Debug("error writing logs response: %v", err)
+			}
+		}
+	}
+}
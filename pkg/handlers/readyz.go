@@ -13,7 +13,7 @@ func Readyz(client util.Client) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		resp, err := client.Client.Get(client.URL("pools/default"))
 		if err != nil {
-			httputil.RespondErr(w, r, fmt.Errorf("error occurred performing Get: %w", err), http.StatusInternalServerError)
+			httputil.RespondErr(w, r, util.RedactError(fmt.Errorf("error occurred performing Get: %w", err)), http.StatusInternalServerError)
 			return
 		}
 		defer resp.Body.Close()
@@ -0,0 +1,61 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	httputil "github.com/couchbase/couchbase-exporter/pkg/http/util"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+)
+
+// SnapshotPath is where the state of every cluster as last collected is
+// served.
+const SnapshotPath = "/snapshot"
+
+// snapshotDocument is the body of /snapshot.
+type snapshotDocument struct {
+	Version    string                       `json:"version"`
+	Targets    []Target                     `json:"targets"`
+	LastScrape *util.ScrapeStatus           `json:"lastScrape"`
+	Clusters   map[string]util.ClusterState `json:"clusters"`
+}
+
+// Snapshot responds to GET /snapshot with a single JSON document of the
+// topology and every series of each cluster as last scraped, to attach to a
+// support ticket.  The metrics are gathered first if they have not been
+// scraped yet.
+func Snapshot(info *ExporterInfo) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			httputil.RespondErr(w, r, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+
+			return
+		}
+
+		families := info.Scrapes.LastFamilies()
+		if families == nil {
+			// the error is in the last scrape, whatever could be gathered
+			// is still worth having.
+			families, _ = info.Scrapes.Gather()
+		}
+
+		status := info.status()
+
+		httputil.Respond(w, r, snapshotDocument{
+			Version:    status.Version,
+			Targets:    status.Targets,
+			LastScrape: status.LastScrape,
+			Clusters:   util.ClusterStates(families),
+		}, http.StatusOK)
+	}
+}
@@ -2,6 +2,7 @@ package log
 
 import (
 	gofmt "fmt"
+	"io"
 	"math"
 	"os"
 	"time"
@@ -27,9 +28,17 @@ type Logger struct {
 
 	lvl    string
 	format string
+	// quiet is true for each level that is filtered out, so that messages
+	// at that level are not formatted only to be dropped.
+	quietDebug bool
+	quietInfo  bool
 }
 
 func (l *Logger) Debug(fmt string, v ...interface{}) {
+	if l.quietDebug {
+		return
+	}
+
 	err := l.debug.Log(prepareLog(fmt, v...))
 	if err != nil {
 		panic(err)
@@ -37,6 +46,10 @@ func (l *Logger) Debug(fmt string, v ...interface{}) {
 }
 
 func (l *Logger) Info(fmt string, v ...interface{}) {
+	if l.quietInfo {
+		return
+	}
+
 	err := l.info.Log(prepareLog(fmt, v...))
 	if err != nil {
 		panic(err)
@@ -44,14 +57,29 @@ func (l *Logger) Info(fmt string, v ...interface{}) {
 }
 
 func (l *Logger) Warn(fmt string, v ...interface{}) {
-	err := l.warn.Log(prepareLog(fmt, v...))
-	if err != nil {
-		panic(err)
-	}
+	l.throttled(l.warn, "warn", fmt, v...)
 }
 
 func (l *Logger) Error(fmt string, v ...interface{}) {
-	err := l.err.Log(prepareLog(fmt, v...))
+	l.throttled(l.err, "error", fmt, v...)
+}
+
+// throttled logs the message unless the same message was logged within the
+// throttle interval, adding the number of times it was repeated since.
+func (l *Logger) throttled(logger log.Logger, lvl string, fmt string, v ...interface{}) {
+	key, message := prepareLog(fmt, v...)
+
+	ok, repeated := throttle.allow(lvl, fmt, message, time.Now())
+	if !ok {
+		return
+	}
+
+	keyvals := []interface{}{key, message}
+	if repeated > 0 {
+		keyvals = append(keyvals, "repeated", repeated)
+	}
+
+	err := logger.Log(keyvals...)
 	if err != nil {
 		panic(err)
 	}
@@ -95,20 +123,39 @@ type Config struct {
 }
 
 func New(cfg *Config) *Logger {
+	// every line is also kept for /-/logs.
+	w := log.NewSyncWriter(io.MultiWriter(os.Stderr, recent))
+
 	var l log.Logger
 	if cfg.Format == "json" {
-		l = log.NewJSONLogger(log.NewSyncWriter(os.Stderr))
+		l = log.NewJSONLogger(w)
 	} else {
-		l = log.NewLogfmtLogger(log.NewSyncWriter(os.Stderr))
+		l = log.NewLogfmtLogger(w)
 	}
 
 	l = log.With(level.NewFilter(l, parseLevel(cfg.Level)), "ts", unixTimeFormat, "date", timestampFormat, "logger", "metrics", "caller", log.DefaultCaller)
 
-	return &Logger{debug: level.Debug(l), info: level.Info(l), warn: level.Warn(l), err: level.Error(l), lvl: cfg.Level, format: cfg.Format}
+	quietDebug, quietInfo := quietLevels(cfg.Level)
+
+	return &Logger{debug: level.Debug(l), info: level.Info(l), warn: level.Warn(l), err: level.Error(l), lvl: cfg.Level, format: cfg.Format,
+		quietDebug: quietDebug, quietInfo: quietInfo}
 }
 
 func prepareLog(fmt string, v ...interface{}) (string, string) {
-	return "message", gofmt.Sprintf(fmt, v...)
+	return "message", Redact(gofmt.Sprintf(fmt, v...))
+}
+
+// quietLevels reports whether debug and info messages are filtered out at
+// level l, as parseLevel filters them.
+func quietLevels(l string) (bool, bool) {
+	switch l {
+	case "info":
+		return true, false
+	case "warn", "warning", "error", "err":
+		return true, true
+	}
+
+	return false, false
 }
 
 func parseLevel(l string) level.Option {
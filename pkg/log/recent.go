@@ -0,0 +1,60 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package log
+
+import (
+	"strings"
+	"sync"
+)
+
+// RecentLines is the number of log lines kept for /-/logs.
+const RecentLines = 1000
+
+var recent = &ring{lines: make([]string, RecentLines)}
+
+// ring keeps the most recent log lines written to it.  Each log line is
+// written with a single call to Write.
+type ring struct {
+	mutex sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func (r *ring) Write(p []byte) (int, error) {
+	line := strings.TrimRight(string(p), "\n")
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	r.full = r.full || r.next == 0
+
+	return len(p), nil
+}
+
+// Recent returns up to the last n log lines, oldest first, or all those kept
+// if n is not positive.
+func Recent(n int) []string {
+	recent.mutex.Lock()
+	defer recent.mutex.Unlock()
+
+	lines := append([]string{}, recent.lines[:recent.next]...)
+	if recent.full {
+		lines = append(append([]string{}, recent.lines[recent.next:]...), lines...)
+	}
+
+	if n > 0 && n < len(lines) {
+		lines = lines[len(lines)-n:]
+	}
+
+	return lines
+}
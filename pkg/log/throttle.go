@@ -0,0 +1,110 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package log
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// maxThrottledMessages bounds the number of distinct messages remembered, past
+// which the messages that may be logged again are forgotten.
+const maxThrottledMessages = 1000
+
+var (
+	// LoggedMessagesOpts, ThrottledMessagesOpts and MessageLabels describe the
+	// counters of warnings and errors, so that they can be listed with the
+	// other metrics the exporter reports about itself.
+	LoggedMessagesOpts = prometheus.CounterOpts{
+		Namespace: "cbexporter",
+		Name:      "log_messages_total",
+		Help:      "Number of warnings and errors by level and class, the message before its values are filled in, including those throttled.",
+	}
+	ThrottledMessagesOpts = prometheus.CounterOpts{
+		Namespace: "cbexporter",
+		Name:      "log_messages_throttled_total",
+		Help:      "Number of warnings and errors by level and class that were not logged because the same message was logged recently.",
+	}
+	MessageLabels = []string{"level", "class"}
+
+	loggedMessages    = promauto.NewCounterVec(LoggedMessagesOpts, MessageLabels)
+	throttledMessages = promauto.NewCounterVec(ThrottledMessagesOpts, MessageLabels)
+
+	throttle = &throttler{messages: map[string]*repeat{}}
+)
+
+// throttler logs the same message at most once per interval.
+type throttler struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	messages map[string]*repeat
+}
+
+type repeat struct {
+	logged     time.Time
+	suppressed int
+}
+
+// SetThrottle logs identical warnings and errors at most once per interval,
+// with the number of times they were repeated in between. Zero disables it.
+func SetThrottle(interval time.Duration) {
+	throttle.mutex.Lock()
+	defer throttle.mutex.Unlock()
+
+	throttle.interval = interval
+	throttle.messages = map[string]*repeat{}
+}
+
+// allow counts the message of the given level and class, and returns whether
+// it may be logged now and how many times it was suppressed since last logged.
+func (t *throttler) allow(lvl, class, message string, now time.Time) (bool, int) {
+	loggedMessages.WithLabelValues(lvl, class).Inc()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.interval <= 0 {
+		return true, 0
+	}
+
+	key := lvl + "\x00" + message
+
+	r, ok := t.messages[key]
+	if ok && now.Sub(r.logged) < t.interval {
+		r.suppressed++
+
+		throttledMessages.WithLabelValues(lvl, class).Inc()
+
+		return false, 0
+	}
+
+	suppressed := 0
+	if ok {
+		suppressed = r.suppressed
+	} else if len(t.messages) >= maxThrottledMessages {
+		t.forget(now)
+	}
+
+	t.messages[key] = &repeat{logged: now}
+
+	return true, suppressed
+}
+
+// forget removes the messages whose interval has passed.
+func (t *throttler) forget(now time.Time) {
+	for key, r := range t.messages {
+		if now.Sub(r.logged) >= t.interval {
+			delete(t.messages, key)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"strconv"
+)
+
+const (
+	AuditEnabled        = "auditEnabled"
+	AuditRotateInterval = "auditRotateInterval"
+	AuditRotateSize     = "auditRotateSize"
+	AuditDisabledEvents = "auditDisabledEvents"
+	AuditDroppedEvents  = "auditDroppedEvents"
+
+	// AuditDroppedEventsStat is the name of the stat counting audit events
+	// memcached failed to write, in the Couchbase Server 7 stats API.
+	AuditDroppedEventsStat = "kv_audit_dropped_events"
+)
+
+// AuditSettings is the result of /settings/audit.
+type AuditSettings struct {
+	AuditdEnabled  bool          `json:"auditdEnabled"`
+	RotateInterval float64       `json:"rotateInterval"`
+	RotateSize     float64       `json:"rotateSize"`
+	LogPath        string        `json:"logPath"`
+	Disabled       []int         `json:"disabled"`
+	DisabledUsers  []interface{} `json:"disabledUsers"`
+}
+
+// StatsRange is the result of /pools/default/stats/range/<stat>, available
+// from Couchbase Server 7.  Each value is a [timestamp, "value"] pair.
+type StatsRange struct {
+	Data []struct {
+		Metric map[string]interface{} `json:"metric"`
+		Values [][]interface{}        `json:"values"`
+	} `json:"data"`
+}
+
+// Last returns the most recent value of the first series, which is the only
+// series when the range was requested with a nodes aggregation.
+func (s StatsRange) Last() (float64, bool) {
+	if len(s.Data) == 0 {
+		return 0, false
+	}
+
+	return lastValue(s.Data[0].Values)
+}
+
+// LastByNode returns the most recent value of each node's series, for a range
+// requested without a nodes aggregation, which labels each series with the
+// node it was read from.
+func (s StatsRange) LastByNode() map[string]float64 {
+	values := map[string]float64{}
+
+	for _, series := range s.LastBySeries() {
+		values[series.Node] = series.Value
+	}
+
+	return values
+}
+
+// SeriesValue is the most recent value of a series of a stats range, with the
+// node it was read from and the bucket it is scoped to, if any.
+type SeriesValue struct {
+	Node   string
+	Bucket string
+	Value  float64
+}
+
+// LastBySeries returns the most recent value of each node's series, for a
+// range requested without a nodes aggregation, which for a stat scoped to
+// buckets has a series for each bucket on each node.
+func (s StatsRange) LastBySeries() []SeriesValue {
+	values := []SeriesValue{}
+
+	for _, series := range s.Data {
+		nodes, _ := series.Metric["nodes"].([]interface{})
+		if len(nodes) != 1 {
+			continue
+		}
+
+		node, ok := nodes[0].(string)
+		if !ok {
+			continue
+		}
+
+		bucket, _ := series.Metric["bucket"].(string)
+
+		if value, ok := lastValue(series.Values); ok {
+			values = append(values, SeriesValue{Node: node, Bucket: bucket, Value: value})
+		}
+	}
+
+	return values
+}
+
+func lastValue(values [][]interface{}) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	sample := values[len(values)-1]
+	if len(sample) != 2 {
+		return 0, false
+	}
+
+	str, ok := sample[1].(string)
+	if !ok {
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return value, true
+}
@@ -0,0 +1,90 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// AuthModeBasic authenticates with the Couchbase username and password.
+	AuthModeBasic = "basic"
+	// AuthModeBearer authenticates with a pre-generated bearer token, such as
+	// a JWT.
+	AuthModeBearer = "bearer"
+	// AuthModeCertificate authenticates with the client certificate alone.
+	AuthModeCertificate = "certificate"
+	// AuthModeSPNEGO authenticates with Kerberos through SPNEGO, for clusters
+	// behind Kerberos protected proxies.
+	AuthModeSPNEGO = "spnego"
+
+	unknownAuthMode string = "unknown authentication mode"
+	missingToken    string = "bearer authentication needs a token or token file"
+	missingCommand  string = "spnego authentication needs a command printing the SPNEGO token"
+)
+
+var (
+	ErrUnknownAuthMode = fmt.Errorf(unknownAuthMode)
+	ErrMissingToken    = fmt.Errorf(missingToken)
+	ErrMissingCommand  = fmt.Errorf(missingCommand)
+)
+
+// AuthConfig selects how requests to a cluster are authenticated.
+type AuthConfig struct {
+	// Mode is basic, bearer, certificate or spnego.
+	Mode string `json:"mode"`
+	// Token is the bearer token, for the bearer mode.
+	Token string `json:"token,omitempty"`
+	// TokenFile holds the bearer token, and is read again for every request
+	// so that the token can be rotated without restarting the exporter.
+	TokenFile string `json:"tokenFile,omitempty"`
+	// SPNEGOCommand prints the SPNEGO token for the service principal it is
+	// given, for the spnego mode.
+	SPNEGOCommand []string `json:"spnegoCommand,omitempty"`
+}
+
+// Validate checks that the mode is known and has what it needs.
+func (a AuthConfig) Validate() error {
+	switch a.Mode {
+	case "", AuthModeBasic, AuthModeCertificate:
+		return nil
+	case AuthModeBearer:
+		if a.Token == "" && a.TokenFile == "" {
+			return ErrMissingToken
+		}
+
+		return nil
+	case AuthModeSPNEGO:
+		if len(a.SPNEGOCommand) == 0 {
+			return ErrMissingCommand
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAuthMode, a.Mode)
+	}
+}
+
+// BearerToken returns the token to authenticate with in the bearer mode,
+// from the token file if there is one.
+func (a AuthConfig) BearerToken() (string, error) {
+	if a.TokenFile == "" {
+		return a.Token, nil
+	}
+
+	token, err := ioutil.ReadFile(a.TokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bearer token: %w", err)
+	}
+
+	return strings.TrimSpace(string(token)), nil
+}
@@ -0,0 +1,56 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	BackupRepositorySize = "backupRepositorySize"
+	BackupLastSuccess    = "backupLastSuccess"
+	BackupTaskDuration   = "backupTaskDuration"
+	BackupFailedTasks    = "backupFailedTasks"
+
+	BackupServiceName      = "backup"
+	BackupTaskStatusDone   = "done"
+	BackupTaskStatusFailed = "failed"
+	BackupTaskTypeBackup   = "BACKUP"
+)
+
+// BackupRepository is an entry of /api/v1/cluster/self/repository/active
+// on the backup service.
+type BackupRepository struct {
+	ID       string `json:"id"`
+	PlanName string `json:"plan_name"`
+	State    string `json:"state"`
+	Archive  string `json:"archive"`
+	Repo     string `json:"repo"`
+}
+
+// BackupRepositoryInfo is the result of
+// /api/v1/cluster/self/repository/active/<id>/info.
+type BackupRepositoryInfo struct {
+	Name    string  `json:"name"`
+	Size    float64 `json:"size"`
+	Backups []struct {
+		Date     string  `json:"date"`
+		Type     string  `json:"type"`
+		Complete bool    `json:"complete"`
+		Size     float64 `json:"size"`
+	} `json:"backups"`
+}
+
+// BackupTask is an entry of
+// /api/v1/cluster/self/repository/active/<id>/taskHistory.
+type BackupTask struct {
+	TaskName string `json:"task_name"`
+	Status   string `json:"status"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Type     string `json:"type"`
+	Error    string `json:"error"`
+}
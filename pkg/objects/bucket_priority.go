@@ -0,0 +1,54 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+// BucketPriority collects the buckets whose names match any of High in every
+// collection, and every other bucket only in every LowEvery-th collection, so
+// that the buckets that matter most stay fresh when there are too many to
+// collect them all within the refresh interval.  Each pattern is a regular
+// expression that must match the whole name.  Every bucket is collected in
+// every collection if High is empty or LowEvery is at most 1.
+type BucketPriority struct {
+	High     []string `json:"high,omitempty"`
+	LowEvery int      `json:"lowEvery,omitempty"`
+}
+
+// Schedule compiles the priorities into a function that returns whether the
+// bucket is due in the given collection, counting from 0.
+func (p BucketPriority) Schedule() (func(bucket string, collection int) bool, error) {
+	high, err := compileBucketPatterns(p.High)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(high) == 0 || p.LowEvery <= 1 {
+		return func(string, int) bool { return true }, nil
+	}
+
+	return func(bucket string, collection int) bool {
+		return collection%p.LowEvery == 0 || matchesAny(high, bucket)
+	}, nil
+}
+
+const (
+	DefaultSlowBucketsAfter = 3
+	DefaultSlowBucketsEvery = 5
+)
+
+// SlowBucketsConfig degrades a bucket whose stats took longer than Threshold
+// seconds to request in After collections in a row, collecting it only every
+// Every-th collection until a request for its stats is quick again, so that
+// one slow bucket does not hold up the collection of the rest.  No bucket is
+// degraded if Threshold is 0.
+type SlowBucketsConfig struct {
+	Threshold float64 `json:"threshold"`
+	After     int     `json:"after"`
+	Every     int     `json:"every"`
+}
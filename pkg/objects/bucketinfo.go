@@ -22,6 +22,10 @@ const (
 	DataUsed               = "dataUsed"
 	MemUsed                = "memUsed"
 	VbActiveNumNonResident = "vbActiveNumNonResident"
+
+	// BucketMaxTTL is the key of the bucket's maximum time to live, which is
+	// a setting rather than a basic stat.
+	BucketMaxTTL = "maxTTL"
 )
 
 type BucketInfo struct {
@@ -52,6 +56,7 @@ type BucketInfo struct {
 	} `json:"ddocs"`
 	ReplicaIndex           bool        `json:"replicaIndex"`
 	AutoCompactionSettings interface{} `json:"autoCompactionSettings"`
+	PurgeInterval          float64     `json:"purgeInterval"`
 	UUID                   string      `json:"uuid"`
 	VBucketServerMap       struct {
 		HashAlgorithm string   `json:"hashAlgorithm"`
@@ -72,4 +77,5 @@ type BucketInfo struct {
 	ConflictResolutionType string             `json:"conflictResolutionType"`
 	BucketCapabilitiesVer  string             `json:"bucketCapabilitiesVer"`
 	BucketCapabilities     []string           `json:"bucketCapabilities"`
+	EncryptionAtRestKeyID  *int               `json:"encryptionAtRestKeyId,omitempty"`
 }
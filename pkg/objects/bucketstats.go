@@ -128,6 +128,9 @@ const (
 	EpDiskqueueDrain                    = "ep_diskqueue_drain"
 	EpDiskqueueFill                     = "ep_diskqueue_fill"
 	EpDiskqueueItems                    = "ep_diskqueue_items"
+	EpExpiredAccess                     = "ep_expired_access"
+	EpExpiredCompactor                  = "ep_expired_compactor"
+	EpExpiredPager                      = "ep_expired_pager"
 	EpFlusherTodo                       = "ep_flusher_todo"
 	EpItemCommitFailed                  = "ep_item_commit_failed"
 	EpKvSize                            = "ep_kv_size"
@@ -151,6 +154,7 @@ const (
 	EpReplicaHlcDrift                   = "ep_replica_hlc_drift"
 	EpReplicaHlcDriftCount              = "ep_replica_hlc_drift_count"
 	EpTmpOomErrors                      = "ep_tmp_oom_errors"
+	EpTotalDelItems                     = "ep_total_del_items"
 	EpVbTotal                           = "ep_vb_total"
 	Evictions                           = "evictions"
 	BucketStatsGetHits                  = "get_hits"
@@ -185,6 +189,7 @@ const (
 	VbPendingQueueFill                  = "vb_pending_queue_fill"
 	VbPendingQueueSize                  = "vb_pending_queue_size"
 	BucketStatsVbReplicaCurrItems       = "vb_replica_curr_items"
+	BucketStatsVbReplicaCurrItemsSkew   = "vb_replica_curr_items_skew" // derived from curr_items and vb_replica_curr_items
 	VbReplicaEject                      = "vb_replica_eject"
 	VbReplicaItmMemory                  = "vb_replica_itm_memory"
 	VbReplicaMetaDataMemory             = "vb_replica_meta_data_memory"
@@ -225,20 +230,17 @@ const (
 )
 
 // /pools/default/buckets/<bucket-name>/nodes/<node-name>/stats
-// separate struct as the Samples needs to be a map[string]interface{}.
+// separate struct as the samples of each node may be anything, see Samples.
 type PerNodeBucketStats struct {
 	HostName string `json:"hostname,omitempty"` // per node stats only
 	Op       struct {
-		Samples      map[string]interface{} `json:"samples"`
-		SamplesCount int                    `json:"samplesCount"`
-		IsPersistent bool                   `json:"isPersistent"`
-		LastTStamp   int64                  `json:"lastTStamp"`
-		Interval     int                    `json:"interval"`
+		Samples      Samples `json:"samples"`
+		SamplesCount int     `json:"samplesCount"`
+		IsPersistent bool    `json:"isPersistent"`
+		LastTStamp   int64   `json:"lastTStamp"`
+		Interval     int     `json:"interval"`
 	} `json:"op"`
-	HotKeys []struct {
-		Name string  `json:"name"`
-		Ops  float64 `json:"ops"`
-	} `json:"hot_keys,omitempty"`
+	HotKeys []HotKey `json:"hot_keys,omitempty"`
 }
 
 // /pools/default/buckets/<bucket-name>/stats only
@@ -252,8 +254,5 @@ type BucketStats struct {
 		LastTStamp   float64              `json:"lastTStamp"`
 		Interval     float64              `json:"interval"`
 	} `json:"op"`
-	HotKeys []struct {
-		Name string  `json:"name"`
-		Ops  float64 `json:"ops"`
-	} `json:"hot_keys,omitempty"`
+	HotKeys []HotKey `json:"hot_keys,omitempty"`
 }
@@ -0,0 +1,69 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import "strings"
+
+const (
+	// The bucket types reported by /pools/default/buckets.
+	CouchbaseBucketType = "membase"
+	EphemeralBucketType = "ephemeral"
+	MemcachedBucketType = "memcached"
+)
+
+// BucketTypeName returns the name the web console gives a bucket type, which
+// for Couchbase buckets differs from the type the REST API reports.
+func BucketTypeName(bucketType string) string {
+	if bucketType == CouchbaseBucketType {
+		return "couchbase"
+	}
+
+	return bucketType
+}
+
+// BucketStatApplies returns whether buckets of the given type report a stat.
+// Ephemeral buckets never persist to disk, and memcached buckets have no
+// vBuckets, persistence or DCP, so their stats are a subset of those of
+// Couchbase buckets.  Buckets of any other type are assumed to report them all.
+func BucketStatApplies(bucketType, stat string) bool {
+	switch bucketType {
+	case EphemeralBucketType:
+		return !persistenceStat(stat)
+	case MemcachedBucketType:
+		return !persistenceStat(stat) && !engineStat(stat)
+	default:
+		return true
+	}
+}
+
+// persistenceStat returns whether stat describes writing to or reading from
+// disk.
+func persistenceStat(stat string) bool {
+	for _, prefix := range []string{"couch_", "disk_", "avg_disk_", "avg_bg_wait_time", "ep_bg_fetched", "ep_diskqueue_", "ep_flusher_", "ep_item_commit_failed", "ep_queue_size", "ep_dcp_views_"} {
+		if strings.HasPrefix(stat, prefix) {
+			return true
+		}
+	}
+
+	// the vBucket queues are the disk write queues.
+	return strings.HasPrefix(stat, "vb_") && strings.Contains(stat, "_queue_")
+}
+
+// engineStat returns whether stat is reported by the storage engine of
+// Couchbase and ephemeral buckets.
+func engineStat(stat string) bool {
+	for _, prefix := range []string{"ep_", "vb_", "avg_", "xdc_", "curr_items_tot"} {
+		if strings.HasPrefix(stat, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
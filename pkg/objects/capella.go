@@ -0,0 +1,117 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import "strings"
+
+const (
+	CapellaClusterHealthy        = "capellaClusterHealthy"
+	CapellaClusterNodes          = "capellaClusterNodes"
+	CapellaServiceGroupNodes     = "capellaServiceGroupNodes"
+	CapellaServiceGroupNodeCPU   = "capellaServiceGroupNodeCPU"
+	CapellaServiceGroupNodeRAM   = "capellaServiceGroupNodeRAM"
+	CapellaBucketItems           = "capellaBucketItems"
+	CapellaBucketOpsPerSecond    = "capellaBucketOpsPerSecond"
+	CapellaBucketDiskUsed        = "capellaBucketDiskUsed"
+	CapellaBucketMemoryUsed      = "capellaBucketMemoryUsed"
+	CapellaBucketMemoryAllocated = "capellaBucketMemoryAllocated"
+
+	// DefaultCapellaURL is the address of the Capella public API.
+	DefaultCapellaURL = "https://cloudapi.cloud.couchbase.com"
+
+	capellaHealthyState = "healthy"
+)
+
+// CapellaConfig configures the collection of metrics from clusters hosted in
+// Couchbase Capella, alongside the self-managed cluster.
+type CapellaConfig struct {
+	// URL is the address of the Capella public API.
+	URL string `json:"url"`
+	// OrganizationID is the organization that owns the clusters.
+	OrganizationID string `json:"organizationId"`
+	// APIKey is the secret of an API key with read access to the clusters.
+	APIKey string `json:"apiKey"`
+	// Clusters are the clusters to collect from, none by default.
+	Clusters []CapellaClusterRef `json:"clusters"`
+}
+
+// CapellaClusterRef identifies a Capella cluster.
+type CapellaClusterRef struct {
+	ProjectID string `json:"projectId"`
+	ClusterID string `json:"clusterId"`
+}
+
+// Enabled reports whether any Capella clusters are configured.
+func (c CapellaConfig) Enabled() bool {
+	return len(c.Clusters) > 0
+}
+
+// CapellaCluster is the result of
+// /v4/organizations/<org>/projects/<project>/clusters/<cluster>.
+type CapellaCluster struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	CurrentState    string `json:"currentState"`
+	CouchbaseServer struct {
+		Version string `json:"version"`
+	} `json:"couchbaseServer"`
+	ServiceGroups []CapellaServiceGroup `json:"serviceGroups"`
+}
+
+// Healthy reports whether Capella considers the cluster healthy.
+func (c CapellaCluster) Healthy() bool {
+	return c.CurrentState == capellaHealthyState
+}
+
+// Nodes returns the number of nodes across every service group.
+func (c CapellaCluster) Nodes() int {
+	nodes := 0
+	for _, group := range c.ServiceGroups {
+		nodes += group.NumOfNodes
+	}
+
+	return nodes
+}
+
+// CapellaServiceGroup is a set of identical nodes running the same services.
+type CapellaServiceGroup struct {
+	Node struct {
+		Compute struct {
+			CPU int `json:"cpu"`
+			RAM int `json:"ram"`
+		} `json:"compute"`
+	} `json:"node"`
+	NumOfNodes int      `json:"numOfNodes"`
+	Services   []string `json:"services"`
+}
+
+// ServiceNames returns the services of the group, comma separated.
+func (g CapellaServiceGroup) ServiceNames() string {
+	return strings.Join(g.Services, ",")
+}
+
+// CapellaBuckets is the result of
+// /v4/organizations/<org>/projects/<project>/clusters/<cluster>/buckets.
+type CapellaBuckets struct {
+	Data []CapellaBucket `json:"data"`
+}
+
+type CapellaBucket struct {
+	ID                   string `json:"id"`
+	Name                 string `json:"name"`
+	Type                 string `json:"type"`
+	MemoryAllocationInMb int    `json:"memoryAllocationInMb"`
+	Stats                struct {
+		ItemCount       int `json:"itemCount"`
+		OpsPerSecond    int `json:"opsPerSecond"`
+		DiskUsedInMib   int `json:"diskUsedInMib"`
+		MemoryUsedInMib int `json:"memoryUsedInMib"`
+	} `json:"stats"`
+}
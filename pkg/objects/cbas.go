@@ -19,8 +19,29 @@ const (
 	CbasIoWrites          = "cbas_io_writes"
 	CbasSystemLoadAverage = "cbas_system_load_average"
 	CbasThreadCount       = "cbas_thread_count"
+
+	// Keys for the metrics read from the analytics service itself.
+	CbasLinkConnected         = "CbasLinkConnected"
+	CbasDatasetItemsProcessed = "CbasDatasetItemsProcessed"
+	CbasDatasetProgress       = "CbasDatasetProgress"
+	CbasDatasetTimeLag        = "CbasDatasetTimeLag"
+	CbasFailedRecords         = "CbasFailedRecords"
+
+	// CbasFailedRecordsStat is the name of the stat counting records the
+	// analytics service failed to ingest, in the Couchbase Server 7 stats API.
+	CbasFailedRecordsStat = "cbas_failed_to_parse_records_count"
 )
 
+// CbasNodeMetrics are the metrics only reported on nodes running the
+// analytics service, keyed by their configuration key.
+var CbasNodeMetrics = map[string]bool{
+	CbasLinkConnected:         true,
+	CbasDatasetItemsProcessed: true,
+	CbasDatasetProgress:       true,
+	CbasDatasetTimeLag:        true,
+	CbasFailedRecords:         true,
+}
+
 type Analytics struct {
 	Op struct {
 		Samples      map[string][]float64 `json:"samples"`
@@ -30,3 +51,42 @@ type Analytics struct {
 		Interval     int                  `json:"interval"`
 	} `json:"op"`
 }
+
+// AnalyticsIngestion is the result of /analytics/status/ingestion on the
+// analytics service.
+type AnalyticsIngestion struct {
+	Links []AnalyticsLink `json:"links"`
+}
+
+// AnalyticsLink is the ingestion state of the datasets of one link.
+type AnalyticsLink struct {
+	Name   string                    `json:"name"`
+	Scope  string                    `json:"scope"`
+	Status string                    `json:"status"`
+	State  []AnalyticsIngestionState `json:"state"`
+}
+
+// AnalyticsIngestionState is the ingestion progress shared by a group of the
+// link's datasets.  The time lag is in milliseconds.
+type AnalyticsIngestionState struct {
+	Progress       float64 `json:"progress"`
+	TimeLag        float64 `json:"timeLag"`
+	ItemsProcessed float64 `json:"itemsProcessed"`
+	Scopes         []struct {
+		Name        string `json:"name"`
+		Collections []struct {
+			Name string `json:"name"`
+		} `json:"collections"`
+	} `json:"scopes"`
+}
+
+// QualifiedName returns the name of the link within its scope.
+func (l AnalyticsLink) QualifiedName() string {
+	return l.Scope + "." + l.Name
+}
+
+// Connected returns whether the link is connected, which it is unless it has
+// been stopped or suspended.
+func (l AnalyticsLink) Connected() bool {
+	return l.Status != "stopped" && l.Status != "suspended"
+}
@@ -0,0 +1,32 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	ClientTmpOomErrors = "clientTmpOomErrors"
+	ClientNotMyVbucket = "clientNotMyVbucket"
+	ClientAuthErrors   = "clientAuthErrors"
+
+	// the names of the data service's stats in the Couchbase Server 7 stats
+	// API that count the errors client SDKs see.
+	KVTmpOomErrorsStat  = "kv_ep_tmp_oom_errors"
+	KVNotMyVbucketsStat = "kv_ep_num_not_my_vbuckets"
+	KVAuthErrorsStat    = "kv_auth_errors"
+)
+
+// ClientErrorStats are the stats read by the client errors collector, by the
+// key of the metric each is exported as.  The temporary OOM and not my vBucket
+// errors are counted for each bucket on each node, and authentication errors
+// for each node.
+var ClientErrorStats = map[string]string{
+	ClientTmpOomErrors: KVTmpOomErrorsStat,
+	ClientNotMyVbucket: KVNotMyVbucketsStat,
+	ClientAuthErrors:   KVAuthErrorsStat,
+}
@@ -0,0 +1,63 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import "fmt"
+
+const (
+	// ClusterRoleLabel marks every series of a cluster with its role.
+	ClusterRoleLabel = "cluster_role"
+
+	// ClusterRolePrimary is the role of a cluster serving applications.
+	ClusterRolePrimary = "primary"
+	// ClusterRoleStandby is the role of a read-only XDCR standby or disaster
+	// recovery cluster.
+	ClusterRoleStandby = "standby"
+
+	invalidClusterRole string = "invalid cluster role"
+)
+
+var (
+	ErrInvalidClusterRole = fmt.Errorf(invalidClusterRole)
+)
+
+// ClusterRoleConfig marks the role of the clusters collected from, so that
+// dashboards can tell primaries from standbys.
+type ClusterRoleConfig struct {
+	// Role is primary or standby, and labels every series of the top level
+	// cluster with cluster_role when set.  Other clusters take it unless they
+	// set a role of their own.
+	Role string `json:"role"`
+	// SuppressOnStandby drops the Metrics of standby clusters.
+	SuppressOnStandby bool `json:"suppressOnStandby"`
+	// Metrics are the metrics, named as collected, that are only of use for
+	// alerting on a primary.
+	Metrics []string `json:"metrics"`
+}
+
+// Validate checks that the role is known.
+func (c ClusterRoleConfig) Validate() error {
+	switch c.Role {
+	case "", ClusterRolePrimary, ClusterRoleStandby:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidClusterRole, c.Role)
+	}
+}
+
+// DefaultStandbySuppressedMetrics are the derived metrics alerts are built on,
+// which would only raise false alarms on a standby.
+func DefaultStandbySuppressedMetrics() []string {
+	return []string{
+		HealthStatusMetric,
+		"cbbucketstat_vbuckets_replica_curr_items_skew",
+		"cbpernodebucket_vb_replica_curr_items_skew",
+	}
+}
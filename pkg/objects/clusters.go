@@ -0,0 +1,214 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	invalidBucketFilter string = "invalid bucket filter"
+	invalidCluster      string = "invalid cluster"
+)
+
+var (
+	ErrInvalidBucketFilter = fmt.Errorf(invalidBucketFilter)
+	ErrInvalidCluster      = fmt.Errorf(invalidCluster)
+)
+
+// BucketFilter selects the buckets that are collected by name.  Each pattern
+// is a regular expression that must match the whole name.  A bucket is
+// collected if it matches any of Include, or Include is empty, and none of
+// Exclude.
+type BucketFilter struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// Empty reports whether the filter collects every bucket.
+func (f BucketFilter) Empty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Matcher compiles the filter.
+func (f BucketFilter) Matcher() (func(bucket string) bool, error) {
+	include, err := compileBucketPatterns(f.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	exclude, err := compileBucketPatterns(f.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(bucket string) bool {
+		return (len(include) == 0 || matchesAny(include, bucket)) && !matchesAny(exclude, bucket)
+	}, nil
+}
+
+func compileBucketPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalidBucketFilter, pattern, err)
+		}
+
+		compiled = append(compiled, re)
+	}
+
+	return compiled, nil
+}
+
+func matchesAny(patterns []*regexp.Regexp, bucket string) bool {
+	for _, re := range patterns {
+		if re.MatchString(bucket) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClusterConfig is another cluster to collect from beside the one the top
+// level settings connect to.  Any setting left empty is taken from the top
+// level.
+type ClusterConfig struct {
+	// Name identifies the cluster in the logs, the cluster label is still
+	// the name the cluster reports.
+	Name              string      `json:"name"`
+	CouchbaseAddress  string      `json:"couchbaseAddress,omitempty"`
+	CouchbasePort     int         `json:"couchbasePort,omitempty"`
+	CouchbaseUser     string      `json:"couchbaseUser,omitempty"`
+	CouchbasePassword string      `json:"couchbasePassword,omitempty"`
+	CouchbaseAuth     *AuthConfig `json:"couchbaseAuth,omitempty"`
+	Ca                string      `json:"ca,omitempty"`
+	ClientCertificate string      `json:"clientCertificate,omitempty"`
+	ClientKey         string      `json:"clientKey,omitempty"`
+	// Role replaces the top level cluster role, unless it is empty.
+	Role string `json:"role,omitempty"`
+	// Buckets replaces the top level bucket filter, unless it is empty.
+	Buckets BucketFilter `json:"buckets"`
+	// Collectors enables or disables collectors by name for this cluster.
+	// Collectors that are not listed are enabled.
+	Collectors map[string]bool `json:"collectors,omitempty"`
+}
+
+// CollectorEnabled reports whether the named collector is collected for the
+// cluster, ignoring case.
+func (c ClusterConfig) CollectorEnabled(name string) bool {
+	for collector, enabled := range c.Collectors {
+		if strings.EqualFold(collector, name) {
+			return enabled
+		}
+	}
+
+	return true
+}
+
+// ForCluster returns the configuration to collect from the given cluster
+// with, the top level settings with those the cluster overrides.
+func (e *ExporterConfig) ForCluster(c ClusterConfig) *ExporterConfig {
+	derived := *e
+	derived.Clusters = nil
+
+	// the exporter runs beside at most the node of the top level cluster.
+	derived.Sidecar.Enabled = false
+	derived.NodeName = ""
+
+	if c.CouchbaseAddress != "" {
+		derived.CouchbaseAddress = c.CouchbaseAddress
+	}
+
+	if c.CouchbasePort != 0 {
+		derived.CouchbasePort = c.CouchbasePort
+	}
+
+	if c.CouchbaseUser != "" {
+		derived.CouchbaseUser = c.CouchbaseUser
+	}
+
+	if c.CouchbasePassword != "" {
+		derived.CouchbasePassword = c.CouchbasePassword
+	}
+
+	if c.CouchbaseAuth != nil {
+		derived.CouchbaseAuth = *c.CouchbaseAuth
+	}
+
+	// the TLS material is replaced as a whole, so that a cluster with its own
+	// CA does not present the client certificate of another.
+	if c.Ca != "" || c.ClientCertificate != "" || c.ClientKey != "" {
+		derived.Ca = c.Ca
+		derived.ClientCertificate = c.ClientCertificate
+		derived.ClientKey = c.ClientKey
+	}
+
+	if c.Role != "" {
+		derived.ClusterRole.Role = c.Role
+	}
+
+	if !c.Buckets.Empty() {
+		derived.Buckets = c.Buckets
+	}
+
+	return &derived
+}
+
+// ValidateClusters checks the bucket filter, priorities and role of the top
+// level, and that every other cluster has a name of its own, a valid bucket
+// filter, authentication and role.
+func (e *ExporterConfig) ValidateClusters() error {
+	if _, err := e.Buckets.Matcher(); err != nil {
+		return err
+	}
+
+	if _, err := e.BucketPriority.Schedule(); err != nil {
+		return err
+	}
+
+	if err := e.ClusterRole.Validate(); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+
+	for _, c := range e.Clusters {
+		if c.Name == "" {
+			return fmt.Errorf("%w: every cluster needs a name", ErrInvalidCluster)
+		}
+
+		if names[c.Name] {
+			return fmt.Errorf("%w: more than one cluster is named %s", ErrInvalidCluster, c.Name)
+		}
+
+		names[c.Name] = true
+
+		derived := e.ForCluster(c)
+
+		if _, err := derived.Buckets.Matcher(); err != nil {
+			return fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+
+		if err := derived.CouchbaseAuth.Validate(); err != nil {
+			return fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+
+		if err := derived.ClusterRole.Validate(); err != nil {
+			return fmt.Errorf("cluster %s: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
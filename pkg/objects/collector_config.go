@@ -18,6 +18,7 @@ import (
 
 const (
 	DefaultNamespace                = "cb"
+	ExporterNamespace               = "cbexporter"
 	DefaultUptimeMetric             = "up"
 	DefaultScrapeDurationMetric     = "scrape_duration_seconds"
 	DefaultUptimeMetricHelp         = "Couchbase cluster API is responding"
@@ -28,6 +29,33 @@ const (
 	KeyspaceLabel                   = "keyspace"
 	TargetLabel                     = "target"
 	SourceLabel                     = "source"
+	SeverityLabel                   = "severity"
+	MessageLabel                    = "message"
+	RepositoryLabel                 = "repository"
+	PlanLabel                       = "plan"
+	TaskTypeLabel                   = "type"
+	DesignDocLabel                  = "ddoc"
+	ServerGroupLabel                = "server_group"
+	ClusterUUIDLabel                = "cluster_uuid"
+	BucketUUIDLabel                 = "bucket_uuid"
+	ServicesLabel                   = "services"
+	FingerprintLabel                = "fingerprint"
+	StatementLabel                  = "statement"
+	DurationBucketLabel             = "le"
+	LinkLabel                       = "link"
+	DatasetLabel                    = "dataset"
+	BucketTypeLabel                 = "bucket_type"
+	EditionLabel                    = "edition"
+	VersionLabel                    = "version"
+	CompatVersionLabel              = "compat_version"
+	DocumentKeyLabel                = "key"
+	RankLabel                       = "rank"
+	StateLabel                      = "state"
+	IndexLabel                      = "index"
+	StorageModeLabel                = "storage_mode"
+	ServiceLabel                    = "service"
+	EncryptionLevelLabel            = "level"
+	EncryptedDataLabel              = "data"
 	SearchMetricPrefix              = "fts_"
 	QueryMetricPrefix               = "query_"
 	IndexMetricPrefix               = "index_"
@@ -42,14 +70,29 @@ type CollectorConfig struct {
 	Metrics   map[string]MetricInfo `json:"metrics"`
 }
 
-func (m *MetricInfo) GetPrometheusDescription(namespace string, subsystem string) *prometheus.Desc {
+// Lookup returns the metric configured under key, provided it is enabled.
+func (c *CollectorConfig) Lookup(key string) (MetricInfo, bool) {
+	m, ok := c.Metrics[key]
+	if !ok || !m.Enabled {
+		return m, false
+	}
+
+	return m, true
+}
+
+// FQName returns the fully qualified name the metric is exported under.
+func (m *MetricInfo) FQName(namespace string, subsystem string) string {
 	name := m.Name
 	if m.NameOverride != "" {
 		name = m.NameOverride
 	}
 
+	return prometheus.BuildFQName(namespace, subsystem, name)
+}
+
+func (m *MetricInfo) GetPrometheusDescription(namespace string, subsystem string) *prometheus.Desc {
 	return prometheus.NewDesc(
-		prometheus.BuildFQName(namespace, subsystem, name),
+		m.FQName(namespace, subsystem),
 		m.HelpText,
 		GetLabelKeys(m.Labels),
 		nil)
@@ -89,46 +132,104 @@ type MetricInfo struct {
 	NameOverride string   `json:"nameOverride"`
 	HelpText     string   `json:"helpText"`
 	Labels       []string `json:"labels"`
+	// Aggregate exports the minimum, average and maximum over the window of
+	// samples Couchbase Server returns alongside the latest sample, when
+	// sample window aggregates are enabled.
+	Aggregate bool `json:"aggregate,omitempty"`
+	// Type is how the metric is exported, a gauge unless set, and Endpoint
+	// the REST endpoint it is read from when that is not its collector's.
+	// They describe what the collector does rather than configure it, so are
+	// not read from the configuration file.
+	Type     string `json:"-"`
+	Endpoint string `json:"-"`
 }
 
 func GetQueryCollectorDefaultConfig() *CollectorConfig {
-	return queryCollectorDefaultConfig()
+	return withHelpText(queryCollectorDefaultConfig())
 }
 
 func GetBucketInfoCollectorDefaultConfig() *CollectorConfig {
-	return bucketInfoCollectorDefaultConfig()
+	return withHelpText(bucketInfoCollectorDefaultConfig())
 }
 
 func GetAnalyticsCollectorDefaultConfig() *CollectorConfig {
-	return analyticsCollectorDefaultConfig()
+	return withHelpText(analyticsCollectorDefaultConfig())
 }
 
 func GetIndexCollectorDefaultConfig() *CollectorConfig {
-	return indexCollectorDefaultConfig()
+	return withHelpText(indexCollectorDefaultConfig())
 }
 
 func GetSearchCollectorDefaultConfig() *CollectorConfig {
-	return searchCollectorDefaultConfig()
+	return withHelpText(searchCollectorDefaultConfig())
 }
 
 func GetTaskCollectorDefaultConfig() *CollectorConfig {
-	return taskCollectorDefaultConfig()
+	return withHelpText(taskCollectorDefaultConfig())
 }
 
 func GetEventingCollectorDefaultConfig() *CollectorConfig {
-	return eventingCollectorDefaultConfig()
+	return withHelpText(eventingCollectorDefaultConfig())
 }
 
 func GetNodeCollectorDefaultConfig() *CollectorConfig {
-	return nodeCollectorDefaultConfig()
+	return withHelpText(nodeCollectorDefaultConfig())
 }
 
 func GetBucketStatsCollectorDefaultConfig() *CollectorConfig {
-	return bucketStatsCollectorDefaultConfig()
+	return withHelpText(bucketStatsCollectorDefaultConfig())
+}
+
+func GetAlertsCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(alertsCollectorDefaultConfig())
+}
+
+func GetAuditCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(auditCollectorDefaultConfig())
+}
+
+func GetSecurityCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(securityCollectorDefaultConfig())
+}
+
+func GetBackupCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(backupCollectorDefaultConfig())
+}
+
+func GetViewsCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(viewsCollectorDefaultConfig())
+}
+
+func GetCapellaCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(capellaCollectorDefaultConfig())
+}
+
+func GetSlowQueriesCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(slowQueriesCollectorDefaultConfig())
+}
+
+func GetPreparedCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(preparedCollectorDefaultConfig())
+}
+
+func GetHotKeysCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(hotKeysCollectorDefaultConfig())
+}
+
+func GetKVConnectionsCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(kvConnectionsCollectorDefaultConfig())
+}
+
+func GetClientErrorsCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(clientErrorsCollectorDefaultConfig())
+}
+
+func GetRollupCollectorDefaultConfig() *CollectorConfig {
+	return withHelpText(rollupCollectorDefaultConfig())
 }
 
 func GetPerNodeBucketStatsCollectorDefaultConfig() *CollectorConfig {
-	return perNodeBucketStatsCollectorDefaultConfig()
+	return withHelpText(perNodeBucketStatsCollectorDefaultConfig())
 }
 
 func perNodeBucketStatsCollectorDefaultConfig() *CollectorConfig {
@@ -893,6 +994,27 @@ func perNodeBucketStatsCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
 				Enabled:      true,
 			},
+			"EpExpiredAccess": {
+				NameOverride: "",
+				Name:         "ep_expired_access",
+				HelpText:     "Number of items per second that had expired when a client accessed them in this bucket",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
+			"EpExpiredCompactor": {
+				NameOverride: "",
+				Name:         "ep_expired_compactor",
+				HelpText:     "Number of items per second the compactor found expired in this bucket",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
+			"EpExpiredPager": {
+				NameOverride: "",
+				Name:         "ep_expired_pager",
+				HelpText:     "Number of items per second the expiry pager found expired in this bucket",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
 			"EpFlusherTodo": {
 				NameOverride: "",
 				Name:         "ep_flusher_todo",
@@ -1054,6 +1176,13 @@ func perNodeBucketStatsCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
 				Enabled:      true,
 			},
+			"EpTotalDelItems": {
+				NameOverride: "",
+				Name:         "ep_total_del_items",
+				HelpText:     "Number of deleted items per second persisted as tombstones in this bucket, which are kept until the metadata purge interval has passed",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
 			"EpVbTotal": {
 				NameOverride: "",
 				Name:         "ep_vb_total",
@@ -1294,6 +1423,13 @@ func perNodeBucketStatsCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
 				Enabled:      true,
 			},
+			"VbReplicaCurrItemsSkew": {
+				NameOverride: "",
+				Name:         "vb_replica_curr_items_skew",
+				HelpText:     "Number of items the replica vBuckets in this bucket are short of a copy of every active item for each replica, which widens as replication falls behind",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
 			"VbReplicaEject": {
 				NameOverride: "",
 				Name:         "vb_replica_eject",
@@ -1774,6 +1910,7 @@ func bucketStatsCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{BucketLabel, ClusterLabel},
 				NameOverride: "",
 				Enabled:      true,
+				Aggregate:    true,
 			},
 			"EpActiveAheadExceptions": {
 				Name:         "ep_active_ahead_exceptions",
@@ -1809,6 +1946,7 @@ func bucketStatsCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{BucketLabel, ClusterLabel},
 				NameOverride: "",
 				Enabled:      true,
+				Aggregate:    true,
 			},
 			"EpDcp2IBackoff": {
 				Name:         "ep_dcp_2i_backoff",
@@ -2076,6 +2214,27 @@ func bucketStatsCollectorDefaultConfig() *CollectorConfig {
 				NameOverride: "",
 				Enabled:      true,
 			},
+			"EpExpiredAccess": {
+				Name:         "ep_expired_access",
+				HelpText:     "Number of items per second that had expired when a client accessed them in this bucket",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				NameOverride: "",
+				Enabled:      true,
+			},
+			"EpExpiredCompactor": {
+				Name:         "ep_expired_compactor",
+				HelpText:     "Number of items per second the compactor found expired in this bucket",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				NameOverride: "",
+				Enabled:      true,
+			},
+			"EpExpiredPager": {
+				Name:         "ep_expired_pager",
+				HelpText:     "Number of items per second the expiry pager found expired in this bucket",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				NameOverride: "",
+				Enabled:      true,
+			},
 			"EpFlusherTodo": {
 				Name:         "ep_flusher_todo",
 				HelpText:     "Number of items currently being written",
@@ -2237,6 +2396,13 @@ func bucketStatsCollectorDefaultConfig() *CollectorConfig {
 				NameOverride: "",
 				Enabled:      true,
 			},
+			"EpTotalDelItems": {
+				Name:         "ep_total_del_items",
+				HelpText:     "Number of deleted items per second persisted as tombstones in this bucket, which are kept until the metadata purge interval has passed",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				NameOverride: "",
+				Enabled:      true,
+			},
 			"EpVbTotal": {
 				Name:         "ep_vb_total",
 				HelpText:     "Total number of vBuckets for this bucket",
@@ -2355,6 +2521,7 @@ func bucketStatsCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{BucketLabel, ClusterLabel},
 				NameOverride: "",
 				Enabled:      true,
+				Aggregate:    true,
 			},
 			"RestRequests": {
 				Name:         "rest_requests",
@@ -2587,6 +2754,13 @@ func bucketStatsCollectorDefaultConfig() *CollectorConfig {
 				NameOverride: "vbuckets_replica_curr_items",
 				Enabled:      true,
 			},
+			"VbReplicaCurrItemsSkew": {
+				Name:         "vb_replica_curr_items_skew",
+				HelpText:     "Number of items the replica vBuckets in this bucket are short of a copy of every active item for each replica, which widens as replication falls behind",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				NameOverride: "vbuckets_replica_curr_items_skew",
+				Enabled:      true,
+			},
 			"VbReplicaEject": {
 				Name:         "vb_replica_eject",
 				HelpText:     "Number of items per second being ejected to disk from replica vBuckets in this bucket",
@@ -2704,6 +2878,36 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Is this node healthy",
 				Labels:       []string{ClusterLabel, NodeLabel},
 			},
+			ClockSkew: {
+				Name:         "clock_skew_seconds",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Seconds the clock of the node is ahead of the exporter's, or behind it if negative, to within a second",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			ClusterInfo: {
+				Name:         "cluster_info",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Always 1, labelled with the UUID of the cluster, which unlike its name cannot be changed, its edition, version and compatibility version",
+				Labels:       []string{ClusterLabel, ClusterUUIDLabel, EditionLabel, VersionLabel, CompatVersionLabel},
+				Endpoint:     "/pools",
+			},
+			VersionInfo: {
+				Name:         "version_info",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Always 1, labelled with the version of Couchbase Server the node runs",
+				Labels:       []string{ClusterLabel, NodeLabel, VersionLabel},
+			},
+			ServerGroupInfo: {
+				Name:         "server_group_info",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Always 1, labelled with the server group the node belongs to",
+				Labels:       []string{ClusterLabel, NodeLabel, ServerGroupLabel},
+				Endpoint:     "/pools/default/serverGroups",
+			},
 			"systemStatsCPUUtilizationRate": {
 				Name:         "systemstats_cpu_utilization_rate",
 				NameOverride: "",
@@ -2739,6 +2943,34 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
 			},
+			"systemStatsCPUStolenRate": {
+				Name:         "systemstats_cpu_stolen_rate",
+				NameOverride: "",
+				HelpText:     "Percentage of CPU time stolen from this server by the hypervisor for other virtual machines.",
+				Labels:       []string{NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
+			"systemStatsCPUCoresAvailable": {
+				Name:         "systemstats_cpu_cores_available",
+				NameOverride: "",
+				HelpText:     "Number of CPU cores available to Couchbase Server on this server, which is less than the number of cores when limited by a container.",
+				Labels:       []string{NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
+			"systemStatsMemLimit": {
+				Name:         "systemstats_mem_limit",
+				NameOverride: "",
+				HelpText:     "Bytes of memory available to Couchbase Server on this server, which is less than the total memory when limited by a container.",
+				Labels:       []string{NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
+			"systemStatsAllocstall": {
+				Name:         "systemstats_allocstall",
+				NameOverride: "",
+				HelpText:     "Number of times the kernel stalled an allocation to reclaim memory on this server since it started, which rises under memory pressure.",
+				Labels:       []string{NodeLabel, ClusterLabel},
+				Enabled:      true,
+			},
 			"interestingStatsCouchDocsActualDiskSize": {
 				Name:         "interestingstats_couch_docs_actual_disk_size",
 				NameOverride: "",
@@ -2850,6 +3082,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				NameOverride: "",
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"memoryTotal": {
 				Name:         "memory_total",
@@ -2857,6 +3090,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				NameOverride: "",
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"memoryFree": {
 				Name:         "memory_free",
@@ -2864,6 +3098,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				NameOverride: "",
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"mcdMemoryAllocated": {
 				Name:         "memcached_memory_allocated",
@@ -2871,6 +3106,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "memcached_memory_allocated",
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"mcdMemoryReserved": {
 				Name:         "memcached_memory_reserved",
@@ -2878,6 +3114,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "memcached_memory_reserved",
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"clusterMembership": {
 				Name:         "cluster_membership",
@@ -2885,6 +3122,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "whether or not node is part of the CB cluster",
 				Labels:       []string{NodeLabel, ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrFailover": {
 				Name:         "failover",
@@ -2892,6 +3130,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "failover",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrFailoverNode": {
 				Name:         "failover_node",
@@ -2899,6 +3138,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "failover_node",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrFailoverComplete": {
 				Name:         "failover_complete",
@@ -2906,6 +3146,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "failover_complete",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrFailoverIncomplete": {
 				Name:         "failover_incomplete",
@@ -2913,6 +3154,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "failover_incomplete",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrRebalanceStart": {
 				Name:         "rebalance_start",
@@ -2920,6 +3162,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "rebalance_start",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrRebalanceStop": {
 				Name:         "rebalance_stop",
@@ -2927,6 +3170,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "rebalance_stop",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrRebalanceSuccess": {
 				Name:         "rebalance_success",
@@ -2934,6 +3178,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "rebalance_success",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrRebalanceFail": {
 				Name:         "rebalance_fail",
@@ -2941,6 +3186,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "rebalance_failure",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrGracefulFailoverStart": {
 				Name:         "graceful_failover_start",
@@ -2948,6 +3194,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "graceful_failover_start",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrGracefulFailoverSuccess": {
 				Name:         "graceful_failover_success",
@@ -2955,6 +3202,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "graceful_failover_success",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 			"ctrGracefulFailoverFail": {
 				Name:         "graceful_failover_fail",
@@ -2962,6 +3210,7 @@ func nodeCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "graceful_failover_fail",
 				Labels:       []string{ClusterLabel},
 				Enabled:      true,
+				Type:         MetricTypeCounter,
 			},
 		},
 	}
@@ -3155,6 +3404,13 @@ func taskCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Progress of a rebalance task per node",
 				Labels:       []string{NodeLabel, ClusterLabel},
 			},
+			"rebalanceIndex": {
+				Name:         "index_rebalance_progress",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Progress of the index service stage of a running rebalance",
+				Labels:       []string{ClusterLabel},
+			},
 			"compacting": {
 				Name:         "compacting_progress",
 				NameOverride: "",
@@ -3162,6 +3418,43 @@ func taskCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Progress of a bucket compaction task",
 				Labels:       []string{BucketLabel, ClusterLabel},
 			},
+			"compactionRunning": {
+				Name:         "compaction_running",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Whether the bucket is being compacted",
+				Labels:       []string{BucketLabel, ClusterLabel},
+			},
+			"compactionLastDuration": {
+				Name:         "compaction_last_duration_seconds",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "How long the last compaction of the bucket seen by the exporter took, to within a scrape interval",
+				Labels:       []string{BucketLabel, ClusterLabel},
+			},
+			"compactionDocsThreshold": {
+				Name:         "compaction_docs_fragmentation_threshold",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Percentage fragmentation of the bucket's data files that triggers auto-compaction",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				Endpoint:     "/pools/default/buckets",
+			},
+			"compactionViewsThreshold": {
+				Name:         "compaction_views_fragmentation_threshold",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Percentage fragmentation of the bucket's view index files that triggers auto-compaction",
+				Labels:       []string{BucketLabel, ClusterLabel},
+				Endpoint:     "/pools/default/buckets",
+			},
+			"compactionPurgeInterval": {
+				Name:         "compaction_metadata_purge_interval_seconds",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "How long the bucket keeps the tombstones of deleted documents before compaction purges them",
+				Labels:       []string{BucketLabel, ClusterLabel},
+			},
 			"clusterLogsCollection": {
 				Name:         "cluster_logs_collection_progress",
 				NameOverride: "",
@@ -3204,6 +3497,38 @@ func taskCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Number of errors",
 				Labels:       []string{BucketLabel, TargetLabel, ClusterLabel},
 			},
+			"xdcrDocsFailedCrSource": {
+				Name:         "xdcr_docs_failed_cr_source",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of documents not replicated because the target won conflict resolution on the source side",
+				Labels:       []string{BucketLabel, TargetLabel, ClusterLabel},
+				Endpoint:     xdcrStatsEndpoint,
+			},
+			"xdcrDocsFiltered": {
+				Name:         "xdcr_docs_filtered",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of documents not replicated because of the replication's filter",
+				Labels:       []string{BucketLabel, TargetLabel, ClusterLabel},
+				Endpoint:     xdcrStatsEndpoint,
+			},
+			"xdcrCheckpoints": {
+				Name:         "xdcr_checkpoints",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of checkpoints the replication has taken",
+				Labels:       []string{BucketLabel, TargetLabel, ClusterLabel},
+				Endpoint:     xdcrStatsEndpoint,
+			},
+			"xdcrFailedCheckpoints": {
+				Name:         "xdcr_failed_checkpoints",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of checkpoints the replication failed to take",
+				Labels:       []string{BucketLabel, TargetLabel, ClusterLabel},
+				Endpoint:     xdcrStatsEndpoint,
+			},
 			"progressDocsTotal": {
 				Name:         "docs_total",
 				NameOverride: "",
@@ -3232,6 +3557,27 @@ func taskCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Number of Replica VBuckets remaining",
 				Labels:       []string{BucketLabel, SourceLabel, TargetLabel, ClusterLabel},
 			},
+			"warmupState": {
+				Name:         "warmup_state",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Phase of the warmup of the bucket on a node that is loading it from disk after a restart",
+				Labels:       []string{BucketLabel, NodeLabel, StateLabel, ClusterLabel},
+			},
+			"warmupEstimatedItems": {
+				Name:         "warmup_estimated_items",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Estimated number of items to load in the warmup of the bucket on a node",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+			},
+			"warmupItems": {
+				Name:         "warmup_items",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of items loaded so far in the warmup of the bucket on a node",
+				Labels:       []string{BucketLabel, NodeLabel, ClusterLabel},
+			},
 		},
 	}
 
@@ -3305,12 +3651,29 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Bytes of Index RAM quota still available on this server.",
 				Labels:       []string{ClusterLabel},
 			},
+			"IndexerMemoryQuota": {
+				Name:         "indexer_memory_quota",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Memory quota of the indexer on this node, as reported by the indexer.",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Endpoint:     indexerStatsEndpoint,
+			},
+			"IndexerMemoryUsed": {
+				Name:         "indexer_memory_used",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Memory used by the indexer on this node, as reported by the indexer.",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Endpoint:     indexerStatsEndpoint,
+			},
 			"DocsIndexed": {
 				Name:         "num_docs_indexed",
 				NameOverride: "",
 				Enabled:      true,
 				HelpText:     "Number of documents indexed by the indexer since last startup.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"ItemsCount": {
 				Name:         "items_count",
@@ -3318,6 +3681,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "The number of items currently indexed.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"IndexFragPercent": {
 				Name:         "frag_percent",
@@ -3325,6 +3689,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Percentage fragmentation of the index.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"NumDocsPendingQueued": {
 				Name:         "num_docs_pending_queued",
@@ -3332,6 +3697,23 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Number of documents pending to be indexed.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
+			},
+			"NumDocsQueued": {
+				Name:         "num_docs_queued",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of documents queued by the indexer but not yet processed.",
+				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
+			},
+			"NumDocsPending": {
+				Name:         "num_docs_pending",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of documents pending to be sent to the indexer.",
+				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"NumRequests": {
 				Name:         "num_requests",
@@ -3339,6 +3721,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Number of requests served by the indexer since last startup.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"CacheMisses": {
 				Name:         "cache_misses",
@@ -3346,6 +3729,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Accesses to this index data from disk.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"CacheHits": {
 				Name:         "cache_hits",
@@ -3353,6 +3737,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Accesses to this index data from RAM.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"CacheHitPercent": {
 				Name:         "cache_hit_percent",
@@ -3360,6 +3745,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Percentage of memory accesses that were served from the managed cache.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"NumRowsReturned": {
 				Name:         "num_rows_returned",
@@ -3367,6 +3753,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Total number of rows returned so far by the indexer.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"IndexResidentPercent": {
 				Name:         "resident_percent",
@@ -3374,6 +3761,7 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Percentage of the data held in memory.",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 			"AvgScanLatency": {
 				Name:         "avg_scan_latency",
@@ -3381,6 +3769,23 @@ func indexCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				HelpText:     "Average time to serve a scan request (nanoseconds).",
 				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
+			},
+			IndexStorageInfo: {
+				Name:         "storage_info",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Always 1, labelled with the storage mode of each index, plasma, memory_optimized or forestdb",
+				Labels:       []string{ClusterLabel, KeyspaceLabel, IndexLabel, StorageModeLabel},
+				Endpoint:     indexerStatsEndpoint,
+			},
+			IndexDuplicates: {
+				Name:         "duplicate_indexes",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of indexes on the keyspace with the same keys, condition and partitioning as another index on it",
+				Labels:       []string{ClusterLabel, KeyspaceLabel},
+				Endpoint:     indexerStatsEndpoint,
 			},
 		},
 	}
@@ -3450,6 +3855,48 @@ func analyticsCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "Number of threads for Analytics node",
 				Labels:       []string{ClusterLabel},
 			},
+			CbasLinkConnected: {
+				Name:         "link_connected",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Whether the Analytics link is connected, rather than stopped or suspended",
+				Labels:       []string{ClusterLabel, LinkLabel},
+				Endpoint:     cbasIngestionEndpoint,
+			},
+			CbasDatasetItemsProcessed: {
+				Name:         "dataset_items_processed_total",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of items ingested into the Analytics dataset",
+				Labels:       []string{ClusterLabel, LinkLabel, DatasetLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     cbasIngestionEndpoint,
+			},
+			CbasDatasetProgress: {
+				Name:         "dataset_ingestion_progress",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Fraction of the source data ingested into the Analytics dataset",
+				Labels:       []string{ClusterLabel, LinkLabel, DatasetLabel},
+				Endpoint:     cbasIngestionEndpoint,
+			},
+			CbasDatasetTimeLag: {
+				Name:         "dataset_ingestion_lag_seconds",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "How far ingestion into the Analytics dataset is behind its source",
+				Labels:       []string{ClusterLabel, LinkLabel, DatasetLabel},
+				Endpoint:     cbasIngestionEndpoint,
+			},
+			CbasFailedRecords: {
+				Name:         "failed_records_total",
+				NameOverride: "",
+				Enabled:      true,
+				HelpText:     "Number of records Analytics failed to ingest across the cluster, requires Couchbase Server 7",
+				Labels:       []string{ClusterLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + CbasFailedRecordsStat,
+			},
 		},
 	}
 
@@ -3467,49 +3914,56 @@ func bucketInfoCollectorDefaultConfig() *CollectorConfig {
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_dataused",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 			"diskFetches": {
 				Name:         "basic_diskfetches",
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_diskfetches",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
+			},
+			"maxTTL": {
+				Name:         "max_ttl_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Maximum time to live in seconds the bucket sets on documents, 0 if documents do not expire unless they set an expiry",
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 			"diskUsed": {
 				Name:         "basic_diskused_bytes",
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_diskused",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 			"itemCount": {
 				Name:         "basic_itemcount",
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_itemcount",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 			"memUsed": {
 				Name:         "basic_memused_bytes",
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_memused",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 			"opsPerSec": {
 				Name:         "basic_opspersec",
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_opspersec",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 			"quotaPercentUsed": {
 				Name:         "basic_quota_user_percent",
 				Enabled:      true,
 				NameOverride: "",
 				HelpText:     "basic_quotapercentused",
-				Labels:       []string{BucketLabel, ClusterLabel},
+				Labels:       []string{BucketLabel, BucketTypeLabel, ClusterLabel},
 			},
 		},
 	}
@@ -3656,6 +4110,613 @@ func queryCollectorDefaultConfig() *CollectorConfig {
 				HelpText:     "number of query warnings",
 				Labels:       []string{ClusterLabel},
 			},
+			"QueryVitalsCPUUserPercent": {
+				Name:         "vitals_cpu_user_percent",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Percentage of CPU time the query service of the node spent in user mode",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			"QueryVitalsCPUSysPercent": {
+				Name:         "vitals_cpu_sys_percent",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Percentage of CPU time the query service of the node spent in kernel mode",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			"QueryVitalsMemoryUsage": {
+				Name:         "vitals_memory_usage_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Bytes of memory allocated by the query service of the node",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			"QueryVitalsRequestsCompleted": {
+				Name:         "vitals_requests_completed",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of requests the query service of the node has completed since it started",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			"QueryVitalsRequestTimeMean": {
+				Name:         "vitals_request_time_mean_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Mean time the query service of the node took to complete a request",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			"QueryVitalsRequestQuotaUsedHWM": {
+				Name:         "vitals_request_quota_used_hwm_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Most memory of its quota a single request on the node has used, on servers with a per request memory quota",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func alertsCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Alerts",
+		Namespace: DefaultNamespace + "alerts",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			UIAlerts: {
+				Name:         "ui_alerts",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of alerts currently shown in the Couchbase web console",
+				Labels:       []string{ClusterLabel},
+			},
+			UIAlertInfo: {
+				Name:         "ui_alert_info",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Always 1 for each alert currently shown in the Couchbase web console",
+				Labels:       []string{ClusterLabel, MessageLabel},
+			},
+			Events: {
+				Name:         "events",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of events retained in the system event log by severity, requires Couchbase Server 7.1",
+				Labels:       []string{ClusterLabel, SeverityLabel},
+				Endpoint:     "/events",
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func auditCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Audit",
+		Namespace: DefaultNamespace + "audit",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			AuditEnabled: {
+				Name:         "enabled",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Whether auditing is enabled on the cluster",
+				Labels:       []string{ClusterLabel},
+			},
+			AuditRotateInterval: {
+				Name:         "rotate_interval_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Interval in seconds after which the audit log is rotated",
+				Labels:       []string{ClusterLabel},
+			},
+			AuditRotateSize: {
+				Name:         "rotate_size_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Size in bytes after which the audit log is rotated",
+				Labels:       []string{ClusterLabel},
+			},
+			AuditDisabledEvents: {
+				Name:         "disabled_events",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of audit event types that have been disabled",
+				Labels:       []string{ClusterLabel},
+			},
+			AuditDroppedEvents: {
+				Name:         "dropped_events_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of audit events auditd failed to write across the cluster, requires Couchbase Server 7",
+				Labels:       []string{ClusterLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + AuditDroppedEventsStat,
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func securityCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Security",
+		Namespace: DefaultNamespace + "security",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			NodeEncryption: {
+				Name:         "node_encryption_enabled",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Whether the node encrypts its traffic with the other nodes of the cluster",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Endpoint:     "/pools/default",
+			},
+			ClusterEncryption: {
+				Name:         "cluster_encryption_enabled",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Whether node-to-node encryption is enabled on every node of the cluster",
+				Labels:       []string{ClusterLabel},
+				Endpoint:     "/pools/default",
+			},
+			ClusterEncryptionLevel: {
+				Name:         "cluster_encryption_level_info",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Level of node-to-node encryption, control, all or strict, or none if it is not enabled",
+				Labels:       []string{ClusterLabel, EncryptionLevelLabel},
+			},
+			EncryptionAtRest: {
+				Name:         "encryption_at_rest_enabled",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Whether each kind of data outside buckets is encrypted at rest, requires Couchbase Server 8",
+				Labels:       []string{ClusterLabel, EncryptedDataLabel},
+				Endpoint:     "/settings/security/encryptionAtRest",
+			},
+			BucketEncryptionAtRest: {
+				Name:         "bucket_encryption_at_rest_enabled",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Whether the data of the bucket is encrypted at rest, requires Couchbase Server 8",
+				Labels:       []string{ClusterLabel, BucketLabel},
+				Endpoint:     "/pools/default/buckets",
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func backupCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Backup",
+		Namespace: DefaultNamespace + "backup",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			BackupRepositorySize: {
+				Name:         "repository_size_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Size of the backup repository in bytes",
+				Labels:       []string{ClusterLabel, RepositoryLabel, PlanLabel},
+			},
+			BackupLastSuccess: {
+				Name:         "last_success_timestamp_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Unix time at which the last successful backup to the repository finished",
+				Labels:       []string{ClusterLabel, RepositoryLabel, PlanLabel},
+			},
+			BackupTaskDuration: {
+				Name:         "task_duration_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Duration in seconds of the most recent finished task of each type",
+				Labels:       []string{ClusterLabel, RepositoryLabel, PlanLabel, TaskTypeLabel},
+			},
+			BackupFailedTasks: {
+				Name:         "failed_tasks",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of failed tasks in the task history of the repository",
+				Labels:       []string{ClusterLabel, RepositoryLabel, PlanLabel},
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func viewsCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Views",
+		Namespace: DefaultNamespace + "views",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			ViewsAccesses: {
+				Name:         "accesses",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of view reads per second served by the design document",
+				Labels:       []string{ClusterLabel, BucketLabel, DesignDocLabel},
+				Endpoint:     "/pools/default/buckets/{bucket}/stats",
+			},
+			ViewsUpdateDuration: {
+				Name:         "last_update_duration_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Time in seconds the most recent update of the design document's view index took",
+				Labels:       []string{ClusterLabel, BucketLabel, DesignDocLabel},
+			},
+			ViewsDiskSize: {
+				Name:         "disk_size_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Size of the design document's view index on disk in bytes",
+				Labels:       []string{ClusterLabel, BucketLabel, DesignDocLabel},
+			},
+			ViewsDataSize: {
+				Name:         "data_size_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Size of the live data in the design document's view index in bytes",
+				Labels:       []string{ClusterLabel, BucketLabel, DesignDocLabel},
+			},
+			ViewsUpdaterRunning: {
+				Name:         "updater_running",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "1 if the design document's view index is being updated",
+				Labels:       []string{ClusterLabel, BucketLabel, DesignDocLabel},
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func capellaCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Capella",
+		Namespace: DefaultNamespace + "capella",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			CapellaClusterHealthy: {
+				Name:         "cluster_healthy",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "1 if Capella reports the cluster as healthy",
+				Labels:       []string{ClusterLabel, ClusterUUIDLabel},
+			},
+			CapellaClusterNodes: {
+				Name:         "cluster_nodes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of nodes in the cluster",
+				Labels:       []string{ClusterLabel},
+			},
+			CapellaServiceGroupNodes: {
+				Name:         "service_group_nodes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of nodes in the service group",
+				Labels:       []string{ClusterLabel, ServicesLabel},
+			},
+			CapellaServiceGroupNodeCPU: {
+				Name:         "service_group_node_cpu_cores",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of CPU cores of each node in the service group",
+				Labels:       []string{ClusterLabel, ServicesLabel},
+			},
+			CapellaServiceGroupNodeRAM: {
+				Name:         "service_group_node_memory_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Memory of each node in the service group in bytes",
+				Labels:       []string{ClusterLabel, ServicesLabel},
+			},
+			CapellaBucketItems: {
+				Name:         "bucket_items",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of items in the bucket",
+				Labels:       []string{ClusterLabel, BucketLabel},
+				Endpoint:     capellaBucketsEndpoint,
+			},
+			CapellaBucketOpsPerSecond: {
+				Name:         "bucket_ops_per_second",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of operations per second on the bucket",
+				Labels:       []string{ClusterLabel, BucketLabel},
+				Endpoint:     capellaBucketsEndpoint,
+			},
+			CapellaBucketDiskUsed: {
+				Name:         "bucket_disk_used_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Disk used by the bucket in bytes",
+				Labels:       []string{ClusterLabel, BucketLabel},
+				Endpoint:     capellaBucketsEndpoint,
+			},
+			CapellaBucketMemoryUsed: {
+				Name:         "bucket_memory_used_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Memory used by the bucket in bytes",
+				Labels:       []string{ClusterLabel, BucketLabel},
+				Endpoint:     capellaBucketsEndpoint,
+			},
+			CapellaBucketMemoryAllocated: {
+				Name:         "bucket_memory_quota_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Memory allocated to the bucket in bytes",
+				Labels:       []string{ClusterLabel, BucketLabel},
+				Endpoint:     capellaBucketsEndpoint,
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func slowQueriesCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "SlowQueries",
+		Namespace: DefaultNamespace + "slowquery",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			SlowQueryRequests: {
+				Name:         "requests",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of requests in the completed requests log with the statement fingerprint that took at most le seconds",
+				Labels:       []string{ClusterLabel, FingerprintLabel, DurationBucketLabel},
+			},
+			SlowQueryElapsed: {
+				Name:         "elapsed_seconds",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Time in seconds the requests in the completed requests log with the statement fingerprint took altogether",
+				Labels:       []string{ClusterLabel, FingerprintLabel},
+			},
+			SlowQueryStatement: {
+				Name:         "statement_info",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "The normalized statement of the fingerprint, with its literals replaced by ?",
+				Labels:       []string{ClusterLabel, FingerprintLabel, StatementLabel},
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func preparedCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Prepared",
+		Namespace: DefaultNamespace + "prepared",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			PreparedStatements: {
+				Name:         "statements",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of prepared statements in the plan cache of the query node",
+				Labels:       []string{ClusterLabel, NodeLabel},
+			},
+			PreparedCacheHits: {
+				Name:         "cache_hits_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of executions of plans from the plan cache of the query node",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+			},
+			PreparedCacheMisses: {
+				Name:         "cache_misses_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of statements prepared into the plan cache of the query node, including those prepared again",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+			},
+			PreparedInvalidations: {
+				Name:         "invalidations_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of plans in the plan cache of the query node that were prepared again, as after the indexes they use change",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func rollupCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "Rollup",
+		Namespace: DefaultNamespace + "cluster",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			RollupBuckets: {
+				Name:         "buckets",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of buckets in the cluster",
+				Labels:       []string{ClusterLabel},
+			},
+			RollupOps: {
+				Name:         "ops",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Operations per second across every bucket of the cluster",
+				Labels:       []string{ClusterLabel},
+			},
+			RollupItems: {
+				Name:         "items",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of items across every bucket of the cluster",
+				Labels:       []string{ClusterLabel},
+			},
+			RollupRAMUsed: {
+				Name:         "ram_used_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Memory used by every bucket of the cluster in bytes",
+				Labels:       []string{ClusterLabel},
+			},
+			RollupRAMQuota: {
+				Name:         "ram_quota_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Memory quota of every bucket of the cluster in bytes",
+				Labels:       []string{ClusterLabel},
+			},
+			RollupDiskUsed: {
+				Name:         "disk_used_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Disk used by every bucket of the cluster in bytes",
+				Labels:       []string{ClusterLabel},
+			},
+			RollupDiskQuota: {
+				Name:         "disk_quota_bytes",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Disk space available to the data of the cluster in bytes",
+				Labels:       []string{ClusterLabel},
+				Endpoint:     "/pools/default",
+			},
+			RollupFailuresTolerated: {
+				Name:         "failures_tolerated",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of further node failures the cluster can tolerate without losing data, given the replicas of its buckets and the nodes already down",
+				Labels:       []string{ClusterLabel},
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func hotKeysCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "HotKeys",
+		Namespace: DefaultNamespace + "hotkeys",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			HotKeyInfo: {
+				Name:         "key_info",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "One of the most frequently accessed document keys of the bucket, ranked from 1 for the hottest",
+				Labels:       []string{ClusterLabel, BucketLabel, DocumentKeyLabel, RankLabel},
+			},
+			HotKeyOps: {
+				Name:         "key_ops",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Operations per second on one of the most frequently accessed document keys of the bucket",
+				Labels:       []string{ClusterLabel, BucketLabel, DocumentKeyLabel},
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func kvConnectionsCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "KVConnections",
+		Namespace: DefaultNamespace + "kv",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			KVConnectionsCurrent: {
+				Name:         "connections",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of connections to the data service of the node, across every bucket",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Endpoint:     statsRangeEndpoint + KVCurrConnectionsStat,
+			},
+			KVConnectionsRejected: {
+				Name:         "rejected_connections_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of connections the data service of the node rejected, as when at its connection limit",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + KVRejectedConnsStat,
+			},
+			KVConnectionStructures: {
+				Name:         "connection_structures",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of connection structures the data service of the node has allocated",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Endpoint:     statsRangeEndpoint + KVConnStructuresStat,
+			},
+			KVConnectionsTotal: {
+				Name:         "connections_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of connections the data service of the node has accepted since it started",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + KVTotalConnectionsStat,
+			},
+		},
+	}
+
+	return newConfig
+}
+
+func clientErrorsCollectorDefaultConfig() *CollectorConfig {
+	newConfig := &CollectorConfig{
+		Name:      "ClientErrors",
+		Namespace: DefaultNamespace + "client",
+		Subsystem: "",
+		Metrics: map[string]MetricInfo{
+			ClientTmpOomErrors: {
+				Name:         "tmp_oom_errors_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of temporary out of memory errors the data service of the node returned to clients of the bucket, which they retry after backing off",
+				Labels:       []string{ClusterLabel, BucketLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + KVTmpOomErrorsStat,
+			},
+			ClientNotMyVbucket: {
+				Name:         "not_my_vbucket_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of requests to the bucket the data service of the node rejected as not my vBucket, sent by clients with an out of date cluster map",
+				Labels:       []string{ClusterLabel, BucketLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + KVNotMyVbucketsStat,
+			},
+			ClientAuthErrors: {
+				Name:         "auth_errors_total",
+				Enabled:      true,
+				NameOverride: "",
+				HelpText:     "Number of failed authentications of clients of the data service of the node",
+				Labels:       []string{ClusterLabel, NodeLabel},
+				Type:         MetricTypeCounter,
+				Endpoint:     statsRangeEndpoint + KVAuthErrorsStat,
+			},
 		},
 	}
 
@@ -0,0 +1,87 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// AutoCompaction is the auto-compaction settings of a bucket, or the cluster
+// wide settings from /settings/autoCompaction.
+type AutoCompaction struct {
+	DatabaseFragmentationThreshold FragmentationThreshold `json:"databaseFragmentationThreshold"`
+	ViewFragmentationThreshold     FragmentationThreshold `json:"viewFragmentationThreshold"`
+	ParallelDBAndViewCompaction    bool                   `json:"parallelDBAndViewCompaction"`
+
+	// PurgeInterval is the number of days tombstones are kept before the
+	// metadata purge removes them, which Couchbase Server returns alongside
+	// rather than within the settings.
+	PurgeInterval float64 `json:"-"`
+}
+
+// ClusterAutoCompaction is the result of /settings/autoCompaction.
+type ClusterAutoCompaction struct {
+	AutoCompactionSettings AutoCompaction `json:"autoCompactionSettings"`
+	PurgeInterval          float64        `json:"purgeInterval"`
+}
+
+// FragmentationThreshold is the fragmentation that triggers compaction.  Either
+// value is "undefined" when it is not set.
+type FragmentationThreshold struct {
+	Percentage interface{} `json:"percentage"`
+	Size       interface{} `json:"size"`
+}
+
+// Percent returns the fragmentation percentage that triggers compaction, if
+// one is set.
+func (t FragmentationThreshold) Percent() (float64, bool) {
+	switch percentage := t.Percentage.(type) {
+	case float64:
+		return percentage, true
+	case string:
+		value, err := strconv.ParseFloat(percentage, 64)
+
+		return value, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// UsesDefaultAutoCompaction returns whether the bucket uses the cluster wide
+// auto-compaction settings, which it reports as false in place of its own.
+func (b BucketInfo) UsesDefaultAutoCompaction() bool {
+	own, ok := b.AutoCompactionSettings.(bool)
+
+	return ok && !own
+}
+
+// AutoCompaction returns the bucket's own auto-compaction settings, if it has
+// any.
+func (b BucketInfo) AutoCompaction() (AutoCompaction, bool) {
+	var settings AutoCompaction
+
+	if _, ok := b.AutoCompactionSettings.(map[string]interface{}); !ok {
+		return settings, false
+	}
+
+	data, err := json.Marshal(b.AutoCompactionSettings)
+	if err != nil {
+		return settings, false
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, false
+	}
+
+	settings.PurgeInterval = b.PurgeInterval
+
+	return settings, true
+}
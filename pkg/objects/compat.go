@@ -0,0 +1,69 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+)
+
+const (
+	// CompatCouchbase keeps the names of the official Couchbase exporter,
+	// which this exporter uses by default.
+	CompatCouchbase = "couchbase"
+	// CompatBlakelead renames metrics to those of blakelead/couchbase-exporter.
+	CompatBlakelead = "blakelead"
+
+	unknownCompat string = "unknown metric naming scheme"
+)
+
+var (
+	ErrUnknownCompat = fmt.Errorf(unknownCompat)
+)
+
+// blakeleadRules rename each metric that has a counterpart in
+// blakelead/couchbase-exporter, and move the rest under the same cb_<service>_
+// prefixes.  The more specific rules come first, as each rule sees the names
+// left by the rules before it.
+var blakeleadRules = []RelabelRule{
+	{Match: "cbnode_healthy", Rename: "cb_node_status"},
+	{Match: "cbnode_uptime", Rename: "cb_node_uptime_seconds"},
+	{Match: "cbnode_memory_(total|free)", Rename: "cb_node_ram_${1}_bytes"},
+	{Match: "cbnode_systemstats_cpu_utilization_rate", Rename: "cb_node_cpu_utilization_rate"},
+	{Match: "cbnode_systemstats_(swap|mem)_(total|used|free)", Rename: "cb_node_${1}_${2}_bytes"},
+	{Match: "cbnode_interestingstats_(.*)", Rename: "cb_node_interesting_stats_$1"},
+	{Match: "cbnode_((?:graceful_)?failover.*|rebalance_.*)", Rename: "cb_cluster_${1}_total"},
+	{Match: "cbnode_(.*)", Rename: "cb_node_$1"},
+	{Match: "cbbucketinfo_basic_opspersec", Rename: "cb_bucket_basic_ops_per_sec"},
+	{Match: "cbbucketinfo_basic_diskfetches", Rename: "cb_bucket_basic_disk_fetches"},
+	{Match: "cbbucketinfo_basic_itemcount", Rename: "cb_bucket_basic_item_count"},
+	{Match: "cbbucketinfo_basic_(data|disk|mem)used_bytes", Rename: "cb_bucket_basic_${1}_used_bytes"},
+	{Match: "cbbucketinfo_(.*)", Rename: "cb_bucket_$1"},
+	{Match: "cbbucketstat_(.*)", Rename: "cb_bucket_stats_$1"},
+	{Match: "cbpernodebucket_(.*)", Rename: "cb_bucket_node_stats_$1"},
+	{Match: "cbtask_(.*)", Rename: "cb_task_$1"},
+	{Match: "cbquery_(.*)", Rename: "cb_query_$1"},
+	{Match: "cbindex_(.*)", Rename: "cb_index_$1"},
+	{Match: "cbfts_(.*)", Rename: "cb_fts_$1"},
+	{Match: "cbcbas_(.*)", Rename: "cb_cbas_$1"},
+	{Match: "cbeventing_(.*)", Rename: "cb_eventing_$1"},
+}
+
+// CompatRules returns the relabel rules that emit metrics under the naming
+// scheme of another exporter.
+func CompatRules(scheme string) ([]RelabelRule, error) {
+	switch scheme {
+	case "", CompatCouchbase:
+		return nil, nil
+	case CompatBlakelead:
+		return append([]RelabelRule{}, blakeleadRules...), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCompat, scheme)
+	}
+}
@@ -0,0 +1,37 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+// WhoAmI is the result of /whoami, the identity of the user making the
+// request.
+type WhoAmI struct {
+	ID     string `json:"id"`
+	Domain string `json:"domain"`
+	Name   string `json:"name"`
+	// PasswordChangeDate is only reported for local users, from Couchbase
+	// Server 7.0.
+	PasswordChangeDate string `json:"passwordChangeDate"`
+}
+
+// CredentialsConfig configures the periodic check of the credentials the
+// exporter connects with.
+type CredentialsConfig struct {
+	// Check enables requesting /whoami every refresh.
+	Check bool `json:"check"`
+	// PasswordMaxAge is how many days a local user's password is valid for
+	// after it was changed, as set by the password policy.  0 if passwords
+	// do not expire.
+	PasswordMaxAge int `json:"passwordMaxAge"`
+	// ExpiresAt is when the credentials expire, in RFC 3339 format, for users
+	// such as LDAP users whose expiry Couchbase Server does not know.
+	ExpiresAt string `json:"expiresAt,omitempty"`
+	// WarnBefore is how many days ahead of expiry to start warning.
+	WarnBefore int `json:"warnBefore"`
+}
@@ -0,0 +1,61 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const invalidDebugCapture string = "invalid debug capture endpoints"
+
+var ErrInvalidDebugCapture = fmt.Errorf(invalidDebugCapture)
+
+const (
+	// DefaultDebugCaptureWindow is how many seconds bodies are captured for
+	// after the exporter starts, or after a capture is started through the
+	// admin API.
+	DefaultDebugCaptureWindow = 600
+	// DefaultDebugCaptureMaxBytes is how much of each body is logged.
+	DefaultDebugCaptureMaxBytes = 4096
+)
+
+// DebugCaptureConfig logs the bodies of the requests to, and the responses
+// from, the endpoints of Couchbase Server whose path matches the Endpoints
+// regular expression, for diagnosing responses the exporter fails to parse.
+// Bodies are redacted and cut short at MaxBytes, and only captured for Window
+// seconds, so that a capture left on cannot flood the logs.
+type DebugCaptureConfig struct {
+	Endpoints string `json:"endpoints"`
+	Window    int    `json:"window"`
+	MaxBytes  int    `json:"maxBytes"`
+}
+
+// Validate checks that the endpoints, if any, are a valid regular expression.
+func (c DebugCaptureConfig) Validate() error {
+	if c.Endpoints == "" {
+		return nil
+	}
+
+	_, err := CompileCaptureEndpoints(c.Endpoints)
+
+	return err
+}
+
+// CompileCaptureEndpoints compiles a regular expression matching the paths of
+// the endpoints to capture, which may match any part of a path.
+func CompileCaptureEndpoints(endpoints string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(endpoints)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %s", ErrInvalidDebugCapture, endpoints, err)
+	}
+
+	return re, nil
+}
@@ -0,0 +1,29 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	// DeltaPath serves only the series that changed since it was last
+	// scraped, if delta export is enabled.
+	DeltaPath = "/metrics/delta"
+
+	// DefaultDeltaFullInterval is how many seconds apart every series is
+	// served on DeltaPath, whether or not it changed.
+	DefaultDeltaFullInterval = 3600
+)
+
+// DeltaConfig enables serving only the series that changed since the last
+// scrape, for clusters scraped over constrained links.
+type DeltaConfig struct {
+	Enabled bool `json:"enabled"`
+	// FullInterval is how many seconds apart every series is served, so that
+	// a scraper that missed a change or restarted catches up.  Never if 0.
+	FullInterval int `json:"fullInterval"`
+}
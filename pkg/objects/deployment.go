@@ -0,0 +1,66 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"net"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+const (
+	// DeploymentSidecar is an exporter running beside the node it connects
+	// to, one per node.
+	DeploymentSidecar = "sidecar"
+	// DeploymentRemote is a central exporter connecting to a node on another
+	// host.
+	DeploymentRemote = "remote"
+)
+
+// DeploymentMode returns whether the exporter runs as a sidecar, beside the
+// node it connects to, or as a central exporter of a remote cluster.  It is a
+// sidecar when running in an operator managed pod or when the address of the
+// node is one of this host's.
+func (e *ExporterConfig) DeploymentMode() string {
+	if e.Sidecar.Enabled || isLocalAddress(e.CouchbaseAddress) {
+		return DeploymentSidecar
+	}
+
+	return DeploymentRemote
+}
+
+// isLocalAddress returns whether host resolves to an address of this host.
+// A host that cannot be resolved is taken to be remote.
+func isLocalAddress(host string) bool {
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		log.Debug("unable to resolve %s: %s", host, err)
+		return false
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		log.Debug("unable to list interface addresses: %s", err)
+	}
+
+	for _, ip := range ips {
+		if ip.IsLoopback() {
+			return true
+		}
+
+		for _, addr := range addrs {
+			if network, ok := addr.(*net.IPNet); ok && network.IP.Equal(ip) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,21 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+// DerivedMetric is a gauge computed each time the metrics are gathered from
+// the metrics collected, for site specific ratios.
+type DerivedMetric struct {
+	Name string `json:"name"`
+	Help string `json:"help,omitempty"`
+	// Expr is an arithmetic expression of numbers and the names of collected
+	// metrics with + - * / and parentheses, evaluated for each label set that
+	// every metric in it has a series for.
+	Expr string `json:"expr"`
+}
@@ -0,0 +1,39 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	UIAlerts    = "uiAlerts"
+	UIAlertInfo = "uiAlertInfo"
+	Events      = "events"
+)
+
+// Alert is a warning shown in the Couchbase web console, as listed in the
+// alerts of /pools/default.
+type Alert struct {
+	Msg            string `json:"msg"`
+	ServerTime     string `json:"serverTime"`
+	DisableUIPopUp bool   `json:"disableUIPopUp"`
+}
+
+// SystemEvents is the result of /events, available from Couchbase Server 7.1.
+type SystemEvents struct {
+	Events []SystemEvent `json:"events"`
+}
+
+type SystemEvent struct {
+	Timestamp   string `json:"timestamp"`
+	Component   string `json:"component"`
+	Severity    string `json:"severity"`
+	EventID     int    `json:"event_id"`
+	Description string `json:"description"`
+	Node        string `json:"node"`
+	UUID        string `json:"uuid"`
+}
@@ -11,9 +11,13 @@ package objects
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
 )
@@ -26,26 +30,192 @@ const (
 	envPass = "COUCHBASE_PASS"
 
 	bearerToken = "AUTH_BEARER_TOKEN"
+
+	envCouchToken = "COUCHBASE_TOKEN"
+
+	capellaAPIKey = "CAPELLA_API_KEY"
+
+	defaultCouchAddress  = "localhost"
+	defaultCouchPort     = 8091
+	defaultCouchTLSPort  = 18091
+	defaultCouchUser     = "Administrator"
+	defaultCouchPassword = "password"
+
+	// DefaultSidecarSecretDir is where the operator mounts the admin secret,
+	// with one file per key.
+	DefaultSidecarSecretDir = "/var/run/secrets/couchbase.com/couchbase-server"
+	sidecarUsernameKey      = "username"
+	sidecarPasswordKey      = "password"
 )
 
 type ExporterConfig struct {
-	CouchbaseAddress  string             `json:"couchbaseAddress"`
-	CouchbasePort     int                `json:"couchbasePort"`
-	CouchbaseUser     string             `json:"couchbaseUser"`
-	CouchbasePassword string             `json:"couchbasePassword"`
-	ServerAddress     string             `json:"serverAddress"`
-	ServerPort        int                `json:"serverPort"`
-	RefreshRate       int                `json:"refreshRate"`
-	BackoffLimit      int                `json:"backoffLimit"`
-	LogLevel          string             `json:"logLevel"`
-	LogJSON           bool               `json:"logJson"`
-	Token             string             `json:"token"`
-	Certificate       string             `json:"certificate"`
-	Key               string             `json:"key"`
-	Ca                string             `json:"ca"`
-	ClientCertificate string             `json:"clientCertificate"`
-	ClientKey         string             `json:"clientKey"`
-	Collectors        ExporterCollectors `json:"collectors"`
+	CouchbaseAddress    string             `json:"couchbaseAddress"`
+	CouchbasePort       int                `json:"couchbasePort"`
+	CouchbaseUser       string             `json:"couchbaseUser"`
+	CouchbasePassword   string             `json:"couchbasePassword"`
+	CouchbaseAuth       AuthConfig         `json:"couchbaseAuth"`
+	ServerAddress       string             `json:"serverAddress"`
+	ServerPort          int                `json:"serverPort"`
+	ListenAddresses     []string           `json:"listenAddresses"`
+	AllowedCIDRs        []string           `json:"allowedCidrs"`
+	MaxRequests         int                `json:"maxRequests"`
+	AdminTokenFile      string             `json:"adminTokenFile"`
+	RefreshRate         int                `json:"refreshRate"`
+	MaxIdleConnsPerHost int                `json:"maxIdleConnsPerHost"`
+	ConnMaxLifetime     int                `json:"connMaxLifetime"`
+	RequestTimeout      int                `json:"requestTimeout"`
+	RequestIDs          bool               `json:"requestIds"`
+	LabelCacheTTL       int                `json:"labelCacheTTL"`
+	BackoffLimit        int                `json:"backoffLimit"`
+	LogLevel            string             `json:"logLevel"`
+	LogJSON             bool               `json:"logJson"`
+	LogThrottle         int                `json:"logThrottle"`
+	Token               string             `json:"token"`
+	Certificate         string             `json:"certificate"`
+	Key                 string             `json:"key"`
+	Ca                  string             `json:"ca"`
+	ClientCertificate   string             `json:"clientCertificate"`
+	ClientKey           string             `json:"clientKey"`
+	FIPS                bool               `json:"fips"`
+	SnapshotFile        string             `json:"snapshotFile"`
+	SnapshotMaxAge      int                `json:"snapshotMaxAge"`
+	TextfilePath        string             `json:"textfilePath"`
+	RecordDir           string             `json:"recordDir"`
+	ReplayDir           string             `json:"replayDir"`
+	Faults              FaultConfig        `json:"faults"`
+	DebugCapture        DebugCaptureConfig `json:"debugCapture"`
+	NodeHostnames       HostnameConfig     `json:"nodeHostnames"`
+	Labels              map[string]string  `json:"labels"`
+	OwnerLabelsFile     string             `json:"ownerLabelsFile"`
+	Relabel             []RelabelRule      `json:"relabel"`
+	ValueGuards         []ValueGuard       `json:"valueGuards"`
+	DerivedMetrics      []DerivedMetric    `json:"derivedMetrics"`
+	Compat              string             `json:"compat"`
+	MetricLint          string             `json:"metricLint"`
+	MetricNames         string             `json:"metricNames"`
+	SeriesLimit         int                `json:"seriesLimit"`
+	ClusterMode         bool               `json:"clusterMode"`
+	NodeName            string             `json:"nodeName"`
+	PerNodeScope        string             `json:"perNodeScope"`
+	WaitForRebalance    bool               `json:"waitForRebalance"`
+	UndefinedSamples    string             `json:"undefinedSamples"`
+	WindowAggregates    bool               `json:"windowAggregates"`
+	Exemplars           bool               `json:"exemplars"`
+	Credentials         CredentialsConfig  `json:"credentials"`
+	Sidecar             SidecarConfig      `json:"sidecar"`
+	Capella             CapellaConfig      `json:"capella"`
+	SlowQueries         SlowQueriesConfig  `json:"slowQueries"`
+	PreparedStatements  bool               `json:"preparedStatements"`
+	HotKeys             HotKeysConfig      `json:"hotKeys"`
+	KVConnections       bool               `json:"kvConnections"`
+	ClientErrors        bool               `json:"clientErrors"`
+	ServiceProbes       ServiceProbeConfig `json:"serviceProbes"`
+	Health              HealthConfig       `json:"health"`
+	ClusterRole         ClusterRoleConfig  `json:"clusterRole"`
+	Delta               DeltaConfig        `json:"delta"`
+	Buckets             BucketFilter       `json:"buckets"`
+	BucketPriority      BucketPriority     `json:"bucketPriority"`
+	SlowBuckets         SlowBucketsConfig  `json:"slowBuckets"`
+	Clusters            []ClusterConfig    `json:"clusters"`
+	Collectors          ExporterCollectors `json:"collectors"`
+}
+
+const (
+	HostnameFormReported = ""
+	HostnameFormShort    = "short"
+	HostnameFormFQDN     = "fqdn"
+)
+
+const (
+	// UndefinedSamplesSkip removes the series of a stat whose latest sample is
+	// not a number.
+	UndefinedSamplesSkip = "skip"
+	// UndefinedSamplesNaN exports samples that are not numbers as NaN.
+	UndefinedSamplesNaN = "nan"
+	// UndefinedSamplesZero exports samples that are not numbers as 0.
+	UndefinedSamplesZero = "zero"
+)
+
+const (
+	// PerNodeScopeSelf collects the per node bucket stats of the node the
+	// exporter is attached to.
+	PerNodeScopeSelf = "self"
+	// PerNodeScopeAll collects the per node bucket stats of every node that
+	// serves each bucket.
+	PerNodeScopeAll = "all"
+	// PerNodeScopeAuto collects the per node bucket stats of the node the
+	// exporter is attached to when running beside it, and of every node
+	// otherwise.
+	PerNodeScopeAuto = "auto"
+)
+
+const (
+	// MetricLintOff registers metrics without checking their names.
+	MetricLintOff = "off"
+	// MetricLintWarn logs the metrics whose names break the Prometheus
+	// conventions.
+	MetricLintWarn = "warn"
+	// MetricLintStrict refuses to start if any metric's name breaks the
+	// Prometheus conventions.
+	MetricLintStrict = "strict"
+)
+
+// DefaultLogThrottle is the number of seconds an identical warning or error is
+// not logged again for.
+const DefaultLogThrottle = 300
+
+// DefaultRequestTimeout is the number of seconds a request to Couchbase Server
+// may take before it is abandoned.
+const DefaultRequestTimeout = 30
+
+// DefaultConnMaxLifetime is the number of seconds after which idle connections
+// to Couchbase Server are closed, so that its hostnames are resolved again.
+const DefaultConnMaxLifetime = 300
+
+// DefaultLabelCacheTTL is the number of seconds the cluster name, node hostname
+// and UUIDs are cached for before they are requested again.
+const DefaultLabelCacheTTL = 600
+
+// DefaultSeriesLimit is the number of series of each metric a cluster may
+// have, which is enough for hundreds of buckets on tens of nodes.
+const DefaultSeriesLimit = 10000
+
+// RelabelRule renames, drops or labels the metrics whose name matches a
+// regular expression, just before they are exposed.
+type RelabelRule struct {
+	// Match is a regular expression that must match the whole metric name.
+	Match string `json:"match"`
+	// Rename replaces the metric name, and may refer to groups in Match as $1.
+	Rename string `json:"rename,omitempty"`
+	// Drop removes the matching metrics entirely.
+	Drop bool `json:"drop,omitempty"`
+	// Labels are set on every matching metric.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// SidecarConfig configures the exporter when it runs beside a node in a pod
+// managed by the Couchbase Autonomous Operator.
+type SidecarConfig struct {
+	// Enabled is also set when the operator's environment variables are found.
+	Enabled bool `json:"enabled"`
+	// SecretDir holds the admin credentials, used when none are given.
+	SecretDir string `json:"secretDir"`
+	// InitTimeout is how many seconds to wait for the node to be initialized
+	// before giving up.
+	InitTimeout int `json:"initTimeout"`
+}
+
+// HostnameConfig controls how the hostnames Couchbase Server reports for its
+// nodes are written to the node label.
+type HostnameConfig struct {
+	// StripPort removes the :8091 style port suffix.
+	StripPort bool `json:"stripPort"`
+	// Form is "short" to drop the domain, "fqdn" to resolve the fully
+	// qualified name, or empty to keep the hostname as reported.
+	Form string `json:"form"`
+	// Relabel maps hostnames, either as reported or after normalization, to
+	// the value to use instead.
+	Relabel map[string]string `json:"relabel"`
 }
 
 type ExporterCollectors struct {
@@ -59,6 +229,18 @@ type ExporterCollectors struct {
 	Search             *CollectorConfig `json:"search"`
 	Task               *CollectorConfig `json:"task"`
 	PerNodeBucketStats *CollectorConfig `json:"perNodeBucketStats"`
+	Alerts             *CollectorConfig `json:"alerts"`
+	Audit              *CollectorConfig `json:"audit"`
+	Security           *CollectorConfig `json:"security"`
+	Backup             *CollectorConfig `json:"backup"`
+	Views              *CollectorConfig `json:"views"`
+	Capella            *CollectorConfig `json:"capella"`
+	SlowQueries        *CollectorConfig `json:"slowQueries"`
+	Prepared           *CollectorConfig `json:"prepared"`
+	HotKeys            *CollectorConfig `json:"hotKeys"`
+	KVConnections      *CollectorConfig `json:"kvConnections"`
+	ClientErrors       *CollectorConfig `json:"clientErrors"`
+	Rollup             *CollectorConfig `json:"rollup"`
 }
 
 func (e *ExporterConfig) ParseConfigFile(configFilePath string) error {
@@ -84,18 +266,16 @@ func (e *ExporterConfig) ParseConfigFile(configFilePath string) error {
 		return err
 	}
 
+	e.Collectors.fillHelpText()
+	e.Collectors.fillDescriptions(defaultCollectors())
+
 	defer jsonFile.Close()
 
 	return nil
 }
 
-func (e *ExporterConfig) SetDefaults() {
-	e.BackoffLimit = 5
-	e.Ca = ""
-	e.Certificate = ""
-	e.ClientCertificate = ""
-	e.ClientKey = ""
-	e.Collectors = ExporterCollectors{
+func defaultCollectors() ExporterCollectors {
+	return ExporterCollectors{
 		BucketInfo:         GetBucketInfoCollectorDefaultConfig(),
 		BucketStats:        GetBucketStatsCollectorDefaultConfig(),
 		Analytics:          GetAnalyticsCollectorDefaultConfig(),
@@ -106,18 +286,110 @@ func (e *ExporterConfig) SetDefaults() {
 		Search:             GetSearchCollectorDefaultConfig(),
 		Task:               GetTaskCollectorDefaultConfig(),
 		PerNodeBucketStats: GetPerNodeBucketStatsCollectorDefaultConfig(),
+		Alerts:             GetAlertsCollectorDefaultConfig(),
+		Audit:              GetAuditCollectorDefaultConfig(),
+		Security:           GetSecurityCollectorDefaultConfig(),
+		Backup:             GetBackupCollectorDefaultConfig(),
+		Views:              GetViewsCollectorDefaultConfig(),
+		Capella:            GetCapellaCollectorDefaultConfig(),
+		SlowQueries:        GetSlowQueriesCollectorDefaultConfig(),
+		Prepared:           GetPreparedCollectorDefaultConfig(),
+		HotKeys:            GetHotKeysCollectorDefaultConfig(),
+		KVConnections:      GetKVConnectionsCollectorDefaultConfig(),
+		ClientErrors:       GetClientErrorsCollectorDefaultConfig(),
+		Rollup:             GetRollupCollectorDefaultConfig(),
 	}
-	e.CouchbaseAddress = "localhost"
-	e.CouchbasePort = 8091
-	e.CouchbaseUser = "Administrator"
-	e.CouchbasePassword = "password"
+}
+
+func (e *ExporterConfig) SetDefaults() {
+	e.BackoffLimit = 5
+	e.Ca = ""
+	e.Certificate = ""
+	e.ClientCertificate = ""
+	e.ClientKey = ""
+	e.FIPS = false
+	e.Collectors = defaultCollectors()
+	e.CouchbaseAddress = defaultCouchAddress
+	e.CouchbasePort = defaultCouchPort
+	e.CouchbaseUser = defaultCouchUser
+	e.CouchbasePassword = defaultCouchPassword
+	e.CouchbaseAuth = AuthConfig{Mode: AuthModeBasic}
 	e.Key = ""
 	e.LogJSON = true
 	e.LogLevel = "info"
+	e.LogThrottle = DefaultLogThrottle
 	e.RefreshRate = 60
+	e.MaxIdleConnsPerHost = 10
+	e.ConnMaxLifetime = DefaultConnMaxLifetime
+	e.RequestTimeout = DefaultRequestTimeout
+	e.RequestIDs = false
+	e.LabelCacheTTL = DefaultLabelCacheTTL
 	e.ServerAddress = "0.0.0.0"
 	e.ServerPort = 9091
+	e.ListenAddresses = []string{}
+	e.AllowedCIDRs = []string{}
+	e.MaxRequests = 0
+	e.AdminTokenFile = ""
 	e.Token = ""
+	e.SnapshotFile = ""
+	e.SnapshotMaxAge = 600
+	e.TextfilePath = ""
+	e.RecordDir = ""
+	e.ReplayDir = ""
+	e.Faults = FaultConfig{}
+	e.DebugCapture = DebugCaptureConfig{Window: DefaultDebugCaptureWindow, MaxBytes: DefaultDebugCaptureMaxBytes}
+	e.NodeHostnames = HostnameConfig{Relabel: map[string]string{}}
+	e.Labels = map[string]string{}
+	e.OwnerLabelsFile = ""
+	e.Relabel = []RelabelRule{}
+	e.ValueGuards = []ValueGuard{}
+	e.DerivedMetrics = []DerivedMetric{}
+	e.Compat = ""
+	e.MetricLint = MetricLintWarn
+	e.MetricNames = MetricNamesLegacy
+	e.SeriesLimit = DefaultSeriesLimit
+	e.ClusterMode = false
+	e.NodeName = ""
+	e.PerNodeScope = PerNodeScopeAuto
+	e.WaitForRebalance = false
+	e.UndefinedSamples = UndefinedSamplesSkip
+	e.WindowAggregates = false
+	e.Exemplars = false
+	e.Credentials = CredentialsConfig{Check: true, WarnBefore: 14}
+	e.Sidecar = SidecarConfig{SecretDir: DefaultSidecarSecretDir, InitTimeout: 300}
+	e.Capella = CapellaConfig{URL: DefaultCapellaURL, Clusters: []CapellaClusterRef{}}
+	e.SlowQueries = SlowQueriesConfig{Enabled: false, Buckets: DefaultSlowQueryBuckets}
+	e.PreparedStatements = false
+	e.HotKeys = HotKeysConfig{Enabled: false, Top: DefaultHotKeysTop}
+	e.KVConnections = false
+	e.ClientErrors = false
+	e.ServiceProbes = ServiceProbeConfig{Enabled: false, Timeout: DefaultServiceProbeTimeout}
+	e.Health = HealthConfig{Enabled: false, Checks: DefaultHealthChecks()}
+	e.ClusterRole = ClusterRoleConfig{Metrics: DefaultStandbySuppressedMetrics()}
+	e.Delta = DeltaConfig{Enabled: false, FullInterval: DefaultDeltaFullInterval}
+	e.Buckets = BucketFilter{}
+	e.BucketPriority = BucketPriority{}
+	e.SlowBuckets = SlowBucketsConfig{Threshold: 0, After: DefaultSlowBucketsAfter, Every: DefaultSlowBucketsEvery}
+	e.Clusters = []ClusterConfig{}
+}
+
+// CollectorDeadline is how long each collector may take to collect, the
+// refresh interval, so that a slow cluster can neither hold up a scrape nor
+// run a cycle into the next.
+func (e *ExporterConfig) CollectorDeadline() time.Duration {
+	return time.Duration(e.RefreshRate) * time.Second
+}
+
+// RequestTimeoutDuration is how long a request to Couchbase Server may take,
+// or zero if requests are only bounded by the collector's deadline.
+func (e *ExporterConfig) RequestTimeoutDuration() time.Duration {
+	return time.Duration(e.RequestTimeout) * time.Second
+}
+
+// LabelCacheDuration is how long label values requested from Couchbase Server
+// are cached for.
+func (e *ExporterConfig) LabelCacheDuration() time.Duration {
+	return time.Duration(e.LabelCacheTTL) * time.Second
 }
 
 func (e *ExporterConfig) SetOrDefaultLogJSON(logJSON bool) {
@@ -126,6 +398,12 @@ func (e *ExporterConfig) SetOrDefaultLogJSON(logJSON bool) {
 	}
 }
 
+func (e *ExporterConfig) SetOrDefaultLogThrottle(logThrottle string) {
+	if logThrottle != "" && isInt(logThrottle) {
+		e.LogThrottle, _ = strconv.Atoi(logThrottle)
+	}
+}
+
 func (e *ExporterConfig) SetOrDefaultLogLevel(logLevel string) {
 	if logLevel != "" {
 		e.LogLevel = logLevel
@@ -192,12 +470,75 @@ func (e *ExporterConfig) SetOrDefaultCouchPassword(couchPass string) {
 	}
 }
 
+// SetOrDefaultSPNEGOCommand sets the command printing the SPNEGO token, whose
+// arguments are separated by spaces.
+func (e *ExporterConfig) SetOrDefaultSPNEGOCommand(command string) {
+	if command != "" {
+		e.CouchbaseAuth.SPNEGOCommand = strings.Fields(command)
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultCouchAuth(mode, tokenFile string) {
+	if mode != "" {
+		e.CouchbaseAuth.Mode = mode
+	}
+
+	if tokenFile != "" {
+		e.CouchbaseAuth.TokenFile = tokenFile
+	}
+
+	// override the token in the config file with the ENV var if it has one.
+	if os.Getenv(envCouchToken) != "" {
+		log.Info("using env var bearer token")
+
+		e.CouchbaseAuth.Token = os.Getenv(envCouchToken)
+	}
+}
+
 func (e *ExporterConfig) SetOrDefaultServerAddress(svrAddr string) {
 	if svrAddr != "" {
 		e.ServerAddress = svrAddr
 	}
 }
 
+// SetOrDefaultListenAddresses replaces the addresses from the config file
+// with those given on the command line, if any.
+func (e *ExporterConfig) SetOrDefaultListenAddresses(addresses []string) {
+	if len(addresses) != 0 {
+		e.ListenAddresses = addresses
+	}
+}
+
+// SetOrDefaultAllowedCIDRs replaces the networks from the config file with
+// the comma separated networks given on the command line, if any.
+func (e *ExporterConfig) SetOrDefaultAllowedCIDRs(cidrs string) {
+	if cidrs != "" {
+		e.AllowedCIDRs = strings.Split(cidrs, ",")
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultMaxRequests(maxRequests string) {
+	if maxRequests != "" {
+		e.MaxRequests, _ = strconv.Atoi(maxRequests)
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultAdminTokenFile(adminTokenFile string) {
+	if adminTokenFile != "" {
+		e.AdminTokenFile = adminTokenFile
+	}
+}
+
+// Listeners returns the addresses to serve on: the listen addresses if any
+// are set, or else the server address and port.
+func (e *ExporterConfig) Listeners() []string {
+	if len(e.ListenAddresses) != 0 {
+		return e.ListenAddresses
+	}
+
+	return []string{fmt.Sprintf("%v:%v", e.ServerAddress, e.ServerPort)}
+}
+
 func (e *ExporterConfig) SetOrDefaultServerPort(svrPort string) {
 	if svrPort != "" && isInt(svrPort) {
 		e.ServerPort, _ = strconv.Atoi(svrPort)
@@ -214,6 +555,46 @@ func (e *ExporterConfig) SetOrDefaultRefreshRate(refreshRate string) {
 	}
 }
 
+func (e *ExporterConfig) SetOrDefaultConnMaxLifetime(connMaxLifetime string) {
+	if connMaxLifetime != "" && isInt(connMaxLifetime) {
+		e.ConnMaxLifetime, _ = strconv.Atoi(connMaxLifetime)
+	}
+
+	if e.ConnMaxLifetime < 0 {
+		e.ConnMaxLifetime = DefaultConnMaxLifetime
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultRequestTimeout(requestTimeout string) {
+	if requestTimeout != "" && isInt(requestTimeout) {
+		e.RequestTimeout, _ = strconv.Atoi(requestTimeout)
+	}
+
+	if e.RequestTimeout < 0 {
+		e.RequestTimeout = DefaultRequestTimeout
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultLabelCacheTTL(labelCacheTTL string) {
+	if labelCacheTTL != "" && isInt(labelCacheTTL) {
+		e.LabelCacheTTL, _ = strconv.Atoi(labelCacheTTL)
+	}
+
+	if e.LabelCacheTTL < 0 {
+		e.LabelCacheTTL = DefaultLabelCacheTTL
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultMaxIdleConnsPerHost(maxIdleConns string) {
+	if maxIdleConns != "" && isInt(maxIdleConns) {
+		e.MaxIdleConnsPerHost, _ = strconv.Atoi(maxIdleConns)
+	}
+
+	if e.MaxIdleConnsPerHost <= 0 {
+		e.MaxIdleConnsPerHost = 10
+	}
+}
+
 func (e *ExporterConfig) SetOrDefaultBackoffLimit(backoffLimit string) {
 	if backoffLimit != "" && isInt(backoffLimit) {
 		e.BackoffLimit, _ = strconv.Atoi(backoffLimit)
@@ -261,6 +642,343 @@ func (e *ExporterConfig) SetOrDefaultClientKey(clientKey string) {
 	}
 }
 
+func (e *ExporterConfig) SetOrDefaultFIPS(fips bool) {
+	if fips {
+		e.FIPS = fips
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultSnapshotFile(snapshotFile string) {
+	if snapshotFile != "" {
+		e.SnapshotFile = snapshotFile
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultSnapshotMaxAge(snapshotMaxAge string) {
+	if snapshotMaxAge != "" && isInt(snapshotMaxAge) {
+		e.SnapshotMaxAge, _ = strconv.Atoi(snapshotMaxAge)
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultTextfilePath(textfilePath string) {
+	if textfilePath != "" {
+		e.TextfilePath = textfilePath
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultOwnerLabelsFile(ownerLabelsFile string) {
+	if ownerLabelsFile != "" {
+		e.OwnerLabelsFile = ownerLabelsFile
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultRecordDir(recordDir string) {
+	if recordDir != "" {
+		e.RecordDir = recordDir
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultReplayDir(replayDir string) {
+	if replayDir != "" {
+		e.ReplayDir = replayDir
+	}
+}
+
+// SetOrDefaultFaults replaces the faults from the config file with those
+// given on the command line, if any.
+func (e *ExporterConfig) SetOrDefaultFaults(latencyMillis, errorRate, malformedRate string) {
+	if latencyMillis != "" && isInt(latencyMillis) {
+		e.Faults.LatencyMillis, _ = strconv.Atoi(latencyMillis)
+	}
+
+	if rate, err := strconv.ParseFloat(errorRate, 64); err == nil {
+		e.Faults.ErrorRate = rate
+	}
+
+	if rate, err := strconv.ParseFloat(malformedRate, 64); err == nil {
+		e.Faults.MalformedRate = rate
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultNodeStripPort(stripPort bool) {
+	if stripPort {
+		e.NodeHostnames.StripPort = stripPort
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultNodeHostnameForm(form string) {
+	if form != "" {
+		e.NodeHostnames.Form = form
+	}
+}
+
+// SetOrDefaultLabels adds labels given on the command line to those from the
+// config file, replacing any of the same name.
+func (e *ExporterConfig) SetOrDefaultLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+
+	if e.Labels == nil {
+		e.Labels = map[string]string{}
+	}
+
+	for name, value := range labels {
+		e.Labels[name] = value
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultCompat(compat string) {
+	if compat != "" {
+		e.Compat = compat
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultSeriesLimit(seriesLimit string) {
+	if seriesLimit != "" && isInt(seriesLimit) {
+		e.SeriesLimit, _ = strconv.Atoi(seriesLimit)
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultMetricNames(metricNames string) {
+	if metricNames != "" {
+		e.MetricNames = metricNames
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultMetricLint(metricLint string) {
+	if metricLint != "" {
+		e.MetricLint = metricLint
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultClusterMode(clusterMode bool) {
+	if clusterMode {
+		e.ClusterMode = clusterMode
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultPerNodeScope(scope string) {
+	if scope != "" {
+		e.PerNodeScope = scope
+	}
+}
+
+// CollectAllNodes returns whether per node bucket stats are collected for
+// every node, as they are when the scope is all or in cluster mode, which is
+// the older name for it.  When the scope is auto, every node is collected by
+// a remote exporter, unless it has been given a node to collect, and only its
+// own node by a sidecar.
+func (e *ExporterConfig) CollectAllNodes() bool {
+	if e.ClusterMode {
+		return true
+	}
+
+	switch e.PerNodeScope {
+	case PerNodeScopeAll:
+		return true
+	case PerNodeScopeAuto:
+		mode := e.DeploymentMode()
+		log.Info("running as a %s exporter", mode)
+
+		return mode == DeploymentRemote && e.NodeName == ""
+	case PerNodeScopeSelf, "":
+	default:
+		log.Warn("unknown per node scope %q, per node bucket stats will be collected for this node", e.PerNodeScope)
+	}
+
+	return false
+}
+
+func (e *ExporterConfig) SetOrDefaultNodeName(nodeName string) {
+	if nodeName != "" {
+		e.NodeName = nodeName
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultWaitForRebalance(waitForRebalance bool) {
+	if waitForRebalance {
+		e.WaitForRebalance = waitForRebalance
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultUndefinedSamples(mode string) {
+	if mode != "" {
+		e.UndefinedSamples = mode
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultWindowAggregates(windowAggregates bool) {
+	if windowAggregates {
+		e.WindowAggregates = windowAggregates
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultSlowQueries(slowQueries bool) {
+	if slowQueries {
+		e.SlowQueries.Enabled = slowQueries
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultPreparedStatements(preparedStatements bool) {
+	if preparedStatements {
+		e.PreparedStatements = preparedStatements
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultHotKeys(hotKeys bool) {
+	if hotKeys {
+		e.HotKeys.Enabled = hotKeys
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultHotKeysTop(top string) {
+	if top != "" && isInt(top) {
+		e.HotKeys.Top, _ = strconv.Atoi(top)
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultServiceProbes(serviceProbes bool) {
+	if serviceProbes {
+		e.ServiceProbes.Enabled = serviceProbes
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultSlowBucketThreshold(threshold string) {
+	if value, err := strconv.ParseFloat(threshold, 64); err == nil && value > 0 {
+		e.SlowBuckets.Threshold = value
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultRequestIDs(requestIDs bool) {
+	if requestIDs {
+		e.RequestIDs = requestIDs
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultDebugCaptureEndpoints(endpoints string) {
+	if endpoints != "" {
+		e.DebugCapture.Endpoints = endpoints
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultDebugCaptureWindow(window string) {
+	if window != "" && isInt(window) {
+		e.DebugCapture.Window, _ = strconv.Atoi(window)
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultKVConnections(kvConnections bool) {
+	if kvConnections {
+		e.KVConnections = kvConnections
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultClientErrors(clientErrors bool) {
+	if clientErrors {
+		e.ClientErrors = clientErrors
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultHealth(health bool) {
+	if health {
+		e.Health.Enabled = health
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultClusterRole(role string) {
+	if role != "" {
+		e.ClusterRole.Role = role
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultSuppressOnStandby(suppress bool) {
+	if suppress {
+		e.ClusterRole.SuppressOnStandby = suppress
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultDelta(delta bool) {
+	if delta {
+		e.Delta.Enabled = delta
+	}
+}
+
+func (e *ExporterConfig) SetOrDefaultExemplars(exemplars bool) {
+	if exemplars {
+		e.Exemplars = exemplars
+	}
+}
+
+// SetOrDefaultSidecar enables sidecar mode if requested or if the operator's
+// environment variables are set, and then fills in any connection settings
+// still left at their defaults: the address of the node from the hostname of
+// the pod, the TLS port if a CA is configured and the credentials from the
+// operator's secret.
+func (e *ExporterConfig) SetOrDefaultSidecar(sidecar bool) {
+	if sidecar || os.Getenv(operatorUser) != "" {
+		e.Sidecar.Enabled = true
+	}
+
+	if !e.Sidecar.Enabled {
+		return
+	}
+
+	log.Info("running as an operator sidecar")
+
+	if e.CouchbaseAddress == defaultCouchAddress {
+		if hostname, err := os.Hostname(); err == nil && hostname != "" {
+			e.CouchbaseAddress = hostname
+		}
+	}
+
+	if e.CouchbasePort == defaultCouchPort && e.Ca != "" {
+		e.CouchbasePort = defaultCouchTLSPort
+	}
+
+	if e.CouchbaseUser == defaultCouchUser {
+		if user, ok := readSidecarSecret(e.Sidecar.SecretDir, sidecarUsernameKey); ok {
+			log.Info("using operator secret user")
+
+			e.CouchbaseUser = user
+		}
+	}
+
+	if e.CouchbasePassword == defaultCouchPassword {
+		if pass, ok := readSidecarSecret(e.Sidecar.SecretDir, sidecarPasswordKey); ok {
+			log.Info("using operator secret password")
+
+			e.CouchbasePassword = pass
+		}
+	}
+}
+
+func readSidecarSecret(dir, key string) (string, bool) {
+	if dir == "" {
+		return "", false
+	}
+
+	value, err := ioutil.ReadFile(filepath.Join(dir, key))
+	if err != nil {
+		return "", false
+	}
+
+	secret := strings.TrimSpace(string(value))
+
+	return secret, secret != ""
+}
+
+func (e *ExporterConfig) SetOrDefaultCapellaAPIKey(apiKey string) {
+	if apiKey != "" {
+		e.Capella.APIKey = apiKey
+	}
+
+	// override passed value with ENV var value if it has one.
+	if os.Getenv(capellaAPIKey) != "" {
+		e.Capella.APIKey = os.Getenv(capellaAPIKey)
+	}
+}
+
 func (e *ExporterConfig) ValidateConfig() {
 
 }
@@ -0,0 +1,29 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+// FaultConfig injects faults into the requests made to Couchbase Server, to
+// test how the exporter copes with a slow or failing cluster.  It is meant for
+// development only.
+type FaultConfig struct {
+	// LatencyMillis delays every request by this many milliseconds.
+	LatencyMillis int `json:"latencyMillis"`
+	// ErrorRate is the fraction of requests, from 0 to 1, answered with a
+	// 503 instead of being made.
+	ErrorRate float64 `json:"errorRate"`
+	// MalformedRate is the fraction of responses, from 0 to 1, whose body is
+	// cut short so that it is not valid JSON.
+	MalformedRate float64 `json:"malformedRate"`
+}
+
+// Enabled reports whether any faults are injected.
+func (f FaultConfig) Enabled() bool {
+	return f.LatencyMillis > 0 || f.ErrorRate > 0 || f.MalformedRate > 0
+}
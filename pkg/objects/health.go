@@ -0,0 +1,79 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	// HealthStatusMetric is the name the health summary is exported under.
+	HealthStatusMetric = "couchbase_health_status"
+	// ComponentLabel is the label of the health summary naming the component.
+	ComponentLabel = "component"
+
+	HealthOK       = 0
+	HealthWarning  = 1
+	HealthCritical = 2
+)
+
+// HealthConfig configures the health summary, which grades each component of
+// the cluster as ok, warning or critical from the metrics the exporter
+// collects, for simple traffic light dashboards.
+type HealthConfig struct {
+	Enabled bool          `json:"enabled"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+// HealthCheck grades a component by the worst series of a metric.  A series
+// at or above Warning is a warning, and at or above Critical is critical, or,
+// when Below is set, below them instead.
+type HealthCheck struct {
+	// Component is the value of the component label, as kv or xdcr.
+	Component string `json:"component"`
+	// Metric is the name of the metric the exporter collects, before any
+	// naming scheme or relabel rules are applied.
+	Metric   string  `json:"metric"`
+	Warning  float64 `json:"warning"`
+	Critical float64 `json:"critical"`
+	// Below is set for metrics that are unhealthy when low, as resident
+	// ratios.
+	Below bool `json:"below,omitempty"`
+}
+
+// Grade returns the health status of a value of the check's metric.
+func (h HealthCheck) Grade(value float64) int {
+	past := func(threshold float64) bool {
+		if h.Below {
+			return value < threshold
+		}
+
+		return value >= threshold
+	}
+
+	switch {
+	case past(h.Critical):
+		return HealthCritical
+	case past(h.Warning):
+		return HealthWarning
+	default:
+		return HealthOK
+	}
+}
+
+// DefaultHealthChecks grade the data, index and query services and XDCR by a
+// few of the stats their dashboards are usually built around.
+func DefaultHealthChecks() []HealthCheck {
+	return []HealthCheck{
+		{Component: "kv", Metric: "cbbucketinfo_basic_quota_user_percent", Warning: 85, Critical: 95},
+		{Component: "kv", Metric: "cbbucketstat_vbuckets_active_resident_items_ratio", Warning: 30, Critical: 10, Below: true},
+		{Component: "index", Metric: "cbindex_ram_percent", Warning: 80, Critical: 95},
+		{Component: "query", Metric: "cbquery_queued_requests", Warning: 10, Critical: 100},
+		{Component: "query", Metric: "cbquery_requests_5000ms", Warning: 1, Critical: 5},
+		{Component: "xdcr", Metric: "cbtask_xdcr_changes_left", Warning: 100000, Critical: 1000000},
+		{Component: "xdcr", Metric: "cbtask_xdcr_errors", Warning: 1, Critical: 10},
+	}
+}
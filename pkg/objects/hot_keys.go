@@ -0,0 +1,35 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	HotKeyInfo = "hotKeyInfo"
+	HotKeyOps  = "hotKeyOps"
+
+	// DefaultHotKeysTop is how many of the hottest keys of each bucket are
+	// exported by default.
+	DefaultHotKeysTop = 10
+)
+
+// HotKeysConfig configures the collector of the most frequently accessed
+// document keys of each bucket.
+type HotKeysConfig struct {
+	// Enabled enables reading the hot keys of every bucket every scrape.
+	Enabled bool `json:"enabled"`
+	// Top is how many of the hottest keys of each bucket are exported.
+	Top int `json:"top"`
+}
+
+// HotKey is a document key and the rate it is accessed at, as sampled by the
+// data service.
+type HotKey struct {
+	Name string  `json:"name"`
+	Ops  float64 `json:"ops"`
+}
@@ -9,6 +9,8 @@
 
 package objects
 
+import "strings"
+
 const (
 	// Samples Keys.
 	IndexMemoryQuota  = "index_memory_quota"
@@ -21,6 +23,8 @@ const (
 	IndexItemsCount           = "items_count"
 	IndexFragPercent          = "frag_percent"
 	IndexNumDocsPendingQueued = "num_docs_pending_queued"
+	IndexNumDocsQueued        = "num_docs_queued"
+	IndexNumDocsPending       = "num_docs_pending"
 	IndexNumRequests          = "num_requests"
 	IndexCacheMisses          = "cache_misses"
 	IndexCacheHits            = "cache_hits"
@@ -28,10 +32,76 @@ const (
 	IndexNumRowsReturned      = "num_rows_returned"
 	IndexResidentPercent      = "resident_percent"
 	IndexAvgScanLatency       = "avg_scan_latency"
+
+	// IndexerStats is the key of the node wide section of the Indexer Stats.
+	IndexerStats = "indexer"
+
+	// these are the keys of the metrics read from the status of every index.
+	IndexStorageInfo = "IndexStorageInfo"
+	IndexDuplicates  = "IndexDuplicates"
 )
 
+// IndexerMetrics maps the names of the metrics read from the node wide section
+// of the Indexer Stats to the stat they are read from.
+var IndexerMetrics = map[string]string{
+	"indexer_memory_quota": "memory_quota",
+	"indexer_memory_used":  "memory_used",
+}
+
 type Index struct {
 	Op struct {
 		Samples map[string][]float64 `json:"samples"`
 	} `json:"op"`
 }
+
+// IndexStatus is the status of every index in the cluster, as returned by
+// /indexStatus.
+type IndexStatus struct {
+	Indexes []IndexDefinition `json:"indexes"`
+}
+
+// IndexDefinition is an index, or a replica of one, in IndexStatus.
+type IndexDefinition struct {
+	Bucket     string `json:"bucket"`
+	Scope      string `json:"scope"`
+	Collection string `json:"collection"`
+	// IndexName is the name of the index, the same for all its replicas.
+	IndexName   string `json:"indexName"`
+	StorageMode string `json:"storageMode"`
+	ReplicaID   int    `json:"replicaId"`
+	// Definition is the statement that creates the index.
+	Definition string `json:"definition"`
+}
+
+// Keyspace returns the bucket, scope and collection of the index, separated
+// by colons as in the Indexer Stats, or only the bucket on servers without
+// collections.
+func (d IndexDefinition) Keyspace() string {
+	if d.Scope == "" {
+		return d.Bucket
+	}
+
+	return d.Bucket + ":" + d.Scope + ":" + d.Collection
+}
+
+// Signature returns what the index covers, its keyspace, keys, condition and
+// partitioning, ignoring its name and the options in its WITH clause, so that
+// equivalent indexes have the same signature.
+func (d IndexDefinition) Signature() string {
+	definition := strings.Join(strings.Fields(d.Definition), " ")
+
+	kind := "INDEX"
+	if strings.HasPrefix(strings.ToUpper(definition), "CREATE PRIMARY INDEX") {
+		kind = "PRIMARY INDEX"
+	}
+
+	if i := strings.Index(strings.ToUpper(definition), " ON "); i >= 0 {
+		definition = definition[i+len(" ON "):]
+	}
+
+	if i := strings.LastIndex(strings.ToUpper(definition), " WITH "); i >= 0 {
+		definition = definition[:i]
+	}
+
+	return kind + " " + definition
+}
@@ -0,0 +1,35 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	KVConnectionsCurrent   = "kvConnectionsCurrent"
+	KVConnectionsRejected  = "kvConnectionsRejected"
+	KVConnectionStructures = "kvConnectionStructures"
+	KVConnectionsTotal     = "kvConnectionsTotal"
+
+	// the names of memcached's connection stats in the Couchbase Server 7
+	// stats API.
+	KVCurrConnectionsStat  = "kv_curr_connections"
+	KVRejectedConnsStat    = "kv_rejected_conns"
+	KVConnStructuresStat   = "kv_connection_structures"
+	KVTotalConnectionsStat = "kv_total_connections"
+)
+
+// KVConnectionStats are the stats of the data service's connections read by
+// the KV connections collector, by the key of the metric each is exported as.
+// They are memcached's own stats for the whole node, rather than the bucket
+// scoped curr_connections.
+var KVConnectionStats = map[string]string{
+	KVConnectionsCurrent:   KVCurrConnectionsStat,
+	KVConnectionsRejected:  KVRejectedConnsStat,
+	KVConnectionStructures: KVConnStructuresStat,
+	KVConnectionsTotal:     KVTotalConnectionsStat,
+}
@@ -0,0 +1,257 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"sort"
+)
+
+const (
+	MetricTypeGauge     = "gauge"
+	MetricTypeCounter   = "counter"
+	MetricTypeHistogram = "histogram"
+)
+
+// Endpoints shared by metrics read from somewhere other than their collector's
+// endpoint.
+const (
+	statsRangeEndpoint     = "/pools/default/stats/range/"
+	indexerStatsEndpoint   = "indexer:/api/v1/stats"
+	cbasIngestionEndpoint  = "analytics:/analytics/status/ingestion"
+	xdcrStatsEndpoint      = "/pools/default/buckets/@xdcr-{bucket}/stats"
+	capellaBucketsEndpoint = "capella:/v4/organizations/{organization}/projects/{project}/clusters/{cluster}/buckets"
+)
+
+// CatalogEntry describes a single metric the exporter can emit.
+type CatalogEntry struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Labels    []string `json:"labels"`
+	Endpoint  string   `json:"endpoint"`
+	Help      string   `json:"help"`
+	Collector string   `json:"collector"`
+}
+
+// metricHelp supplies help text for default metrics that were added without
+// any.  It is keyed by the metric key used in the collector configuration.
+var metricHelp = map[string]string{
+	"CouchSpatialDataSize":              "Size of active data in spatial views in bytes",
+	"CouchSpatialDiskSize":              "Total size of spatial view data on disk in bytes",
+	"CouchSpatialOps":                   "Number of spatial view operations",
+	"CouchViewsDiskSize":                "Total size of view data on disk in bytes",
+	"EpDcpViewsIndexesCount":            "Number of views and indexes DCP connections",
+	"EpDcpViewsIndexesItemsRemaining":   "Number of items remaining to be sent to views and indexes DCP consumers",
+	"EpDcpViewsIndexesProducerCount":    "Number of views and indexes DCP senders",
+	"EpDcpViewsIndexesTotalBacklogSize": "Total size of the views and indexes DCP backlog",
+	"EpDcpViewsIndexesItemsSent":        "Number of items per second sent to views and indexes DCP consumers",
+	"EpDcpViewsIndexesTotalBytes":       "Number of bytes per second sent to views and indexes DCP consumers",
+	"EpDcpViewsIndexesBackoff":          "Number of backoffs for views and indexes DCP connections",
+	"BgWaitCount":                       "Number of background fetches waited on",
+	"BgWaitTotal":                       "Total time spent waiting for background fetches in microseconds",
+	"DiskCommitCount":                   "Number of disk commits",
+	"DiskCommitTotal":                   "Total time spent committing to disk in microseconds",
+	"DiskUpdateCount":                   "Number of disk updates",
+	"DiskUpdateTotal":                   "Total time spent updating disk in microseconds",
+	"EpActiveHlcDrift":                  "Total absolute hybrid logical clock drift of active vBuckets in microseconds",
+	"EpActiveHlcDriftCount":             "Number of hybrid logical clock drift samples for active vBuckets",
+	"EpClockCasDriftTheresholExceeded":  "Number of times the CAS drift threshold was exceeded",
+	"EpDcp2iTotalBacklogSize":           "Total size of the index DCP backlog",
+	"EpDcpCbasTotalBacklogSize":         "Total size of the analytics DCP backlog",
+	"EpDcpCbasTotalBytes":               "Number of bytes per second sent to analytics DCP consumers",
+	"EpDcpFtsBackoff":                   "Number of backoffs for search DCP connections",
+	"EpDcpFtsCount":                     "Number of search DCP connections",
+	"EpDcpFtsItemsRemaining":            "Number of items remaining to be sent to search DCP consumers",
+	"EpDcpFtsItemsSent":                 "Number of items per second sent to search DCP consumers",
+	"EpDcpFtsProducerCount":             "Number of search DCP senders",
+	"EpDcpFtsTotalBacklogSize":          "Total size of the search DCP backlog",
+	"EpDcpFtsTotalBytes":                "Number of bytes per second sent to search DCP consumers",
+	"EpDcpOtherTotalBacklogSize":        "Total size of the DCP backlog for other consumers",
+	"EpDcpReplicaTotalBacklogSize":      "Total size of the replication DCP backlog",
+	"EpDcpViewsTotalBacklogSize":        "Total size of the views DCP backlog",
+	"EpDcpXdcrTotalBacklogSize":         "Total size of the XDCR DCP backlog",
+	"EpReplicaHlcDriftCount":            "Number of hybrid logical clock drift samples for replica vBuckets",
+	"VbActiveQueueItems":                "Number of items in the disk queue for active vBuckets",
+	"VbReplicaNumNonResident":           "Number of non-resident items in replica vBuckets",
+	"VbTotalQueueAge":                   "Sum of the age of items in the disk queue in milliseconds",
+	"CPULocalMs":                        "CPU time consumed on the node in milliseconds",
+	"MemActualUsed":                     "Memory in use on the node in bytes",
+	"MemTotal":                          "Total memory on the node in bytes",
+	"MemUsedSys":                        "Memory used by the system in bytes",
+
+	"eventingBucketOpExceptionCount":     "Number of bucket operation exceptions raised by eventing functions",
+	"eventingCheckpointFailureCount":     "Number of eventing checkpoint failures",
+	"eventingOnUpdateFailure":            "Number of failed OnUpdate handler invocations",
+	"eventingOnUpdateSuccess":            "Number of successful OnUpdate handler invocations",
+	"eventingTestBucketOpExceptionCount": "Number of bucket operation exceptions raised by the test function",
+	"eventingTestCheckpointFailureCount": "Number of checkpoint failures for the test function",
+	"eventingTestDcpBacklog":             "Number of mutations remaining in the DCP backlog of the test function",
+	"eventingTestFailedCount":            "Number of failed handler executions for the test function",
+	"eventingTestN1QlOpExceptionCount":   "Number of N1QL operation exceptions raised by the test function",
+	"eventingTestOnDeleteFailure":        "Number of failed OnDelete handler invocations for the test function",
+	"eventingTestOnDeleteSuccess":        "Number of successful OnDelete handler invocations for the test function",
+	"eventingTestOnUpdateFailure":        "Number of failed OnUpdate handler invocations for the test function",
+	"eventingTestOnUpdateSuccess":        "Number of successful OnUpdate handler invocations for the test function",
+	"eventingTestProcessedCount":         "Number of mutations processed by the test function",
+	"eventingTestTimeoutCount":           "Number of handler executions that timed out for the test function",
+}
+
+// withHelpText fills in any empty help text from the metadata table.
+func withHelpText(config *CollectorConfig) *CollectorConfig {
+	if config == nil {
+		return config
+	}
+
+	for key, value := range config.Metrics {
+		if value.HelpText != "" {
+			continue
+		}
+
+		if help, ok := metricHelp[key]; ok {
+			value.HelpText = help
+			config.Metrics[key] = value
+		}
+	}
+
+	return config
+}
+
+// fillHelpText applies the metadata table to a loaded configuration, so config
+// files generated before the table existed still export help text.
+func (e *ExporterCollectors) fillHelpText() {
+	for _, source := range e.sources() {
+		withHelpText(source.config)
+	}
+}
+
+// fillDescriptions applies the type and endpoint of each default metric to a
+// loaded configuration, as they are not read from the configuration file.
+func (e *ExporterCollectors) fillDescriptions(defaults ExporterCollectors) {
+	defaultSources := defaults.sources()
+
+	for i, source := range e.sources() {
+		if source.config == nil || defaultSources[i].config == nil {
+			continue
+		}
+
+		for key, value := range source.config.Metrics {
+			if metric, ok := defaultSources[i].config.Metrics[key]; ok {
+				value.Type = metric.Type
+				value.Endpoint = metric.Endpoint
+				source.config.Metrics[key] = value
+			}
+		}
+	}
+}
+
+type collectorSource struct {
+	config   *CollectorConfig
+	endpoint string
+}
+
+func (e *ExporterCollectors) sources() []collectorSource {
+	return []collectorSource{
+		{e.BucketInfo, "/pools/default/buckets"},
+		{e.BucketStats, "/pools/default/buckets/{bucket}/stats"},
+		{e.Analytics, "/pools/default/buckets/@cbas/stats"},
+		{e.Eventing, "/pools/default/buckets/@eventing/stats"},
+		{e.Index, "/pools/default/buckets/@index/stats"},
+		{e.Node, "/pools/default"},
+		{e.Query, "/pools/default/buckets/@query/stats"},
+		{e.Search, "/pools/default/buckets/@fts/stats"},
+		{e.Task, "/pools/default/tasks"},
+		{e.PerNodeBucketStats, "/pools/default/buckets/{bucket}/nodes/{node}/stats"},
+		{e.Alerts, "/pools/default"},
+		{e.Audit, "/settings/audit"},
+		{e.Security, "/settings/security"},
+		{e.Backup, "backup:/api/v1/cluster/self/repository/active"},
+		{e.Views, "views:/{bucket}/_design/{ddoc}/_info"},
+		{e.Capella, "capella:/v4/organizations/{organization}/projects/{project}/clusters/{cluster}"},
+		{e.SlowQueries, "query:/query/service system:completed_requests"},
+		{e.Prepared, "query:/query/service system:prepareds"},
+		{e.HotKeys, "/pools/default/buckets/{bucket}/stats hot_keys"},
+		{e.KVConnections, "/pools/default/stats/range"},
+		{e.ClientErrors, "/pools/default/stats/range"},
+		{e.Rollup, "/pools/default/buckets"},
+	}
+}
+
+// Endpoint returns the REST endpoint the given collector reads its metrics from.
+func (e *ExporterCollectors) Endpoint(config *CollectorConfig) string {
+	for _, source := range e.sources() {
+		if source.config == config {
+			return source.endpoint
+		}
+	}
+
+	return ""
+}
+
+// Catalog lists every enabled metric, including the up and scrape duration
+// metrics of each collector and the metrics the exporter reports about
+// itself, sorted by name.
+func (e *ExporterCollectors) Catalog() []CatalogEntry {
+	entries := SelfMetrics()
+
+	for _, source := range e.sources() {
+		c := source.config
+		if c == nil {
+			continue
+		}
+
+		entries = append(entries,
+			CatalogEntry{
+				Name:      (&MetricInfo{Name: DefaultUptimeMetric}).FQName(c.Namespace, c.Subsystem),
+				Type:      MetricTypeGauge,
+				Labels:    []string{ClusterLabel},
+				Endpoint:  source.endpoint,
+				Help:      DefaultUptimeMetricHelp,
+				Collector: c.Name,
+			},
+			CatalogEntry{
+				Name:      (&MetricInfo{Name: DefaultScrapeDurationMetric}).FQName(c.Namespace, c.Subsystem),
+				Type:      MetricTypeGauge,
+				Labels:    []string{ClusterLabel},
+				Endpoint:  source.endpoint,
+				Help:      DefaultScrapeDurationMetricHelp,
+				Collector: c.Name,
+			})
+
+		for _, value := range c.Metrics {
+			if !value.Enabled {
+				continue
+			}
+
+			metricType := value.Type
+			if metricType == "" {
+				metricType = MetricTypeGauge
+			}
+
+			endpoint := value.Endpoint
+			if endpoint == "" {
+				endpoint = source.endpoint
+			}
+
+			entries = append(entries, CatalogEntry{
+				Name:      value.FQName(c.Namespace, c.Subsystem),
+				Type:      metricType,
+				Labels:    GetLabelKeys(value.Labels),
+				Endpoint:  endpoint,
+				Help:      value.HelpText,
+				Collector: c.Name,
+			})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
@@ -0,0 +1,58 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+)
+
+const (
+	// MetricNamesLegacy exports metrics under the names they have always had.
+	MetricNamesLegacy = "legacy"
+	// MetricNamesBoth exports metrics under both their legacy and corrected
+	// names, marking the legacy ones deprecated, while dashboards and alerts
+	// are migrated.
+	MetricNamesBoth = "both"
+	// MetricNamesCorrected exports metrics only under their corrected names.
+	MetricNamesCorrected = "corrected"
+
+	unknownMetricNames string = "unknown metric names"
+)
+
+var (
+	ErrUnknownMetricNames = fmt.Errorf(unknownMetricNames)
+)
+
+// NameCorrection renames the metrics whose legacy name matches, multiplying
+// their values by Scale if set, where the legacy name has the wrong unit.
+// Counters are also given the _total suffix if they do not have it.
+type NameCorrection struct {
+	Match  string
+	Rename string
+	Scale  float64
+}
+
+// NameCorrections are the corrections of the legacy names that do not follow
+// the Prometheus naming conventions.
+var NameCorrections = []NameCorrection{
+	{Match: "(cbbucketstat|cbpernodebucket)_cpu_(idle|local)_ms", Rename: "${1}_cpu_${2}_seconds", Scale: 0.001},
+	{Match: "cbnode_uptime", Rename: "cbnode_uptime_seconds"},
+}
+
+// ValidateMetricNames returns an error unless names is one of the modes of
+// exporting legacy and corrected metric names.
+func ValidateMetricNames(names string) error {
+	switch names {
+	case "", MetricNamesLegacy, MetricNamesBoth, MetricNamesCorrected:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownMetricNames, names)
+	}
+}
@@ -9,6 +9,8 @@
 
 package objects
 
+import "time"
+
 const (
 	// System Stats Keys.
 	CPUUtilizationRate = "cpu_utilization_rate"
@@ -16,6 +18,10 @@ const (
 	SwapUsed           = "swap_used"
 	MemTotal           = "mem_total"
 	MemFree            = "mem_free"
+	CPUStolenRate      = "cpu_stolen_rate"
+	CPUCoresAvailable  = "cpu_cores_available"
+	MemLimit           = "mem_limit"
+	Allocstall         = "allocstall"
 
 	// Interesting Stats Keys.
 	CmdGet                                 = "cmd_get"
@@ -46,14 +52,39 @@ const (
 	GracefulFailoverStart   = "graceful_failover_start"
 	GracefulFailoverSuccess = "graceful_failover_success"
 	GracefulFailoverFail    = "graceful_failover_fail"
+
+	// ClusterInfo is the key of the metric carrying the cluster UUID.
+	ClusterInfo = "clusterInfo"
+
+	// ClockSkew is the key of the metric of how far the clock of each node
+	// is from the exporter's.
+	ClockSkew = "clockSkew"
 )
 
+// NodeTime is the time on the clock of a node, and when it was read on the
+// exporter's clock.
+type NodeTime struct {
+	// Time is the Date of the node's response, which is to the second.
+	Time     time.Time
+	Sent     time.Time
+	Received time.Time
+}
+
+// Skew returns how far the clock of the node is ahead of the exporter's, or
+// behind it if negative, taking the node to have answered half way through
+// the request and half way through the second of its Date.
+func (t NodeTime) Skew() time.Duration {
+	answered := t.Sent.Add(t.Received.Sub(t.Sent) / 2)
+
+	return t.Time.Add(time.Second / 2).Sub(answered)
+}
+
 type Nodes struct {
 	Name                   string            `json:"name"`
 	Nodes                  []Node            `json:"nodes"`
 	Buckets                map[string]string `json:"buckets"`        //
 	RemoteClusters         map[string]string `json:"remoteClusters"` //
-	Alerts                 []interface{}     `json:"alerts"`
+	Alerts                 []Alert           `json:"alerts"`
 	AlertsSilenceURL       string
 	RebalanceStatus        string                 `json:"rebalanceStatus"`
 	RebalanceProgressURI   string                 `json:"rebalanceProgressUri"` //
@@ -111,6 +142,13 @@ type StorageTotals struct {
 }
 
 // Node struct itself
+// Pools is the result of /pools.
+type Pools struct {
+	UUID                  string `json:"uuid"`
+	IsEnterprise          bool   `json:"isEnterprise"`
+	ImplementationVersion string `json:"implementationVersion"`
+}
+
 // contains a lot more fields when listed in a bucketinfo struct
 // @ /pools/default/buckets
 
@@ -139,6 +177,7 @@ type Node struct {
 	Ports                *Ports                      `json:"ports,omitempty"`
 	Services             []string                    `json:"services,omitempty"`
 	AlternateAddresses   *AlternateAddressesExternal `json:"alternateAddresses,omitempty"`
+	NodeEncryption       bool                        `json:"nodeEncryption"`
 }
 
 type Ports struct {
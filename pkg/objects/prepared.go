@@ -0,0 +1,32 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	PreparedStatements    = "preparedStatements"
+	PreparedCacheHits     = "preparedCacheHits"
+	PreparedCacheMisses   = "preparedCacheMisses"
+	PreparedInvalidations = "preparedInvalidations"
+
+	// PreparedsStatement reads the plan cache of every query node.
+	PreparedsStatement = "SELECT name, node, uses, planPreparedTime FROM system:prepareds"
+)
+
+// Prepared is a prepared statement in the plan cache of a query node.
+type Prepared struct {
+	Name string `json:"name"`
+	Node string `json:"node"`
+	// Uses counts the executions of the cached plan.
+	Uses float64 `json:"uses"`
+	// PlanPreparedTime is when the plan was last prepared, which changes when
+	// the plan is prepared again, for example after an index it used was
+	// dropped.
+	PlanPreparedTime string `json:"planPreparedTime"`
+}
@@ -29,8 +29,27 @@ const (
 	QuerySelects         = "query_selects"
 	QueryServiceTime     = "query_service_time"
 	QueryWarnings        = "query_warnings"
+
+	// QueryService is the name of the query service in the services of a
+	// node.
+	QueryService = "n1ql"
 )
 
+// QueryVitalsMetrics maps the names of the metrics read from the vitals of
+// the query service of a node to the vital they are read from.
+var QueryVitalsMetrics = map[string]string{
+	"vitals_cpu_user_percent":             "cpu.user.percent",
+	"vitals_cpu_sys_percent":              "cpu.sys.percent",
+	"vitals_memory_usage_bytes":           "memory.usage",
+	"vitals_requests_completed":           "request.completed.count",
+	"vitals_request_time_mean_seconds":    "request_time.mean",
+	"vitals_request_quota_used_hwm_bytes": "request.quota.used.hwm",
+}
+
+// QueryVitals are the vitals of the query service of a node, as returned by
+// /admin/vitals.  Each is a number, or a duration such as "1.5ms".
+type QueryVitals map[string]interface{}
+
 type Query struct {
 	Op struct {
 		Samples map[string][]float64 `json:"samples"`
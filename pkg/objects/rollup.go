@@ -0,0 +1,51 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	RollupOps               = "rollupOps"
+	RollupItems             = "rollupItems"
+	RollupRAMUsed           = "rollupRAMUsed"
+	RollupRAMQuota          = "rollupRAMQuota"
+	RollupDiskUsed          = "rollupDiskUsed"
+	RollupDiskQuota         = "rollupDiskQuota"
+	RollupBuckets           = "rollupBuckets"
+	RollupFailuresTolerated = "rollupFailuresTolerated"
+)
+
+// FailuresTolerated returns how many more of the nodes serving the bucket can
+// fail before some of its data has no copy left.  Each node that is already
+// unhealthy or failed over has cost the bucket a replica, and a bucket cannot
+// survive losing every healthy node, however many replicas it is configured
+// with.  Memcached buckets keep no replicas and tolerate no failures.
+func (b BucketInfo) FailuresTolerated() int {
+	if b.BucketType == MemcachedBucketType {
+		return 0
+	}
+
+	healthy := 0
+
+	for _, node := range b.Nodes {
+		if node.Status == "healthy" && node.ClusterMembership == "active" {
+			healthy++
+		}
+	}
+
+	tolerated := b.ReplicaNumber - (len(b.Nodes) - healthy)
+	if tolerated > healthy-1 {
+		tolerated = healthy - 1
+	}
+
+	if tolerated < 0 {
+		return 0
+	}
+
+	return tolerated
+}
@@ -0,0 +1,307 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+)
+
+const (
+	invalidSamples string = "samples must be a JSON object"
+	invalidObject  string = "expected a JSON object"
+	// undefinedSample is how Couchbase Server reports a sample it has no
+	// value for.
+	undefinedSample = "undefined"
+)
+
+var (
+	ErrInvalidSamples = fmt.Errorf(invalidSamples)
+	ErrInvalidObject  = fmt.Errorf(invalidObject)
+
+	samplePool = sync.Pool{
+		New: func() interface{} {
+			// a minute of samples, the most a stats request returns by default.
+			samples := make([]float64, 0, 60)
+			return &samples
+		},
+	}
+)
+
+// Samples are the samples of each stat of a per node stats response, which
+// are usually numbers but may be anything.  A stat whose samples are null has
+// nil samples, and a sample that is not a number, such as "undefined", is NaN.
+//
+// Responses with the stats of a bucket on every node can be megabytes, so the
+// samples are decoded straight from the response into slices from a pool
+// rather than into interface values, and should be released once they have
+// been read.
+type Samples map[string][]float64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *Samples) UnmarshalJSON(data []byte) error {
+	return s.decode(json.NewDecoder(bytes.NewReader(data)))
+}
+
+// decode decodes the samples decoder reads next.
+func (s *Samples) decode(decoder *json.Decoder) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token == nil {
+		*s = nil
+		return nil
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return ErrInvalidSamples
+	}
+
+	samples := Samples{}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		stat, _ := token.(string)
+
+		if samples[stat], err = decodeSamples(decoder); err != nil {
+			return err
+		}
+	}
+
+	// the closing brace.
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	*s = samples
+
+	return nil
+}
+
+// DecodeJSON decodes the stats of a bucket on a node straight from decoder,
+// so that a response need not be read into memory before it is decoded.
+func (s *PerNodeBucketStats) DecodeJSON(decoder *json.Decoder) error {
+	return decodeObject(decoder, func(field string) error {
+		switch field {
+		case "hostname":
+			return decoder.Decode(&s.HostName)
+		case "hot_keys":
+			return decoder.Decode(&s.HotKeys)
+		case "op":
+			return decodeObject(decoder, func(field string) error {
+				switch field {
+				case "samples":
+					return s.Op.Samples.decode(decoder)
+				case "samplesCount":
+					return decoder.Decode(&s.Op.SamplesCount)
+				case "isPersistent":
+					return decoder.Decode(&s.Op.IsPersistent)
+				case "lastTStamp":
+					return decoder.Decode(&s.Op.LastTStamp)
+				case "interval":
+					return decoder.Decode(&s.Op.Interval)
+				default:
+					return decoder.Decode(&json.RawMessage{})
+				}
+			})
+		default:
+			return decoder.Decode(&json.RawMessage{})
+		}
+	})
+}
+
+// decodeObject decodes the object decoder reads next, calling decodeField to
+// decode the value of each of its fields.
+func decodeObject(decoder *json.Decoder, decodeField func(field string) error) error {
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("%w, got %v", ErrInvalidObject, token)
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		field, _ := token.(string)
+
+		if err := decodeField(field); err != nil {
+			return err
+		}
+	}
+
+	// the closing brace.
+	_, err = decoder.Token()
+
+	return err
+}
+
+// MarshalJSON implements json.Marshaler, writing samples that are not numbers
+// as Couchbase Server does.
+func (s Samples) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+
+	encoded := make(map[string][]interface{}, len(s))
+
+	for stat, samples := range s {
+		if samples == nil {
+			encoded[stat] = nil
+			continue
+		}
+
+		values := make([]interface{}, len(samples))
+
+		for i, sample := range samples {
+			if math.IsNaN(sample) || math.IsInf(sample, 0) {
+				values[i] = undefinedSample
+			} else {
+				values[i] = sample
+			}
+		}
+
+		encoded[stat] = values
+	}
+
+	return json.Marshal(encoded)
+}
+
+// Release returns the samples to the pool they were decoded into.  They must
+// not be read afterwards.
+func (s Samples) Release() {
+	for stat, samples := range s {
+		if samples != nil {
+			released := samples[:0]
+			samplePool.Put(&released)
+		}
+
+		delete(s, stat)
+	}
+}
+
+// decodeSamples decodes the samples of one stat, which are an array of
+// samples, null, or sometimes a single sample.
+func decodeSamples(decoder *json.Decoder) ([]float64, error) {
+	token, err := decoder.Token()
+	if err != nil || token == nil {
+		return nil, err
+	}
+
+	samples := *samplePool.Get().(*[]float64)
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		sample, err := decodeSample(decoder, token)
+		return append(samples, sample), err
+	}
+
+	// each sample is decoded from its bytes rather than read as a token, which
+	// would box it in an interface.
+	var value sample
+
+	for decoder.More() {
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		samples = append(samples, float64(value))
+	}
+
+	// the closing bracket.
+	_, err = decoder.Token()
+
+	return samples, err
+}
+
+// sample is a sample in an array of samples.  A null sample is 0, and one that
+// is not a number NaN.
+type sample float64
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *sample) UnmarshalJSON(data []byte) error {
+	switch {
+	case string(data) == "null":
+		*s = 0
+	case len(data) > 1 && data[0] == '"':
+		*s = sample(parseSample(data[1 : len(data)-1]))
+	default:
+		*s = sample(parseSample(data))
+	}
+
+	return nil
+}
+
+// parseSample parses a number, returning NaN for anything else.
+func parseSample(data []byte) float64 {
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return math.NaN()
+	}
+
+	return value
+}
+
+// decodeSample returns the sample token starts, for stats whose samples are
+// not an array.  A null sample is 0, and one that is not a number NaN.
+func decodeSample(decoder *json.Decoder, token json.Token) (float64, error) {
+	switch value := token.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return value, nil
+	case string:
+		sample, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return math.NaN(), nil
+		}
+
+		return sample, nil
+	case json.Delim:
+		return math.NaN(), skipValue(decoder)
+	default:
+		return math.NaN(), nil
+	}
+}
+
+// skipValue skips the rest of an array or object whose opening delimiter has
+// been read.
+func skipValue(decoder *json.Decoder) error {
+	for depth := 1; depth > 0; {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		if delim, ok := token.(json.Delim); ok {
+			if delim == '[' || delim == '{' {
+				depth++
+			} else {
+				depth--
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	NodeEncryption           = "nodeEncryption"
+	ClusterEncryption        = "clusterEncryption"
+	ClusterEncryptionLevel   = "clusterEncryptionLevel"
+	EncryptionAtRest         = "encryptionAtRest"
+	BucketEncryptionAtRest   = "bucketEncryptionAtRest"
+	NoClusterEncryptionLevel = "none"
+)
+
+// SecuritySettings is the result of /settings/security.  The cluster
+// encryption level only applies while node-to-node encryption is enabled.
+type SecuritySettings struct {
+	ClusterEncryptionLevel string `json:"clusterEncryptionLevel"`
+	TLSMinVersion          string `json:"tlsMinVersion"`
+}
+
+// EncryptionAtRestSettings is the result of
+// /settings/security/encryptionAtRest, available from Couchbase Server 8,
+// keyed by the kind of data encrypted, such as config, log or audit.
+type EncryptionAtRestSettings map[string]EncryptionAtRestSetting
+
+// EncryptionAtRestSetting is how one kind of data is encrypted at rest.
+type EncryptionAtRestSetting struct {
+	EncryptionMethod string `json:"encryptionMethod"`
+	EncryptionKeyID  int    `json:"encryptionKeyId"`
+}
+
+// Enabled returns whether the data is encrypted at all.
+func (s EncryptionAtRestSetting) Enabled() bool {
+	return s.EncryptionMethod != "" && s.EncryptionMethod != "disabled"
+}
+
+// ClusterEncryption returns whether node-to-node encryption is enabled on
+// every node, which is how Couchbase Server enables it for the cluster.
+func (n Nodes) ClusterEncryption() bool {
+	if len(n.Nodes) == 0 {
+		return false
+	}
+
+	for _, node := range n.Nodes {
+		if !node.NodeEncryption {
+			return false
+		}
+	}
+
+	return true
+}
+
+// EncryptedAtRest returns whether the bucket's data is encrypted at rest,
+// and false for its second result on versions that do not report it.
+func (b BucketInfo) EncryptedAtRest() (bool, bool) {
+	if b.EncryptionAtRestKeyID == nil {
+		return false, false
+	}
+
+	return *b.EncryptionAtRestKeyID >= 0, true
+}
@@ -0,0 +1,188 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// SelfMetricsCollector is the collector the metrics the exporter reports
+	// about itself are listed under.
+	SelfMetricsCollector = "Exporter"
+	// SelfMetricsEndpoint is where the metrics the exporter reports about
+	// itself come from, as they are not read from Couchbase Server.
+	SelfMetricsEndpoint = "exporter"
+)
+
+// selfMetrics lists the metrics the exporter reports about itself.  They are
+// registered with the default registry as the packages reporting them are
+// loaded, rather than configured like the metrics of the collectors, so each
+// is recorded here as it is created.
+var (
+	selfMetricsMutex sync.Mutex
+	selfMetrics      = []CatalogEntry{
+		selfMetric(prometheus.Opts(log.LoggedMessagesOpts), MetricTypeCounter, log.MessageLabels),
+		selfMetric(prometheus.Opts(log.ThrottledMessagesOpts), MetricTypeCounter, log.MessageLabels),
+	}
+)
+
+func selfMetric(opts prometheus.Opts, metricType string, labels []string) CatalogEntry {
+	return CatalogEntry{
+		Name:      prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		Type:      metricType,
+		Labels:    append([]string{}, labels...),
+		Endpoint:  SelfMetricsEndpoint,
+		Help:      opts.Help,
+		Collector: SelfMetricsCollector,
+	}
+}
+
+func recordSelfMetric(opts prometheus.Opts, metricType string, labels []string) {
+	selfMetricsMutex.Lock()
+	defer selfMetricsMutex.Unlock()
+
+	selfMetrics = append(selfMetrics, selfMetric(opts, metricType, labels))
+}
+
+// SelfMetrics lists the metrics the exporter reports about itself, sorted by
+// name.
+func SelfMetrics() []CatalogEntry {
+	selfMetricsMutex.Lock()
+	defer selfMetricsMutex.Unlock()
+
+	entries := append([]CatalogEntry{}, selfMetrics...)
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	return entries
+}
+
+// counterCreated records when each series of the counters the exporter
+// reports about itself was created, keyed by counterSeriesKey.  The client
+// library does not record it, and the counters read from Couchbase Server were
+// created by the server, so only these can be given a creation time.
+var (
+	counterCreatedMutex sync.Mutex
+	counterCreated      = map[string]time.Time{}
+)
+
+// counterSeriesKey identifies a series by its metric name and sorted label
+// pairs.
+func counterSeriesKey(name string, labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for label, value := range labels {
+		pairs = append(pairs, label+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// CounterCreated returns when the series of a counter the exporter reports
+// about itself was created, and false for any other series.
+func CounterCreated(name string, labels map[string]string) (time.Time, bool) {
+	counterCreatedMutex.Lock()
+	defer counterCreatedMutex.Unlock()
+
+	created, ok := counterCreated[counterSeriesKey(name, labels)]
+
+	return created, ok
+}
+
+// ExporterCounterVec is a counter the exporter reports about itself, which
+// records when each of its series is created.
+type ExporterCounterVec struct {
+	*prometheus.CounterVec
+	name   string
+	labels []string
+}
+
+// WithLabelValues returns the series of the counter with the given label
+// values, recording when it was created the first time it is asked for.
+func (v *ExporterCounterVec) WithLabelValues(lvs ...string) prometheus.Counter {
+	counter := v.CounterVec.WithLabelValues(lvs...)
+
+	labels := make(map[string]string, len(v.labels))
+	for i, label := range v.labels {
+		labels[label] = lvs[i]
+	}
+
+	key := counterSeriesKey(v.name, labels)
+
+	counterCreatedMutex.Lock()
+	defer counterCreatedMutex.Unlock()
+
+	if _, ok := counterCreated[key]; !ok {
+		counterCreated[key] = time.Now()
+	}
+
+	return counter
+}
+
+// NewExporterCounterVec creates and registers a counter the exporter reports
+// about itself.
+func NewExporterCounterVec(opts prometheus.CounterOpts, labels []string) *ExporterCounterVec {
+	recordSelfMetric(prometheus.Opts(opts), MetricTypeCounter, labels)
+
+	return &ExporterCounterVec{
+		CounterVec: promauto.NewCounterVec(opts, labels),
+		name:       prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name),
+		labels:     append([]string{}, labels...),
+	}
+}
+
+// NewExporterGauge creates and registers a gauge the exporter reports about
+// itself.
+func NewExporterGauge(opts prometheus.GaugeOpts) prometheus.Gauge {
+	recordSelfMetric(prometheus.Opts(opts), MetricTypeGauge, nil)
+
+	return promauto.NewGauge(opts)
+}
+
+// NewExporterGaugeVec creates and registers a gauge the exporter reports
+// about itself.
+func NewExporterGaugeVec(opts prometheus.GaugeOpts, labels []string) *prometheus.GaugeVec {
+	recordSelfMetric(prometheus.Opts(opts), MetricTypeGauge, labels)
+
+	return promauto.NewGaugeVec(opts, labels)
+}
+
+// RecordExporterGauge lists a gauge the exporter reports about itself that is
+// added by a gatherer rather than registered with the default registry,
+// returning its opts.
+func RecordExporterGauge(opts prometheus.GaugeOpts, labels []string) prometheus.GaugeOpts {
+	recordSelfMetric(prometheus.Opts(opts), MetricTypeGauge, labels)
+
+	return opts
+}
+
+// NewExporterHistogram creates and registers a histogram the exporter reports
+// about itself.
+func NewExporterHistogram(opts prometheus.HistogramOpts) prometheus.Histogram {
+	recordSelfMetric(prometheus.Opts{
+		Namespace: opts.Namespace,
+		Subsystem: opts.Subsystem,
+		Name:      opts.Name,
+		Help:      opts.Help,
+	}, MetricTypeHistogram, nil)
+
+	return promauto.NewHistogram(opts)
+}
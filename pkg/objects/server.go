@@ -18,3 +18,30 @@ type Server struct {
 type Servers struct {
 	Servers []Server `json:"servers"`
 }
+
+const ServerGroupInfo = "serverGroupInfo"
+
+// ServerGroups is the result of /pools/default/serverGroups, which is only
+// available in Couchbase Server Enterprise Edition.
+type ServerGroups struct {
+	Groups []ServerGroup `json:"groups"`
+}
+
+type ServerGroup struct {
+	Name  string `json:"name"`
+	URI   string `json:"uri"`
+	Nodes []Node `json:"nodes"`
+}
+
+// ByHostname maps the hostname of each node to the name of its server group.
+func (s ServerGroups) ByHostname() map[string]string {
+	groups := map[string]string{}
+
+	for _, group := range s.Groups {
+		for _, node := range group.Nodes {
+			groups[node.Hostname] = group.Name
+		}
+	}
+
+	return groups
+}
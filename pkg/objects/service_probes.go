@@ -0,0 +1,52 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+const (
+	// ServiceProbesCollector names the collector of the probes.
+	ServiceProbesCollector = "ServiceProbes"
+
+	// ServiceUpMetric is 1 while the port of a service of a node answers.
+	ServiceUpMetric = "couchbase_service_up"
+
+	// DefaultServiceProbeTimeout is how many seconds a port is given to
+	// answer by default.
+	DefaultServiceProbeTimeout = 2
+)
+
+// ServiceProbeConfig configures the probes of the port of every service of
+// every node.
+type ServiceProbeConfig struct {
+	// Enabled enables probing the ports every scrape.
+	Enabled bool `json:"enabled"`
+	// Timeout is how many seconds a port is given to answer.
+	Timeout int `json:"timeout"`
+}
+
+// ServicePort is the port a service serves on, and the port it serves TLS
+// on.
+type ServicePort struct {
+	Port    int
+	TLSPort int
+	// HTTP is whether the service serves HTTP, rather than the memcached
+	// protocol of the data service.
+	HTTP bool
+}
+
+// ServicePorts maps the services a node may run, as named in its services,
+// to their ports.
+var ServicePorts = map[string]ServicePort{
+	"kv":       {Port: 11210, TLSPort: 11207},
+	"n1ql":     {Port: 8093, TLSPort: 18093, HTTP: true},
+	"index":    {Port: 9102, TLSPort: 19102, HTTP: true},
+	"fts":      {Port: 8094, TLSPort: 18094, HTTP: true},
+	"cbas":     {Port: 8095, TLSPort: 18095, HTTP: true},
+	"eventing": {Port: 8096, TLSPort: 18096, HTTP: true},
+}
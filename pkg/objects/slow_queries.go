@@ -0,0 +1,101 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const (
+	SlowQueryRequests  = "slowQueryRequests"
+	SlowQueryElapsed   = "slowQueryElapsed"
+	SlowQueryStatement = "slowQueryStatement"
+
+	// CompletedRequestsStatement reads every request in the completed
+	// requests log, by its statement, or the text of the prepared statement
+	// it executed, and the time it took in seconds.
+	CompletedRequestsStatement = "SELECT IFMISSINGORNULL(statement, preparedText) AS statement, " +
+		"STR_TO_DURATION(elapsedTime) / 1e9 AS elapsed FROM system:completed_requests"
+
+	// maxStatementLength is the length normalized statements are cut to for
+	// the statement label.
+	maxStatementLength = 200
+)
+
+var (
+	// DefaultSlowQueryBuckets are the upper bounds in seconds of the
+	// durations requests are counted by, above the default threshold of one
+	// second for a request to be logged.
+	DefaultSlowQueryBuckets = []float64{1, 2.5, 5, 10, 30, 60}
+
+	stringLiteral  = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`)
+	numberLiteral  = regexp.MustCompile(`(^|[^\w$.])[0-9]+(?:\.[0-9]+)?(?:[eE][+-]?[0-9]+)?\b`)
+	literalList    = regexp.MustCompile(`\?(?:\s*,\s*\?)+`)
+	repeatedSpaces = regexp.MustCompile(`\s+`)
+)
+
+// SlowQueriesConfig configures the collector of the query service's log of
+// completed requests.
+type SlowQueriesConfig struct {
+	// Enabled enables querying system:completed_requests every scrape.
+	Enabled bool `json:"enabled"`
+	// Buckets are the upper bounds in seconds of the durations requests are
+	// counted by.
+	Buckets []float64 `json:"buckets"`
+}
+
+// CompletedRequest is a request in the completed requests log.
+type CompletedRequest struct {
+	Statement string  `json:"statement"`
+	Elapsed   float64 `json:"elapsed"`
+}
+
+// QueryResponse is the response of the query service to a statement, whose
+// results depend on the statement.
+type QueryResponse struct {
+	Status  string          `json:"status"`
+	Results json.RawMessage `json:"results"`
+	Errors  []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"errors"`
+}
+
+// NormalizeStatement replaces the literals in a statement with ?, and lists
+// of them with a single ?, so that requests that only differ in their values
+// are counted together.
+func NormalizeStatement(statement string) string {
+	normalized := stringLiteral.ReplaceAllString(statement, "?")
+	normalized = numberLiteral.ReplaceAllString(normalized, "$1?")
+	normalized = literalList.ReplaceAllString(normalized, "?")
+
+	return strings.TrimSpace(repeatedSpaces.ReplaceAllString(normalized, " "))
+}
+
+// Fingerprint identifies a normalized statement in labels.
+func Fingerprint(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+// TruncateStatement returns the normalized statement cut short enough to
+// label metrics with.
+func TruncateStatement(normalized string) string {
+	if runes := []rune(normalized); len(runes) > maxStatementLength {
+		return string(runes[:maxStatementLength]) + "..."
+	}
+
+	return normalized
+}
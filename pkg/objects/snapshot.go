@@ -0,0 +1,25 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+// MetricSample is a single persisted gauge value.  Name is the MetricInfo name
+// of the metric the sample belongs to, not the fully qualified prometheus name.
+type MetricSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+	Value  float64           `json:"value"`
+}
+
+// Snapshot is the on-disk representation of the last values collected by the
+// cycle driven collectors, keyed by collector name.
+type Snapshot struct {
+	Timestamp  int64                     `json:"timestamp"`
+	Collectors map[string][]MetricSample `json:"collectors"`
+}
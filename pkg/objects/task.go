@@ -11,6 +11,10 @@
 
 package objects
 
+import (
+	"strconv"
+)
+
 // /pools/default/tasks.
 type Task struct {
 	StatusID      string  `json:"statusId"`
@@ -52,6 +56,7 @@ type Task struct {
 	TotalChanges int64  `json:"totalChanges,omitempty"`
 
 	// XDCR stuff
+	ID             string        `json:"id,omitempty"`
 	ChangesLeft    int64         `json:"changesLeft,omitempty"`
 	DocsChecked    int64         `json:"docsChecked,omitempty"`
 	DocsWritten    int64         `json:"docsWritten,omitempty"`
@@ -64,6 +69,10 @@ type Task struct {
 
 	// loadingSampleBucket
 	Pid string `json:"pid,omitempty"`
+
+	// warming_up, of the bucket on the node.
+	Node  string                 `json:"node,omitempty"`
+	Stats map[string]interface{} `json:"stats,omitempty"`
 }
 
 // NodeProgress is the ingoing/outgoing detailed progress of a task in a node.
@@ -74,10 +83,74 @@ type NodeProgress struct {
 	ReplicaVBucketsLeft int64 `json:"replicaVBucketsLeft,omitempty"`
 }
 
+// StageInfo is the progress of one service's stage of a rebalance. The start
+// and completed times are timestamps, or false until the stage reaches them.
 type StageInfo struct {
-	TotalProgress   int64         `json:"totalProgress,omitempty"`
-	PerNodeProgress []interface{} `json:"perNodeProgress,omitempty"`
-	StartTime       string        `json:"startTime,omitempty"`
-	CompletedTime   string        `json:"completedTime,omitempty"`
-	TimeTaken       int64         `json:"timeTaken,omitempty"`
+	TotalProgress   float64            `json:"totalProgress,omitempty"`
+	PerNodeProgress map[string]float64 `json:"perNodeProgress,omitempty"`
+	StartTime       interface{}        `json:"startTime,omitempty"`
+	CompletedTime   interface{}        `json:"completedTime,omitempty"`
+	TimeTaken       int64              `json:"timeTaken,omitempty"`
+}
+
+// Started returns whether the rebalance has reached the stage.
+func (s StageInfo) Started() bool {
+	startTime, ok := s.StartTime.(string)
+
+	return ok && startTime != ""
+}
+
+const (
+	// Warmup stats of a warming_up task, which are reported as strings.
+	WarmupState               = "ep_warmup_state"
+	WarmupEstimatedValueCount = "ep_warmup_estimated_value_count"
+	WarmupValueCount          = "ep_warmup_value_count"
+)
+
+// WarmupStat returns the named warmup stat of a warming_up task as a number.
+// ok is false if the task does not report it, or reports it as "unknown", as
+// memcached does until it has an estimate.
+func (t Task) WarmupStat(name string) (float64, bool) {
+	switch stat := t.Stats[name].(type) {
+	case float64:
+		return stat, true
+	case string:
+		value, err := strconv.ParseFloat(stat, 64)
+		return value, err == nil
+	default:
+		return 0, false
+	}
+}
+
+const (
+	// Keys of the per replication stats of the XDCR stats of a bucket.
+	XdcrDocsFailedCrSource = "docs_failed_cr_source"
+	XdcrDocsFiltered       = "docs_filtered"
+	XdcrNumCheckpoints     = "num_checkpoints"
+	XdcrNumFailedCkpts     = "num_failedckpts"
+)
+
+// XdcrStatMetrics maps the keys of the task metrics read from the XDCR stats
+// of the source bucket of a replication to their stat.
+var XdcrStatMetrics = map[string]string{
+	"xdcrDocsFailedCrSource": XdcrDocsFailedCrSource,
+	"xdcrDocsFiltered":       XdcrDocsFiltered,
+	"xdcrCheckpoints":        XdcrNumCheckpoints,
+	"xdcrFailedCheckpoints":  XdcrNumFailedCkpts,
+}
+
+// XdcrStats is the result of /pools/default/buckets/@xdcr-<bucket>/stats.
+// The stats of each outgoing replication of the bucket are keyed by
+// replications/<replication id>/<stat>.
+type XdcrStats struct {
+	Op struct {
+		Samples map[string][]float64 `json:"samples"`
+	} `json:"op"`
+}
+
+// Replication returns the samples of a stat of the given replication.
+func (s XdcrStats) Replication(id, stat string) ([]float64, bool) {
+	samples, ok := s.Op.Samples["replications/"+id+"/"+stat]
+
+	return samples, ok
 }
@@ -0,0 +1,26 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+// ValueGuard bounds the values of the metrics whose name matches a regular
+// expression, for counters that Couchbase Server reports as negative or
+// absurdly large after a failover.
+type ValueGuard struct {
+	// Match is a regular expression that must match the whole metric name,
+	// as collected.
+	Match string `json:"match"`
+	// Min and Max are the smallest and largest sane values, either of which
+	// may be left out.
+	Min *float64 `json:"min,omitempty"`
+	Max *float64 `json:"max,omitempty"`
+	// Drop removes a series whose value is out of bounds, rather than only
+	// counting it as an anomaly.
+	Drop bool `json:"drop,omitempty"`
+}
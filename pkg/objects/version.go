@@ -0,0 +1,60 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VersionInfo is the key of the metric carrying the version of each node.
+const VersionInfo = "versionInfo"
+
+const (
+	EnterpriseEdition = "enterprise"
+	CommunityEdition  = "community"
+)
+
+// Edition returns the edition of Couchbase Server the cluster runs.
+func (p Pools) Edition() string {
+	if p.IsEnterprise {
+		return EnterpriseEdition
+	}
+
+	return CommunityEdition
+}
+
+// ServerVersion strips the edition from a version such as 7.1.1-3175-enterprise,
+// as it is the same for every node of a cluster and labelled separately.
+func ServerVersion(version string) string {
+	version = strings.TrimSuffix(version, "-"+EnterpriseEdition)
+
+	return strings.TrimSuffix(version, "-"+CommunityEdition)
+}
+
+// CompatVersion returns the version of Couchbase Server whose features the
+// cluster can use, which is that of the oldest node until every node has been
+// upgraded.  Each node reports it as the major version shifted left by 16 bits
+// plus the minor version.
+func (n Nodes) CompatVersion() string {
+	compat := 0
+
+	for _, node := range n.Nodes {
+		if compat == 0 || (node.ClusterCompatibility > 0 && node.ClusterCompatibility < compat) {
+			compat = node.ClusterCompatibility
+		}
+	}
+
+	if compat == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf("%d.%d", compat>>16, compat&0xffff)
+}
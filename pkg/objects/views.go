@@ -0,0 +1,84 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package objects
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ViewsAccesses       = "viewsAccesses"
+	ViewsUpdateDuration = "viewsUpdateDuration"
+	ViewsDiskSize       = "viewsDiskSize"
+	ViewsDataSize       = "viewsDataSize"
+	ViewsUpdaterRunning = "viewsUpdaterRunning"
+
+	// DesignDocPrefix prefixes the id of every design document.
+	DesignDocPrefix = "_design/"
+)
+
+// DesignDocs is the result of /pools/default/buckets/<bucket>/ddocs.
+type DesignDocs struct {
+	Rows []struct {
+		Doc struct {
+			Meta struct {
+				ID  string `json:"id"`
+				Rev string `json:"rev"`
+			} `json:"meta"`
+		} `json:"doc"`
+	} `json:"rows"`
+}
+
+// Names returns the names of the design documents without the _design/ prefix.
+func (d DesignDocs) Names() []string {
+	names := make([]string, 0, len(d.Rows))
+
+	for _, row := range d.Rows {
+		names = append(names, strings.TrimPrefix(row.Doc.Meta.ID, DesignDocPrefix))
+	}
+
+	return names
+}
+
+// DesignDocInfo is the result of <bucket>/_design/<ddoc>/_info on the views
+// port.  It describes the view index of the node that served the request.
+type DesignDocInfo struct {
+	Name      string `json:"name"`
+	ViewIndex struct {
+		Signature      string  `json:"signature"`
+		DiskSize       float64 `json:"disk_size"`
+		DataSize       float64 `json:"data_size"`
+		UpdaterRunning bool    `json:"updater_running"`
+		Stats          struct {
+			Updates       float64 `json:"updates"`
+			UpdateHistory []struct {
+				IndexingTime float64 `json:"indexing_time"`
+			} `json:"update_history"`
+		} `json:"stats"`
+	} `json:"view_index"`
+}
+
+// LastUpdateDuration returns the time in seconds the most recent index update
+// took.  The update history is kept newest first.
+func (d DesignDocInfo) LastUpdateDuration() (float64, bool) {
+	history := d.ViewIndex.Stats.UpdateHistory
+	if len(history) == 0 {
+		return 0, false
+	}
+
+	return history[0].IndexingTime, true
+}
+
+// AccessesStat returns the name of the bucket stat counting reads of the
+// design document's views.
+func (d DesignDocInfo) AccessesStat() string {
+	return fmt.Sprintf("views/%s/accesses", d.ViewIndex.Signature)
+}
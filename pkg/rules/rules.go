@@ -0,0 +1,277 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package rules generates Prometheus alerting rules from the exporter
+// configuration, so that rule expressions always use the names metrics are
+// actually exported under.
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	groupName             = "couchbase"
+	severityLabel         = "severity"
+	severityWarning       = "warning"
+	severityCritical      = "critical"
+	prometheusRuleAPI     = "monitoring.coreos.com/v1"
+	prometheusRuleKind    = "PrometheusRule"
+	defaultDiskQueueRange = "10m"
+	yamlIndent            = 2
+)
+
+// Thresholds parameterizes the generated rules.
+type Thresholds struct {
+	// NodeDownFor is how long a node must be unhealthy before alerting.
+	NodeDownFor time.Duration
+	// ResidentRatio is the active resident ratio percentage below which a
+	// bucket is considered to be under memory pressure.
+	ResidentRatio float64
+	// DiskQueue is the number of items in the disk write queue above which
+	// continued growth is alerted on.
+	DiskQueue float64
+	// RebalanceStuckFor is how long rebalance progress may remain unchanged.
+	RebalanceStuckFor time.Duration
+}
+
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		NodeDownFor:       time.Minute,
+		ResidentRatio:     10,
+		DiskQueue:         1000000,
+		RebalanceStuckFor: 30 * time.Minute,
+	}
+}
+
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// PrometheusRule is the prometheus-operator custom resource wrapping a RuleFile.
+type PrometheusRule struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       RuleFile `yaml:"spec"`
+}
+
+type Metadata struct {
+	Name string `yaml:"name"`
+}
+
+// ruleSpec describes a rule in terms of a collector and metric key.  Expr is
+// a format string that receives the metric name as every operand.
+type ruleSpec struct {
+	alert       string
+	collector   *objects.CollectorConfig
+	key         string
+	expr        string
+	duration    time.Duration
+	severity    string
+	summary     string
+	description string
+}
+
+// Generate builds the rule file.  Rules for metrics that are missing or
+// disabled in the configuration are left out.
+func Generate(config *objects.ExporterConfig, thresholds Thresholds) RuleFile {
+	c := config.Collectors
+	specs := []ruleSpec{
+		{
+			alert:       "CouchbaseNodeDown",
+			collector:   c.Node,
+			key:         "healthy",
+			expr:        "%[1]s == 0",
+			duration:    thresholds.NodeDownFor,
+			severity:    severityCritical,
+			summary:     "Couchbase node is not healthy",
+			description: "Node {{ $labels.node }} in cluster {{ $labels.cluster }} has not been healthy for " + formatDuration(thresholds.NodeDownFor) + ".",
+		},
+		{
+			alert:       "CouchbaseResidentRatioLow",
+			collector:   c.BucketStats,
+			key:         "VbActiveResidentItemsRatio",
+			expr:        "%[1]s < " + formatFloat(thresholds.ResidentRatio),
+			duration:    15 * time.Minute,
+			severity:    severityWarning,
+			summary:     "Couchbase bucket active resident ratio is low",
+			description: "Only {{ $value }}% of active items in bucket {{ $labels.bucket }} are resident in memory.",
+		},
+		{
+			alert:       "CouchbaseDiskQueueGrowing",
+			collector:   c.BucketStats,
+			key:         "DiskWriteQueue",
+			expr:        "%[1]s > " + formatFloat(thresholds.DiskQueue) + " and deriv(%[1]s[" + defaultDiskQueueRange + "]) > 0",
+			duration:    10 * time.Minute,
+			severity:    severityWarning,
+			summary:     "Couchbase disk write queue is growing",
+			description: "The disk write queue for bucket {{ $labels.bucket }} holds {{ $value }} items and is still growing.",
+		},
+		{
+			alert:       "CouchbaseOOMErrors",
+			collector:   c.BucketStats,
+			key:         "EpOomErrors",
+			expr:        "%[1]s > 0",
+			duration:    5 * time.Minute,
+			severity:    severityCritical,
+			summary:     "Couchbase bucket is rejecting writes",
+			description: "Bucket {{ $labels.bucket }} is returning out of memory errors to clients.",
+		},
+		{
+			alert:       "CouchbaseTemporaryOOMErrors",
+			collector:   c.BucketStats,
+			key:         "EpTmpOomErrors",
+			expr:        "%[1]s > 0",
+			duration:    5 * time.Minute,
+			severity:    severityWarning,
+			summary:     "Couchbase bucket is backing off clients",
+			description: "Bucket {{ $labels.bucket }} is returning temporary out of memory errors to clients.",
+		},
+		{
+			alert:       "CouchbaseRebalanceStuck",
+			collector:   c.Task,
+			key:         "rebalance",
+			expr:        "%[1]s > 0 and %[1]s < 100 and changes(%[1]s[" + formatDuration(thresholds.RebalanceStuckFor) + "]) == 0",
+			severity:    severityWarning,
+			summary:     "Couchbase rebalance is not progressing",
+			description: "Rebalance of cluster {{ $labels.cluster }} has been at {{ $value }}% for " + formatDuration(thresholds.RebalanceStuckFor) + ".",
+		},
+		{
+			alert:       "CouchbaseXdcrErrors",
+			collector:   c.Task,
+			key:         "xdcrErrors",
+			expr:        "%[1]s > 0",
+			duration:    10 * time.Minute,
+			severity:    severityWarning,
+			summary:     "Couchbase XDCR replication is reporting errors",
+			description: "The replication of bucket {{ $labels.bucket }} to {{ $labels.target }} has been reporting {{ $value }} errors.",
+		},
+		{
+			alert:       "CouchbaseXdcrCheckpointsFailing",
+			collector:   c.Task,
+			key:         "xdcrFailedCheckpoints",
+			expr:        "increase(%[1]s[15m]) > 0",
+			duration:    15 * time.Minute,
+			severity:    severityWarning,
+			summary:     "Couchbase XDCR replication is failing to checkpoint",
+			description: "The replication of bucket {{ $labels.bucket }} to {{ $labels.target }} keeps failing to checkpoint and will restart from its last checkpoint.",
+		},
+		{
+			alert:       "CouchbaseNoFailureTolerance",
+			collector:   c.Rollup,
+			key:         "rollupFailuresTolerated",
+			expr:        "%[1]s == 0",
+			duration:    15 * time.Minute,
+			severity:    severityWarning,
+			summary:     "Couchbase cluster cannot tolerate another node failure",
+			description: "Another node failure in cluster {{ $labels.cluster }} would lose data, as some bucket has no replica left to fail over to.",
+		},
+		{
+			alert:       "CouchbaseEncryptionDisabled",
+			collector:   c.Security,
+			key:         "clusterEncryption",
+			expr:        "%[1]s < max_over_time(%[1]s[1d])",
+			severity:    severityWarning,
+			summary:     "Couchbase node-to-node encryption was turned off",
+			description: "Node-to-node encryption of cluster {{ $labels.cluster }} was enabled within the last day and no longer is.",
+		},
+	}
+
+	group := RuleGroup{
+		Name:  groupName,
+		Rules: []Rule{},
+	}
+
+	for _, spec := range specs {
+		if spec.collector == nil {
+			continue
+		}
+
+		metric, ok := spec.collector.Lookup(spec.key)
+		if !ok {
+			continue
+		}
+
+		rule := Rule{
+			Alert:  spec.alert,
+			Expr:   fmt.Sprintf(spec.expr, metric.FQName(spec.collector.Namespace, spec.collector.Subsystem)),
+			Labels: map[string]string{severityLabel: spec.severity},
+			Annotations: map[string]string{
+				"summary":     spec.summary,
+				"description": spec.description,
+			},
+		}
+
+		if spec.duration > 0 {
+			rule.For = formatDuration(spec.duration)
+		}
+
+		group.Rules = append(group.Rules, rule)
+	}
+
+	return RuleFile{Groups: []RuleGroup{group}}
+}
+
+// AsPrometheusRule wraps the rule file in a prometheus-operator resource.
+func (r RuleFile) AsPrometheusRule(name string) PrometheusRule {
+	return PrometheusRule{
+		APIVersion: prometheusRuleAPI,
+		Kind:       prometheusRuleKind,
+		Metadata:   Metadata{Name: name},
+		Spec:       r,
+	}
+}
+
+// Marshal renders a RuleFile or PrometheusRule using the two space indentation
+// conventional for Prometheus and Kubernetes manifests.
+func Marshal(doc interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(yamlIndent)
+
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func formatDuration(d time.Duration) string {
+	return model.Duration(d).String()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
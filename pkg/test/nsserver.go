@@ -0,0 +1,372 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+// Package test provides a fake Couchbase Server cluster manager, ns_server,
+// for testing collectors against its REST API without a cluster.
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+)
+
+const (
+	// DefaultClusterName is the name of the cluster a new Server reports.
+	DefaultClusterName = "fake-cluster"
+	// DefaultNode is the hostname of the node a new Server starts with, which
+	// the exporter's client is connected to.
+	DefaultNode = "localhost:8091"
+
+	Username = "Administrator"
+	Password = "password"
+
+	rebalanceSuccess = "rebalance_success"
+)
+
+// Server is a fake ns_server serving buckets, nodes, bucket and per node
+// stats and tasks.  Its topology can be changed while collectors use it, and
+// any path can be made to fail.  It is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	pools       objects.Nodes
+	uuid        string
+	buckets     []objects.BucketInfo
+	bucketStats map[string]map[string][]float64
+	nodeStats   map[string]map[string]map[string]interface{}
+	tasks       []objects.Task
+	faults      map[string]int
+	requests    map[string]int
+}
+
+// NewServer starts a fake ns_server for a balanced cluster of one node and no
+// buckets.  It must be closed when no longer needed.
+func NewServer() *Server {
+	s := &Server{
+		pools: objects.Nodes{
+			ClusterName:     DefaultClusterName,
+			Balanced:        true,
+			RebalanceStatus: "none",
+			Counters:        map[string]float64{},
+			Nodes: []objects.Node{
+				{Hostname: DefaultNode, ThisNode: true, Status: "healthy", ClusterMembership: "active", Services: []string{"kv"}},
+			},
+		},
+		uuid:        "fake-cluster-uuid",
+		buckets:     []objects.BucketInfo{},
+		bucketStats: map[string]map[string][]float64{},
+		nodeStats:   map[string]map[string]map[string]interface{}{},
+		tasks:       []objects.Task{},
+		faults:      map[string]int{},
+		requests:    map[string]int{},
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+
+	return s
+}
+
+// CouchbaseClient returns a client of the server, authenticated as Username.
+func (s *Server) CouchbaseClient() util.Client {
+	u, _ := url.Parse(s.URL)
+	port, _ := strconv.Atoi(u.Port())
+
+	return util.NewClient(u.Scheme+"://"+u.Hostname(), port, Username, Password, nil)
+}
+
+// SetClusterName changes the name of the cluster.
+func (s *Server) SetClusterName(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pools.ClusterName = name
+}
+
+// AddNode adds a node to the cluster, leaving it unbalanced.
+func (s *Server) AddNode(node objects.Node) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pools.Nodes = append(s.pools.Nodes, node)
+	s.pools.Balanced = false
+}
+
+// RemoveNode removes the node with the given hostname from the cluster,
+// leaving it unbalanced, along with its per node stats.
+func (s *Server) RemoveNode(hostname string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	nodes := []objects.Node{}
+
+	for _, node := range s.pools.Nodes {
+		if node.Hostname != hostname {
+			nodes = append(nodes, node)
+		}
+	}
+
+	s.pools.Nodes = nodes
+	s.pools.Balanced = false
+
+	for _, stats := range s.nodeStats {
+		delete(stats, hostname)
+	}
+}
+
+// AddBucket adds a bucket, replacing any of the same name.
+func (s *Server) AddBucket(bucket objects.BucketInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeBucket(bucket.Name)
+	s.buckets = append(s.buckets, bucket)
+}
+
+// RemoveBucket removes the named bucket and its stats.
+func (s *Server) RemoveBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.removeBucket(name)
+	delete(s.bucketStats, name)
+	delete(s.nodeStats, name)
+}
+
+func (s *Server) removeBucket(name string) {
+	buckets := []objects.BucketInfo{}
+
+	for _, bucket := range s.buckets {
+		if bucket.Name != name {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	s.buckets = buckets
+}
+
+// SetBucketSamples sets the samples of the stats of a bucket, by stat.
+func (s *Server) SetBucketSamples(bucket string, samples map[string][]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bucketStats[bucket] = samples
+}
+
+// SetNodeSamples sets the samples of the stats of a bucket on one node, by
+// stat.  Samples are usually numbers, but may be anything Couchbase Server
+// can return, such as "undefined".
+func (s *Server) SetNodeSamples(bucket, hostname string, samples map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.nodeStats[bucket] == nil {
+		s.nodeStats[bucket] = map[string]map[string]interface{}{}
+	}
+
+	s.nodeStats[bucket][hostname] = samples
+}
+
+// SetTasks replaces the tasks that are running.
+func (s *Server) SetTasks(tasks []objects.Task) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tasks = tasks
+}
+
+// StartRebalance starts a rebalance, which runs until FinishRebalance.
+func (s *Server) StartRebalance(progress float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pools.RebalanceStatus = "running"
+	s.pools.Balanced = false
+	s.tasks = append(s.withoutRebalance(), objects.Task{Type: "rebalance", Status: "running", Progress: progress})
+}
+
+// FinishRebalance completes the running rebalance, if any, which balances
+// the cluster.
+func (s *Server) FinishRebalance() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pools.RebalanceStatus = "none"
+	s.pools.Balanced = true
+	s.pools.Counters[rebalanceSuccess]++
+	s.tasks = append(s.withoutRebalance(), objects.Task{Type: "rebalance", Status: "notRunning"})
+}
+
+func (s *Server) withoutRebalance() []objects.Task {
+	tasks := []objects.Task{}
+
+	for _, task := range s.tasks {
+		if task.Type != "rebalance" {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
+
+// Fail makes requests for path, such as "/pools/default/tasks", fail with
+// status, or succeed again if status is 0.
+func (s *Server) Fail(path string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if status == 0 {
+		delete(s.faults, path)
+		return
+	}
+
+	s.faults[path] = status
+}
+
+// Requests returns how many requests have been made for path.
+func (s *Server) Requests(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests[path]
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := "/" + strings.Trim(r.URL.Path, "/")
+	s.requests[path]++
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != Username || pass != Password {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if status, ok := s.faults[path]; ok {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	body, ok := s.route(strings.Split(strings.Trim(path, "/"), "/"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// route returns the body of the response for the path split into segments,
+// or false if the path is not served.
+func (s *Server) route(segments []string) (interface{}, bool) {
+	switch {
+	case match(segments, "pools"):
+		return objects.Pools{UUID: s.uuid, IsEnterprise: true, ImplementationVersion: "7.2.0-5325-enterprise"}, true
+	case match(segments, "pools", "default"), match(segments, "pools", "nodes"):
+		return s.pools, true
+	case match(segments, "pools", "default", "buckets"):
+		return s.buckets, true
+	case match(segments, "pools", "default", "tasks"):
+		return s.tasks, true
+	case match(segments, "whoami"):
+		return objects.WhoAmI{ID: Username, Domain: "local"}, true
+	case match(segments, "pools", "default", "buckets", "*", "stats"):
+		if !s.hasBucket(segments[3]) {
+			return nil, false
+		}
+
+		var stats objects.BucketStats
+		stats.Op.Samples = s.bucketStats[segments[3]]
+
+		return stats, true
+	case match(segments, "pools", "default", "buckets", "*", "nodes"):
+		if !s.hasBucket(segments[3]) {
+			return nil, false
+		}
+
+		return s.servers(segments[3]), true
+	case match(segments, "pools", "default", "buckets", "*", "nodes", "*", "stats"):
+		samples, ok := s.nodeStats[segments[3]][segments[5]]
+		if !ok && (!s.hasBucket(segments[3]) || !s.hasNode(segments[5])) {
+			return nil, false
+		}
+
+		// written as they were set, which objects.PerNodeBucketStats
+		// could not do for samples that are not numbers.
+		return map[string]interface{}{
+			"hostname": segments[5],
+			"op":       map[string]interface{}{"samples": samples},
+		}, true
+	}
+
+	return nil, false
+}
+
+func (s *Server) servers(bucket string) objects.Servers {
+	servers := objects.Servers{Servers: []objects.Server{}}
+
+	for _, node := range s.pools.Nodes {
+		servers.Servers = append(servers.Servers, objects.Server{
+			Hostname: node.Hostname,
+			Stats:    map[string]string{"uri": fmt.Sprintf("/pools/default/buckets/%s/nodes/%s/stats", bucket, url.PathEscape(node.Hostname))},
+		})
+	}
+
+	return servers
+}
+
+func (s *Server) hasBucket(name string) bool {
+	for _, bucket := range s.buckets {
+		if bucket.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) hasNode(hostname string) bool {
+	for _, node := range s.pools.Nodes {
+		if node.Hostname == hostname {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match reports whether segments matches pattern, in which "*" matches any
+// one segment.
+func match(segments []string, pattern ...string) bool {
+	if len(segments) != len(pattern) {
+		return false
+	}
+
+	for i, segment := range pattern {
+		if segment != "*" && segment != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,158 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	invalidCIDR string = "invalid CIDR"
+
+	rejectedNotAllowed   = "not_allowed"
+	rejectedTooMany      = "too_many_requests"
+	rejectedUnauthorized = "unauthorized"
+)
+
+var (
+	ErrInvalidCIDR = fmt.Errorf(invalidCIDR)
+
+	rejectedRequestsVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "http_requests_rejected_total",
+			Help:      "Number of requests that were rejected, by reason",
+		},
+		[]string{"reason"})
+)
+
+// allowlistHandler only passes on requests from clients in its networks.
+type allowlistHandler struct {
+	networks []*net.IPNet
+	handler  http.Handler
+}
+
+// NewAllowlistHandler wraps handler so that only clients with an address in
+// one of cidrs are served, and others are refused with 403.  Clients on a
+// Unix domain socket are always served.  handler is returned as it is if
+// cidrs is empty.
+func NewAllowlistHandler(cidrs []string, handler http.Handler) (http.Handler, error) {
+	if len(cidrs) == 0 {
+		return handler, nil
+	}
+
+	networks := []*net.IPNet{}
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalidCIDR, cidr, err)
+		}
+
+		networks = append(networks, network)
+	}
+
+	return &allowlistHandler{networks: networks, handler: handler}, nil
+}
+
+func (h *allowlistHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.allowed(r.RemoteAddr) {
+		rejectedRequestsVec.WithLabelValues(rejectedNotAllowed).Inc()
+		log.Debug("refusing request for %s from %s", r.URL.Path, r.RemoteAddr)
+		http.Error(w, "403 Forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	h.handler.ServeHTTP(w, r)
+}
+
+func (h *allowlistHandler) allowed(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		// the remote address of a Unix domain socket is not a host and port.
+		return remoteAddr == "" || remoteAddr == "@"
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range h.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewLimitHandler wraps handler so that at most max requests are served at
+// once, and any more are refused with 503 rather than queued, as each one may
+// make requests to Couchbase Server.  handler is returned as it is if max is
+// not positive.
+func NewLimitHandler(max int, handler http.Handler) http.Handler {
+	if max <= 0 {
+		return handler
+	}
+
+	inFlight := make(chan struct{}, max)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case inFlight <- struct{}{}:
+			defer func() { <-inFlight }()
+		default:
+			rejectedRequestsVec.WithLabelValues(rejectedTooMany).Inc()
+			http.Error(w, fmt.Sprintf("503 Service Unavailable, limit of %d concurrent requests reached", max), http.StatusServiceUnavailable)
+
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// NewTokenHandler wraps handler so that only requests with the bearer token
+// held in tokenFile are served, and others are refused with 401.  The file is
+// read on every request, so the token can be rotated without a restart.
+func NewTokenHandler(tokenFile string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := ioutil.ReadFile(tokenFile)
+		if err != nil {
+			log.Error("unable to read token for %s: %s", r.URL.Path, err)
+			http.Error(w, "500 Internal Server Error, unable to read bearer token", http.StatusInternalServerError)
+
+			return
+		}
+
+		expected := "Bearer " + strings.TrimSpace(string(token))
+		if strings.TrimSpace(string(token)) == "" ||
+			subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expected)) != 1 {
+			rejectedRequestsVec.WithLabelValues(rejectedUnauthorized).Inc()
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
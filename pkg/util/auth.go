@@ -0,0 +1,86 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+var (
+	// ErrEmptySPNEGOToken is returned when the SPNEGO command prints no token.
+	ErrEmptySPNEGOToken = errors.New("SPNEGO command printed no token")
+)
+
+// Authenticator sets the credentials of a request to Couchbase Server, so
+// that schemes other than the built in ones can be plugged into a Client with
+// NewClientWithAuthenticator.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to the Authenticator interface.
+type AuthenticatorFunc func(req *http.Request) error
+
+// Authenticate implements the Authenticator interface.
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error {
+	return f(req)
+}
+
+// Middleware wraps the transport a Client sends its requests through, once
+// they have been authenticated, so that embedders can sign them, add proxy
+// headers or pick a client certificate without forking the exporter.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements the RoundTripper interface.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// SPNEGOAuthenticator authenticates requests to Kerberos protected proxies
+// with SPNEGO.  The Kerberos exchange itself is left to Command, such as a
+// small GSSAPI helper, which is run for every request with the service
+// principal HTTP@<host> as its last argument and must print the base64
+// encoded SPNEGO token.  A token is only ever used once, Kerberos servers
+// rejecting replayed authenticators.
+type SPNEGOAuthenticator struct {
+	Command []string
+}
+
+// Authenticate implements the Authenticator interface.
+func (a SPNEGOAuthenticator) Authenticate(req *http.Request) error {
+	args := append(append([]string(nil), a.Command[1:]...), "HTTP@"+req.URL.Hostname())
+
+	var stderr bytes.Buffer
+
+	cmd := exec.CommandContext(req.Context(), a.Command[0], args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to get SPNEGO token: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return ErrEmptySPNEGOToken
+	}
+
+	req.Header.Set("Authorization", "Negotiate "+token)
+
+	return nil
+}
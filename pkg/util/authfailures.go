@@ -0,0 +1,227 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	unauthorized string = "authentication failed"
+	forbidden    string = "permission denied"
+)
+
+// DefaultAuthBackoff is how long requests that Couchbase Server rejected are
+// not made again for, unless the client is given a backoff of its own.
+const DefaultAuthBackoff = 5 * time.Minute
+
+var (
+	ErrUnauthorized = fmt.Errorf(unauthorized)
+	ErrForbidden    = fmt.Errorf(forbidden)
+	authFailuresVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "auth_failures_total",
+			Help:      "Number of requests Couchbase Server rejected with 401 or 403, by endpoint, which are not retried until the backoff has passed",
+		},
+		[]string{"endpoint", "status"})
+)
+
+// authState remembers requests that Couchbase Server rejected, so that bad
+// credentials are not retried on every scrape.  A 401 means the credentials
+// themselves are wrong and holds back all requests, whereas a 403 only applies
+// to the endpoint that returned it.  Either is only remembered for the
+// backoff, after which a single request probes whether the credentials or
+// roles have been corrected while the others are still held back, and is
+// forgotten as soon as a request succeeds, so that a node restarting or a
+// token being rotated does not stop collection for good.
+type authState struct {
+	mu                sync.Mutex
+	user              string
+	backoff           time.Duration
+	unauthorizedUntil time.Time
+	unauthorizedProbe string
+	forbiddenUntil    map[string]time.Time
+	forbiddenProbes   map[string]bool
+}
+
+func newAuthState(user string) *authState {
+	return &authState{
+		user:            user,
+		backoff:         DefaultAuthBackoff,
+		forbiddenUntil:  map[string]time.Time{},
+		forbiddenProbes: map[string]bool{},
+	}
+}
+
+func (a *authState) setBackoff(backoff time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.backoff = backoff
+}
+
+// check returns an error without making a request when path was rejected
+// within the backoff.  Once the backoff has passed it lets a single request
+// through to probe, returning true, and holds back the others until
+// doneProbing is called for it.
+func (a *authState) check(path string) (bool, error) {
+	if a == nil {
+		return false, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	probing := false
+
+	if !a.unauthorizedUntil.IsZero() {
+		if now.Before(a.unauthorizedUntil) {
+			return false, fmt.Errorf("%w for user %s, not requesting %s until %s", ErrUnauthorized, a.user, path, a.unauthorizedUntil.Format(time.RFC3339))
+		}
+
+		if a.unauthorizedProbe != "" {
+			return false, fmt.Errorf("%w for user %s, not requesting %s while %s probes whether the credentials were corrected",
+				ErrUnauthorized, a.user, path, a.unauthorizedProbe)
+		}
+
+		a.unauthorizedProbe = path
+		probing = true
+	}
+
+	if until, ok := a.forbiddenUntil[path]; ok {
+		if now.Before(until) || a.forbiddenProbes[path] {
+			if probing {
+				a.unauthorizedProbe = ""
+			}
+
+			if a.forbiddenProbes[path] {
+				return false, fmt.Errorf("%w for user %s on %s, not requesting it while another request probes whether it is permitted", ErrForbidden, a.user, path)
+			}
+
+			return false, fmt.Errorf("%w for user %s on %s, not requesting it until %s", ErrForbidden, a.user, path, until.Format(time.RFC3339))
+		}
+
+		a.forbiddenProbes[path] = true
+		probing = true
+	}
+
+	return probing, nil
+}
+
+// doneProbing lets the next request through to probe if the probe of path
+// did not find out whether it is still rejected, such as when it could not
+// reach Couchbase Server.
+func (a *authState) doneProbing(path string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.unauthorizedProbe == path {
+		a.unauthorizedProbe = ""
+	}
+
+	delete(a.forbiddenProbes, path)
+}
+
+// record inspects a response status, holding back requests after a rejection
+// and forgetting it after a success.
+func (a *authState) record(path string, status int) error {
+	if a == nil {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch status {
+	case http.StatusUnauthorized:
+		authFailuresVec.WithLabelValues(EndpointTemplate(path), strconv.Itoa(status)).Inc()
+
+		if a.unauthorizedUntil.IsZero() {
+			log.Error("Couchbase Server rejected the credentials of user %s when requesting %s. Check the configured username and password; "+
+				"no requests will be made for %s, after which they are tried again.", a.user, path, a.backoff)
+		}
+
+		a.unauthorizedUntil = time.Now().Add(a.backoff)
+
+		return fmt.Errorf("%w for user %s on %s", ErrUnauthorized, a.user, path)
+	case http.StatusForbidden:
+		authFailuresVec.WithLabelValues(EndpointTemplate(path), strconv.Itoa(status)).Inc()
+
+		if _, ok := a.forbiddenUntil[path]; !ok {
+			log.Error("User %s is not permitted to read %s. Grant the user a role with read access to it; "+
+				"the endpoint will not be requested for %s, after which it is tried again.", a.user, path, a.backoff)
+		}
+
+		a.forbiddenUntil[path] = time.Now().Add(a.backoff)
+
+		return fmt.Errorf("%w for user %s on %s", ErrForbidden, a.user, path)
+	}
+
+	if status >= 200 && status < 300 {
+		if !a.unauthorizedUntil.IsZero() {
+			log.Info("Couchbase Server accepted the credentials of user %s again", a.user)
+
+			a.unauthorizedUntil = time.Time{}
+		}
+
+		if _, ok := a.forbiddenUntil[path]; ok {
+			log.Info("User %s is permitted to read %s again", a.user, path)
+
+			delete(a.forbiddenUntil, path)
+		}
+	}
+
+	return nil
+}
+
+// EndpointTemplate replaces the names of buckets, nodes, stats, backup
+// repositories and design documents in path with placeholders, and drops its
+// query, so that the endpoints of every bucket and node share a label value.
+func EndpointTemplate(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	segments := strings.Split(path, "/")
+
+	for i := range segments {
+		previous := ""
+		if i > 0 {
+			previous = segments[i-1]
+		}
+
+		switch {
+		case previous == "buckets" && strings.HasPrefix(segments[i], "@xdcr-"):
+			segments[i] = "@xdcr-{bucket}"
+		case previous == "buckets" && !strings.HasPrefix(segments[i], "@"):
+			segments[i] = "{bucket}"
+		case previous == "nodes" && segments[i] != "":
+			segments[i] = "{node}"
+		case previous == "range":
+			segments[i] = "{stat}"
+		case previous == "active":
+			segments[i] = "{repository}"
+		case previous+"/" == objects.DesignDocPrefix:
+			segments[i] = "{ddoc}"
+		case i == 0 && len(segments) > 1 && segments[1]+"/" == objects.DesignDocPrefix:
+			segments[i] = "{bucket}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,55 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"context"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+// bucketFilterClient lists only the buckets a filter selects, so that the
+// collectors that go through the buckets of the cluster skip the others.
+type bucketFilterClient struct {
+	CbClient
+	matches func(bucket string) bool
+}
+
+// NewBucketFilterClient wraps client to list only the buckets the filter
+// selects, returning it as it is if the filter is empty.
+func NewBucketFilterClient(client CbClient, filter objects.BucketFilter) (CbClient, error) {
+	if filter.Empty() {
+		return client, nil
+	}
+
+	matches, err := filter.Matcher()
+	if err != nil {
+		return nil, err
+	}
+
+	return &bucketFilterClient{CbClient: client, matches: matches}, nil
+}
+
+func (c *bucketFilterClient) Buckets(ctx context.Context) ([]objects.BucketInfo, error) {
+	buckets, err := c.CbClient.Buckets(ctx)
+	if err != nil {
+		return buckets, err
+	}
+
+	filtered := make([]objects.BucketInfo, 0, len(buckets))
+
+	for _, bucket := range buckets {
+		if c.matches(bucket.Name) {
+			filtered = append(filtered, bucket)
+		}
+	}
+
+	return filtered, nil
+}
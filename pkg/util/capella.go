@@ -0,0 +1,136 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/version"
+	"github.com/pkg/errors"
+)
+
+const capellaTimeout = 30 * time.Second
+
+// CapellaClient reads clusters hosted in Couchbase Capella through its public API.
+type CapellaClient interface {
+	Cluster(projectID, clusterID string) (objects.CapellaCluster, error)
+	Buckets(projectID, clusterID string) ([]objects.CapellaBucket, error)
+}
+
+// CapellaAPIClient is the Capella public API client.
+type CapellaAPIClient struct {
+	url          string
+	organization string
+	Client       http.Client
+}
+
+// NewCapellaClient creates a client for the Capella public API at url that
+// authenticates with the secret of an API key.
+func NewCapellaClient(url, organization, apiKey string) CapellaAPIClient {
+	var transport http.RoundTripper
+
+	if FIPSEnabled() {
+		transport = NewTransport(&tls.Config{}, http.DefaultMaxIdleConnsPerHost)
+	}
+
+	return CapellaAPIClient{
+		url:          strings.TrimSuffix(url, "/"),
+		organization: organization,
+		Client: http.Client{
+			Timeout: capellaTimeout,
+			Transport: &BearerTransport{
+				Token:     apiKey,
+				Transport: transport,
+			},
+		},
+	}
+}
+
+func (c CapellaAPIClient) clusterPath(projectID, clusterID string) string {
+	return fmt.Sprintf("v4/organizations/%s/projects/%s/clusters/%s", c.organization, projectID, clusterID)
+}
+
+// Cluster returns the results of /v4/organizations/<org>/projects/<project>/clusters/<cluster>.
+func (c CapellaAPIClient) Cluster(projectID, clusterID string) (objects.CapellaCluster, error) {
+	var cluster objects.CapellaCluster
+	err := c.get(c.clusterPath(projectID, clusterID), &cluster)
+
+	return cluster, errors.Wrap(err, "failed to Get Capella cluster")
+}
+
+// Buckets returns the results of /v4/organizations/<org>/projects/<project>/clusters/<cluster>/buckets.
+func (c CapellaAPIClient) Buckets(projectID, clusterID string) ([]objects.CapellaBucket, error) {
+	var buckets objects.CapellaBuckets
+	err := c.get(c.clusterPath(projectID, clusterID)+"/buckets", &buckets)
+
+	return buckets.Data, errors.Wrap(err, "failed to Get Capella buckets")
+}
+
+func (c CapellaAPIClient) get(path string, v interface{}) (err error) {
+	defer func() {
+		err = RedactError(err)
+	}()
+
+	resp, err := c.Client.Get(fmt.Sprintf("%s/%s", c.url, path))
+	if err != nil {
+		return errors.Wrapf(err, "failed to Get %s", path)
+	}
+	defer resp.Body.Close()
+
+	bts, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response body from %s", path)
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w for the Capella API key on %s", ErrForbidden, path)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("failed to Get 200 response status: %d", resp.StatusCode)
+	}
+
+	if err := json.Unmarshal(bts, v); err != nil {
+		return errors.Wrapf(err, "failed to unmarshall %s output: %s", path, string(bts))
+	}
+
+	return nil
+}
+
+// BearerTransport is a http.RoundTripper that authenticates with a bearer token.
+type BearerTransport struct {
+	Token string
+
+	Transport http.RoundTripper
+}
+
+func (t *BearerTransport) transport() http.RoundTripper {
+	if t.Transport != nil {
+		return t.Transport
+	}
+
+	return http.DefaultTransport
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req2 := req.Clone(req.Context())
+	req2.Header.Set("Authorization", "Bearer "+t.Token)
+	req2.Header.Set("User-Agent", version.UserAgent())
+
+	return t.transport().RoundTrip(req2)
+}
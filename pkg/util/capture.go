@@ -0,0 +1,212 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+// Capture decides which requests to Couchbase Server have their bodies, and
+// those of their responses, logged.  It is shared by the transports of every
+// client, and started and stopped through the admin API while the exporter
+// runs.
+type Capture struct {
+	mutex     sync.Mutex
+	endpoints *regexp.Regexp
+	until     time.Time
+	window    time.Duration
+	maxBytes  int
+}
+
+// CaptureState is whether, and until when, bodies are captured.
+type CaptureState struct {
+	Endpoints string     `json:"endpoints"`
+	Until     *time.Time `json:"until,omitempty"`
+	MaxBytes  int        `json:"maxBytes"`
+}
+
+// NewCapture creates a capture of the configured endpoints for the configured
+// window from now, if any endpoints are configured.
+func NewCapture(config objects.DebugCaptureConfig) (*Capture, error) {
+	c := &Capture{
+		window:   time.Duration(config.Window) * time.Second,
+		maxBytes: config.MaxBytes,
+	}
+
+	if config.Endpoints == "" {
+		return c, nil
+	}
+
+	if err := c.Start(config.Endpoints, 0); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Start captures the endpoints whose path matches the endpoints regular
+// expression for window, or the configured window if that is zero.
+func (c *Capture) Start(endpoints string, window time.Duration) error {
+	re, err := objects.CompileCaptureEndpoints(endpoints)
+	if err != nil {
+		return err
+	}
+
+	if window <= 0 {
+		window = c.window
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.endpoints = re
+	c.until = time.Now().Add(window)
+
+	log.Warn("capturing the bodies of requests to %s until %s", endpoints, c.until.Format(time.RFC3339))
+
+	return nil
+}
+
+// Stop stops capturing.
+func (c *Capture) Stop() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.endpoints != nil {
+		log.Info("stopped capturing the bodies of requests to %s", c.endpoints)
+	}
+
+	c.endpoints = nil
+}
+
+// State returns the endpoints being captured and until when, if any.
+func (c *Capture) State() CaptureState {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state := CaptureState{MaxBytes: c.maxBytes}
+
+	if c.endpoints != nil && time.Now().Before(c.until) {
+		until := c.until
+		state.Endpoints = c.endpoints.String()
+		state.Until = &until
+	}
+
+	return state
+}
+
+// matches reports whether the endpoint at path is being captured.
+func (c *Capture) matches(path string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.endpoints != nil && time.Now().Before(c.until) && c.endpoints.MatchString(path)
+}
+
+// truncate redacts body and cuts it short at the capture's maximum size.
+func (c *Capture) truncate(body []byte) string {
+	s := log.Redact(string(body))
+
+	if c.maxBytes > 0 && len(s) > c.maxBytes {
+		return fmt.Sprintf("%s... (%d bytes truncated)", s[:c.maxBytes], len(s)-c.maxBytes)
+	}
+
+	return s
+}
+
+// CaptureTransport logs the bodies of the requests made through it, and of
+// their responses, while their endpoint is being captured.
+type CaptureTransport struct {
+	capture   *Capture
+	transport http.RoundTripper
+}
+
+func NewCaptureTransport(capture *Capture, transport http.RoundTripper) *CaptureTransport {
+	return &CaptureTransport{capture: capture, transport: transport}
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *CaptureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.capture.matches(req.URL.Path) {
+		return t.transport.RoundTrip(req)
+	}
+
+	var reqBody []byte
+
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			reqBody, _ = ioutil.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		t.log(req, reqBody, fmt.Sprintf("failed: %s", err))
+
+		return nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		t.log(req, reqBody, fmt.Sprintf("returned %d, reading the body failed after %d bytes: %s, body: %s", resp.StatusCode, len(body), err,
+			t.capture.truncate(body)))
+
+		// the response still fails where it did, so that the capture does not
+		// change the error.
+		resp.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+
+		return resp, nil
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.log(req, reqBody, fmt.Sprintf("returned %d with %d bytes, body: %s", resp.StatusCode, len(body), t.capture.truncate(body)))
+
+	return resp, nil
+}
+
+// log logs the captured request, quoting its ID and body if it has them, and
+// its outcome.
+func (t *CaptureTransport) log(req *http.Request, reqBody []byte, outcome string) {
+	msg := fmt.Sprintf("captured %s %s", req.Method, req.URL.Path)
+
+	if id := req.Header.Get(RequestIDHeader); id != "" {
+		msg += fmt.Sprintf(" (%s %s)", RequestIDHeader, id)
+	}
+
+	if len(reqBody) > 0 {
+		msg += fmt.Sprintf(" with body: %s,", t.capture.truncate(reqBody))
+	}
+
+	log.Info("%s %s", msg, outcome)
+}
+
+// errReader fails every read with err.
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
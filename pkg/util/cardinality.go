@@ -0,0 +1,184 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"sync"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	seriesLabels        = []string{"metric"}
+	seriesDroppedLabels = []string{"metric", objects.ClusterLabel}
+
+	seriesOpts = objects.RecordExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "series",
+			Help:      "Number of series of the metric exported by the most recent scrape",
+		},
+		seriesLabels)
+	seriesDroppedOpts = objects.RecordExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "series_dropped",
+			Help:      "Number of series of the metric for the cluster dropped by the most recent scrape because they were over the series limit",
+		},
+		seriesDroppedLabels)
+)
+
+// seriesLimitGatherer caps the number of series each metric family may have
+// for each cluster, so that a cluster with thousands of buckets and nodes
+// cannot overwhelm Prometheus.  Series already exported are kept in favour of
+// new ones, so the series exported stay the same while a family is limited.
+type seriesLimitGatherer struct {
+	gatherer prometheus.Gatherer
+	limit    int
+	registry *prometheus.Registry
+	series   *prometheus.GaugeVec
+	dropped  *prometheus.GaugeVec
+
+	mutex    sync.Mutex
+	admitted map[string]map[string]map[string]bool
+	limited  map[string]map[string]bool
+}
+
+// NewSeriesLimitGatherer wraps a gatherer so that no metric family has more
+// than limit series for any one cluster, reporting the number of series of
+// each family and the number dropped.  A limit of 0 means no limit.
+func NewSeriesLimitGatherer(gatherer prometheus.Gatherer, limit int) prometheus.Gatherer {
+	if limit <= 0 {
+		return gatherer
+	}
+
+	g := &seriesLimitGatherer{
+		gatherer: gatherer,
+		limit:    limit,
+		registry: prometheus.NewRegistry(),
+		series:   prometheus.NewGaugeVec(seriesOpts, seriesLabels),
+		dropped:  prometheus.NewGaugeVec(seriesDroppedOpts, seriesDroppedLabels),
+		admitted: map[string]map[string]map[string]bool{},
+		limited:  map[string]map[string]bool{},
+	}
+
+	g.registry.MustRegister(g.series, g.dropped)
+
+	return g
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *seriesLimitGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	g.mutex.Lock()
+	g.series.Reset()
+	g.dropped.Reset()
+
+	for _, family := range families {
+		family.Metric = g.limitFamily(family.GetName(), family.Metric)
+		g.series.WithLabelValues(family.GetName()).Set(float64(len(family.Metric)))
+	}
+	g.mutex.Unlock()
+
+	own, ownErr := g.registry.Gather()
+	if err == nil {
+		err = ownErr
+	}
+
+	return append(families, own...), err
+}
+
+// limitFamily returns the metrics of the family that are within the limit for
+// their cluster.
+func (g *seriesLimitGatherer) limitFamily(name string, metrics []*dto.Metric) []*dto.Metric {
+	previous := g.admitted[name]
+	admitted := map[string]map[string]bool{}
+	keys := make([]string, len(metrics))
+
+	// series exported by the last scrape keep their place.
+	for i, metric := range metrics {
+		cluster := clusterOf(metric)
+		key := seriesKey(name, metric)
+		keys[i] = key
+
+		if admitted[cluster] == nil {
+			admitted[cluster] = map[string]bool{}
+		}
+
+		if previous[cluster][key] && len(admitted[cluster]) < g.limit {
+			admitted[cluster][key] = true
+		}
+	}
+
+	dropped := map[string]int{}
+	kept := metrics[:0]
+
+	for i, metric := range metrics {
+		cluster := clusterOf(metric)
+
+		if !admitted[cluster][keys[i]] {
+			if len(admitted[cluster]) >= g.limit {
+				dropped[cluster]++
+				continue
+			}
+
+			admitted[cluster][keys[i]] = true
+		}
+
+		kept = append(kept, metric)
+	}
+
+	g.admitted[name] = admitted
+	g.logLimited(name, dropped)
+
+	return kept
+}
+
+// logLimited reports each family and cluster once when it goes over the
+// limit, and again once it is back under it.
+func (g *seriesLimitGatherer) logLimited(name string, dropped map[string]int) {
+	limited := map[string]bool{}
+
+	for cluster, count := range dropped {
+		g.dropped.WithLabelValues(name, cluster).Set(float64(count))
+
+		limited[cluster] = true
+
+		if !g.limited[name][cluster] {
+			log.Error("metric %s of cluster %q has more than %d series, %d were dropped", name, cluster, g.limit, count)
+		}
+	}
+
+	for cluster := range g.limited[name] {
+		if !limited[cluster] {
+			log.Info("metric %s of cluster %q is back under %d series", name, cluster, g.limit)
+		}
+	}
+
+	if len(limited) == 0 {
+		delete(g.limited, name)
+	} else {
+		g.limited[name] = limited
+	}
+}
+
+func clusterOf(metric *dto.Metric) string {
+	for _, label := range metric.Label {
+		if label.GetName() == objects.ClusterLabel {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,124 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"sort"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// clusterRoleGatherer labels every series of a cluster with its role.
+type clusterRoleGatherer struct {
+	gatherer prometheus.Gatherer
+	role     string
+}
+
+// NewClusterRoleGatherer wraps the gatherer of a cluster so that every series
+// with a cluster label is also labelled with the cluster's role, if it has
+// one.  The exporter's own metrics, which have no cluster label, are left as
+// they are.
+func NewClusterRoleGatherer(gatherer prometheus.Gatherer, role string) prometheus.Gatherer {
+	if role == "" {
+		return gatherer
+	}
+
+	return &clusterRoleGatherer{
+		gatherer: gatherer,
+		role:     role,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *clusterRoleGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			if clusterOf(metric) == "" || clusterRoleOf(metric) != "" {
+				continue
+			}
+
+			metric.Label = append(metric.Label, labelPair(objects.ClusterRoleLabel, g.role))
+
+			sort.Slice(metric.Label, func(i, j int) bool {
+				return metric.Label[i].GetName() < metric.Label[j].GetName()
+			})
+		}
+	}
+
+	return families, err
+}
+
+func clusterRoleOf(metric *dto.Metric) string {
+	for _, label := range metric.Label {
+		if label.GetName() == objects.ClusterRoleLabel {
+			return label.GetValue()
+		}
+	}
+
+	return ""
+}
+
+// standbyGatherer drops the series of standby clusters of the suppressed
+// metrics.
+type standbyGatherer struct {
+	gatherer prometheus.Gatherer
+	metrics  map[string]bool
+}
+
+// NewStandbyGatherer wraps a gatherer so that the series of standby clusters
+// of the metrics in config are dropped, if their suppression is enabled.
+func NewStandbyGatherer(gatherer prometheus.Gatherer, config objects.ClusterRoleConfig) prometheus.Gatherer {
+	if !config.SuppressOnStandby || len(config.Metrics) == 0 {
+		return gatherer
+	}
+
+	metrics := map[string]bool{}
+	for _, name := range config.Metrics {
+		metrics[name] = true
+	}
+
+	return &standbyGatherer{
+		gatherer: gatherer,
+		metrics:  metrics,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *standbyGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	kept := families[:0]
+
+	for _, family := range families {
+		if !g.metrics[family.GetName()] {
+			kept = append(kept, family)
+			continue
+		}
+
+		metrics := family.Metric[:0]
+
+		for _, metric := range family.Metric {
+			if clusterRoleOf(metric) != objects.ClusterRoleStandby {
+				metrics = append(metrics, metric)
+			}
+		}
+
+		if len(metrics) > 0 {
+			family.Metric = metrics
+			kept = append(kept, family)
+		}
+	}
+
+	return kept, err
+}
@@ -1,12 +1,34 @@
 // This struct/interfaces combo sets up a process that executes on
 // a periodic cycle.  Creating a CycleController allows you to subscribe/unsubscribe
 // with a Worker interface with a DoWork method.  This DoWork method will be
-// executed at the specified interval of the CycleController.
+// executed at the specified interval of the CycleController, with a context
+// that is cancelled when the CycleController is stopped.
 
 package util
 
 import (
+	"context"
 	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cycleLagVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "cycle_lag_seconds",
+			Help:      "How much longer than the refresh interval passed between the starts of the last two cycles, as when a cycle overruns the interval",
+		},
+		[]string{"cluster"})
+	nextCollectionVec = objects.NewExporterGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "collector_next_collection_timestamp_seconds",
+			Help:      "When the collector is next expected to collect, the start of the next cycle",
+		},
+		[]string{"cluster", "collector"})
 )
 
 type CycleController interface {
@@ -17,26 +39,49 @@ type CycleController interface {
 }
 
 type cycleController struct {
+	cluster      string
 	interval     int
 	workers      *[]*Worker
-	timer        time.Ticker
+	timer        *time.Ticker
 	done         chan bool
 	workerUpdate chan *[]*Worker
 	processing   bool
+	ctx          context.Context
+	cancel       context.CancelFunc
 }
 
 type Worker interface {
-	DoWork()
+	DoWork(context.Context)
+}
+
+// NamedWorker is a worker that reports when it will next collect, under its
+// name.
+type NamedWorker interface {
+	Worker
+	WorkerName() string
 }
 
 func NewCycleController(intervalMilliseconds int) CycleController {
+	return NewClusterCycleController("", intervalMilliseconds)
+}
+
+// NewClusterCycleController returns a cycle controller for the workers of a
+// cluster, named as in the configuration, which reports its lag and when its
+// workers next collect under the cluster's name.  Each cluster has a cycle of
+// its own, so that a slow cluster does not hold up the others.
+func NewClusterCycleController(cluster string, intervalMilliseconds int) CycleController {
+	ctx, cancel := context.WithCancel(context.Background())
+
 	cycle := cycleController{
+		cluster:      cluster,
 		interval:     intervalMilliseconds * int(time.Millisecond),
 		workers:      &[]*Worker{},
-		timer:        *time.NewTicker(time.Duration(intervalMilliseconds * int(time.Millisecond))),
+		timer:        time.NewTicker(time.Duration(intervalMilliseconds * int(time.Millisecond))),
 		done:         make(chan bool),
 		workerUpdate: make(chan *[]*Worker, 1),
 		processing:   false,
+		ctx:          ctx,
+		cancel:       cancel,
 	}
 
 	return &cycle
@@ -79,8 +124,16 @@ func (c *cycleController) Start() {
 	c.timer.Reset(time.Duration(c.interval))
 	c.processing = true
 
-	go func(t *time.Ticker, d *chan bool, workers *[]*Worker, workersUpdate *chan *[]*Worker) {
+	// a stopped cycle may be started again.
+	if c.ctx.Err() != nil {
+		c.ctx, c.cancel = context.WithCancel(context.Background())
+	}
+
+	go func(ctx context.Context, t *time.Ticker, d *chan bool, workers *[]*Worker, workersUpdate *chan *[]*Worker) {
 		currWorkers := workers
+		interval := time.Duration(c.interval)
+
+		var lastStart time.Time
 
 		for {
 			select {
@@ -88,20 +141,45 @@ func (c *cycleController) Start() {
 				currWorkers = nw
 			case <-*d:
 				return
-			case <-t.C:
+			case tick := <-t.C:
+				start := time.Now()
+				if !lastStart.IsZero() {
+					cycleLagVec.WithLabelValues(c.cluster).Set((start.Sub(lastStart) - interval).Seconds())
+				}
+
+				lastStart = start
+
 				for _, worker := range *currWorkers {
 					if worker != nil {
 						w := *worker
-						w.DoWork()
+						w.DoWork(ctx)
+
+						if named, ok := w.(NamedWorker); ok {
+							nextCollectionVec.WithLabelValues(c.cluster, named.WorkerName()).Set(float64(nextCycle(tick, interval, time.Now()).Unix()))
+						}
 					}
 				}
 			}
 		}
-	}(&c.timer, &c.done, c.workers, &c.workerUpdate)
+	}(c.ctx, c.timer, &c.done, c.workers, &c.workerUpdate)
 }
 
+// Stop stops the cycle, cancelling the context of any work in progress.
 func (c *cycleController) Stop() {
+	c.cancel()
 	c.done <- true
 	c.timer.Stop()
 	c.processing = false
 }
+
+// nextCycle returns when the cycle after the one started by tick will start:
+// at the next tick, or as soon as this cycle ends if it overran the interval,
+// as the ticker keeps the tick it could not deliver.
+func nextCycle(tick time.Time, interval time.Duration, now time.Time) time.Time {
+	next := tick.Add(interval)
+	if next.Before(now) {
+		return now
+	}
+
+	return next
+}
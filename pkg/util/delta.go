@@ -0,0 +1,159 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	deltaHeartbeatOpts = objects.RecordExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "delta_heartbeat_timestamp_seconds",
+			Help:      "Unix time of this delta scrape, which is always served so that a scrape with no changes is told apart from a failed one",
+		},
+		nil)
+	deltaUnchangedOpts = objects.RecordExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "delta_series_unchanged",
+			Help:      "Number of series left out of this delta scrape because they had not changed since the last one",
+		},
+		nil)
+	deltaFullOpts = objects.RecordExporterGauge(
+		prometheus.GaugeOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "delta_full",
+			Help:      "1 if every series is served in this delta scrape, 0 if only those that changed are",
+		},
+		nil)
+)
+
+// deltaGatherer only returns the series whose value changed since it was
+// last gathered, and every series once each full interval.
+type deltaGatherer struct {
+	gatherer     prometheus.Gatherer
+	fullInterval time.Duration
+
+	mu       sync.Mutex
+	last     map[string]deltaValue
+	lastFull time.Time
+}
+
+// deltaValue is what a series is compared by: its value, or the count and sum
+// of a histogram or summary.
+type deltaValue struct {
+	value uint64
+	count uint64
+}
+
+// NewDeltaGatherer wraps a gatherer so that each gathering only returns the
+// series that are new or changed since the last, with a heartbeat.  Series
+// that disappear cannot be told apart from those that did not change, so
+// every series is returned once each fullInterval, unless it is 0.
+func NewDeltaGatherer(gatherer prometheus.Gatherer, fullInterval time.Duration) prometheus.Gatherer {
+	return &deltaGatherer{
+		gatherer:     gatherer,
+		fullInterval: fullInterval,
+		last:         map[string]deltaValue{},
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *deltaGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	full := len(g.last) == 0 || (g.fullInterval > 0 && now.Sub(g.lastFull) >= g.fullInterval)
+
+	if full {
+		g.lastFull = now
+	}
+
+	current := make(map[string]deltaValue, len(g.last))
+	kept := families[:0]
+	unchanged := 0
+
+	for _, family := range families {
+		metrics := family.Metric[:0]
+
+		for _, metric := range family.Metric {
+			key := seriesKey(family.GetName(), metric)
+			value := deltaValueOf(metric)
+			current[key] = value
+
+			if last, ok := g.last[key]; ok && last == value && !full {
+				unchanged++
+				continue
+			}
+
+			metrics = append(metrics, metric)
+		}
+
+		if len(metrics) > 0 {
+			family.Metric = metrics
+			kept = append(kept, family)
+		}
+	}
+
+	g.last = current
+
+	fullValue := 0.0
+	if full {
+		fullValue = 1
+	}
+
+	kept = append(kept,
+		gaugeFamily(deltaHeartbeatOpts, float64(now.UnixNano())/float64(time.Second)),
+		gaugeFamily(deltaUnchangedOpts, float64(unchanged)),
+		gaugeFamily(deltaFullOpts, fullValue))
+
+	return kept, err
+}
+
+func deltaValueOf(metric *dto.Metric) deltaValue {
+	switch {
+	case metric.Gauge != nil:
+		return deltaValue{value: math.Float64bits(metric.GetGauge().GetValue())}
+	case metric.Counter != nil:
+		return deltaValue{value: math.Float64bits(metric.GetCounter().GetValue())}
+	case metric.Untyped != nil:
+		return deltaValue{value: math.Float64bits(metric.GetUntyped().GetValue())}
+	case metric.Histogram != nil:
+		return deltaValue{value: math.Float64bits(metric.GetHistogram().GetSampleSum()), count: metric.GetHistogram().GetSampleCount()}
+	case metric.Summary != nil:
+		return deltaValue{value: math.Float64bits(metric.GetSummary().GetSampleSum()), count: metric.GetSummary().GetSampleCount()}
+	default:
+		return deltaValue{}
+	}
+}
+
+func gaugeFamily(opts prometheus.GaugeOpts, value float64) *dto.MetricFamily {
+	name := prometheus.BuildFQName(opts.Namespace, opts.Subsystem, opts.Name)
+	help := opts.Help
+	gauge := dto.MetricType_GAUGE
+
+	return &dto.MetricFamily{
+		Name:   &name,
+		Help:   &help,
+		Type:   &gauge,
+		Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: &value}}},
+	}
+}
@@ -0,0 +1,366 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	invalidDerivedMetric string = "invalid derived metric"
+	derivedMetricHelp    string = "Derived from the configured expression"
+)
+
+var (
+	ErrInvalidDerivedMetric = fmt.Errorf(invalidDerivedMetric)
+)
+
+// expr is a node of a parsed derived metric expression.
+type expr interface {
+	// eval returns the value of the node for a label set, and false if a
+	// metric has no series for it or it divides by zero.
+	eval(series map[string]map[string]float64, key string) (float64, bool)
+}
+
+type numberExpr float64
+
+func (n numberExpr) eval(map[string]map[string]float64, string) (float64, bool) {
+	return float64(n), true
+}
+
+type metricExpr string
+
+func (m metricExpr) eval(series map[string]map[string]float64, key string) (float64, bool) {
+	value, ok := series[string(m)][key]
+	return value, ok
+}
+
+type negateExpr struct {
+	operand expr
+}
+
+func (n negateExpr) eval(series map[string]map[string]float64, key string) (float64, bool) {
+	value, ok := n.operand.eval(series, key)
+	return -value, ok
+}
+
+type binaryExpr struct {
+	op          byte
+	left, right expr
+}
+
+func (b binaryExpr) eval(series map[string]map[string]float64, key string) (float64, bool) {
+	left, ok := b.left.eval(series, key)
+	if !ok {
+		return 0, false
+	}
+
+	right, ok := b.right.eval(series, key)
+	if !ok {
+		return 0, false
+	}
+
+	switch b.op {
+	case '+':
+		return left + right, true
+	case '-':
+		return left - right, true
+	case '*':
+		return left * right, true
+	default:
+		if right == 0 {
+			return 0, false
+		}
+
+		return left / right, true
+	}
+}
+
+// exprParser parses expressions by recursive descent, * and / binding more
+// tightly than + and -.
+type exprParser struct {
+	input   string
+	pos     int
+	metrics []string
+}
+
+func parseExpr(input string) (expr, []string, error) {
+	p := &exprParser{input: input}
+
+	e, err := p.sum()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, nil, fmt.Errorf("unexpected %q at %d", p.input[p.pos:], p.pos)
+	}
+
+	if len(p.metrics) == 0 {
+		return nil, nil, fmt.Errorf("%q refers to no metric", input)
+	}
+
+	return e, p.metrics, nil
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *exprParser) sum() (expr, error) {
+	left, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.skipSpace(); p.pos < len(p.input) && (p.input[p.pos] == '+' || p.input[p.pos] == '-'); p.skipSpace() {
+		op := p.input[p.pos]
+		p.pos++
+
+		right, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) product() (expr, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.skipSpace(); p.pos < len(p.input) && (p.input[p.pos] == '*' || p.input[p.pos] == '/'); p.skipSpace() {
+		op := p.input[p.pos]
+		p.pos++
+
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *exprParser) unary() (expr, error) {
+	p.skipSpace()
+
+	if p.pos < len(p.input) && p.input[p.pos] == '-' {
+		p.pos++
+
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return negateExpr{operand: operand}, nil
+	}
+
+	return p.operand()
+}
+
+func (p *exprParser) operand() (expr, error) {
+	if p.pos >= len(p.input) {
+		return nil, fmt.Errorf("unexpected end of %q", p.input)
+	}
+
+	start := p.pos
+	c := rune(p.input[p.pos])
+
+	switch {
+	case c == '(':
+		p.pos++
+
+		e, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.skipSpace(); p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing ) in %q", p.input)
+		}
+
+		p.pos++
+
+		return e, nil
+	case unicode.IsDigit(c) || c == '.':
+		for p.pos < len(p.input) && (unicode.IsDigit(rune(p.input[p.pos])) || strings.ContainsRune(".eE", rune(p.input[p.pos]))) {
+			p.pos++
+		}
+
+		value, err := strconv.ParseFloat(p.input[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", p.input[start:p.pos])
+		}
+
+		return numberExpr(value), nil
+	case c == '_' || c == ':' || unicode.IsLetter(c):
+		for p.pos < len(p.input) && isMetricNameChar(rune(p.input[p.pos])) {
+			p.pos++
+		}
+
+		name := p.input[start:p.pos]
+		p.metrics = append(p.metrics, name)
+
+		return metricExpr(name), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d", string(c), p.pos)
+	}
+}
+
+func isMetricNameChar(c rune) bool {
+	return c == '_' || c == ':' || (c < unicode.MaxASCII && (unicode.IsLetter(c) || unicode.IsDigit(c)))
+}
+
+type derivedMetric struct {
+	objects.DerivedMetric
+	expr    expr
+	metrics []string
+}
+
+// derivedGatherer adds the configured derived metrics to everything gathered.
+type derivedGatherer struct {
+	gatherer prometheus.Gatherer
+	metrics  []derivedMetric
+}
+
+// NewDerivedGatherer wraps a gatherer so that it also returns the derived
+// metrics, each a gauge with a series for every label set that all the
+// metrics in its expression share.  A label set for which the expression
+// divides by zero is left out.
+func NewDerivedGatherer(gatherer prometheus.Gatherer, metrics []objects.DerivedMetric) (prometheus.Gatherer, error) {
+	if len(metrics) == 0 {
+		return gatherer, nil
+	}
+
+	parsed := make([]derivedMetric, 0, len(metrics))
+	names := map[string]bool{}
+
+	for i, metric := range metrics {
+		if !model.IsValidMetricName(model.LabelValue(metric.Name)) {
+			return nil, fmt.Errorf("%w %d: %q is not a valid metric name", ErrInvalidDerivedMetric, i, metric.Name)
+		}
+
+		if names[metric.Name] {
+			return nil, fmt.Errorf("%w %d: %s is derived more than once", ErrInvalidDerivedMetric, i, metric.Name)
+		}
+
+		names[metric.Name] = true
+
+		e, refs, err := parseExpr(metric.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrInvalidDerivedMetric, i, err)
+		}
+
+		parsed = append(parsed, derivedMetric{DerivedMetric: metric, expr: e, metrics: refs})
+	}
+
+	return &derivedGatherer{
+		gatherer: gatherer,
+		metrics:  parsed,
+	}, nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *derivedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	byName := map[string]*dto.MetricFamily{}
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	for _, metric := range g.metrics {
+		if _, ok := byName[metric.Name]; ok {
+			log.Warn("derived metric %s is not added, a metric of the same name was collected", metric.Name)
+			continue
+		}
+
+		if family := metric.derive(byName); len(family.Metric) > 0 {
+			families = append(families, family)
+		}
+	}
+
+	return families, err
+}
+
+// derive evaluates the expression for every label set of the first metric in
+// it, with the values of the others for the same label set.
+func (m derivedMetric) derive(byName map[string]*dto.MetricFamily) *dto.MetricFamily {
+	series := map[string]map[string]float64{}
+	labels := map[string][]*dto.LabelPair{}
+
+	for _, name := range m.metrics {
+		family, ok := byName[name]
+		if !ok {
+			continue
+		}
+
+		series[name] = map[string]float64{}
+
+		for _, metric := range family.Metric {
+			value, ok := metricValue(family.GetType(), metric)
+			if !ok {
+				continue
+			}
+
+			key := seriesKey("", metric)
+			series[name][key] = value
+
+			if name == m.metrics[0] {
+				labels[key] = metric.Label
+			}
+		}
+	}
+
+	name, help := m.Name, m.Help
+	if help == "" {
+		help = derivedMetricHelp + " " + m.Expr
+	}
+
+	gauge := dto.MetricType_GAUGE
+	family := &dto.MetricFamily{Name: &name, Help: &help, Type: &gauge}
+
+	for key, pairs := range labels {
+		value, ok := m.expr.eval(series, key)
+		if !ok || math.IsInf(value, 0) {
+			continue
+		}
+
+		v := value
+		family.Metric = append(family.Metric, &dto.Metric{Label: pairs, Gauge: &dto.Gauge{Value: &v}})
+	}
+
+	sort.Slice(family.Metric, func(i, j int) bool {
+		return seriesKey("", family.Metric[i]) < seriesKey("", family.Metric[j])
+	})
+
+	return family
+}
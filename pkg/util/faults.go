@@ -0,0 +1,106 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	faultLatency   = "latency"
+	faultError     = "error"
+	faultMalformed = "malformed"
+)
+
+var injectedFaultsVec = objects.NewExporterCounterVec(
+	prometheus.CounterOpts{
+		Namespace: objects.ExporterNamespace,
+		Name:      "injected_faults_total",
+		Help:      "Number of faults injected into requests to Couchbase Server, by fault",
+	},
+	[]string{"fault"})
+
+// FaultTransport injects the configured faults into the requests made
+// through it: delaying them, failing them with a 503 or truncating the
+// response body.
+type FaultTransport struct {
+	faults    objects.FaultConfig
+	transport http.RoundTripper
+	// Random returns a number in [0, 1) to decide whether to inject a fault.
+	Random func() float64
+}
+
+func NewFaultTransport(faults objects.FaultConfig, transport http.RoundTripper) *FaultTransport {
+	return &FaultTransport{faults: faults, transport: transport, Random: rand.Float64}
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.faults.LatencyMillis > 0 {
+		injectedFaultsVec.WithLabelValues(faultLatency).Inc()
+
+		timer := time.NewTimer(time.Duration(t.faults.LatencyMillis) * time.Millisecond)
+
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	if t.Random() < t.faults.ErrorRate {
+		injectedFaultsVec.WithLabelValues(faultError).Inc()
+
+		body := "injected fault"
+
+		return &http.Response{
+			Status:        "503 Service Unavailable",
+			StatusCode:    http.StatusServiceUnavailable,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          ioutil.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || t.Random() >= t.faults.MalformedRate {
+		return resp, err
+	}
+
+	injectedFaultsVec.WithLabelValues(faultMalformed).Inc()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a brace followed by the first half of any JSON document is never valid.
+	truncated := append([]byte("{"), body[:len(body)/2]...)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(truncated))
+	resp.ContentLength = int64(len(truncated))
+	resp.Header.Del("Content-Length")
+
+	return resp, nil
+}
@@ -0,0 +1,103 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrWeakSignature is returned in FIPS mode for a certificate signed with
+// MD5 or SHA-1, which FIPS 140 no longer approves for signatures.
+var ErrWeakSignature = errors.New("certificate signature algorithm is not FIPS approved")
+
+// FIPSCipherSuites are the only cipher suites negotiated in FIPS mode: AES-GCM
+// with ECDHE key exchange, as approved by NIST SP 800-52.  CBC suites, whose
+// MAC is SHA-1, and ChaCha20-Poly1305 are left out.
+var FIPSCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+}
+
+// FIPSCurves are the NIST curves ECDHE may use in FIPS mode, X25519 not
+// being approved.
+var FIPSCurves = []tls.CurveID{tls.CurveP256, tls.CurveP384}
+
+var fipsMode atomic.Bool
+
+func init() {
+	fipsMode.Store(fipsBuild)
+}
+
+// SetFIPS turns FIPS mode on or off for every TLS connection made or served
+// from then on.  It cannot be turned off in builds with the fips tag.
+func SetFIPS(enabled bool) {
+	fipsMode.Store(enabled || fipsBuild)
+}
+
+// FIPSEnabled returns whether TLS is restricted to FIPS approved algorithms.
+func FIPSEnabled() bool {
+	return fipsMode.Load()
+}
+
+// ApplyFIPS restricts config to FIPS approved algorithms.  TLS 1.3 is not
+// negotiated, because its cipher suites cannot be restricted and include
+// ChaCha20-Poly1305, and peer certificates signed with MD5 or SHA-1 are
+// rejected.
+func ApplyFIPS(config *tls.Config) {
+	config.MinVersion = tls.VersionTLS12
+	config.MaxVersion = tls.VersionTLS12
+	config.CipherSuites = FIPSCipherSuites
+	config.CurvePreferences = FIPSCurves
+	config.VerifyConnection = verifyFIPSConnection
+}
+
+func verifyFIPSConnection(state tls.ConnectionState) error {
+	for _, cert := range state.PeerCertificates {
+		if err := CheckFIPSCertificate(cert); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckFIPSCertificate returns ErrWeakSignature if cert is signed with MD5 or
+// SHA-1.
+func CheckFIPSCertificate(cert *x509.Certificate) error {
+	switch cert.SignatureAlgorithm {
+	case x509.MD2WithRSA, x509.MD5WithRSA, x509.SHA1WithRSA, x509.DSAWithSHA1, x509.ECDSAWithSHA1:
+		return fmt.Errorf("%w: %s is signed with %s", ErrWeakSignature, cert.Subject, cert.SignatureAlgorithm)
+	}
+
+	return nil
+}
+
+// CheckFIPSKeyPair checks every certificate in the chain of keypair, the
+// exporter's own certificates being held to the same rules as its peers'.
+func CheckFIPSKeyPair(keypair tls.Certificate) error {
+	for _, der := range keypair.Certificate {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return err
+		}
+
+		if err := CheckFIPSCertificate(cert); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	invalidHealthCheck string = "invalid health check"
+)
+
+var (
+	ErrInvalidHealthCheck = fmt.Errorf(invalidHealthCheck)
+
+	healthStatusOpts = objects.RecordExporterGauge(
+		prometheus.GaugeOpts{
+			Name: objects.HealthStatusMetric,
+			Help: "Health of the component graded by the configured thresholds, 0 if ok, 1 on a warning and 2 if critical",
+		},
+		[]string{objects.ClusterLabel, objects.ComponentLabel})
+)
+
+// healthGatherer adds the health summary of each cluster to everything
+// gathered, grading each component by the worst series of the metrics checked
+// for it.
+type healthGatherer struct {
+	gatherer prometheus.Gatherer
+	checks   []objects.HealthCheck
+}
+
+// NewHealthGatherer wraps a gatherer so that it also returns the health status
+// of each component with a check, if the health summary is enabled.  A
+// component is only reported once a metric checked for it has been collected.
+func NewHealthGatherer(gatherer prometheus.Gatherer, config objects.HealthConfig) (prometheus.Gatherer, error) {
+	if !config.Enabled || len(config.Checks) == 0 {
+		return gatherer, nil
+	}
+
+	for i, check := range config.Checks {
+		if check.Component == "" {
+			return nil, fmt.Errorf("%w %d: no component", ErrInvalidHealthCheck, i)
+		}
+
+		if !model.IsValidMetricName(model.LabelValue(check.Metric)) {
+			return nil, fmt.Errorf("%w %d: %q is not a valid metric name", ErrInvalidHealthCheck, i, check.Metric)
+		}
+
+		if (check.Below && check.Critical > check.Warning) || (!check.Below && check.Critical < check.Warning) {
+			return nil, fmt.Errorf("%w %d: the critical threshold of %s is not past its warning threshold", ErrInvalidHealthCheck, i, check.Metric)
+		}
+	}
+
+	return &healthGatherer{
+		gatherer: gatherer,
+		checks:   config.Checks,
+	}, nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *healthGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	byName := map[string]*dto.MetricFamily{}
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	// the status of each component of each cluster.
+	statuses := map[healthKey]map[string]int{}
+
+	for _, check := range g.checks {
+		family, ok := byName[check.Metric]
+		if !ok {
+			continue
+		}
+
+		for _, metric := range family.Metric {
+			value, ok := metricValue(family.GetType(), metric)
+			if !ok {
+				continue
+			}
+
+			cluster := healthKey{cluster: clusterOf(metric), role: clusterRoleOf(metric)}
+			if statuses[cluster] == nil {
+				statuses[cluster] = map[string]int{}
+			}
+
+			status, seen := statuses[cluster][check.Component]
+			if grade := check.Grade(value); !seen || grade > status {
+				statuses[cluster][check.Component] = grade
+			}
+		}
+	}
+
+	if len(statuses) == 0 {
+		return families, err
+	}
+
+	return append(families, healthFamily(statuses)), err
+}
+
+// healthKey identifies a cluster, the role of which the health status is
+// labelled with too.
+type healthKey struct {
+	cluster string
+	role    string
+}
+
+func metricValue(metricType dto.MetricType, metric *dto.Metric) (float64, bool) {
+	switch metricType {
+	case dto.MetricType_GAUGE:
+		return metric.GetGauge().GetValue(), true
+	case dto.MetricType_COUNTER:
+		return metric.GetCounter().GetValue(), true
+	case dto.MetricType_UNTYPED:
+		return metric.GetUntyped().GetValue(), true
+	default:
+		return 0, false
+	}
+}
+
+func healthFamily(statuses map[healthKey]map[string]int) *dto.MetricFamily {
+	name := healthStatusOpts.Name
+	help := healthStatusOpts.Help
+	gauge := dto.MetricType_GAUGE
+
+	family := &dto.MetricFamily{Name: &name, Help: &help, Type: &gauge}
+
+	for cluster, components := range statuses {
+		for component, status := range components {
+			labels := []*dto.LabelPair{}
+
+			if cluster.cluster != "" {
+				labels = append(labels, labelPair(objects.ClusterLabel, cluster.cluster))
+			}
+
+			if cluster.role != "" {
+				labels = append(labels, labelPair(objects.ClusterRoleLabel, cluster.role))
+			}
+
+			labels = append(labels, labelPair(objects.ComponentLabel, component))
+			value := float64(status)
+
+			family.Metric = append(family.Metric, &dto.Metric{Label: labels, Gauge: &dto.Gauge{Value: &value}})
+		}
+	}
+
+	sort.Slice(family.Metric, func(i, j int) bool {
+		return labelsKey(family.Metric[i]) < labelsKey(family.Metric[j])
+	})
+
+	return family
+}
+
+func labelPair(name, value string) *dto.LabelPair {
+	return &dto.LabelPair{Name: &name, Value: &value}
+}
+
+func labelsKey(metric *dto.Metric) string {
+	key := ""
+	for _, label := range metric.Label {
+		key += label.GetValue() + "\xff"
+	}
+
+	return key
+}
@@ -0,0 +1,116 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+// HostnameNormalizer rewrites node hostnames for the node label, so they can
+// match the labels of other exporters such as node_exporter.
+type HostnameNormalizer struct {
+	config objects.HostnameConfig
+
+	mu    sync.Mutex
+	fqdns map[string]string
+}
+
+func NewHostnameNormalizer(config objects.HostnameConfig) *HostnameNormalizer {
+	switch config.Form {
+	case objects.HostnameFormReported, objects.HostnameFormShort, objects.HostnameFormFQDN:
+	default:
+		log.Warn("unknown node hostname form %q, node hostnames will be used as reported", config.Form)
+	}
+
+	return &HostnameNormalizer{
+		config: config,
+		fqdns:  map[string]string{},
+	}
+}
+
+// Normalize applies the configured port stripping, hostname form and relabel
+// table to a hostname as reported by Couchbase Server.
+func (n *HostnameNormalizer) Normalize(hostname string) string {
+	if n == nil || hostname == "" {
+		return hostname
+	}
+
+	if relabelled, ok := n.config.Relabel[hostname]; ok {
+		return relabelled
+	}
+
+	host, port, err := net.SplitHostPort(hostname)
+	if err != nil {
+		host, port = hostname, ""
+	}
+
+	switch n.config.Form {
+	case objects.HostnameFormShort:
+		host = shortHostname(host)
+	case objects.HostnameFormFQDN:
+		host = n.fqdn(host)
+	}
+
+	normalized := host
+	if port != "" && !n.config.StripPort {
+		normalized = net.JoinHostPort(host, port)
+	}
+
+	if relabelled, ok := n.config.Relabel[normalized]; ok {
+		return relabelled
+	}
+
+	return normalized
+}
+
+func shortHostname(host string) string {
+	if net.ParseIP(host) != nil {
+		return host
+	}
+
+	return strings.SplitN(host, ".", 2)[0]
+}
+
+// fqdn resolves the fully qualified name of a host, caching the result, or
+// returns the host unchanged if it cannot be resolved.
+func (n *HostnameNormalizer) fqdn(host string) string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if fqdn, ok := n.fqdns[host]; ok {
+		return fqdn
+	}
+
+	fqdn := host
+
+	if net.ParseIP(host) != nil {
+		if names, err := net.LookupAddr(host); err == nil && len(names) > 0 {
+			fqdn = names[0]
+		} else {
+			log.Debug("unable to resolve the name of %s: %v", host, err)
+		}
+	} else {
+		if cname, err := net.LookupCNAME(host); err == nil && cname != "" {
+			fqdn = cname
+		} else {
+			log.Debug("unable to resolve the fully qualified name of %s: %v", host, err)
+		}
+	}
+
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	n.fqdns[host] = fqdn
+
+	return fqdn
+}
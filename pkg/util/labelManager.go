@@ -1,9 +1,11 @@
 package util
 
 import (
+	"context"
 	"strings"
 	"time"
 
+	"github.com/couchbase/couchbase-exporter/pkg/log"
 	"github.com/couchbase/couchbase-exporter/pkg/objects"
 )
 
@@ -13,20 +15,51 @@ type LabelKeyPair struct {
 }
 
 type MetricContext struct {
-	ClusterName  string
-	NodeHostname string
-	BucketName   string
-	Keyspace     string
-	Source       string
-	Target       string
+	ClusterName   string
+	NodeHostname  string
+	BucketName    string
+	Keyspace      string
+	Source        string
+	Target        string
+	Severity      string
+	Message       string
+	Repository    string
+	Plan          string
+	TaskType      string
+	DesignDoc     string
+	ServerGroup   string
+	ClusterUUID   string
+	BucketUUID    string
+	Services      string
+	Fingerprint   string
+	Statement     string
+	Bound         string
+	Link          string
+	Dataset       string
+	BucketType    string
+	Edition       string
+	Version       string
+	CompatVersion string
+	DocumentKey   string
+	Rank          string
+	State         string
+	Index         string
+	StorageMode   string
+	Service       string
+	Encryption    string
+	EncryptedData string
+
+	// requests is the context the UUIDs of the cluster and bucket are looked
+	// up with when a metric is labelled with them.
+	requests context.Context
 }
 
 // CbLabelManager is an interface to be used to obtain a context for labels and to retrieve label values from a context and/or the metric configuration
 // Attempts to centralize this code so it is testable and logic is segregated.
 type CbLabelManager interface {
-	GetBasicMetricContext() (MetricContext, error)
-	GetMetricContext(bucket, keyspace string) (MetricContext, error)
-	GetMetricContextWithSourceAndTarget(bucket, keyspace, source, target string) (MetricContext, error)
+	GetBasicMetricContext(context.Context) (MetricContext, error)
+	GetMetricContext(reqCtx context.Context, bucket, keyspace string) (MetricContext, error)
+	GetMetricContextWithSourceAndTarget(reqCtx context.Context, bucket, keyspace, source, target string) (MetricContext, error)
 	GetLabelValues(labels []string, context MetricContext) []string
 	GetLabelKeys(labels []string) []string
 }
@@ -34,9 +67,16 @@ type CbLabelManager interface {
 type labelManager struct {
 	client            CbClient
 	labelCacheChannel chan cache
+	hostnames         *HostnameNormalizer
 }
 
 func NewLabelManager(client CbClient, duration time.Duration) CbLabelManager {
+	return NewLabelManagerWithHostnames(client, duration, nil)
+}
+
+// NewLabelManagerWithHostnames creates a label manager that writes node
+// hostnames to the node label through the given normalizer.
+func NewLabelManagerWithHostnames(client CbClient, duration time.Duration, hostnames *HostnameNormalizer) CbLabelManager {
 	cacheChannel := make(chan cache, 1)
 
 	cacheChannel <- newCache(duration)
@@ -44,15 +84,19 @@ func NewLabelManager(client CbClient, duration time.Duration) CbLabelManager {
 	lbl := &labelManager{
 		client:            client,
 		labelCacheChannel: cacheChannel,
+		hostnames:         hostnames,
 	}
 
 	return lbl
 }
 
-func (l *labelManager) GetMetricContext(bucket, keyspace string) (MetricContext, error) {
+// GetMetricContext returns the labels of a bucket and keyspace, requesting
+// the cluster name and node hostname with reqCtx when they are not cached.
+func (l *labelManager) GetMetricContext(reqCtx context.Context, bucket, keyspace string) (MetricContext, error) {
 	ctx := MetricContext{
 		BucketName: bucket,
 		Keyspace:   keyspace,
+		requests:   reqCtx,
 	}
 	labelCache := <-l.labelCacheChannel
 
@@ -64,7 +108,7 @@ func (l *labelManager) GetMetricContext(bucket, keyspace string) (MetricContext,
 		val, _ := labelCache.get(objects.ClusterLabel).(string)
 		ctx.ClusterName = val
 	} else {
-		clusterName, err := l.client.ClusterName()
+		clusterName, err := l.client.ClusterName(reqCtx)
 		if err != nil {
 			return ctx, err
 		}
@@ -76,7 +120,7 @@ func (l *labelManager) GetMetricContext(bucket, keyspace string) (MetricContext,
 		val, _ := labelCache.get(objects.NodeLabel).(string)
 		ctx.NodeHostname = val
 	} else {
-		node, err := l.client.GetCurrentNode()
+		node, err := l.client.GetCurrentNode(reqCtx)
 		if err != nil {
 			return ctx, err
 		}
@@ -87,8 +131,8 @@ func (l *labelManager) GetMetricContext(bucket, keyspace string) (MetricContext,
 	return ctx, nil
 }
 
-func (l *labelManager) GetMetricContextWithSourceAndTarget(bucket, keyspace, source, target string) (MetricContext, error) {
-	ctx, err := l.GetMetricContext(bucket, keyspace)
+func (l *labelManager) GetMetricContextWithSourceAndTarget(reqCtx context.Context, bucket, keyspace, source, target string) (MetricContext, error) {
+	ctx, err := l.GetMetricContext(reqCtx, bucket, keyspace)
 	ctx.Source = source
 	ctx.Target = target
 
@@ -99,8 +143,8 @@ func (l *labelManager) GetMetricContextWithSourceAndTarget(bucket, keyspace, sou
 	return ctx, nil
 }
 
-func (l *labelManager) GetBasicMetricContext() (MetricContext, error) {
-	return l.GetMetricContextWithSourceAndTarget("", "", "", "")
+func (l *labelManager) GetBasicMetricContext(reqCtx context.Context) (MetricContext, error) {
+	return l.GetMetricContextWithSourceAndTarget(reqCtx, "", "", "", "")
 }
 
 func (l *labelManager) GetLabelValues(labels []string, context MetricContext) []string {
@@ -115,11 +159,65 @@ func (l *labelManager) GetLabelValues(labels []string, context MetricContext) []
 		case objects.KeyspaceLabel:
 			values = append(values, context.Keyspace)
 		case objects.NodeLabel:
-			values = append(values, context.NodeHostname)
+			values = append(values, l.hostnames.Normalize(context.NodeHostname))
 		case objects.TargetLabel:
 			values = append(values, context.Target)
 		case objects.SourceLabel:
 			values = append(values, context.Source)
+		case objects.SeverityLabel:
+			values = append(values, context.Severity)
+		case objects.MessageLabel:
+			values = append(values, context.Message)
+		case objects.RepositoryLabel:
+			values = append(values, context.Repository)
+		case objects.PlanLabel:
+			values = append(values, context.Plan)
+		case objects.TaskTypeLabel:
+			values = append(values, context.TaskType)
+		case objects.DesignDocLabel:
+			values = append(values, context.DesignDoc)
+		case objects.ServerGroupLabel:
+			values = append(values, context.ServerGroup)
+		case objects.ClusterUUIDLabel:
+			values = append(values, l.clusterUUID(context))
+		case objects.BucketUUIDLabel:
+			values = append(values, l.bucketUUID(context))
+		case objects.ServicesLabel:
+			values = append(values, context.Services)
+		case objects.FingerprintLabel:
+			values = append(values, context.Fingerprint)
+		case objects.StatementLabel:
+			values = append(values, context.Statement)
+		case objects.DurationBucketLabel:
+			values = append(values, context.Bound)
+		case objects.LinkLabel:
+			values = append(values, context.Link)
+		case objects.DatasetLabel:
+			values = append(values, context.Dataset)
+		case objects.BucketTypeLabel:
+			values = append(values, objects.BucketTypeName(context.BucketType))
+		case objects.EditionLabel:
+			values = append(values, context.Edition)
+		case objects.VersionLabel:
+			values = append(values, context.Version)
+		case objects.CompatVersionLabel:
+			values = append(values, context.CompatVersion)
+		case objects.DocumentKeyLabel:
+			values = append(values, context.DocumentKey)
+		case objects.RankLabel:
+			values = append(values, context.Rank)
+		case objects.StateLabel:
+			values = append(values, context.State)
+		case objects.IndexLabel:
+			values = append(values, context.Index)
+		case objects.StorageModeLabel:
+			values = append(values, context.StorageMode)
+		case objects.ServiceLabel:
+			values = append(values, context.Service)
+		case objects.EncryptionLevelLabel:
+			values = append(values, context.Encryption)
+		case objects.EncryptedDataLabel:
+			values = append(values, context.EncryptedData)
 		default:
 			if strings.Contains(label, ":") {
 				splits := strings.Split(label, ":")
@@ -133,6 +231,81 @@ func (l *labelManager) GetLabelValues(labels []string, context MetricContext) []
 	return values
 }
 
+// clusterUUID returns the UUID in the context, or else the cached UUID of the
+// cluster, so that the UUID is only requested when a metric is labelled with it.
+func (l *labelManager) clusterUUID(context MetricContext) string {
+	if context.ClusterUUID != "" {
+		return context.ClusterUUID
+	}
+
+	labelCache := <-l.labelCacheChannel
+
+	defer func() {
+		l.labelCacheChannel <- labelCache
+	}()
+
+	if !labelCache.isExpired(objects.ClusterUUIDLabel) {
+		val, _ := labelCache.get(objects.ClusterUUIDLabel).(string)
+		return val
+	}
+
+	uuid, err := l.client.ClusterUUID(context.requestContext())
+	if err != nil {
+		log.Error("failed to retrieve the cluster UUID: %s", err)
+		return ""
+	}
+
+	labelCache.set(objects.ClusterUUIDLabel, uuid)
+
+	return uuid
+}
+
+// bucketUUID returns the UUID in the context, or else the cached UUID of the
+// context's bucket.  The UUIDs of every bucket are cached together, so a
+// bucket that has been deleted or filtered out is not looked for again until
+// the cache expires.
+func (l *labelManager) bucketUUID(ctx MetricContext) string {
+	if ctx.BucketUUID != "" || ctx.BucketName == "" {
+		return ctx.BucketUUID
+	}
+
+	labelCache := <-l.labelCacheChannel
+
+	defer func() {
+		l.labelCacheChannel <- labelCache
+	}()
+
+	uuids, _ := labelCache.get(objects.BucketUUIDLabel).(map[string]string)
+
+	if labelCache.isExpired(objects.BucketUUIDLabel) {
+		buckets, err := l.client.Buckets(ctx.requestContext())
+		if err != nil {
+			log.Error("failed to retrieve bucket UUIDs: %s", err)
+			return ""
+		}
+
+		uuids = map[string]string{}
+		for _, bucket := range buckets {
+			uuids[bucket.Name] = bucket.UUID
+		}
+
+		labelCache.set(objects.BucketUUIDLabel, uuids)
+	}
+
+	return uuids[ctx.BucketName]
+}
+
+// requestContext is the context to look up the labels of the metric with,
+// or the background context for a MetricContext that was not returned by the
+// label manager.
+func (m MetricContext) requestContext() context.Context {
+	if m.requests == nil {
+		return context.Background()
+	}
+
+	return m.requests
+}
+
 func (l *labelManager) GetLabelKeys(labels []string) []string {
 	return objects.GetLabelKeys(labels)
 }
@@ -0,0 +1,160 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil/promlint"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const metricLint string = "metric does not follow the Prometheus naming conventions"
+
+var (
+	ErrMetricLint = fmt.Errorf(metricLint)
+
+	// lintExceptions are metrics exported since before they were linted,
+	// which keep their names so that existing dashboards still work.
+	lintExceptions = map[string]bool{
+		"cbbucketstat_cpu_idle_ms":     true,
+		"cbbucketstat_cpu_local_ms":    true,
+		"cbpernodebucket_cpu_idle_ms":  true,
+		"cbpernodebucket_cpu_local_ms": true,
+	}
+)
+
+// LintingRegisterer checks the names and help of the metrics of every
+// collector registered through it against the Prometheus conventions, as
+// promlint does.  Problems are logged, or in strict mode fail the
+// registration.
+type LintingRegisterer struct {
+	prometheus.Registerer
+	strict bool
+}
+
+// NewLintingRegisterer wraps registerer to lint as mode selects, returning it
+// as it is if linting is off.
+func NewLintingRegisterer(registerer prometheus.Registerer, mode string) prometheus.Registerer {
+	if mode == objects.MetricLintOff {
+		return registerer
+	}
+
+	return &LintingRegisterer{Registerer: registerer, strict: mode == objects.MetricLintStrict}
+}
+
+func (r *LintingRegisterer) Register(collector prometheus.Collector) error {
+	problems := LintCollector(collector)
+
+	if len(problems) != 0 && r.strict {
+		texts := make([]string, 0, len(problems))
+		for _, problem := range problems {
+			texts = append(texts, problem.Metric+": "+problem.Text)
+		}
+
+		return fmt.Errorf("%w: %s", ErrMetricLint, strings.Join(texts, "; "))
+	}
+
+	for _, problem := range problems {
+		log.Warn("metric %s does not follow the Prometheus naming conventions: %s", problem.Metric, problem.Text)
+	}
+
+	return r.Registerer.Register(collector)
+}
+
+func (r *LintingRegisterer) MustRegister(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		if err := r.Register(collector); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LintCollector returns the problems promlint finds with the metrics the
+// collector describes.  A description does not say what type a metric is, so
+// the checks of counters and histograms are not made.
+func LintCollector(collector prometheus.Collector) []promlint.Problem {
+	descs := make(chan *prometheus.Desc)
+
+	go func() {
+		collector.Describe(descs)
+		close(descs)
+	}()
+
+	families := map[string]*dto.MetricFamily{}
+
+	for desc := range descs {
+		name, help, ok := parseDesc(desc)
+		if !ok || lintExceptions[name] {
+			continue
+		}
+
+		untyped := dto.MetricType_UNTYPED
+		value := 0.0
+
+		// promlint ignores families without metrics.
+		family := &dto.MetricFamily{
+			Name:   &name,
+			Type:   &untyped,
+			Metric: []*dto.Metric{{Untyped: &dto.Untyped{Value: &value}}},
+		}
+
+		if help != "" {
+			family.Help = &help
+		}
+
+		families[name] = family
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	sorted := make([]*dto.MetricFamily, 0, len(names))
+	for _, name := range names {
+		sorted = append(sorted, families[name])
+	}
+
+	problems, _ := promlint.NewWithMetricFamilies(sorted).Lint()
+
+	return problems
+}
+
+// parseDesc returns the name and help of desc, which are only available from
+// its string form.
+func parseDesc(desc *prometheus.Desc) (string, string, bool) {
+	s := strings.TrimPrefix(desc.String(), "Desc{fqName: ")
+
+	quoted, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", false
+	}
+
+	name, _ := strconv.Unquote(quoted)
+	s = strings.TrimPrefix(s[len(quoted):], ", help: ")
+
+	quoted, err = strconv.QuotedPrefix(s)
+	if err != nil {
+		return "", "", false
+	}
+
+	help, _ := strconv.Unquote(quoted)
+
+	return name, help, true
+}
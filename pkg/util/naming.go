@@ -0,0 +1,160 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+type nameCorrection struct {
+	objects.NameCorrection
+	match *regexp.Regexp
+}
+
+// namingGatherer exports metrics under their corrected names instead of, or
+// as well as, their legacy names.
+type namingGatherer struct {
+	gatherer    prometheus.Gatherer
+	names       string
+	corrections []nameCorrection
+}
+
+// NewNamingGatherer wraps a gatherer to export its metrics under the names
+// selected, which is one of the objects.MetricNames modes.
+func NewNamingGatherer(gatherer prometheus.Gatherer, names string) (prometheus.Gatherer, error) {
+	if err := objects.ValidateMetricNames(names); err != nil {
+		return nil, err
+	}
+
+	if names == "" || names == objects.MetricNamesLegacy {
+		return gatherer, nil
+	}
+
+	corrections := make([]nameCorrection, 0, len(objects.NameCorrections))
+	for _, correction := range objects.NameCorrections {
+		corrections = append(corrections, nameCorrection{
+			NameCorrection: correction,
+			match:          regexp.MustCompile("^(?:" + correction.Match + ")$"),
+		})
+	}
+
+	return &namingGatherer{gatherer: gatherer, names: names, corrections: corrections}, nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *namingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	named := make([]*dto.MetricFamily, 0, len(families))
+
+	for _, family := range families {
+		corrected, ok := g.correct(family)
+		if !ok {
+			named = append(named, family)
+			continue
+		}
+
+		if g.names == objects.MetricNamesBoth {
+			help := "Deprecated, renamed to " + corrected.GetName()
+			if family.GetHelp() != "" {
+				help = family.GetHelp() + ", deprecated and renamed to " + corrected.GetName()
+			}
+
+			family.Help = &help
+			named = append(named, family)
+		}
+
+		named = append(named, corrected)
+	}
+
+	sort.Slice(named, func(i, j int) bool {
+		return named[i].GetName() < named[j].GetName()
+	})
+
+	return named, err
+}
+
+// correct returns a copy of the family under its corrected name, or false if
+// its name is correct.
+func (g *namingGatherer) correct(family *dto.MetricFamily) (*dto.MetricFamily, bool) {
+	name := family.GetName()
+	scale := 0.0
+
+	for _, correction := range g.corrections {
+		if correction.match.MatchString(name) {
+			name = correction.match.ReplaceAllString(name, correction.Rename)
+			scale = correction.Scale
+
+			break
+		}
+	}
+
+	if family.GetType() == dto.MetricType_COUNTER && !strings.HasSuffix(name, "_total") {
+		name += "_total"
+	}
+
+	if name == family.GetName() {
+		return nil, false
+	}
+
+	corrected := &dto.MetricFamily{
+		Name:   &name,
+		Help:   family.Help,
+		Type:   family.Type,
+		Metric: make([]*dto.Metric, 0, len(family.Metric)),
+	}
+
+	for _, metric := range family.Metric {
+		corrected.Metric = append(corrected.Metric, scaleMetric(metric, scale))
+	}
+
+	return corrected, true
+}
+
+// scaleMetric returns a copy of a metric with its value multiplied by scale,
+// unless scale is zero.  Summaries and histograms are copied as they are.
+func scaleMetric(metric *dto.Metric, scale float64) *dto.Metric {
+	scaled := &dto.Metric{
+		Label:       append([]*dto.LabelPair{}, metric.Label...),
+		Gauge:       metric.Gauge,
+		Counter:     metric.Counter,
+		Summary:     metric.Summary,
+		Untyped:     metric.Untyped,
+		Histogram:   metric.Histogram,
+		TimestampMs: metric.TimestampMs,
+	}
+
+	if scale == 0 {
+		return scaled
+	}
+
+	if metric.Gauge != nil {
+		value := metric.GetGauge().GetValue() * scale
+		scaled.Gauge = &dto.Gauge{Value: &value}
+	}
+
+	if metric.Counter != nil {
+		value := metric.GetCounter().GetValue() * scale
+		scaled.Counter = &dto.Counter{Value: &value, Exemplar: metric.GetCounter().GetExemplar()}
+	}
+
+	if metric.Untyped != nil {
+		value := metric.GetUntyped().GetValue() * scale
+		scaled.Untyped = &dto.Untyped{Value: &value}
+	}
+
+	return scaled
+}
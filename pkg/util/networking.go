@@ -12,13 +12,18 @@
 package util
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,56 +34,188 @@ import (
 
 const (
 	CaError string = "failed to append CA certificate"
+
+	// DefaultMaxIdleConnsPerHost is the number of idle connections kept open
+	// to each node, enough for the collectors scraping it concurrently.
+	DefaultMaxIdleConnsPerHost = 10
+
+	// DefaultRequestTimeout bounds every request made by the client, on top of
+	// any deadline of the context it is made with.
+	DefaultRequestTimeout = 30 * time.Second
 )
 
 type CbClient interface {
 	URL(string) string
-	Get(string, interface{}) error
-	Buckets() ([]objects.BucketInfo, error)
-	BucketStats(string) (objects.BucketStats, error)
-	BucketPerNodeStats(string, string) (objects.BucketStats, error)
-	Nodes() (objects.Nodes, error)
-	ClusterName() (string, error)
-	NodesNodes() (objects.Nodes, error)
-	BucketNodes(string) ([]interface{}, error)
-	Tasks() ([]objects.Task, error)
-	Servers(string) (objects.Servers, error)
-	Query() (objects.Query, error)
-	Index() (objects.Index, error)
-	Fts() (objects.FTS, error)
-	Cbas() (objects.Analytics, error)
-	Eventing() (objects.Eventing, error)
-	QueryNode(string) (objects.Query, error)
-	IndexNode(string) (objects.Index, error)
-	GetCurrentNode() (objects.Node, error)
-	IndexStats() (map[string]map[string]interface{}, error)
+	Get(context.Context, string, interface{}) error
+	Buckets(context.Context) ([]objects.BucketInfo, error)
+	BucketStats(context.Context, string) (objects.BucketStats, error)
+	BucketPerNodeStats(context.Context, string, string) (objects.BucketStats, error)
+	Nodes(context.Context) (objects.Nodes, error)
+	ClusterName(context.Context) (string, error)
+	ClusterUUID(context.Context) (string, error)
+	Pools(context.Context) (objects.Pools, error)
+	NodesNodes(context.Context) (objects.Nodes, error)
+	BucketNodes(context.Context, string) ([]interface{}, error)
+	Tasks(context.Context) ([]objects.Task, error)
+	XdcrStats(ctx context.Context, bucket string) (objects.XdcrStats, error)
+	AutoCompaction(context.Context) (objects.AutoCompaction, error)
+	Servers(context.Context, string) (objects.Servers, error)
+	Query(context.Context) (objects.Query, error)
+	Index(context.Context) (objects.Index, error)
+	Fts(context.Context) (objects.FTS, error)
+	Cbas(context.Context) (objects.Analytics, error)
+	AnalyticsIngestion(context.Context) (objects.AnalyticsIngestion, error)
+	Eventing(context.Context) (objects.Eventing, error)
+	QueryNode(context.Context, string) (objects.Query, error)
+	IndexNode(context.Context, string) (objects.Index, error)
+	GetCurrentNode(context.Context) (objects.Node, error)
+	IndexStats(context.Context) (map[string]map[string]interface{}, error)
+	IndexStatus(context.Context) (objects.IndexStatus, error)
+	Events(context.Context) (objects.SystemEvents, error)
+	AuditSettings(context.Context) (objects.AuditSettings, error)
+	SecuritySettings(context.Context) (objects.SecuritySettings, error)
+	EncryptionAtRestSettings(context.Context) (objects.EncryptionAtRestSettings, error)
+	StatsRange(context.Context, string) (objects.StatsRange, error)
+	NodeStatsRange(context.Context, string) (objects.StatsRange, error)
+	BackupRepositories(context.Context) ([]objects.BackupRepository, error)
+	BackupRepositoryInfo(context.Context, string) (objects.BackupRepositoryInfo, error)
+	BackupTaskHistory(context.Context, string) ([]objects.BackupTask, error)
+	DesignDocs(context.Context, string) (objects.DesignDocs, error)
+	DesignDocInfo(context.Context, string, string) (objects.DesignDocInfo, error)
+	ServerGroups(context.Context) (objects.ServerGroups, error)
+	WhoAmI(context.Context) (objects.WhoAmI, error)
+	CompletedRequests(context.Context) ([]objects.CompletedRequest, error)
+	Prepareds(context.Context) ([]objects.Prepared, error)
+	QueryVitals(context.Context) (objects.QueryVitals, error)
+	NodeTime(ctx context.Context, hostname string) (objects.NodeTime, error)
 }
 
 // Client is the couchbase client.
 type Client struct {
-	port   int
-	domain string
-	Client http.Client
+	port    int
+	domain  string
+	auth    *authState
+	timeout time.Duration
+	Client  http.Client
 }
 
-// NewClient creates a new couchbase client.
+// NewClient creates a new couchbase client with its own pooled transport.
 func NewClient(domain string, port int, user, password string, config *tls.Config) Client {
+	return NewClientWithTransport(domain, port, user, password, NewTransport(config, DefaultMaxIdleConnsPerHost))
+}
+
+// NewClientWithTransport creates a new couchbase client that makes its
+// requests through transport, so that connections are pooled across every
+// collector sharing it.
+func NewClientWithTransport(domain string, port int, user, password string, transport http.RoundTripper) Client {
+	return NewClientWithAuth(domain, port, objects.AuthConfig{Mode: objects.AuthModeBasic}, user, password, transport)
+}
+
+// NewClientWithAuth creates a new couchbase client that authenticates its
+// requests as auth selects: with the username and password, with a bearer
+// token, with SPNEGO, or with only the client certificate of transport's TLS
+// config.
+func NewClientWithAuth(domain string, port int, auth objects.AuthConfig, user, password string, transport http.RoundTripper) Client {
+	var authenticator Authenticator
+
+	if auth.Mode == objects.AuthModeSPNEGO {
+		authenticator = SPNEGOAuthenticator{Command: auth.SPNEGOCommand}
+	}
+
 	var client = Client{
-		domain: domain,
-		port:   port,
+		domain:  domain,
+		port:    port,
+		auth:    newAuthState(user),
+		timeout: DefaultRequestTimeout,
 		Client: http.Client{
 			Transport: &AuthTransport{
-				Username: user,
-				Password: password,
-				config:   config,
+				Username:      user,
+				Password:      password,
+				Auth:          auth,
+				Authenticator: authenticator,
+				Transport:     transport,
 			},
-
 		},
 	}
 
 	return client
 }
 
+// NewClientWithAuthenticator creates a new couchbase client whose requests
+// are each authenticated by authenticator.
+func NewClientWithAuthenticator(domain string, port int, authenticator Authenticator, transport http.RoundTripper) Client {
+	client := NewClientWithAuth(domain, port, objects.AuthConfig{}, "", "", transport)
+	client.Client.Transport.(*AuthTransport).Authenticator = authenticator
+
+	return client
+}
+
+// WithMiddleware returns a copy of the client whose authenticated requests
+// pass through each middleware in turn, the first being the outermost.
+func (c Client) WithMiddleware(middleware ...Middleware) Client {
+	if auth, ok := c.Client.Transport.(*AuthTransport); ok {
+		wrapped := *auth
+		wrapped.Transport = chainMiddleware(auth.transport(), middleware)
+		c.Client.Transport = &wrapped
+
+		return c
+	}
+
+	c.Client.Transport = chainMiddleware(c.Client.Transport, middleware)
+
+	return c
+}
+
+func chainMiddleware(transport http.RoundTripper, middleware []Middleware) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+
+	return transport
+}
+
+// WithTimeout returns a copy of the client whose requests are each abandoned
+// after timeout, or only when their context is done if timeout is zero.
+func (c Client) WithTimeout(timeout time.Duration) Client {
+	c.timeout = timeout
+
+	return c
+}
+
+// WithAuthBackoff returns a copy of the client that, like the client it is a
+// copy of, holds back requests Couchbase Server rejected for backoff.
+func (c Client) WithAuthBackoff(backoff time.Duration) Client {
+	c.auth.setBackoff(backoff)
+
+	return c
+}
+
+// NewTransport creates a pooled transport for requests to Couchbase Server,
+// which keeps up to maxIdleConnsPerHost idle connections open to each node,
+// and uses HTTP/2 with the nodes that offer it over TLS.  A copy of config is
+// restricted to FIPS approved algorithms in FIPS mode.
+func NewTransport(config *tls.Config, maxIdleConnsPerHost int) *http.Transport {
+	if FIPSEnabled() {
+		config = config.Clone()
+		ApplyFIPS(config)
+	}
+
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		TLSClientConfig:       config,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   10 * time.Second,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
 // configTLS examines the configuration and creates a TLS configuration.
 func ConfigClientTLS(cacert, chain, key string) *tls.Config {
 	tlsClientConfig := &tls.Config{
@@ -123,45 +260,204 @@ func (c Client) IndexerURL(path string) string {
 	return url
 }
 
-func (c Client) IndexAPIGet(path string, v interface{}) error {
-	resp, err := c.Client.Get(c.IndexerURL(path))
+func (c Client) BackupURL(path string) string {
+	var url string
+
+	switch c.port {
+	case 18091:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 18097, path)
+	default:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 8097, path)
+	}
+
+	return url
+}
+
+func (c Client) ViewsURL(path string) string {
+	var url string
+
+	switch c.port {
+	case 18091:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 18092, path)
+	default:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 8092, path)
+	}
+
+	return url
+}
+
+func (c Client) QueryURL(path string) string {
+	var url string
+
+	switch c.port {
+	case 18091:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 18093, path)
+	default:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 8093, path)
+	}
+
+	return url
+}
+
+func (c Client) AnalyticsURL(path string) string {
+	var url string
+
+	switch c.port {
+	case 18091:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 18095, path)
+	default:
+		url = fmt.Sprintf("%s:%d/%s", c.domain, 8095, path)
+	}
+
+	return url
+}
+
+func (c Client) IndexAPIGet(ctx context.Context, path string, v interface{}) error {
+	return c.get(ctx, c.IndexerURL(path), path, v)
+}
+
+func (c Client) BackupAPIGet(ctx context.Context, path string, v interface{}) error {
+	return c.get(ctx, c.BackupURL(path), path, v)
+}
+
+func (c Client) ViewsAPIGet(ctx context.Context, path string, v interface{}) error {
+	return c.get(ctx, c.ViewsURL(path), path, v)
+}
+
+func (c Client) QueryAPIGet(ctx context.Context, path string, v interface{}) error {
+	return c.get(ctx, c.QueryURL(path), path, v)
+}
+
+func (c Client) AnalyticsAPIGet(ctx context.Context, path string, v interface{}) error {
+	return c.get(ctx, c.AnalyticsURL(path), path, v)
+}
+
+// NodeTime reads the clock of the node with the given hostname, as listed in
+// the nodes of the cluster, from the Date of its cluster manager's response to
+// /pools.
+func (c Client) NodeTime(ctx context.Context, hostname string) (nodeTime objects.NodeTime, err error) {
+	// errors may quote the URL requested.
+	defer func() {
+		err = RedactError(err)
+	}()
+
+	host, _, err := net.SplitHostPort(hostname)
 	if err != nil {
-		return errors.Wrapf(err, "failed to Get %s", path)
+		host = hostname
 	}
 
-	bts, err := ioutil.ReadAll(resp.Body)
+	scheme := "http"
+	if strings.HasPrefix(c.domain, "https://") {
+		scheme = "https"
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	url := fmt.Sprintf("%s://%s/pools", scheme, net.JoinHostPort(host, strconv.Itoa(c.port)))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return errors.Wrapf(err, "failed to read response body from %s", path)
+		return nodeTime, errors.Wrapf(err, "failed to create request for the time of %s", hostname)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return errors.Errorf("failed to Get 200 response status: %d", resp.StatusCode)
+	nodeTime.Sent = time.Now()
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nodeTime, errors.Wrapf(err, "failed to Get the time of %s", hostname)
 	}
 
-	if err := json.Unmarshal(bts, v); err != nil {
-		return errors.Wrapf(err, "failed to unmarshall %s output: %s", path, string(bts))
+	nodeTime.Received = time.Now()
+
+	resp.Body.Close()
+
+	nodeTime.Time, err = http.ParseTime(resp.Header.Get("Date"))
+
+	return nodeTime, errors.Wrapf(err, "failed to read the time of %s", hostname)
+}
+
+// Get requests path from the cluster manager, giving up when ctx is done.
+func (c Client) Get(ctx context.Context, path string, v interface{}) error {
+	return c.get(ctx, c.URL(path), path, v)
+}
+
+func (c Client) get(ctx context.Context, url, path string, v interface{}) error {
+	probing, err := c.auth.check(path)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if probing {
+		defer c.auth.doneProbing(path)
+	}
+
+	return c.request(ctx, url, path, v)
 }
 
-func (c Client) Get(path string, v interface{}) error {
-	resp, err := c.Client.Get(c.URL(path))
+// streamDecoder is implemented by responses large enough that they are
+// decoded straight from the response body rather than read into memory first.
+type streamDecoder interface {
+	DecodeJSON(decoder *json.Decoder) error
+}
+
+// request requests path even while requests are held back after a
+// rejection, so that it can find out whether the credentials were corrected.
+func (c Client) request(ctx context.Context, url, path string, v interface{}) (err error) {
+	// errors may quote the URL requested or the response.
+	defer func() {
+		err = RedactError(err)
+	}()
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return errors.Wrapf(err, "failed to Get %s", path)
+		return errors.Wrapf(err, "failed to create request for %s", path)
 	}
 
-	bts, err := ioutil.ReadAll(resp.Body)
+	resp, err := c.Client.Do(req)
 	if err != nil {
-		return errors.Wrapf(err, "failed to read response body from %s", path)
+		return errors.Wrapf(err, "failed to Get %s", path)
+	}
+
+	// whatever is left of the body is read so that the connection can be
+	// reused.
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if err := c.auth.record(path, resp.StatusCode); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
+		if id := RequestID(resp); id != "" {
+			return errors.Errorf("failed to Get 200 response status: %d (%s %s)", resp.StatusCode, RequestIDHeader, id)
+		}
+
 		return errors.Errorf("failed to Get 200 response status: %d", resp.StatusCode)
 	}
 
+	if decoder, ok := v.(streamDecoder); ok {
+		return errors.Wrapf(decoder.DecodeJSON(json.NewDecoder(resp.Body)), "failed to decode %s output", path)
+	}
+
+	bts, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read response body from %s", path)
+	}
+
 	if err := json.Unmarshal(bts, v); err != nil {
 		return errors.Wrapf(err, "failed to unmarshall %s output: %s", path, string(bts))
 	}
@@ -173,7 +469,11 @@ func (c Client) Get(path string, v interface{}) error {
 type AuthTransport struct {
 	Username string
 	Password string
-	config   *tls.Config
+	// Auth selects basic authentication with Username and Password when its
+	// mode is empty.
+	Auth objects.AuthConfig
+	// Authenticator, when set, authenticates every request instead of Auth.
+	Authenticator Authenticator
 
 	Transport http.RoundTripper
 }
@@ -183,17 +483,9 @@ func (t *AuthTransport) transport() http.RoundTripper {
 		return t.Transport
 	}
 
-	return &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		TLSClientConfig:       t.config,
-		TLSHandshakeTimeout:   10 * time.Second,
-		MaxIdleConns:          100,
-		IdleConnTimeout:       90 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
+	return http.DefaultTransport
 }
 
-
 // RoundTrip implements the RoundTripper interface.
 func (t *AuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req2 := new(http.Request)
@@ -204,135 +496,376 @@ func (t *AuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		req2.Header[k] = append([]string(nil), s...)
 	}
 
-	req2.SetBasicAuth(t.Username, t.Password)
+	switch {
+	case t.Authenticator != nil:
+		if err := t.Authenticator.Authenticate(req2); err != nil {
+			return nil, err
+		}
+	case t.Auth.Mode == objects.AuthModeBearer:
+		token, err := t.Auth.BearerToken()
+		if err != nil {
+			return nil, err
+		}
+
+		req2.Header.Set("Authorization", "Bearer "+token)
+	case t.Auth.Mode == objects.AuthModeCertificate:
+		// the client certificate presented by the transport is all there is.
+	default:
+		req2.SetBasicAuth(t.Username, t.Password)
+	}
+
 	req2.Header.Set("User-Agent", version.UserAgent())
 
 	return t.transport().RoundTrip(req2)
 }
 
 // Buckets returns the results of /pools/default/buckets.
-func (c Client) Buckets() ([]objects.BucketInfo, error) {
+func (c Client) Buckets(ctx context.Context) ([]objects.BucketInfo, error) {
 	var buckets []objects.BucketInfo
-	err := c.Get("pools/default/buckets", &buckets)
+	err := c.Get(ctx, "pools/default/buckets", &buckets)
 
 	return buckets, errors.Wrap(err, "failed to Get buckets")
 }
 
-// BucketStats returns the results of /pools/default/buckets/<bucket_name>/stats.
-func (c Client) BucketStats(name string) (objects.BucketStats, error) {
+// BucketStats returns the results of /pools/default/buckets/<bucket_name>/stats,
+// giving up when ctx is done.
+func (c Client) BucketStats(ctx context.Context, name string) (objects.BucketStats, error) {
 	var stats objects.BucketStats
-	err := c.Get(fmt.Sprintf("pools/default/buckets/%s/stats", name), &stats)
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/%s/stats", name), &stats)
 
 	return stats, errors.Wrap(err, "failed to Get bucket stats")
 }
 
-func (c Client) BucketPerNodeStats(bucket, node string) (objects.BucketStats, error) {
+func (c Client) BucketPerNodeStats(ctx context.Context, bucket, node string) (objects.BucketStats, error) {
 	var stats objects.BucketStats
-	err := c.Get(fmt.Sprintf("pools/default/buckets/%s/nodes/%s/stats", bucket, node), &stats)
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/%s/nodes/%s/stats", bucket, node), &stats)
 
 	return stats, errors.Wrap(err, "failed to Get bucket stats")
 }
 
 // Nodes returns the results of /pools/default/.
-func (c Client) Nodes() (objects.Nodes, error) {
+func (c Client) Nodes(ctx context.Context) (objects.Nodes, error) {
 	var nodes objects.Nodes
-	err := c.Get("pools/default", &nodes)
+	err := c.Get(ctx, "pools/default", &nodes)
 
 	return nodes, errors.Wrap(err, "failed to Get nodes")
 }
 
 // ClusterName returns the name of the Cluster.
-func (c Client) ClusterName() (string, error) {
+func (c Client) ClusterName(ctx context.Context) (string, error) {
 	var nodes objects.Nodes
-	err := c.Get("pools/default", &nodes)
+	err := c.Get(ctx, "pools/default", &nodes)
 
 	return nodes.ClusterName, errors.Wrap(err, "failed to retrieve ClusterName")
 }
 
+// ClusterUUID returns the UUID of the Cluster.
+func (c Client) ClusterUUID(ctx context.Context) (string, error) {
+	pools, err := c.Pools(ctx)
+
+	return pools.UUID, errors.Wrap(err, "failed to retrieve ClusterUUID")
+}
+
+// Pools returns the results of /pools, which describes the cluster and the
+// node answering.
+func (c Client) Pools(ctx context.Context) (objects.Pools, error) {
+	var pools objects.Pools
+	err := c.Get(ctx, "pools", &pools)
+
+	return pools, errors.Wrap(err, "failed to retrieve pools")
+}
+
 // NodesNodes returns the results of /pools/nodes/.
-func (c Client) NodesNodes() (objects.Nodes, error) {
+func (c Client) NodesNodes(ctx context.Context) (objects.Nodes, error) {
 	var nodes objects.Nodes
-	err := c.Get("pools/nodes", &nodes)
+	err := c.Get(ctx, "pools/nodes", &nodes)
 
 	return nodes, errors.Wrap(err, "failed to Get nodes")
 }
 
 // BucketNodes returns the nodes that this bucket spans.
-func (c Client) BucketNodes(bucket string) ([]interface{}, error) {
+func (c Client) BucketNodes(ctx context.Context, bucket string) ([]interface{}, error) {
 	var nodes []interface{}
-	err := c.Get(fmt.Sprintf("pools/default/buckets/%s/nodes", bucket), nodes)
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/%s/nodes", bucket), nodes)
 
 	return nodes, errors.Wrap(err, "failed to Get nodes")
 }
 
 // Tasks returns the results of /pools/default/tasks.
-func (c Client) Tasks() ([]objects.Task, error) {
+func (c Client) Tasks(ctx context.Context) ([]objects.Task, error) {
 	var tasks []objects.Task
-	err := c.Get("pools/default/tasks", &tasks)
+	err := c.Get(ctx, "pools/default/tasks", &tasks)
 
 	return tasks, errors.Wrap(err, "failed to Get tasks")
 }
 
-func (c Client) Servers(bucket string) (objects.Servers, error) {
+// XdcrStats returns the stats of the outgoing replications of a bucket.
+func (c Client) XdcrStats(ctx context.Context, bucket string) (objects.XdcrStats, error) {
+	var stats objects.XdcrStats
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/@xdcr-%s/stats", bucket), &stats)
+
+	return stats, errors.Wrapf(err, "failed to Get XDCR stats of %s", bucket)
+}
+
+// AutoCompaction returns the cluster wide auto-compaction settings, which
+// apply to the buckets that do not override them.
+func (c Client) AutoCompaction(ctx context.Context) (objects.AutoCompaction, error) {
+	var settings objects.ClusterAutoCompaction
+	err := c.Get(ctx, "settings/autoCompaction", &settings)
+	settings.AutoCompactionSettings.PurgeInterval = settings.PurgeInterval
+
+	return settings.AutoCompactionSettings, errors.Wrap(err, "failed to Get auto-compaction settings")
+}
+
+func (c Client) Servers(ctx context.Context, bucket string) (objects.Servers, error) {
 	var servers objects.Servers
-	err := c.Get(fmt.Sprintf("pools/default/buckets/%s/nodes", bucket), &servers)
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/%s/nodes", bucket), &servers)
 
 	return servers, errors.Wrap(err, "failed to Get servers")
 }
 
-func (c Client) Query() (objects.Query, error) {
+// ServerGroups returns the results of /pools/default/serverGroups.
+func (c Client) ServerGroups(ctx context.Context) (objects.ServerGroups, error) {
+	var groups objects.ServerGroups
+	err := c.Get(ctx, "pools/default/serverGroups", &groups)
+
+	return groups, errors.Wrap(err, "failed to Get server groups")
+}
+
+// WhoAmI returns the results of /whoami.
+func (c Client) WhoAmI(ctx context.Context) (objects.WhoAmI, error) {
+	var who objects.WhoAmI
+
+	// whoami is requested even while requests are held back after a 401, as
+	// the credentials check is what finds out that they were corrected.
+	err := c.request(ctx, c.URL("whoami"), "whoami", &who)
+
+	return who, errors.Wrap(err, "failed to Get whoami")
+}
+
+func (c Client) Query(ctx context.Context) (objects.Query, error) {
 	var query objects.Query
-	err := c.Get("pools/default/buckets/@query/stats", &query)
+	err := c.Get(ctx, "pools/default/buckets/@query/stats", &query)
 
 	return query, errors.Wrap(err, "failed to Get query stats")
 }
 
-func (c Client) Index() (objects.Index, error) {
+func (c Client) Index(ctx context.Context) (objects.Index, error) {
 	var index objects.Index
-	err := c.Get("pools/default/buckets/@index/stats", &index)
+	err := c.Get(ctx, "pools/default/buckets/@index/stats", &index)
 
 	return index, errors.Wrap(err, "failed to Get index stats")
 }
 
-func (c Client) Fts() (objects.FTS, error) {
+// IndexStatus returns the status and definition of every index in the
+// cluster.
+func (c Client) IndexStatus(ctx context.Context) (objects.IndexStatus, error) {
+	var status objects.IndexStatus
+	err := c.Get(ctx, "indexStatus", &status)
+
+	return status, errors.Wrap(err, "failed to Get index status")
+}
+
+func (c Client) Fts(ctx context.Context) (objects.FTS, error) {
 	var fts objects.FTS
-	err := c.Get("pools/default/buckets/@fts/stats", &fts)
+	err := c.Get(ctx, "pools/default/buckets/@fts/stats", &fts)
 
 	return fts, errors.Wrap(err, "failed to Get FTS stats")
 }
 
-func (c Client) Cbas() (objects.Analytics, error) {
+func (c Client) Cbas(ctx context.Context) (objects.Analytics, error) {
 	var cbas objects.Analytics
-	err := c.Get("pools/default/buckets/@cbas/stats", &cbas)
+	err := c.Get(ctx, "pools/default/buckets/@cbas/stats", &cbas)
 
 	return cbas, errors.Wrap(err, "failed to Get Analytics stats")
 }
 
-func (c Client) Eventing() (objects.Eventing, error) {
+// AnalyticsIngestion returns the ingestion state of every link and dataset
+// from the analytics service of the node.
+func (c Client) AnalyticsIngestion(ctx context.Context) (objects.AnalyticsIngestion, error) {
+	var ingestion objects.AnalyticsIngestion
+	err := c.AnalyticsAPIGet(ctx, "analytics/status/ingestion", &ingestion)
+
+	return ingestion, errors.Wrap(err, "failed to Get Analytics ingestion status")
+}
+
+func (c Client) Eventing(ctx context.Context) (objects.Eventing, error) {
 	var eventing objects.Eventing
-	err := c.Get("pools/default/buckets/@eventing/stats", &eventing)
+	err := c.Get(ctx, "pools/default/buckets/@eventing/stats", &eventing)
 
 	return eventing, errors.Wrap(err, "failed to Get eventing stats")
 }
 
-func (c Client) QueryNode(node string) (objects.Query, error) {
+// Events returns the results of /events, which requires Couchbase Server 7.1.
+func (c Client) Events(ctx context.Context) (objects.SystemEvents, error) {
+	var events objects.SystemEvents
+	err := c.Get(ctx, "events", &events)
+
+	return events, errors.Wrap(err, "failed to Get system events")
+}
+
+// AuditSettings returns the results of /settings/audit.
+func (c Client) AuditSettings(ctx context.Context) (objects.AuditSettings, error) {
+	var settings objects.AuditSettings
+	err := c.Get(ctx, "settings/audit", &settings)
+
+	return settings, errors.Wrap(err, "failed to Get audit settings")
+}
+
+// SecuritySettings returns the results of /settings/security.
+func (c Client) SecuritySettings(ctx context.Context) (objects.SecuritySettings, error) {
+	var settings objects.SecuritySettings
+	err := c.Get(ctx, "settings/security", &settings)
+
+	return settings, errors.Wrap(err, "failed to Get security settings")
+}
+
+// EncryptionAtRestSettings returns the results of
+// /settings/security/encryptionAtRest, which requires Couchbase Server 8.
+func (c Client) EncryptionAtRestSettings(ctx context.Context) (objects.EncryptionAtRestSettings, error) {
+	var settings objects.EncryptionAtRestSettings
+	err := c.Get(ctx, "settings/security/encryptionAtRest", &settings)
+
+	return settings, errors.Wrap(err, "failed to Get encryption at rest settings")
+}
+
+// StatsRange returns the cluster wide total of a stat over the last minute
+// from /pools/default/stats/range/<stat>, which requires Couchbase Server 7.
+func (c Client) StatsRange(ctx context.Context, stat string) (objects.StatsRange, error) {
+	var stats objects.StatsRange
+	err := c.Get(ctx, fmt.Sprintf("pools/default/stats/range/%s?start=-60&nodesAggregation=sum", stat), &stats)
+
+	return stats, errors.Wrapf(err, "failed to Get %s stats range", stat)
+}
+
+// NodeStatsRange returns each node's value of a stat over the last minute
+// from /pools/default/stats/range/<stat>, which requires Couchbase Server 7.
+func (c Client) NodeStatsRange(ctx context.Context, stat string) (objects.StatsRange, error) {
+	var stats objects.StatsRange
+	err := c.Get(ctx, fmt.Sprintf("pools/default/stats/range/%s?start=-60", stat), &stats)
+
+	return stats, errors.Wrapf(err, "failed to Get %s stats range", stat)
+}
+
+// BackupRepositories returns the active repositories from the backup service.
+func (c Client) BackupRepositories(ctx context.Context) ([]objects.BackupRepository, error) {
+	var repositories []objects.BackupRepository
+	err := c.BackupAPIGet(ctx, "api/v1/cluster/self/repository/active", &repositories)
+
+	return repositories, errors.Wrap(err, "failed to Get backup repositories")
+}
+
+// BackupRepositoryInfo returns the size and backups of an active repository.
+func (c Client) BackupRepositoryInfo(ctx context.Context, id string) (objects.BackupRepositoryInfo, error) {
+	var info objects.BackupRepositoryInfo
+	err := c.BackupAPIGet(ctx, fmt.Sprintf("api/v1/cluster/self/repository/active/%s/info", id), &info)
+
+	return info, errors.Wrapf(err, "failed to Get backup repository %s info", id)
+}
+
+// BackupTaskHistory returns the backup and merge tasks run against an active repository.
+func (c Client) BackupTaskHistory(ctx context.Context, id string) ([]objects.BackupTask, error) {
+	var tasks []objects.BackupTask
+	err := c.BackupAPIGet(ctx, fmt.Sprintf("api/v1/cluster/self/repository/active/%s/taskHistory", id), &tasks)
+
+	return tasks, errors.Wrapf(err, "failed to Get backup repository %s task history", id)
+}
+
+// DesignDocs returns the design documents of a bucket.
+func (c Client) DesignDocs(ctx context.Context, bucket string) (objects.DesignDocs, error) {
+	var ddocs objects.DesignDocs
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/%s/ddocs", bucket), &ddocs)
+
+	return ddocs, errors.Wrapf(err, "failed to Get design documents of %s", bucket)
+}
+
+// DesignDocInfo returns the view index information of a design document from
+// the views port, giving up when ctx is done.
+func (c Client) DesignDocInfo(ctx context.Context, bucket, ddoc string) (objects.DesignDocInfo, error) {
+	var info objects.DesignDocInfo
+
+	path := fmt.Sprintf("%s/%s%s/_info", bucket, objects.DesignDocPrefix, ddoc)
+	err := c.get(ctx, c.ViewsURL(path), path, &info)
+
+	return info, errors.Wrapf(err, "failed to Get design document %s info", ddoc)
+}
+
+// QueryService runs a read only statement on the query service of the node
+// and decodes its results into v.
+func (c Client) QueryService(ctx context.Context, statement string, v interface{}) error {
+	var response objects.QueryResponse
+
+	if err := c.QueryAPIGet(ctx, "query/service?statement="+url.QueryEscape(statement), &response); err != nil {
+		return err
+	}
+
+	if response.Status != "success" {
+		msgs := make([]string, 0, len(response.Errors))
+		for _, e := range response.Errors {
+			msgs = append(msgs, fmt.Sprintf("%d %s", e.Code, e.Msg))
+		}
+
+		return errors.Errorf("query returned %s: %s", response.Status, strings.Join(msgs, "; "))
+	}
+
+	return errors.Wrap(json.Unmarshal(response.Results, v), "failed to unmarshall query results")
+}
+
+// CompletedRequests returns the requests in the query service's log of
+// completed requests, other than those the exporter made to read it.
+func (c Client) CompletedRequests(ctx context.Context) ([]objects.CompletedRequest, error) {
+	var results []objects.CompletedRequest
+
+	if err := c.QueryService(ctx, objects.CompletedRequestsStatement, &results); err != nil {
+		return nil, errors.Wrap(err, "failed to Get completed requests")
+	}
+
+	requests := make([]objects.CompletedRequest, 0, len(results))
+
+	for _, request := range results {
+		if request.Statement != objects.CompletedRequestsStatement {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests, nil
+}
+
+// Prepareds returns the prepared statements in the plan cache of every query
+// node.
+func (c Client) Prepareds(ctx context.Context) ([]objects.Prepared, error) {
+	var prepareds []objects.Prepared
+	err := c.QueryService(ctx, objects.PreparedsStatement, &prepareds)
+
+	return prepareds, errors.Wrap(err, "failed to Get prepared statements")
+}
+
+// QueryVitals returns the vitals of the query service of the node.
+func (c Client) QueryVitals(ctx context.Context) (objects.QueryVitals, error) {
+	var vitals objects.QueryVitals
+	err := c.QueryAPIGet(ctx, "admin/vitals", &vitals)
+
+	return vitals, errors.Wrap(err, "failed to Get query vitals")
+}
+
+func (c Client) QueryNode(ctx context.Context, node string) (objects.Query, error) {
 	var query objects.Query
-	err := c.Get(fmt.Sprintf("pools/default/buckets/@query/nodes/%s/stats", node), &query)
+	err := c.Get(ctx, fmt.Sprintf("pools/default/buckets/@query/nodes/%s/stats", node), &query)
 
 	return query, errors.Wrap(err, "failed to Get query stats")
 }
 
 //
-func (c Client) IndexNode(node string) (objects.Index, error) {
+func (c Client) IndexNode(ctx context.Context, node string) (objects.Index, error) {
 	var index objects.Index
-	err := c.Get("pools/default/buckets/@index/stats", &index)
+	err := c.Get(ctx, "pools/default/buckets/@index/stats", &index)
 
 	return index, errors.Wrap(err, "failed to Get index stats")
 }
 
-func (c Client) IndexStats() (map[string]map[string]interface{}, error) {
+func (c Client) IndexStats(ctx context.Context) (map[string]map[string]interface{}, error) {
 	var data map[string]map[string]interface{}
-	err := c.IndexAPIGet("/api/v1/stats", &data)
+	err := c.IndexAPIGet(ctx, "/api/v1/stats", &data)
 
 	if err != nil {
 		return data, errors.Wrap(err, "Failed to get Indexer stats")
@@ -342,8 +875,8 @@ func (c Client) IndexStats() (map[string]map[string]interface{}, error) {
 }
 
 // potentially deprecated.
-func (c Client) GetCurrentNode() (objects.Node, error) {
-	nodes, err := c.Nodes()
+func (c Client) GetCurrentNode(ctx context.Context) (objects.Node, error) {
+	nodes, err := c.Nodes(ctx)
 
 	var retNode objects.Node
 
@@ -0,0 +1,165 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// MetricsHandler serves the metrics of a gatherer in the Prometheus text
+// format, or in the OpenMetrics format to scrapers that ask for it.  In the
+// OpenMetrics format each series of the counters the exporter reports about
+// itself is followed by a _created sample of when it was created.  The
+// counters read from Couchbase Server are not, as the exporter does not know
+// when the server created them.
+type MetricsHandler struct {
+	gatherer prometheus.Gatherer
+	text     http.Handler
+}
+
+func NewMetricsHandler(gatherer prometheus.Gatherer) *MetricsHandler {
+	return &MetricsHandler{
+		gatherer: gatherer,
+		text:     promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}),
+	}
+}
+
+func (h *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := expfmt.NegotiateIncludingOpenMetrics(r.Header)
+	if format != expfmt.FmtOpenMetrics {
+		h.text.ServeHTTP(w, r)
+		return
+	}
+
+	families, err := h.gatherer.Gather()
+	if err != nil {
+		log.Error("failed to gather metrics: %s", err)
+		http.Error(w, fmt.Sprintf("failed to gather metrics: %s", err), http.StatusInternalServerError)
+
+		return
+	}
+
+	var buf bytes.Buffer
+
+	for _, family := range families {
+		if err := writeOpenMetricsFamily(&buf, family); err != nil {
+			log.Error("failed to encode metrics: %s", err)
+			http.Error(w, fmt.Sprintf("failed to encode metrics: %s", err), http.StatusInternalServerError)
+
+			return
+		}
+	}
+
+	if _, err := expfmt.FinalizeOpenMetrics(&buf); err != nil {
+		http.Error(w, fmt.Sprintf("failed to encode metrics: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", string(format))
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Debug("failed to write metrics: %s", err)
+	}
+}
+
+// seriesKey identifies a series by its metric name and sorted label pairs.
+func seriesKey(name string, metric *dto.Metric) string {
+	pairs := make([]string, 0, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		pairs = append(pairs, label.GetName()+"="+label.GetValue())
+	}
+
+	sort.Strings(pairs)
+
+	return name + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// writeOpenMetricsFamily writes family to buf in the OpenMetrics format,
+// following each sample of a counter with its _created sample when the
+// exporter knows when the series was created.
+func writeOpenMetricsFamily(buf *bytes.Buffer, family *dto.MetricFamily) error {
+	if family.GetType() != dto.MetricType_COUNTER || !strings.HasSuffix(family.GetName(), "_total") {
+		_, err := expfmt.MetricFamilyToOpenMetrics(buf, family)
+		return err
+	}
+
+	createdName := strings.TrimSuffix(family.GetName(), "_total") + "_created"
+	gauge := dto.MetricType_GAUGE
+
+	for i, metric := range family.GetMetric() {
+		single := &dto.MetricFamily{
+			Name:   family.Name,
+			Help:   family.Help,
+			Type:   family.Type,
+			Metric: []*dto.Metric{metric},
+		}
+
+		// the samples of a counter must not be interleaved, so each series is
+		// written on its own and the comments are kept only for the first.
+		if err := writeSamples(buf, single, i == 0); err != nil {
+			return err
+		}
+
+		labels := make(map[string]string, len(metric.GetLabel()))
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		createdAt, ok := objects.CounterCreated(family.GetName(), labels)
+		if !ok {
+			continue
+		}
+
+		seconds := float64(createdAt.UnixNano()) / float64(time.Second)
+
+		createdFamily := &dto.MetricFamily{
+			Name:   &createdName,
+			Type:   &gauge,
+			Metric: []*dto.Metric{{Label: metric.Label, Gauge: &dto.Gauge{Value: &seconds}}},
+		}
+
+		if err := writeSamples(buf, createdFamily, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeSamples writes family to buf in the OpenMetrics format, leaving out its
+// HELP and TYPE comments unless comments is set.
+func writeSamples(buf *bytes.Buffer, family *dto.MetricFamily, comments bool) error {
+	var out bytes.Buffer
+	if _, err := expfmt.MetricFamilyToOpenMetrics(&out, family); err != nil {
+		return err
+	}
+
+	for _, line := range strings.SplitAfter(out.String(), "\n") {
+		if !comments && strings.HasPrefix(line, "# ") {
+			continue
+		}
+
+		buf.WriteString(line)
+	}
+
+	return nil
+}
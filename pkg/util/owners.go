@@ -0,0 +1,159 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	invalidOwnerLabels string = "invalid owner labels"
+)
+
+var (
+	ErrInvalidOwnerLabels = fmt.Errorf(invalidOwnerLabels)
+)
+
+// ownerLabelGatherer adds the labels mapped to each bucket in a file, such as
+// the team or service owning it, to the metrics of that bucket.
+type ownerLabelGatherer struct {
+	gatherer prometheus.Gatherer
+	path     string
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	owners  map[string][]*dto.LabelPair
+}
+
+// NewOwnerLabelGatherer wraps a gatherer so that every metric with a bucket
+// label also carries the labels mapped to that bucket in the JSON file at
+// path, an object of bucket names to objects of label names to values.  The
+// file is read again whenever it changes, and if it cannot be the previous
+// mapping is kept.  A metric's own label takes precedence over an owner label
+// of the same name.  gatherer is returned as it is if path is empty.
+func NewOwnerLabelGatherer(gatherer prometheus.Gatherer, path string) (prometheus.Gatherer, error) {
+	if path == "" {
+		return gatherer, nil
+	}
+
+	g := &ownerLabelGatherer{
+		gatherer: gatherer,
+		path:     path,
+	}
+
+	if err := g.reload(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// reload reads the mapping again if the file has changed since it was last
+// read.
+func (g *ownerLabelGatherer) reload() error {
+	info, err := os.Stat(g.path)
+	if err != nil {
+		return err
+	}
+
+	if info.ModTime().Equal(g.modTime) && info.Size() == g.size {
+		return nil
+	}
+
+	data, err := ioutil.ReadFile(g.path)
+	if err != nil {
+		return err
+	}
+
+	mapping := map[string]map[string]string{}
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return fmt.Errorf("%w in %s: %s", ErrInvalidOwnerLabels, g.path, err)
+	}
+
+	owners := make(map[string][]*dto.LabelPair, len(mapping))
+
+	for bucket, labels := range mapping {
+		for name, value := range labels {
+			if err := validateStaticLabelName(name); err != nil || name == objects.BucketLabel {
+				return fmt.Errorf("%w in %s: %q is not a valid owner label of bucket %s", ErrInvalidOwnerLabels, g.path, name, bucket)
+			}
+
+			owners[bucket] = append(owners[bucket], labelPair(name, value))
+		}
+	}
+
+	g.owners = owners
+	g.modTime = info.ModTime()
+	g.size = info.Size()
+
+	return nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *ownerLabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if err := g.reload(); err != nil {
+		log.Error("unable to reload owner labels, keeping the previous ones: %s", err)
+	}
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			metric.Label = g.withOwnerLabels(metric.Label)
+		}
+	}
+
+	return families, err
+}
+
+func (g *ownerLabelGatherer) withOwnerLabels(labels []*dto.LabelPair) []*dto.LabelPair {
+	existing := map[string]bool{}
+	bucket := ""
+
+	for _, label := range labels {
+		existing[label.GetName()] = true
+
+		if label.GetName() == objects.BucketLabel {
+			bucket = label.GetValue()
+		}
+	}
+
+	owners, ok := g.owners[bucket]
+	if !ok || bucket == "" {
+		return labels
+	}
+
+	for _, label := range owners {
+		if !existing[label.GetName()] {
+			labels = append(labels, label)
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+
+	return labels
+}
@@ -0,0 +1,96 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+)
+
+// ServiceProber checks that the port of each service of a node answers, to
+// catch a service that is wedged while the cluster manager still reports the
+// node healthy.
+type ServiceProber struct {
+	// Ports maps each service probed to its port.
+	Ports map[string]objects.ServicePort
+	// TLS selects the TLS port of each service, and HTTPS.
+	TLS     bool
+	Timeout time.Duration
+	// Client makes the requests to the HTTP services, with the TLS config
+	// used to reach the cluster.
+	Client *http.Client
+}
+
+// NewServiceProber creates a prober for the nodes of the cluster client
+// reads, that reaches their services as client does, over TLS if client uses
+// it.
+func NewServiceProber(client Client, timeout time.Duration) *ServiceProber {
+	httpClient := client.Client
+	httpClient.Timeout = timeout
+
+	return &ServiceProber{
+		Ports:   objects.ServicePorts,
+		TLS:     client.port == 18091,
+		Timeout: timeout,
+		Client:  &httpClient,
+	}
+}
+
+// Probes returns whether service is one the prober knows the port of.
+func (p *ServiceProber) Probes(service string) bool {
+	_, ok := p.Ports[service]
+	return ok
+}
+
+// Probe checks that the port of service on host accepts a connection, and
+// for HTTP services that it answers a request, with any status.
+func (p *ServiceProber) Probe(host, service string) error {
+	ports, ok := p.Ports[service]
+	if !ok {
+		return fmt.Errorf("no port known for service %s", service)
+	}
+
+	port, scheme := ports.Port, "http"
+	if p.TLS {
+		port, scheme = ports.TLSPort, "https"
+	}
+
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+
+	if !ports.HTTP {
+		conn, err := net.DialTimeout("tcp", address, p.Timeout)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+address+"/", nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := p.Client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
@@ -0,0 +1,261 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+const (
+	noRecordings string = "no recorded responses found"
+
+	recordedCyclePrefix = "cycle-"
+)
+
+var ErrNoRecordings = fmt.Errorf(noRecordings)
+
+// recordedResponse is a response to a request to Couchbase Server as saved
+// in a recording.  Only the response is saved, so no credentials are.
+type recordedResponse struct {
+	Method      string `json:"method"`
+	Host        string `json:"host"`
+	Path        string `json:"path"`
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body"`
+}
+
+// recordingKey identifies a request by its host and path, as the host is the
+// node for requests made to each node.
+func recordingKey(method, host, path string) string {
+	return method + " " + host + path
+}
+
+// recordedCycle holds the responses of a cycle by request, and by method and
+// path alone.
+type recordedCycle struct {
+	byHost map[string]recordedResponse
+	byPath map[string]recordedResponse
+}
+
+func recordedCycleDir(dir string, cycle int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s%06d", recordedCyclePrefix, cycle))
+}
+
+// RecordingTransport saves every response to a request made through it in a
+// directory for each cycle of the given refresh interval, so that they can be
+// replayed later with a ReplayTransport.
+type RecordingTransport struct {
+	dir       string
+	interval  time.Duration
+	start     time.Time
+	transport http.RoundTripper
+}
+
+func NewRecordingTransport(dir string, interval time.Duration, transport http.RoundTripper) *RecordingTransport {
+	return &RecordingTransport{dir: dir, interval: interval, start: time.Now(), transport: transport}
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	recorded := recordedResponse{
+		Method:      req.Method,
+		Host:        req.URL.Host,
+		Path:        req.URL.RequestURI(),
+		Status:      resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(body),
+	}
+
+	if err := t.save(recordingKey(req.Method, req.URL.Host, req.URL.RequestURI()), recorded); err != nil {
+		log.Warn("failed to record response from %s: %s", req.URL.Path, err)
+	}
+
+	return resp, nil
+}
+
+func (t *RecordingTransport) save(key string, recorded recordedResponse) error {
+	cycle := 0
+	if t.interval > 0 {
+		cycle = int(time.Since(t.start) / t.interval)
+	}
+
+	dir := recordedCycleDir(t.dir, cycle)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(recorded, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256([]byte(key))
+
+	return WriteFileAtomic(filepath.Join(dir, hex.EncodeToString(sum[:])+".json"), data, 0o644)
+}
+
+// ReplayTransport answers requests with the responses saved by a
+// RecordingTransport instead of making them, moving on to the next recorded
+// cycle at every refresh interval and starting again after the last.
+type ReplayTransport struct {
+	interval time.Duration
+	start    time.Time
+	cycles   []recordedCycle
+}
+
+// NewReplayTransport loads the recording in dir.
+func NewReplayTransport(dir string, interval time.Duration) (*ReplayTransport, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), recordedCyclePrefix) {
+			names = append(names, entry.Name())
+		}
+	}
+
+	sort.Strings(names)
+
+	t := &ReplayTransport{interval: interval, start: time.Now()}
+
+	for _, name := range names {
+		cycle, err := loadRecordedCycle(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		t.cycles = append(t.cycles, cycle)
+	}
+
+	if len(t.cycles) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoRecordings, dir)
+	}
+
+	return t, nil
+}
+
+func loadRecordedCycle(dir string) (recordedCycle, error) {
+	cycle := recordedCycle{byHost: map[string]recordedResponse{}, byPath: map[string]recordedResponse{}}
+
+	// Glob sorts the files, so the response used for a path recorded from
+	// several hosts is always the same one.
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return cycle, err
+	}
+
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return cycle, err
+		}
+
+		var recorded recordedResponse
+		if err := json.Unmarshal(data, &recorded); err != nil {
+			return cycle, fmt.Errorf("failed to parse recorded response %s: %w", file, err)
+		}
+
+		cycle.byHost[recordingKey(recorded.Method, recorded.Host, recorded.Path)] = recorded
+
+		if _, ok := cycle.byPath[recordingKey(recorded.Method, "", recorded.Path)]; !ok {
+			cycle.byPath[recordingKey(recorded.Method, "", recorded.Path)] = recorded
+		}
+	}
+
+	return cycle, nil
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, ok := t.find(req)
+	if !ok {
+		log.Debug("no recorded response for %s", req.URL.Path)
+
+		recorded = recordedResponse{Status: http.StatusNotFound, Body: noRecordings}
+	}
+
+	resp := &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{},
+		Body:          ioutil.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+
+	if recorded.ContentType != "" {
+		resp.Header.Set("Content-Type", recorded.ContentType)
+	}
+
+	return resp, nil
+}
+
+// find looks for the response to req in the current cycle and then in the
+// cycles before it, as not every request is made in every cycle.  Failing
+// that, a response from any host for the same path is used, so that a
+// recording can be replayed against another address.
+func (t *ReplayTransport) find(req *http.Request) (recordedResponse, bool) {
+	current := 0
+	if t.interval > 0 {
+		current = int(time.Since(t.start)/t.interval) % len(t.cycles)
+	}
+
+	byHost := recordingKey(req.Method, req.URL.Host, req.URL.RequestURI())
+	byPath := recordingKey(req.Method, "", req.URL.RequestURI())
+
+	for i := 0; i < len(t.cycles); i++ {
+		if recorded, ok := t.cycles[(current-i+len(t.cycles))%len(t.cycles)].byHost[byHost]; ok {
+			return recorded, true
+		}
+	}
+
+	for i := 0; i < len(t.cycles); i++ {
+		if recorded, ok := t.cycles[(current-i+len(t.cycles))%len(t.cycles)].byPath[byPath]; ok {
+			return recorded, true
+		}
+	}
+
+	return recordedResponse{}, false
+}
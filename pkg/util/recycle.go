@@ -0,0 +1,49 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+// idleConnectionCloser is a transport whose idle connections can be closed,
+// such as *http.Transport.
+type idleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+// RecycleConnections closes the idle connections of transport every lifetime
+// until ctx is done, so that the next request to a node dials it again and
+// looks its hostname up again.  Without this the pooled connections to a node
+// are kept for as long as they are used, and a node moved behind DNS, as by
+// DNS based failover, is only picked up on restart.  Between refreshes every
+// connection is idle, so no connection outlives lifetime by much more than a
+// refresh.  A lifetime of zero keeps connections for as long as they are used.
+func RecycleConnections(ctx context.Context, transport idleConnectionCloser, lifetime time.Duration) {
+	if lifetime <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(lifetime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Debug("closing idle connections to Couchbase Server to resolve its nodes again")
+			transport.CloseIdleConnections()
+		}
+	}
+}
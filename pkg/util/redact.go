@@ -0,0 +1,37 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import "github.com/couchbase/couchbase-exporter/pkg/log"
+
+// redactedError is an error whose message has its secrets redacted, as they
+// would be when logged, while errors.Is and errors.As still see the error it
+// wraps.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return log.Redact(e.err.Error())
+}
+
+func (e redactedError) Unwrap() error {
+	return e.err
+}
+
+// RedactError wraps err so that its message never carries the credentials of
+// the URL requested or any quoted in a response.
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return redactedError{err: err}
+}
@@ -0,0 +1,176 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	invalidRelabelRule string = "invalid relabel rule"
+	relabelConflict    string = "relabeling produced metrics of different types with the same name"
+)
+
+var (
+	ErrInvalidRelabelRule = fmt.Errorf(invalidRelabelRule)
+	ErrRelabelConflict    = fmt.Errorf(relabelConflict)
+)
+
+type relabelRule struct {
+	objects.RelabelRule
+	match *regexp.Regexp
+}
+
+// relabelGatherer applies the configured relabel rules to everything
+// gathered, so metrics can keep the names and labels of another exporter.
+type relabelGatherer struct {
+	gatherer prometheus.Gatherer
+	rules    []relabelRule
+}
+
+// NewRelabelGatherer wraps a gatherer with relabel rules, which are applied in
+// order to each metric name, each seeing the name left by the rules before it.
+func NewRelabelGatherer(gatherer prometheus.Gatherer, rules []objects.RelabelRule) (prometheus.Gatherer, error) {
+	if len(rules) == 0 {
+		return gatherer, nil
+	}
+
+	compiled := make([]relabelRule, 0, len(rules))
+
+	for i, rule := range rules {
+		match, err := regexp.Compile("^(?:" + rule.Match + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrInvalidRelabelRule, i, err)
+		}
+
+		if rule.Rename != "" && !strings.Contains(rule.Rename, "$") && !model.IsValidMetricName(model.LabelValue(rule.Rename)) {
+			return nil, fmt.Errorf("%w %d: %q is not a valid metric name", ErrInvalidRelabelRule, i, rule.Rename)
+		}
+
+		for name := range rule.Labels {
+			if !model.LabelName(name).IsValid() {
+				return nil, fmt.Errorf("%w %d: %q is not a valid label name", ErrInvalidRelabelRule, i, name)
+			}
+		}
+
+		compiled = append(compiled, relabelRule{RelabelRule: rule, match: match})
+	}
+
+	return &relabelGatherer{
+		gatherer: gatherer,
+		rules:    compiled,
+	}, nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *relabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	errs := prometheus.MultiError{}
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	byName := map[string]*dto.MetricFamily{}
+	relabelled := []*dto.MetricFamily{}
+
+	for _, family := range families {
+		if !g.relabel(family) {
+			continue
+		}
+
+		// renaming may merge families, which is only valid if they are of
+		// the same type.
+		if existing, ok := byName[family.GetName()]; ok {
+			if existing.GetType() != family.GetType() {
+				errs = append(errs, fmt.Errorf("%w: %s", ErrRelabelConflict, family.GetName()))
+				continue
+			}
+
+			existing.Metric = append(existing.Metric, family.Metric...)
+
+			continue
+		}
+
+		byName[family.GetName()] = family
+		relabelled = append(relabelled, family)
+	}
+
+	sort.Slice(relabelled, func(i, j int) bool {
+		return relabelled[i].GetName() < relabelled[j].GetName()
+	})
+
+	return relabelled, errs.MaybeUnwrap()
+}
+
+// relabel applies the rules to a family, returning false if it is dropped.
+func (g *relabelGatherer) relabel(family *dto.MetricFamily) bool {
+	for _, rule := range g.rules {
+		name := family.GetName()
+		if !rule.match.MatchString(name) {
+			continue
+		}
+
+		if rule.Drop {
+			return false
+		}
+
+		if rule.Rename != "" {
+			renamed := rule.match.ReplaceAllString(name, rule.Rename)
+			if model.IsValidMetricName(model.LabelValue(renamed)) {
+				family.Name = &renamed
+			} else {
+				log.Debug("not renaming %s to invalid metric name %q", name, renamed)
+			}
+		}
+
+		for _, metric := range family.Metric {
+			metric.Label = setLabels(metric.Label, rule.Labels)
+		}
+	}
+
+	return true
+}
+
+// setLabels sets the given labels on a metric, replacing any existing label of
+// the same name.
+func setLabels(labels []*dto.LabelPair, set map[string]string) []*dto.LabelPair {
+	if len(set) == 0 {
+		return labels
+	}
+
+	kept := make([]*dto.LabelPair, 0, len(labels)+len(set))
+
+	for _, label := range labels {
+		if _, ok := set[label.GetName()]; !ok {
+			kept = append(kept, label)
+		}
+	}
+
+	for name, value := range set {
+		name, value := name, value
+		kept = append(kept, &dto.LabelPair{Name: &name, Value: &value})
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		return kept[i].GetName() < kept[j].GetName()
+	})
+
+	return kept
+}
@@ -0,0 +1,81 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+// RequestIDHeader is the header each request to Couchbase Server is
+// identified by, when request IDs are enabled.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDTransport gives every request made through it an X-Request-ID of
+// its own and logs the request and its outcome by that ID at debug level, so
+// that a request the exporter logged can be found in the logs of a proxy or
+// load balancer in front of the cluster, and the other way around.  Errors
+// carry the ID too.
+type RequestIDTransport struct {
+	transport http.RoundTripper
+}
+
+func NewRequestIDTransport(transport http.RoundTripper) *RequestIDTransport {
+	return &RequestIDTransport{transport: transport}
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *RequestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := req.Header.Get(RequestIDHeader)
+
+	if id == "" {
+		id = newRequestID()
+
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+
+	start := time.Now()
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		log.Debug("%s %s %s %s failed after %s: %s", RequestIDHeader, id, req.Method, req.URL.Path, time.Since(start), err)
+
+		return nil, fmt.Errorf("%w (%s %s)", err, RequestIDHeader, id)
+	}
+
+	log.Debug("%s %s %s %s returned %d after %s", RequestIDHeader, id, req.Method, req.URL.Path, resp.StatusCode, time.Since(start))
+
+	return resp, nil
+}
+
+// RequestID returns the ID the response's request was sent with, if any.
+func RequestID(resp *http.Response) string {
+	if resp == nil || resp.Request == nil {
+		return ""
+	}
+
+	return resp.Request.Header.Get(RequestIDHeader)
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
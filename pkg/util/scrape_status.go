@@ -0,0 +1,103 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const upSuffix = "_up"
+
+// ScrapeStatus describes the last time the metrics were gathered.
+type ScrapeStatus struct {
+	Time            time.Time `json:"time"`
+	DurationSeconds float64   `json:"durationSeconds"`
+	Error           string    `json:"error,omitempty"`
+	// Up holds the value of every up metric gathered, by metric name, and is
+	// 0 for a collector that could not reach Couchbase Server.
+	Up map[string]float64 `json:"up"`
+}
+
+// ScrapeStatusGatherer remembers how the last gathering of the gatherer it
+// wraps went, and what it gathered, so that it can be reported without
+// collecting again.
+type ScrapeStatusGatherer struct {
+	gatherer prometheus.Gatherer
+
+	mu       sync.Mutex
+	last     ScrapeStatus
+	families []*dto.MetricFamily
+}
+
+func NewScrapeStatusGatherer(gatherer prometheus.Gatherer) *ScrapeStatusGatherer {
+	return &ScrapeStatusGatherer{gatherer: gatherer}
+}
+
+func (g *ScrapeStatusGatherer) Gather() ([]*dto.MetricFamily, error) {
+	start := time.Now()
+	families, err := g.gatherer.Gather()
+
+	status := ScrapeStatus{
+		Time:            start,
+		DurationSeconds: time.Since(start).Seconds(),
+		Up:              map[string]float64{},
+	}
+
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	for _, family := range families {
+		if !strings.HasSuffix(family.GetName(), upSuffix) {
+			continue
+		}
+
+		// a collector of several clusters is up only if all of them are.
+		up := 1.0
+
+		for _, metric := range family.GetMetric() {
+			if metric.GetGauge().GetValue() < up {
+				up = metric.GetGauge().GetValue()
+			}
+		}
+
+		status.Up[family.GetName()] = up
+	}
+
+	g.mu.Lock()
+	g.last = status
+	g.families = families
+	g.mu.Unlock()
+
+	return families, err
+}
+
+// LastScrape returns the status of the last gathering, which has a zero Time
+// if there has not been one yet.
+func (g *ScrapeStatusGatherer) LastScrape() ScrapeStatus {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.last
+}
+
+// LastFamilies returns what the last gathering returned, which must not be
+// modified, and nil if there has not been one yet.
+func (g *ScrapeStatusGatherer) LastFamilies() []*dto.MetricFamily {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.families
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/log"
@@ -14,6 +17,9 @@ import (
 
 const (
 	certAndKeyError = "please specify both cert and key arguments"
+
+	// UnixScheme prefixes the path of a Unix domain socket to listen on.
+	UnixScheme = "unix://"
 )
 
 var (
@@ -33,24 +39,61 @@ func (s *Server) Start(address string, handler http.Handler) {
 
 	s.err = make(chan error)
 
+	listener, err := listen(address)
+
 	go func() {
-		s.err <- s.server.ListenAndServe()
+		if err != nil {
+			s.err <- err
+			return
+		}
+
+		s.err <- s.server.Serve(listener)
 	}()
 }
 
 func (s *Server) StartWithTLS(address string, handler http.Handler, cert string, key string) {
 	s.server = &http.Server{
-		Addr:    address,
-		Handler: handler,
+		Addr:      address,
+		Handler:   handler,
+		TLSConfig: serverTLSConfig(),
 	}
 
 	s.err = make(chan error)
 
+	listener, err := listen(address)
+
 	go func() {
-		s.err <- s.server.ListenAndServeTLS(cert, key)
+		if err != nil {
+			s.err <- err
+			return
+		}
+
+		s.err <- s.server.ServeTLS(listener, cert, key)
 	}()
 }
 
+// listen opens a listener on address, which is either a TCP host:port, the
+// path of a Unix domain socket prefixed with unix:// or a socket passed by
+// systemd prefixed with systemd://.
+func listen(address string) (net.Listener, error) {
+	if strings.HasPrefix(address, SystemdScheme) {
+		return listenActivated(strings.TrimPrefix(address, SystemdScheme))
+	}
+
+	path := strings.TrimPrefix(address, UnixScheme)
+	if path == address {
+		return net.Listen("tcp", address)
+	}
+
+	// a socket left behind by an exporter that did not exit cleanly would
+	// otherwise stop us from listening.
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return net.Listen("unix", path)
+}
+
 func (s *Server) Restart() {
 	log.Info("Restarting server")
 
@@ -94,6 +137,11 @@ func (s *Server) pickStart(address string, handler http.Handler, cert string, ke
 	}
 
 	if useTLS {
+		if err := checkFIPSServerCertificate(cert, key); err != nil {
+			log.Error("%s", err)
+			os.Exit(1)
+		}
+
 		s.StartWithTLS(address, handler, cert, key)
 	} else {
 		s.Start(address, handler)
@@ -118,6 +166,34 @@ func (s *Server) restartWithTLSIfNecessary(cert string, key string) {
 	}
 }
 
+// serverTLSConfig returns the TLS configuration to serve with, which is only
+// restricted in FIPS mode.
+func serverTLSConfig() *tls.Config {
+	if !FIPSEnabled() {
+		return nil
+	}
+
+	config := &tls.Config{}
+	ApplyFIPS(config)
+
+	return config
+}
+
+// checkFIPSServerCertificate refuses, in FIPS mode, to serve with a
+// certificate signed with MD5 or SHA-1.
+func checkFIPSServerCertificate(cert string, key string) error {
+	if !FIPSEnabled() {
+		return nil
+	}
+
+	keypair, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return err
+	}
+
+	return CheckFIPSKeyPair(keypair)
+}
+
 func createTLSConfig(cert string, key string) (*tls.Config, error) {
 	keypair, err := tls.LoadX509KeyPair(cert, key)
 	if err != nil {
@@ -133,10 +209,43 @@ func createTLSConfig(cert string, key string) (*tls.Config, error) {
 	}, nil
 }
 
+// ServeAll serves handler on every address, each of which is restarted on
+// its own if it fails.  systemd is told the exporter is ready once every
+// address is being listened on.
+func ServeAll(addresses []string, handler http.Handler, cert string, key string) {
+	var started, wg sync.WaitGroup
+
+	started.Add(len(addresses))
+
+	for _, address := range addresses {
+		wg.Add(1)
+
+		go func(address string) {
+			defer wg.Done()
+
+			serve(address, handler, cert, key, started.Done)
+		}(address)
+	}
+
+	started.Wait()
+
+	if err := NotifySystemd("READY=1\nSTATUS=Serving metrics on " + strings.Join(addresses, ", ")); err != nil {
+		log.Warn("%s", err)
+	}
+
+	wg.Wait()
+}
+
 func Serve(address string, handler http.Handler, cert string, key string) {
+	serve(address, handler, cert, key, func() {})
+}
+
+// serve serves handler on address, calling started once it is listening.
+func serve(address string, handler http.Handler, cert string, key string, started func()) {
 	server := &Server{}
 
 	server.pickStart(address, handler, cert, key)
+	started()
 
 	for {
 		select {
@@ -0,0 +1,46 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+const (
+	healthyNodeStatus  = "healthy"
+	nodeNotReadyFormat = "node %s is %s"
+)
+
+// WaitForNodeInit polls the node the client is attached to every interval
+// until it has been initialized and reports itself healthy, so that a sidecar
+// started alongside Couchbase Server does not collect before the node is
+// ready.
+func WaitForNodeInit(ctx context.Context, client CbClient, interval time.Duration, maxRetries int) error {
+	return Retry(ctx, interval, maxRetries, func() (bool, error) {
+		node, err := client.GetCurrentNode(ctx)
+		if err != nil {
+			log.Info("Waiting for node initialization: %s", err)
+			return false, &RetryError{E: err}
+		}
+
+		if node.Status != healthyNodeStatus {
+			err := fmt.Errorf(nodeNotReadyFormat, node.Hostname, node.Status)
+			log.Info("Waiting for node initialization: %s", err)
+
+			return false, &RetryError{E: err}
+		}
+
+		return true, nil
+	})
+}
@@ -0,0 +1,106 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"math"
+	"sort"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ClusterState is the state of a cluster as last collected: the nodes and
+// buckets its series were labelled with, and the value of every series.
+type ClusterState struct {
+	Nodes   []string                 `json:"nodes"`
+	Buckets []string                 `json:"buckets"`
+	Metrics map[string][]SeriesState `json:"metrics"`
+}
+
+// SeriesState is the value of a series with the labels other than cluster.
+// The value is null if it is not a number, as JSON has no NaN.
+type SeriesState struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  *float64          `json:"value"`
+}
+
+// ClusterStates groups every series with a cluster label by cluster, leaving
+// out the exporter's own metrics.  Histograms and summaries are left out too,
+// their counts and sums being of little use without their buckets.
+func ClusterStates(families []*dto.MetricFamily) map[string]ClusterState {
+	states := map[string]ClusterState{}
+	nodes := map[string]map[string]bool{}
+	buckets := map[string]map[string]bool{}
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			cluster := clusterOf(metric)
+			if cluster == "" {
+				continue
+			}
+
+			value, ok := metricValue(family.GetType(), metric)
+			if !ok {
+				continue
+			}
+
+			state, ok := states[cluster]
+			if !ok {
+				state = ClusterState{Metrics: map[string][]SeriesState{}}
+				nodes[cluster] = map[string]bool{}
+				buckets[cluster] = map[string]bool{}
+			}
+
+			series := SeriesState{Labels: map[string]string{}}
+
+			for _, label := range metric.Label {
+				switch label.GetName() {
+				case objects.ClusterLabel:
+					continue
+				case objects.NodeLabel:
+					nodes[cluster][label.GetValue()] = true
+				case objects.BucketLabel:
+					buckets[cluster][label.GetValue()] = true
+				}
+
+				series.Labels[label.GetName()] = label.GetValue()
+			}
+
+			if !math.IsNaN(value) && !math.IsInf(value, 0) {
+				series.Value = &value
+			}
+
+			state.Metrics[family.GetName()] = append(state.Metrics[family.GetName()], series)
+			states[cluster] = state
+		}
+	}
+
+	for cluster, state := range states {
+		state.Nodes = sortedKeys(nodes[cluster])
+		state.Buckets = sortedKeys(buckets[cluster])
+		states[cluster] = state
+	}
+
+	return states
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
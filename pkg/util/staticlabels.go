@@ -0,0 +1,122 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	invalidStaticLabel string = "static labels must be given as name=value"
+)
+
+var (
+	ErrInvalidStaticLabel = fmt.Errorf(invalidStaticLabel)
+)
+
+// ParseStaticLabel parses a name=value pair given on the command line.
+func ParseStaticLabel(pair string) (string, string, error) {
+	parts := strings.SplitN(pair, "=", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("%w: %q", ErrInvalidStaticLabel, pair)
+	}
+
+	if err := validateStaticLabelName(parts[0]); err != nil {
+		return "", "", err
+	}
+
+	return parts[0], parts[1], nil
+}
+
+// ValidateStaticLabels checks the names of labels read from a config file.
+func ValidateStaticLabels(labels map[string]string) error {
+	for name := range labels {
+		if err := validateStaticLabelName(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateStaticLabelName(name string) error {
+	if !model.LabelName(name).IsValid() || strings.HasPrefix(name, model.ReservedLabelPrefix) {
+		return fmt.Errorf("%w: %q is not a valid label name", ErrInvalidStaticLabel, name)
+	}
+
+	return nil
+}
+
+// staticLabelGatherer adds the same labels to every metric gathered, including
+// those of the exporter itself, for fleets where the labels cannot be added
+// by relabeling in Prometheus.
+type staticLabelGatherer struct {
+	gatherer prometheus.Gatherer
+	labels   []*dto.LabelPair
+}
+
+// NewStaticLabelGatherer wraps a gatherer so that every metric it returns
+// carries the given labels.  A metric's own label takes precedence over a
+// static label of the same name.
+func NewStaticLabelGatherer(gatherer prometheus.Gatherer, labels map[string]string) prometheus.Gatherer {
+	if len(labels) == 0 {
+		return gatherer
+	}
+
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+
+	for name, value := range labels {
+		name, value := name, value
+		pairs = append(pairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+
+	return &staticLabelGatherer{
+		gatherer: gatherer,
+		labels:   pairs,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *staticLabelGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			metric.Label = g.withStaticLabels(metric.Label)
+		}
+	}
+
+	return families, err
+}
+
+func (g *staticLabelGatherer) withStaticLabels(labels []*dto.LabelPair) []*dto.LabelPair {
+	existing := map[string]bool{}
+	for _, label := range labels {
+		existing[label.GetName()] = true
+	}
+
+	for _, label := range g.labels {
+		if !existing[label.GetName()] {
+			labels = append(labels, label)
+		}
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].GetName() < labels[j].GetName()
+	})
+
+	return labels
+}
@@ -0,0 +1,136 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+)
+
+const (
+	// SystemdScheme prefixes the index of a socket passed to the exporter by
+	// systemd socket activation.
+	SystemdScheme = "systemd://"
+
+	// listenFdsStart is the first file descriptor systemd passes sockets on.
+	listenFdsStart = 3
+
+	notifySocket = "NOTIFY_SOCKET"
+	listenPID    = "LISTEN_PID"
+	listenFDs    = "LISTEN_FDS"
+	listenNames  = "LISTEN_FDNAMES"
+
+	unknownSocket string = "no such activated socket"
+)
+
+var (
+	ErrUnknownSocket = fmt.Errorf(unknownSocket)
+
+	activation struct {
+		once  sync.Once
+		files []*os.File
+	}
+)
+
+// activatedFiles returns the sockets systemd passed to the exporter, which
+// are only for this process if LISTEN_PID is its PID.  The variables are
+// cleared so that they are not passed on.
+func activatedFiles() []*os.File {
+	activation.once.Do(func() {
+		defer func() {
+			os.Unsetenv(listenPID)
+			os.Unsetenv(listenFDs)
+			os.Unsetenv(listenNames)
+		}()
+
+		if pid, err := strconv.Atoi(os.Getenv(listenPID)); err != nil || pid != os.Getpid() {
+			return
+		}
+
+		count, err := strconv.Atoi(os.Getenv(listenFDs))
+		if err != nil || count <= 0 {
+			return
+		}
+
+		names := strings.Split(os.Getenv(listenNames), ":")
+
+		for i := 0; i < count; i++ {
+			name := fmt.Sprintf("LISTEN_FD_%d", listenFdsStart+i)
+			if i < len(names) && names[i] != "" {
+				name = names[i]
+			}
+
+			activation.files = append(activation.files, os.NewFile(uintptr(listenFdsStart+i), name))
+		}
+	})
+
+	return activation.files
+}
+
+// ActivatedSockets returns an address for each socket systemd passed to the
+// exporter by socket activation, none if it was not socket activated.
+func ActivatedSockets() []string {
+	addresses := []string{}
+
+	for i, file := range activatedFiles() {
+		log.Info("using socket %s passed by systemd", file.Name())
+
+		addresses = append(addresses, SystemdScheme+strconv.Itoa(i))
+	}
+
+	return addresses
+}
+
+// listenActivated returns a listener on the activated socket with index.  The
+// socket itself stays open when the listener is closed, so that restarting a
+// server listens on it again.
+func listenActivated(index string) (net.Listener, error) {
+	files := activatedFiles()
+
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i >= len(files) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSocket, index)
+	}
+
+	return net.FileListener(files[i])
+}
+
+// NotifySystemd sends state, such as READY=1, to systemd if it started the
+// exporter as a Type=notify service.  It does nothing otherwise.
+func NotifySystemd(state string) error {
+	socket := os.Getenv(notifySocket)
+	if socket == "" {
+		return nil
+	}
+
+	// a leading @ stands for an abstract socket.
+	if strings.HasPrefix(socket, "@") {
+		socket = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+
+	return nil
+}
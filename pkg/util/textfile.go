@@ -0,0 +1,97 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+const (
+	textfileExtension      = ".prom"
+	textfileExtensionError = "textfile path must have a .prom extension to be picked up by node_exporter"
+)
+
+var (
+	ErrTextfileExtension = fmt.Errorf(textfileExtensionError)
+
+	// node_exporter exposes the go_ and process_ families of its own process
+	// and rejects a textfile repeating any of them, so the exporter's own are
+	// never written.
+	textfileExcludedPrefixes = []string{"go_", "process_"}
+)
+
+// TextfileWriter implements the Worker interface for CycleController, writing
+// everything gathered from the registry to a file in the format expected by
+// node_exporter's textfile collector.  This is used in place of serving
+// /metrics on hosts where the exporter is not allowed to listen on a port.
+type TextfileWriter struct {
+	path     string
+	gatherer prometheus.Gatherer
+}
+
+func NewTextfileWriter(path string, gatherer prometheus.Gatherer) (*TextfileWriter, error) {
+	if !strings.HasSuffix(path, textfileExtension) {
+		return nil, ErrTextfileExtension
+	}
+
+	return &TextfileWriter{
+		path:     path,
+		gatherer: gatherer,
+	}, nil
+}
+
+// Implements Worker interface for CycleController.
+func (w *TextfileWriter) DoWork(_ context.Context) {
+	if err := w.Write(); err != nil {
+		log.Error("unable to write textfile %s: %s", w.path, err)
+	}
+}
+
+// Write gathers all metrics and atomically replaces the textfile, as
+// node_exporter may read it at any time.
+func (w *TextfileWriter) Write() error {
+	families, err := w.gatherer.Gather()
+	if err != nil {
+		// Gather returns whatever it could collect alongside the error, so
+		// write out the partial result rather than leaving the file stale.
+		log.Warn("error gathering metrics for textfile: %s", err)
+	}
+
+	var buf bytes.Buffer
+
+	for _, family := range families {
+		if textfileExcluded(family.GetName()) {
+			continue
+		}
+
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", family.GetName(), err)
+		}
+	}
+
+	return WriteFileAtomic(w.path, buf.Bytes(), 0644)
+}
+
+func textfileExcluded(name string) bool {
+	for _, prefix := range textfileExcludedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	clientConnectionsVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "client_connections_total",
+			Help:      "Number of connections requests to Couchbase Server were made on, by whether the connection was reused from the pool",
+		},
+		[]string{"reused"})
+	clientRequestsVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "client_requests_total",
+			Help:      "Number of responses from Couchbase Server, by the protocol they were made with",
+		},
+		[]string{"protocol"})
+	clientDNSDuration = objects.NewExporterHistogram(
+		prometheus.HistogramOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "client_dns_duration_seconds",
+			Help:      "Time taken to look up the address of a Couchbase Server node for a new connection",
+			Buckets:   []float64{.001, .005, .01, .05, .1, .5, 1, 5},
+		})
+	clientTLSHandshakeDuration = objects.NewExporterHistogram(
+		prometheus.HistogramOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "client_tls_handshake_duration_seconds",
+			Help:      "Time taken by the TLS handshake of a new connection to Couchbase Server",
+			Buckets:   []float64{.005, .01, .05, .1, .5, 1, 5, 10},
+		})
+)
+
+// TraceTransport counts whether the requests made through it were made on a
+// new or a reused connection, and with which protocol, and times the DNS
+// lookups and TLS handshakes of new connections, to show how well the
+// connection pool serves a large cluster.
+type TraceTransport struct {
+	transport http.RoundTripper
+}
+
+func NewTraceTransport(transport http.RoundTripper) *TraceTransport {
+	return &TraceTransport{transport: transport}
+}
+
+// RoundTrip implements the RoundTripper interface.
+func (t *TraceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var dnsStart, tlsStart time.Time
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			clientConnectionsVec.WithLabelValues(strconv.FormatBool(info.Reused)).Inc()
+		},
+		DNSStart: func(httptrace.DNSStartInfo) {
+			dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !dnsStart.IsZero() {
+				clientDNSDuration.Observe(time.Since(dnsStart).Seconds())
+			}
+		},
+		TLSHandshakeStart: func() {
+			tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !tlsStart.IsZero() {
+				clientTLSHandshakeDuration.Observe(time.Since(tlsStart).Seconds())
+			}
+		},
+	}
+
+	resp, err := t.transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		return nil, err
+	}
+
+	clientRequestsVec.WithLabelValues(resp.Proto).Inc()
+
+	return resp, nil
+}
@@ -0,0 +1,152 @@
+//  Copyright (c) 2021 Couchbase, Inc.
+//  Licensed under the Apache License, Version 2.0 (the "License"); you may not use this file
+//  except in compliance with the License. You may obtain a copy of the License at
+//    http://www.apache.org/licenses/LICENSE-2.0
+//  Unless required by applicable law or agreed to in writing, software distributed under the
+//  License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND,
+//  either express or implied. See the License for the specific language governing permissions
+//  and limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	invalidValueGuard string = "invalid value guard"
+
+	boundMin = "min"
+	boundMax = "max"
+)
+
+var (
+	ErrInvalidValueGuard = fmt.Errorf(invalidValueGuard)
+
+	valueGuardAnomaliesVec = objects.NewExporterCounterVec(
+		prometheus.CounterOpts{
+			Namespace: objects.ExporterNamespace,
+			Name:      "value_guard_anomalies_total",
+			Help:      "Number of times a series was gathered with a value outside the bounds of its value guard",
+		},
+		[]string{"metric", "bound"})
+)
+
+type valueGuard struct {
+	objects.ValueGuard
+	match *regexp.Regexp
+}
+
+// valueGuardGatherer counts, and optionally drops, the series whose value is
+// outside the bounds of the first value guard matching their metric.
+type valueGuardGatherer struct {
+	gatherer prometheus.Gatherer
+	guards   []valueGuard
+}
+
+// NewValueGuardGatherer wraps a gatherer with value guards, so that a bad
+// sample is counted in cbexporter_value_guard_anomalies_total and, if its
+// guard says so, dropped rather than poisoning dashboards.
+func NewValueGuardGatherer(gatherer prometheus.Gatherer, guards []objects.ValueGuard) (prometheus.Gatherer, error) {
+	if len(guards) == 0 {
+		return gatherer, nil
+	}
+
+	compiled := make([]valueGuard, 0, len(guards))
+
+	for i, guard := range guards {
+		match, err := regexp.Compile("^(?:" + guard.Match + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("%w %d: %s", ErrInvalidValueGuard, i, err)
+		}
+
+		if guard.Min == nil && guard.Max == nil {
+			return nil, fmt.Errorf("%w %d: neither min nor max is set", ErrInvalidValueGuard, i)
+		}
+
+		if guard.Min != nil && guard.Max != nil && *guard.Min > *guard.Max {
+			return nil, fmt.Errorf("%w %d: min is greater than max", ErrInvalidValueGuard, i)
+		}
+
+		compiled = append(compiled, valueGuard{ValueGuard: guard, match: match})
+	}
+
+	return &valueGuardGatherer{
+		gatherer: gatherer,
+		guards:   compiled,
+	}, nil
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *valueGuardGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	kept := families[:0]
+
+	for _, family := range families {
+		guard, ok := g.guard(family.GetName())
+		if !ok {
+			kept = append(kept, family)
+			continue
+		}
+
+		metrics := family.Metric[:0]
+
+		for _, metric := range family.Metric {
+			value, ok := metricValue(family.GetType(), metric)
+
+			bound := ""
+			if ok {
+				bound = guard.violated(value)
+			}
+
+			if bound != "" {
+				valueGuardAnomaliesVec.WithLabelValues(family.GetName(), bound).Inc()
+
+				if guard.Drop {
+					continue
+				}
+			}
+
+			metrics = append(metrics, metric)
+		}
+
+		if len(metrics) > 0 {
+			family.Metric = metrics
+			kept = append(kept, family)
+		}
+	}
+
+	return kept, err
+}
+
+func (g *valueGuardGatherer) guard(name string) (valueGuard, bool) {
+	for _, guard := range g.guards {
+		if guard.match.MatchString(name) {
+			return guard, true
+		}
+	}
+
+	return valueGuard{}, false
+}
+
+// violated returns the bound value is outside of, if any.  NaN, which stands
+// for a missing sample, is left alone.
+func (g valueGuard) violated(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return ""
+	case g.Min != nil && value < *g.Min:
+		return boundMin
+	case g.Max != nil && value > *g.Max:
+		return boundMax
+	default:
+		return ""
+	}
+}
@@ -8,6 +8,10 @@ import (
 
 const Application = "Couchbase Exporter"
 
+// Product names the exporter in its user agent, where it must be a single
+// token.
+const Product = "couchbase-exporter"
+
 var (
 	Version     string
 	BuildNumber string
@@ -44,5 +48,10 @@ func WithBuildNumberAndRevision() string {
 // what unique version of the Exporter has been interacting with Couchbase
 // server.
 func UserAgent() string {
-	return fmt.Sprintf("%s/%s (commit/%s; build/%s)", Application, Version, revision.Revision(), BuildNumber)
+	v := WithRevision()
+	if v == "" {
+		v = "unknown"
+	}
+
+	return fmt.Sprintf("%s/%s (commit/%s; build/%s)", Product, v, revision.Revision(), BuildNumber)
 }
@@ -0,0 +1,59 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveFrom(handler http.Handler, remoteAddr string) int {
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.RemoteAddr = remoteAddr
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestAllowlistHandlerRefusesClientsOutsideNetworks(t *testing.T) {
+	handler, err := util.NewAllowlistHandler([]string{"10.0.0.0/8", " fd00::/8"}, http.NotFoundHandler())
+	assert.Nil(t, err)
+
+	assert.Equal(t, http.StatusNotFound, serveFrom(handler, "10.1.2.3:40000"))
+	assert.Equal(t, http.StatusNotFound, serveFrom(handler, "[fd00::1]:40000"))
+	assert.Equal(t, http.StatusNotFound, serveFrom(handler, "@"))
+	assert.Equal(t, http.StatusForbidden, serveFrom(handler, "192.168.0.1:40000"))
+}
+
+func TestAllowlistHandlerRejectsInvalidCIDR(t *testing.T) {
+	_, err := util.NewAllowlistHandler([]string{"10.0.0.0"}, http.NotFoundHandler())
+
+	assert.ErrorIs(t, err, util.ErrInvalidCIDR)
+}
+
+func TestLimitHandlerRefusesRequestsOverLimit(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := util.NewLimitHandler(1, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entered <- struct{}{}
+		<-release
+	}))
+
+	done := make(chan int)
+
+	go func() {
+		done <- serveFrom(handler, "10.1.2.3:40000")
+	}()
+
+	<-entered
+
+	assert.Equal(t, http.StatusServiceUnavailable, serveFrom(handler, "10.1.2.3:40001"))
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+}
@@ -0,0 +1,126 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	io_prometheus_client "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectValues returns the collected values keyed by metric name and the
+// values of any labels other than cluster.
+func collectValues(t *testing.T, collector prometheus.Collector) map[string]float64 {
+	c := make(chan prometheus.Metric)
+
+	go func() {
+		collector.Collect(c)
+		close(c)
+	}()
+
+	values := map[string]float64{}
+
+	for m := range c {
+		var metric io_prometheus_client.Metric
+		assert.Nil(t, m.Write(&metric))
+
+		key := test.GetFQNameFromDesc(m.Desc())
+
+		for _, label := range metric.GetLabel() {
+			if label.GetName() != objects.ClusterLabel {
+				key += "/" + label.GetValue()
+			}
+		}
+
+		if metric.Counter != nil {
+			values[key] = metric.GetCounter().GetValue()
+		} else {
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+
+	return values
+}
+
+func TestAlertsCollectReportsUIAlertsAndEvents(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{
+		Alerts: []objects.Alert{
+			{Msg: "Approaching full disk warning"},
+			{Msg: "Approaching full disk warning"},
+			{Msg: "Hard out of memory error"},
+		},
+	}, nil)
+	mockClient.EXPECT().Events(gomock.Any()).Times(1).Return(objects.SystemEvents{
+		Events: []objects.SystemEvent{
+			{Severity: "info"},
+			{Severity: "info"},
+			{Severity: "error"},
+		},
+	}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewAlertsCollector(mockClient, defaultConfig.Collectors.Alerts, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"cbalerts_ui_alerts": 3,
+		"cbalerts_ui_alert_info/Approaching full disk warning": 1,
+		"cbalerts_ui_alert_info/Hard out of memory error":      1,
+		"cbalerts_events/info":                                 2,
+		"cbalerts_events/error":                                1,
+		"cbalerts_up":                                          1,
+		"cbalerts_scrape_duration_seconds":                     values["cbalerts_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestAlertsCollectToleratesMissingEventLog(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, nil)
+	mockClient.EXPECT().Events(gomock.Any()).Times(1).Return(objects.SystemEvents{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewAlertsCollector(mockClient, defaultConfig.Collectors.Alerts, labelManager))
+
+	assert.Equal(t, 1.0, values["cbalerts_up"])
+	assert.Equal(t, 0.0, values["cbalerts_ui_alerts"])
+	assert.NotContains(t, values, "cbalerts_events/info")
+}
+
+func TestAlertsCollectReturnsDownIfClientReturnsErrorOnNodes(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewAlertsCollector(mockClient, defaultConfig.Collectors.Alerts, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbalerts_up": 0}, values)
+}
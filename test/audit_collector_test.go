@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditCollectReportsSettingsAndDroppedEvents(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	var stats objects.StatsRange
+	assert.Nil(t, json.Unmarshal([]byte(`{"data": [{"metric": {}, "values": [[1620000000, "3"], [1620000010, "5"]]}]}`), &stats))
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().AuditSettings(gomock.Any()).Times(1).Return(objects.AuditSettings{
+		AuditdEnabled:  true,
+		RotateInterval: 86400,
+		RotateSize:     20971520,
+		Disabled:       []int{8243, 8255},
+	}, nil)
+	mockClient.EXPECT().StatsRange(gomock.Any(), objects.AuditDroppedEventsStat).Times(1).Return(stats, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewAuditCollector(mockClient, defaultConfig.Collectors.Audit, labelManager))
+
+	assert.Equal(t, 1.0, values["cbaudit_enabled"])
+	assert.Equal(t, 86400.0, values["cbaudit_rotate_interval_seconds"])
+	assert.Equal(t, 20971520.0, values["cbaudit_rotate_size_bytes"])
+	assert.Equal(t, 2.0, values["cbaudit_disabled_events"])
+	assert.Equal(t, 5.0, values["cbaudit_dropped_events_total"])
+	assert.Equal(t, 1.0, values["cbaudit_up"])
+}
+
+func TestAuditCollectToleratesMissingStatsAPI(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().AuditSettings(gomock.Any()).Times(1).Return(objects.AuditSettings{}, nil)
+	mockClient.EXPECT().StatsRange(gomock.Any(), objects.AuditDroppedEventsStat).Times(1).Return(objects.StatsRange{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewAuditCollector(mockClient, defaultConfig.Collectors.Audit, labelManager))
+
+	assert.Equal(t, 1.0, values["cbaudit_up"])
+	assert.Equal(t, 0.0, values["cbaudit_enabled"])
+	assert.NotContains(t, values, "cbaudit_dropped_events_total")
+}
+
+func TestAuditCollectReturnsDownIfClientReturnsError(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().AuditSettings(gomock.Any()).Times(1).Return(objects.AuditSettings{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewAuditCollector(mockClient, defaultConfig.Collectors.Audit, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbaudit_up": 0}, values)
+}
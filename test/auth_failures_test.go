@@ -0,0 +1,226 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStatusServer(t *testing.T, statuses map[string]int, requests *int32) (*httptest.Server, util.Client) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+
+		if status, ok := statuses[r.URL.Path]; ok {
+			w.WriteHeader(status)
+			return
+		}
+
+		_, _ = w.Write([]byte("{}"))
+	}))
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	return server, util.NewClient("http://"+u.Hostname(), port, "exporter", "wrong", nil)
+}
+
+func TestClientStopsRequestingAfterUnauthorized(t *testing.T) {
+	var requests int32
+
+	server, client := newStatusServer(t, map[string]int{"/pools/default": http.StatusUnauthorized}, &requests)
+	defer server.Close()
+
+	var nodes objects.Nodes
+
+	err := client.Get(context.Background(), "pools/default", &nodes)
+	assert.ErrorIs(t, err, util.ErrUnauthorized)
+	assert.Contains(t, err.Error(), "exporter")
+
+	// bad credentials apply to every endpoint, so nothing else is requested.
+	var tasks []objects.Task
+
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrUnauthorized)
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default/tasks", &tasks), util.ErrUnauthorized)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+}
+
+func TestClientStopsRequestingForbiddenEndpoints(t *testing.T) {
+	var requests int32
+
+	server, client := newStatusServer(t, map[string]int{"/pools/default/tasks": http.StatusForbidden}, &requests)
+	defer server.Close()
+
+	var tasks []objects.Task
+
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default/tasks", &tasks), util.ErrForbidden)
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default/tasks", &tasks), util.ErrForbidden)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// other endpoints are still requested.
+	var nodes objects.Nodes
+
+	assert.Nil(t, client.Get(context.Background(), "pools/default", &nodes))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestClientAbandonsRequestsAfterTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	client := util.NewClient("http://"+u.Hostname(), port, "exporter", "password", nil).WithTimeout(20 * time.Millisecond)
+
+	var pools objects.Pools
+
+	err = client.Get(context.Background(), "pools", &pools)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientRequestsAgainAfterAuthBackoff(t *testing.T) {
+	var requests int32
+
+	var status int32 = http.StatusUnauthorized
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if s := atomic.LoadInt32(&status); s != http.StatusOK {
+			w.WriteHeader(int(s))
+			return
+		}
+
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	client := util.NewClient("http://"+u.Hostname(), port, "exporter", "rotated", nil).WithAuthBackoff(20 * time.Millisecond)
+
+	var nodes objects.Nodes
+
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrUnauthorized)
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrUnauthorized)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// once the backoff has passed the credentials are tried again, and a
+	// success forgets the rejection.
+	atomic.StoreInt32(&status, http.StatusOK)
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Nil(t, client.Get(context.Background(), "pools/default", &nodes))
+	assert.Nil(t, client.Get(context.Background(), "pools/default/tasks", &nodes))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+
+	atomic.StoreInt32(&status, http.StatusForbidden)
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrForbidden)
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Nil(t, client.Get(context.Background(), "pools/default", &nodes))
+	assert.Equal(t, int32(5), atomic.LoadInt32(&requests))
+}
+
+func TestClientProbesWithOneRequestAfterAuthBackoff(t *testing.T) {
+	var requests int32
+
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the first request is rejected, and the probe after the backoff is
+		// held until every other request has been made.
+		if atomic.AddInt32(&requests, 1) > 1 {
+			<-release
+		}
+
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	client := util.NewClient("http://"+u.Hostname(), port, "exporter", "wrong", nil).WithAuthBackoff(20 * time.Millisecond)
+
+	var nodes objects.Nodes
+
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrUnauthorized)
+	time.Sleep(30 * time.Millisecond)
+
+	probed := make(chan error)
+
+	go func() {
+		var nodes objects.Nodes
+		probed <- client.Get(context.Background(), "pools/default", &nodes)
+	}()
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&requests) == 2 }, time.Second, time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, client.Get(context.Background(), "pools/default/tasks", &nodes), util.ErrUnauthorized)
+	}
+
+	close(release)
+
+	assert.ErrorIs(t, <-probed, util.ErrUnauthorized)
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrUnauthorized)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestEndpointTemplateHidesNames(t *testing.T) {
+	assert.Equal(t, "pools/default/buckets/{bucket}/stats", util.EndpointTemplate("pools/default/buckets/travel-sample/stats"))
+	assert.Equal(t, "pools/default/buckets/{bucket}/nodes/{node}/stats", util.EndpointTemplate("pools/default/buckets/default/nodes/10.0.0.1:8091/stats"))
+	assert.Equal(t, "pools/default/buckets/@xdcr-{bucket}/stats", util.EndpointTemplate("pools/default/buckets/@xdcr-default/stats"))
+	assert.Equal(t, "pools/default/buckets/@query/stats", util.EndpointTemplate("pools/default/buckets/@query/stats"))
+	assert.Equal(t, "pools/default/stats/range/{stat}", util.EndpointTemplate("pools/default/stats/range/kv_ops?start=-60"))
+	assert.Equal(t, "{bucket}/_design/{ddoc}/_info", util.EndpointTemplate("default/_design/dev_beers/_info"))
+	assert.Equal(t, "pools/nodes", util.EndpointTemplate("pools/nodes"))
+}
+
+func TestClientWhoAmIClearsUnauthorized(t *testing.T) {
+	var requests int32
+
+	server, client := newStatusServer(t, map[string]int{"/pools/default": http.StatusUnauthorized}, &requests)
+	defer server.Close()
+
+	var nodes objects.Nodes
+
+	assert.ErrorIs(t, client.Get(context.Background(), "pools/default", &nodes), util.ErrUnauthorized)
+
+	// whoami is requested regardless, and its success lets other requests
+	// through again.
+	_, err := client.WhoAmI(context.Background())
+	assert.Nil(t, err)
+
+	var pools objects.Pools
+
+	assert.Nil(t, client.Get(context.Background(), "pools", &pools))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupCollectReportsRepositoryMetrics(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	node.Services = append(node.Services, objects.BackupServiceName)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(node, nil)
+	mockClient.EXPECT().BackupRepositories(gomock.Any()).Times(1).Return([]objects.BackupRepository{
+		{ID: "nightly", PlanName: "daily"},
+	}, nil)
+	mockClient.EXPECT().BackupRepositoryInfo(gomock.Any(), "nightly").Times(1).Return(objects.BackupRepositoryInfo{Size: 1024}, nil)
+	mockClient.EXPECT().BackupTaskHistory(gomock.Any(), "nightly").Times(1).Return([]objects.BackupTask{
+		{Type: "BACKUP", Status: "done", Start: "2021-06-01T00:00:00Z", End: "2021-06-01T00:10:00Z"},
+		{Type: "BACKUP", Status: "done", Start: "2021-06-02T00:00:00.5Z", End: "2021-06-02T00:05:00.5Z"},
+		{Type: "BACKUP", Status: "failed", Start: "2021-06-03T00:00:00Z", End: "2021-06-03T00:01:00Z"},
+		{Type: "MERGE", Status: "done", Start: "2021-06-02T01:00:00Z", End: "2021-06-02T01:00:30Z"},
+	}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewBackupCollector(mockClient, defaultConfig.Collectors.Backup, labelManager))
+
+	lastSuccess, _ := time.Parse(time.RFC3339, "2021-06-02T00:05:00Z")
+
+	assert.Equal(t, 1024.0, values["cbbackup_repository_size_bytes/daily/nightly"])
+	assert.Equal(t, float64(lastSuccess.Unix()), values["cbbackup_last_success_timestamp_seconds/daily/nightly"])
+	assert.Equal(t, 300.0, values["cbbackup_task_duration_seconds/daily/nightly/backup"])
+	assert.Equal(t, 30.0, values["cbbackup_task_duration_seconds/daily/nightly/merge"])
+	assert.Equal(t, 1.0, values["cbbackup_failed_tasks/daily/nightly"])
+	assert.Equal(t, 1.0, values["cbbackup_up"])
+}
+
+func TestBackupCollectSkipsNodesWithoutBackupService(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(test.GenerateNode(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewBackupCollector(mockClient, defaultConfig.Collectors.Backup, labelManager))
+
+	assert.Equal(t, 1.0, values["cbbackup_up"])
+	assert.Len(t, values, 2)
+}
+
+func TestBackupCollectReturnsDownIfClientReturnsError(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	node.Services = append(node.Services, objects.BackupServiceName)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(node, nil)
+	mockClient.EXPECT().BackupRepositories(gomock.Any()).Times(1).Return(nil, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewBackupCollector(mockClient, defaultConfig.Collectors.Backup, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbbackup_up": 0}, values)
+}
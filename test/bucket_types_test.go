@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketStatApplies(t *testing.T) {
+	for _, tc := range []struct {
+		bucketType string
+		stat       string
+		applies    bool
+	}{
+		{objects.CouchbaseBucketType, "couch_docs_fragmentation", true},
+		{objects.CouchbaseBucketType, "vb_active_queue_size", true},
+		{objects.EphemeralBucketType, "couch_docs_fragmentation", false},
+		{objects.EphemeralBucketType, "ep_diskqueue_items", false},
+		{objects.EphemeralBucketType, "vb_active_queue_size", false},
+		{objects.EphemeralBucketType, "vb_active_resident_items_ratio", true},
+		{objects.EphemeralBucketType, "ep_oom_errors", true},
+		{objects.MemcachedBucketType, "ep_oom_errors", false},
+		{objects.MemcachedBucketType, "vb_active_num", false},
+		{objects.MemcachedBucketType, "curr_items_tot", false},
+		{objects.MemcachedBucketType, "curr_items", true},
+		{objects.MemcachedBucketType, "cmd_get", true},
+		{"unknown", "couch_docs_fragmentation", true},
+	} {
+		assert.Equal(t, tc.applies, objects.BucketStatApplies(tc.bucketType, tc.stat), tc.bucketType+" "+tc.stat)
+	}
+}
+
+func TestBucketStatsSkipsStatsOtherBucketTypesDoNotReport(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	cache := test.GenerateBucket("cache")
+	cache.BucketType = objects.MemcachedBucketType
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{cache}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "cache").Times(1).Return(test.GenerateBucketStats(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	collector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	collector.DoWork(context.Background())
+
+	values := collectValues(t, &collector)
+
+	assert.Contains(t, values, "cbbucketstat_cmd_get/cache")
+	assert.Contains(t, values, "cbbucketstat_curr_items/cache")
+	assert.NotContains(t, values, "cbbucketstat_ep_oom_errors/cache")
+	assert.NotContains(t, values, "cbbucketstat_couch_docs_fragmentation/cache")
+	assert.NotContains(t, values, "cbbucketstat_vbuckets_active_num/cache")
+}
+
+func TestBucketInfoLabelsBucketType(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	data := test.GenerateBucketInfo("data")
+	data.BucketType = objects.CouchbaseBucketType
+	sessions := test.GenerateBucketInfo("sessions")
+	sessions.BucketType = objects.EphemeralBucketType
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{data, sessions}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager))
+
+	assert.Equal(t, data.BucketBasicStats[objects.ItemCount], values["cbbucketinfo_basic_itemcount/data/couchbase"])
+	assert.Equal(t, sessions.BucketBasicStats[objects.ItemCount], values["cbbucketinfo_basic_itemcount/sessions/ephemeral"])
+}
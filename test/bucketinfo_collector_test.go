@@ -25,6 +25,16 @@ var (
 	ErrDummy = fmt.Errorf(DummyError)
 )
 
+// bucketInfoValue returns the value the bucket info collector exports for the
+// metric key of a bucket.
+func bucketInfoValue(bucket objects.BucketInfo, key string) float64 {
+	if key == objects.BucketMaxTTL {
+		return float64(bucket.MaxTTL)
+	}
+
+	return bucket.BucketBasicStats[key]
+}
+
 func TestBucketInfoDescribeReturnsAppropriateValuesBasedOnDefaultConfig(t *testing.T) {
 	defaultConfig := config.GetDefaultConfig()
 	mockCtrl := gomock.NewController(t)
@@ -34,7 +44,7 @@ func TestBucketInfoDescribeReturnsAppropriateValuesBasedOnDefaultConfig(t *testi
 	mockClient := mocks.NewMockCbClient(mockCtrl)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
-	c := make(chan *prometheus.Desc, 9)
+	c := make(chan *prometheus.Desc, 10)
 	testCollector.Describe(c)
 	close(c)
 
@@ -97,7 +107,7 @@ func TestBucketInfoDescribeReturnsAppropriateValuesWithNameOverride(t *testing.T
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
-	c := make(chan *prometheus.Desc, 9)
+	c := make(chan *prometheus.Desc, 10)
 	testCollector.Describe(c)
 	close(c)
 
@@ -148,7 +158,7 @@ func TestBucketInfoCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
@@ -170,13 +180,13 @@ func TestBucketInfoCollectReturnsDownIfClientReturnsErrorOnBuckets(t *testing.T)
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, ErrDummy)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
@@ -198,21 +208,21 @@ func TestBucketInfoCollectReturnsUpWithNoErrorsWithAutomaticCompactionBoolean(t
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucketInfo("wawa-bucket")
 	singleBucket.AutoCompactionSettings = false
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, lblManager)
-	c := make(chan prometheus.Metric, 9)
+	c := make(chan prometheus.Metric, 10)
 	testCollector.Collect(c)
 	close(c)
 
@@ -232,7 +242,7 @@ func TestBucketInfoCollectReturnsUpWithNoErrorsWithAutomaticCompactionBoolean(t
 			gauge, err := test.GetGaugeValue(m)
 
 			assert.Nil(t, err)
-			assert.Equal(t, gauge, singleBucket.BucketBasicStats[key], fqName)
+			assert.Equal(t, gauge, bucketInfoValue(singleBucket, key), fqName)
 		}
 	}
 }
@@ -244,7 +254,7 @@ func TestBucketInfoCollectReturnsUpWithNoErrorsWithAutomaticCompactionObject(t *
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucketInfo("wawa-bucket")
@@ -260,15 +270,15 @@ func TestBucketInfoCollectReturnsUpWithNoErrorsWithAutomaticCompactionObject(t *
 		},
 	}
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, lblManager)
-	c := make(chan prometheus.Metric, 9)
+	c := make(chan prometheus.Metric, 10)
 	testCollector.Collect(c)
 	close(c)
 
@@ -288,7 +298,7 @@ func TestBucketInfoCollectReturnsUpWithNoErrorsWithAutomaticCompactionObject(t *
 			gauge, err := test.GetGaugeValue(m)
 
 			assert.Nil(t, err)
-			assert.Equal(t, gauge, singleBucket.BucketBasicStats[key], fqName)
+			assert.Equal(t, gauge, bucketInfoValue(singleBucket, key), fqName)
 		}
 	}
 }
@@ -300,13 +310,13 @@ func TestBucketInfoCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
@@ -330,19 +340,19 @@ func TestBucketInfoCollectReturnsOneOfEachMetricForASingleBucketWithCorrectValue
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucketInfo("wawa-bucket")
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
-	c := make(chan prometheus.Metric, 9)
+	c := make(chan prometheus.Metric, 10)
 	testCollector.Collect(c)
 	close(c)
 
@@ -362,7 +372,7 @@ func TestBucketInfoCollectReturnsOneOfEachMetricForASingleBucketWithCorrectValue
 			gauge, err := test.GetGaugeValue(m)
 
 			assert.Nil(t, err)
-			assert.Equal(t, gauge, singleBucket.BucketBasicStats[key], fqName)
+			assert.Equal(t, gauge, bucketInfoValue(singleBucket, key), fqName)
 		}
 	}
 }
@@ -374,10 +384,10 @@ func TestBucketInfoCollectReturnsMultipleMetricsForMultipleBuckets(t *testing.T)
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucketInfo("wawa-bucket")
@@ -385,11 +395,11 @@ func TestBucketInfoCollectReturnsMultipleMetricsForMultipleBuckets(t *testing.T)
 
 	secondBucket := test.GenerateBucketInfo("double-bucket")
 	buckets = append(buckets, secondBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager)
-	c := make(chan prometheus.Metric, 16)
+	c := make(chan prometheus.Metric, 18)
 	testCollector.Collect(c)
 	close(c)
 
@@ -419,12 +429,12 @@ func TestBucketInfoCollectReturnsMultipleMetricsForMultipleBuckets(t *testing.T)
 			assert.Nil(t, err)
 
 			if bucket == "wawa-bucket" {
-				assert.Equal(t, gauge, singleBucket.BucketBasicStats[key], fqName)
+				assert.Equal(t, gauge, bucketInfoValue(singleBucket, key), fqName)
 			} else {
-				assert.Equal(t, gauge, secondBucket.BucketBasicStats[key], fqName)
+				assert.Equal(t, gauge, bucketInfoValue(secondBucket, key), fqName)
 			}
 		}
 	}
 
-	assert.Equal(t, 16, count)
+	assert.Equal(t, 18, count)
 }
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -167,13 +168,13 @@ func TestBucketStatsCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 1)
 	testCollector.Collect(c)
@@ -193,19 +194,19 @@ func TestBucketStatsCollectReturnsDownIfClientReturnsErrorOnBuckets(t *testing.T
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, ErrDummy)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, ErrDummy)
 
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 1)
 	testCollector.Collect(c)
@@ -225,25 +226,25 @@ func TestBucketStatsCollectReturnsDownIfClientReturnsErrorOnBucketStats(t *testi
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	stats := test.GenerateBucketStats()
 
-	mockClient.EXPECT().BucketStats(singleBucket.Name).Times(1).Return(stats, ErrDummy)
+	mockClient.EXPECT().BucketStats(gomock.Any(), singleBucket.Name).Times(1).Return(stats, ErrDummy)
 
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 10)
 	testCollector.Collect(c)
@@ -263,18 +264,18 @@ func TestBucketStatsCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 2)
 	testCollector.Collect(c)
@@ -296,24 +297,24 @@ func TestBucketStatsCollectReturnsOneOfEachMetricForASingleBucketWithCorrectValu
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	stats := test.GenerateBucketStats()
 
-	mockClient.EXPECT().BucketStats(singleBucket.Name).Times(1).Return(stats, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), singleBucket.Name).Times(1).Return(stats, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 9)
 	count := 0
@@ -374,26 +375,26 @@ func TestBucketStatsCollectReturnsOneOfEachMetricForASingleBucketWithAutoCompact
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
 	singleBucket.AutoCompactionSettings = false
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	stats := test.GenerateBucketStats()
 
-	mockClient.EXPECT().BucketStats(singleBucket.Name).Times(1).Return(stats, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), singleBucket.Name).Times(1).Return(stats, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, lblManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 9)
 	count := 0
@@ -454,7 +455,7 @@ func TestBucketStatsCollectReturnsOneOfEachMetricForASingleBucketWithAutoCompact
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
@@ -470,20 +471,20 @@ func TestBucketStatsCollectReturnsOneOfEachMetricForASingleBucketWithAutoCompact
 		},
 	}
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	stats := test.GenerateBucketStats()
 
-	mockClient.EXPECT().BucketStats(singleBucket.Name).Times(1).Return(stats, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), singleBucket.Name).Times(1).Return(stats, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, lblManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric, 9)
 	count := 0
@@ -543,10 +544,10 @@ func TestBucketStatsCollectReturnsCorrectValuesWithMultipleBuckets(t *testing.T)
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 
@@ -556,18 +557,18 @@ func TestBucketStatsCollectReturnsCorrectValuesWithMultipleBuckets(t *testing.T)
 	secondBucket := test.GenerateBucket("super-america")
 	buckets = append(buckets, secondBucket)
 
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	firstBucketStats := test.GenerateBucketStats()
 	secondBucketStats := test.GenerateBucketStats()
 
-	mockClient.EXPECT().BucketStats(firstBucket.Name).Times(1).Return(firstBucketStats, nil)
-	mockClient.EXPECT().BucketStats(secondBucket.Name).Times(1).Return(secondBucketStats, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), firstBucket.Name).Times(1).Return(firstBucketStats, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), secondBucket.Name).Times(1).Return(secondBucketStats, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
 
-	testCollector.DoWork()
+	testCollector.DoWork(context.Background())
 
 	c := make(chan prometheus.Metric)
 	count := 0
@@ -613,3 +614,321 @@ func TestBucketStatsCollectReturnsCorrectValuesWithMultipleBuckets(t *testing.T)
 		}
 	}
 }
+
+// bucketScrapeCost returns the value of the exporter's own per bucket metric
+// name for the given collector and bucket.
+func bucketScrapeCost(t *testing.T, name, collector, bucket string) (float64, bool) {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)[name]
+	if !ok {
+		return 0, false
+	}
+
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["collector"] == collector && labels[objects.BucketLabel] == bucket {
+			return metric.GetGauge().GetValue(), true
+		}
+	}
+
+	return 0, false
+}
+
+func TestBucketStatsCollectRecordsScrapeCostPerBucket(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	stats := test.GenerateBucketStats()
+	// the collector adds the stats it derives to the samples it is returned.
+	returned := len(stats.Op.Samples)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("cost-bucket")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "cost-bucket").Times(1).Return(stats, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	testCollector.DoWork(context.Background())
+
+	samples, ok := bucketScrapeCost(t, "cbexporter_bucket_scrape_samples", "BucketStats", "cost-bucket")
+	assert.True(t, ok)
+	assert.Equal(t, float64(returned), samples)
+
+	duration, ok := bucketScrapeCost(t, "cbexporter_bucket_scrape_duration_seconds", "BucketStats", "cost-bucket")
+	assert.True(t, ok)
+	assert.GreaterOrEqual(t, duration, 0.0)
+}
+
+func TestBucketStatsCollectExportsWindowAggregates(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	stats := test.GenerateBucketStats()
+	stats.Op.Samples[objects.Ops] = []float64{4, 1, 7, 4}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).Return(stats, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	testCollector.SetWindowAggregates(true)
+	testCollector.DoWork(context.Background())
+
+	values := collectValues(t, &testCollector)
+
+	assert.Equal(t, 4.0, values["cbbucketstat_ops/wawa-bucket"])
+	assert.Equal(t, 1.0, values["cbbucketstat_ops_min/wawa-bucket"])
+	assert.Equal(t, 4.0, values["cbbucketstat_ops_avg/wawa-bucket"])
+	assert.Equal(t, 7.0, values["cbbucketstat_ops_max/wawa-bucket"])
+	assert.NotContains(t, values, "cbbucketstat_cmd_get_max/wawa-bucket")
+}
+
+func TestBucketStatsCollectOmitsWindowAggregatesByDefault(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).Return(test.GenerateBucketStats(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	testCollector.DoWork(context.Background())
+
+	values := collectValues(t, &testCollector)
+
+	assert.Contains(t, values, "cbbucketstat_ops/wawa-bucket")
+	assert.NotContains(t, values, "cbbucketstat_ops_max/wawa-bucket")
+}
+
+func TestBucketStatsCollectExportsReplicaItemsSkew(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	stats := test.GenerateBucketStats()
+	stats.Op.Samples[objects.BucketStatsCurrItems] = []float64{1000, 1000, 1200}
+	stats.Op.Samples[objects.BucketStatsVbReplicaCurrItems] = []float64{2000, 2150}
+
+	bucket := test.GenerateBucket("wawa-bucket")
+	bucket.ReplicaNumber = 2
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{bucket}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).Return(stats, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	testCollector.DoWork(context.Background())
+
+	values := collectValues(t, &testCollector)
+
+	assert.Equal(t, 250.0, values["cbbucketstat_vbuckets_replica_curr_items_skew/wawa-bucket"])
+	assert.Equal(t, 0.0, statKeyCount(t, "cbexporter_missing_stat_keys_total", objects.BucketStatsVbReplicaCurrItemsSkew))
+}
+
+func statKeyCount(t *testing.T, name, stat string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)[name]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["collector"] == "BucketStats" && labels["stat"] == stat {
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestBucketStatsCollectCountsUnmappedAndMissingStats(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	stats := test.GenerateBucketStats()
+	stats.Op.Samples["ep_made_up_stat"] = []float64{1}
+	stats.Op.Samples["timestamp"] = []float64{1}
+	delete(stats.Op.Samples, objects.BucketStatsCmdGet)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).Return(stats, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	unmapped := statKeyCount(t, "cbexporter_unmapped_stat_keys_total", "ep_made_up_stat")
+	missing := statKeyCount(t, "cbexporter_missing_stat_keys_total", objects.BucketStatsCmdGet)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	testCollector.DoWork(context.Background())
+
+	assert.Equal(t, unmapped+1, statKeyCount(t, "cbexporter_unmapped_stat_keys_total", "ep_made_up_stat"))
+	assert.Equal(t, missing+1, statKeyCount(t, "cbexporter_missing_stat_keys_total", objects.BucketStatsCmdGet))
+	assert.Equal(t, 0.0, statKeyCount(t, "cbexporter_unmapped_stat_keys_total", "timestamp"))
+}
+
+func TestBucketStatsCollectsLowPriorityBucketsLessOften(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(3).Return([]objects.BucketInfo{test.GenerateBucket("critical"), test.GenerateBucket("bulk")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "critical").Times(3).Return(test.GenerateBucketStats(), nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "bulk").Times(2).Return(test.GenerateBucketStats(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	assert.Nil(t, testCollector.SetBucketPriority(objects.BucketPriority{High: []string{"crit.*"}, LowEvery: 2}))
+
+	for i := 0; i < 3; i++ {
+		testCollector.DoWork(context.Background())
+	}
+}
+
+func bucketDegraded(t *testing.T, bucket string) float64 {
+	return clusterBucketDegraded(t, "dummy-cluster", bucket)
+}
+
+func clusterBucketDegraded(t *testing.T, cluster, bucket string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)["cbexporter_bucket_degraded"]
+	if !ok {
+		return -1
+	}
+
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["collector"] == "BucketStats" && labels[objects.ClusterLabel] == cluster && labels["bucket"] == bucket {
+			return metric.GetGauge().GetValue()
+		}
+	}
+
+	return -1
+}
+
+func TestBucketStatsCollectsSlowBucketsLessOftenUntilTheyAreQuick(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	slowRequests := 2
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(7).Return([]objects.BucketInfo{test.GenerateBucket("sluggish"), test.GenerateBucket("quick")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "quick").Times(7).Return(test.GenerateBucketStats(), nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "sluggish").Times(5).DoAndReturn(func(context.Context, string) (objects.BucketStats, error) {
+		if slowRequests > 0 {
+			slowRequests--
+
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		return test.GenerateBucketStats(), nil
+	})
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	testCollector.SetSlowBuckets(objects.SlowBucketsConfig{Threshold: 0.025, After: 2, Every: 3})
+
+	// degraded after the second collection, skipped in the next two and
+	// restored by the quick request of the fifth.
+	for i := 0; i < 2; i++ {
+		testCollector.DoWork(context.Background())
+	}
+
+	assert.Equal(t, 1.0, bucketDegraded(t, "sluggish"))
+	assert.Equal(t, 0.0, bucketDegraded(t, "quick"))
+
+	for i := 0; i < 5; i++ {
+		testCollector.DoWork(context.Background())
+	}
+
+	assert.Equal(t, 0.0, bucketDegraded(t, "sluggish"))
+}
+
+func TestBucketStatsDegradesSameNamedBucketsOfEachClusterApart(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	newCollector := func(cluster string, delay time.Duration) *collectors.BucketStatsCollector {
+		mockClient := mocks.NewMockCbClient(mockCtrl)
+		mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return(cluster, nil)
+		mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(test.GenerateNode(), nil)
+		mockClient.EXPECT().Buckets(gomock.Any()).AnyTimes().Return([]objects.BucketInfo{test.GenerateBucket("shared")}, nil)
+		mockClient.EXPECT().BucketStats(gomock.Any(), "shared").AnyTimes().DoAndReturn(func(context.Context, string) (objects.BucketStats, error) {
+			time.Sleep(delay)
+
+			return test.GenerateBucketStats(), nil
+		})
+
+		testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, util.NewLabelManager(mockClient, 600*time.Second))
+		testCollector.SetSlowBuckets(objects.SlowBucketsConfig{Threshold: 0.025, After: 1, Every: 3})
+
+		return &testCollector
+	}
+
+	slow := newCollector("slow-cluster", 50*time.Millisecond)
+	quick := newCollector("quick-cluster", 0)
+
+	slow.DoWork(context.Background())
+	quick.DoWork(context.Background())
+
+	assert.Equal(t, 1.0, clusterBucketDegraded(t, "slow-cluster", "shared"))
+	assert.Equal(t, 0.0, clusterBucketDegraded(t, "quick-cluster", "shared"))
+}
+
+func TestBucketPriorityRejectsInvalidPatterns(t *testing.T) {
+	_, err := objects.BucketPriority{High: []string{"("}}.Schedule()
+	assert.ErrorIs(t, err, objects.ErrInvalidBucketFilter)
+
+	due, err := objects.BucketPriority{LowEvery: 5}.Schedule()
+	assert.Nil(t, err)
+	assert.True(t, due("any", 3))
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	capellaClusterJSON = `{
+		"id": "c7d1e2f3",
+		"name": "production",
+		"currentState": "healthy",
+		"couchbaseServer": {"version": "7.2.0"},
+		"serviceGroups": [
+			{"node": {"compute": {"cpu": 4, "ram": 16}}, "numOfNodes": 3, "services": ["data", "index"]},
+			{"node": {"compute": {"cpu": 8, "ram": 32}}, "numOfNodes": 2, "services": ["query"]}
+		]
+	}`
+	capellaBucketsJSON = `{
+		"data": [
+			{
+				"id": "dHJhdmVs", "name": "travel", "type": "couchbase", "memoryAllocationInMb": 200,
+				"stats": {"itemCount": 1000, "opsPerSecond": 25, "diskUsedInMib": 12, "memoryUsedInMib": 40}
+			}
+		]
+	}`
+)
+
+var capellaClusters = []objects.CapellaClusterRef{{ProjectID: "p1", ClusterID: "c7d1e2f3"}}
+
+func capellaFixtures(t *testing.T) (objects.CapellaCluster, []objects.CapellaBucket) {
+	var cluster objects.CapellaCluster
+	assert.Nil(t, json.Unmarshal([]byte(capellaClusterJSON), &cluster))
+
+	var buckets objects.CapellaBuckets
+	assert.Nil(t, json.Unmarshal([]byte(capellaBucketsJSON), &buckets))
+
+	return cluster, buckets.Data
+}
+
+func TestCapellaCollectReportsClusterAndBuckets(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	cluster, buckets := capellaFixtures(t)
+
+	mockCapella := mocks.NewMockCapellaClient(mockCtrl)
+	mockCapella.EXPECT().Cluster("p1", "c7d1e2f3").Times(1).Return(cluster, nil)
+	mockCapella.EXPECT().Buckets("p1", "c7d1e2f3").Times(1).Return(buckets, nil)
+
+	labelManager := util.NewLabelManager(mocks.NewMockCbClient(mockCtrl), 0)
+	values := collectValues(t, collectors.NewCapellaCollector(mockCapella, capellaClusters, defaultConfig.Collectors.Capella, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"cbcapella_cluster_healthy/c7d1e2f3":                   1,
+		"cbcapella_cluster_nodes":                              5,
+		"cbcapella_service_group_nodes/data,index":             3,
+		"cbcapella_service_group_nodes/query":                  2,
+		"cbcapella_service_group_node_cpu_cores/data,index":    4,
+		"cbcapella_service_group_node_cpu_cores/query":         8,
+		"cbcapella_service_group_node_memory_bytes/data,index": 16 << 30,
+		"cbcapella_service_group_node_memory_bytes/query":      32 << 30,
+		"cbcapella_bucket_items/travel":                        1000,
+		"cbcapella_bucket_ops_per_second/travel":               25,
+		"cbcapella_bucket_disk_used_bytes/travel":              12 << 20,
+		"cbcapella_bucket_memory_used_bytes/travel":            40 << 20,
+		"cbcapella_bucket_memory_quota_bytes/travel":           200 << 20,
+		"cbcapella_up":                      1,
+		"cbcapella_scrape_duration_seconds": values["cbcapella_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestCapellaCollectReturnsDownIfClusterCannotBeRead(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockCapella := mocks.NewMockCapellaClient(mockCtrl)
+	mockCapella.EXPECT().Cluster("p1", "c7d1e2f3").Times(1).Return(objects.CapellaCluster{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mocks.NewMockCbClient(mockCtrl), 0)
+	values := collectValues(t, collectors.NewCapellaCollector(mockCapella, capellaClusters, defaultConfig.Collectors.Capella, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbcapella_up": 0}, values)
+}
+
+func TestCapellaClientAuthenticatesWithAPIKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v4/organizations/org/projects/p1/clusters/c7d1e2f3":
+			_, _ = w.Write([]byte(capellaClusterJSON))
+		case "/v4/organizations/org/projects/p1/clusters/c7d1e2f3/buckets":
+			_, _ = w.Write([]byte(capellaBucketsJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := util.NewCapellaClient(server.URL+"/", "org", "s3cret")
+
+	cluster, err := client.Cluster("p1", "c7d1e2f3")
+	assert.Nil(t, err)
+	assert.Equal(t, "production", cluster.Name)
+	assert.True(t, cluster.Healthy())
+
+	buckets, err := client.Buckets("p1", "c7d1e2f3")
+	assert.Nil(t, err)
+	assert.Len(t, buckets, 1)
+	assert.Equal(t, 1000, buckets[0].Stats.ItemCount)
+
+	_, err = util.NewCapellaClient(server.URL, "org", "wrong").Cluster("p1", "c7d1e2f3")
+	assert.ErrorIs(t, err, util.ErrForbidden)
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/handlers"
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureRoundTrip(t *testing.T, capture *util.Capture, path, body string) string {
+	transport := util.NewCaptureTransport(capture, util.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body)), Request: req}, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8091"+path, nil)
+	assert.Nil(t, err)
+
+	resp, err := transport.RoundTrip(req)
+	assert.Nil(t, err)
+
+	// the body is still there to be parsed after being captured.
+	read, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, body, string(read))
+
+	return log.Recent(1)[0]
+}
+
+func TestCaptureLogsRedactedAndTruncatedBodiesOfMatchingEndpoints(t *testing.T) {
+	capture, err := util.NewCapture(objects.DebugCaptureConfig{
+		Endpoints: "pools/default/buckets/[^/]+/stats",
+		Window:    60,
+		MaxBytes:  40,
+	})
+	assert.Nil(t, err)
+
+	logged := captureRoundTrip(t, capture, "/pools/default/buckets/default/stats", `{"password":"hunter2","op":{"samples":{"ops":[1,2,3,4,5,6]}}}`)
+	assert.Contains(t, logged, "captured GET /pools/default/buckets/default/stats returned 200 with 61 bytes")
+	assert.Contains(t, logged, `bytes truncated`)
+	assert.NotContains(t, logged, "hunter2")
+	assert.NotContains(t, logged, "[1,2,3,4,5,6]")
+
+	logged = captureRoundTrip(t, capture, "/pools/default", `{"name":"pools-default-not-captured"}`)
+	assert.NotContains(t, logged, "pools-default-not-captured")
+
+	capture.Stop()
+
+	logged = captureRoundTrip(t, capture, "/pools/default/buckets/default/stats", `{"name":"stopped-capture"}`)
+	assert.NotContains(t, logged, "stopped-capture")
+}
+
+func TestCaptureStopsAfterItsWindow(t *testing.T) {
+	capture, err := util.NewCapture(objects.DebugCaptureConfig{Window: 60, MaxBytes: 4096})
+	assert.Nil(t, err)
+	assert.Nil(t, capture.State().Until)
+
+	assert.Nil(t, capture.Start("pools", time.Millisecond))
+	time.Sleep(10 * time.Millisecond)
+
+	logged := captureRoundTrip(t, capture, "/pools/default", `{"name":"expired-capture"}`)
+	assert.NotContains(t, logged, "expired-capture")
+	assert.Equal(t, "", capture.State().Endpoints)
+
+	assert.ErrorIs(t, capture.Start("pools/(", 0), objects.ErrInvalidDebugCapture)
+}
+
+func TestAdminCaptureStartsAndStopsCapturing(t *testing.T) {
+	capture, err := util.NewCapture(objects.DebugCaptureConfig{Window: 60, MaxBytes: 4096})
+	assert.Nil(t, err)
+
+	handler := handlers.AdminCapture(capture)
+
+	rec := adminRequest(handler, http.MethodPost, handlers.AdminCapturePath+"/start?endpoints=pools/default&window=30", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var state util.CaptureState
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &state))
+	assert.Equal(t, "pools/default", state.Endpoints)
+	assert.NotNil(t, state.Until)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), *state.Until, 5*time.Second)
+
+	rec = adminRequest(handler, http.MethodGet, handlers.AdminCapturePath, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "pools/default")
+
+	rec = adminRequest(handler, http.MethodPost, handlers.AdminCapturePath+"/stop", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "", capture.State().Endpoints)
+
+	assert.Equal(t, http.StatusBadRequest, adminRequest(handler, http.MethodPost, handlers.AdminCapturePath+"/start", "").Code)
+	assert.Equal(t, http.StatusBadRequest, adminRequest(handler, http.MethodPost, handlers.AdminCapturePath+"/start?endpoints=(", "").Code)
+	assert.Equal(t, http.StatusBadRequest, adminRequest(handler, http.MethodPost, handlers.AdminCapturePath+"/start?endpoints=pools&window=soon", "").Code)
+	assert.Equal(t, http.StatusNotFound, adminRequest(handler, http.MethodPost, handlers.AdminCapturePath+"/pause", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(handler, http.MethodGet, handlers.AdminCapturePath+"/stop", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(handler, http.MethodDelete, handlers.AdminCapturePath, "").Code)
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+// bucketLabels returns the bucket and cluster of each metric of the family.
+func bucketLabels(family *dto.MetricFamily) []string {
+	buckets := []string{}
+
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		buckets = append(buckets, labels["bucket"]+"/"+labels["cluster"])
+	}
+
+	return buckets
+}
+
+func TestSeriesLimitGathererLimitsEachCluster(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	items := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_item_count"}, []string{"bucket", "cluster"})
+	for _, bucket := range []string{"d", "c", "b"} {
+		items.WithLabelValues(bucket, "big-cluster").Set(1)
+	}
+
+	items.WithLabelValues("a", "small-cluster").Set(1)
+	registry.MustRegister(items)
+
+	gatherer := util.NewSeriesLimitGatherer(registry, 2)
+	families := gatherByName(t, gatherer)
+
+	assert.ElementsMatch(t, []string{"b/big-cluster", "c/big-cluster", "a/small-cluster"}, bucketLabels(families["cbbucketinfo_basic_item_count"]))
+	assert.Equal(t, 3.0, families["cbexporter_series"].GetMetric()[0].GetGauge().GetValue())
+	assert.Len(t, families["cbexporter_series_dropped"].GetMetric(), 1)
+	assert.Equal(t, 1.0, families["cbexporter_series_dropped"].GetMetric()[0].GetGauge().GetValue())
+
+	// a new bucket does not displace those already exported.
+	items.WithLabelValues("a", "big-cluster").Set(1)
+	families = gatherByName(t, gatherer)
+
+	assert.ElementsMatch(t, []string{"b/big-cluster", "c/big-cluster", "a/small-cluster"}, bucketLabels(families["cbbucketinfo_basic_item_count"]))
+	assert.Equal(t, 2.0, families["cbexporter_series_dropped"].GetMetric()[0].GetGauge().GetValue())
+
+	// series that go away make room for others.
+	items.DeleteLabelValues("c", "big-cluster")
+	items.DeleteLabelValues("d", "big-cluster")
+	families = gatherByName(t, gatherer)
+
+	assert.ElementsMatch(t, []string{"a/big-cluster", "b/big-cluster", "a/small-cluster"}, bucketLabels(families["cbbucketinfo_basic_item_count"]))
+	assert.NotContains(t, families, "cbexporter_series_dropped")
+}
+
+func TestSeriesLimitGathererIsNoopWithoutLimit(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	assert.Same(t, registry, util.NewSeriesLimitGatherer(registry, 0))
+}
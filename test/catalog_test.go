@@ -0,0 +1,112 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/stretchr/testify/assert"
+)
+
+func findCatalogEntry(catalog []objects.CatalogEntry, name string) (objects.CatalogEntry, bool) {
+	for _, entry := range catalog {
+		if entry.Name == name {
+			return entry, true
+		}
+	}
+
+	return objects.CatalogEntry{}, false
+}
+
+func TestCatalogEntriesAreComplete(t *testing.T) {
+	catalog := config.GetDefaultConfig().Collectors.Catalog()
+
+	assert.NotEmpty(t, catalog)
+
+	for _, entry := range catalog {
+		assert.NotEmpty(t, entry.Help, entry.Name)
+		assert.NotEmpty(t, entry.Endpoint, entry.Name)
+		assert.NotEmpty(t, entry.Type, entry.Name)
+	}
+}
+
+func TestCatalogDescribesSources(t *testing.T) {
+	catalog := config.GetDefaultConfig().Collectors.Catalog()
+
+	entry, ok := findCatalogEntry(catalog, "cbpernodebucket_couch_spatial_data_size")
+	assert.True(t, ok)
+	assert.Equal(t, "/pools/default/buckets/{bucket}/nodes/{node}/stats", entry.Endpoint)
+	assert.Equal(t, []string{objects.BucketLabel, objects.NodeLabel, objects.ClusterLabel}, entry.Labels)
+	assert.Equal(t, objects.MetricTypeGauge, entry.Type)
+
+	entry, ok = findCatalogEntry(catalog, "cbindex_avg_scan_latency")
+	assert.True(t, ok)
+	assert.Equal(t, "indexer:/api/v1/stats", entry.Endpoint)
+
+	entry, ok = findCatalogEntry(catalog, "cbindex_indexer_memory_used")
+	assert.True(t, ok)
+	assert.Equal(t, "indexer:/api/v1/stats", entry.Endpoint)
+
+	entry, ok = findCatalogEntry(catalog, "cbnode_healthy")
+	assert.True(t, ok)
+	assert.Equal(t, objects.MetricTypeGauge, entry.Type)
+
+	entry, ok = findCatalogEntry(catalog, "cbnode_uptime")
+	assert.True(t, ok)
+	assert.Equal(t, objects.MetricTypeCounter, entry.Type)
+}
+
+func TestCatalogSkipsDisabledMetrics(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+
+	ops := defaultConfig.Collectors.BucketStats.Metrics["Ops"]
+	ops.Enabled = false
+	defaultConfig.Collectors.BucketStats.Metrics["Ops"] = ops
+
+	_, ok := findCatalogEntry(defaultConfig.Collectors.Catalog(), ops.FQName(defaultConfig.Collectors.BucketStats.Namespace, defaultConfig.Collectors.BucketStats.Subsystem))
+	assert.False(t, ok)
+}
+
+func TestCatalogDescribesMetricsOfLoadedConfig(t *testing.T) {
+	loaded := new(objects.ExporterConfig)
+	assert.Nil(t, loaded.ParseConfigFile("../example/config.json"))
+
+	catalog := loaded.Collectors.Catalog()
+
+	entry, ok := findCatalogEntry(catalog, "cbnode_uptime")
+	assert.True(t, ok)
+	assert.Equal(t, objects.MetricTypeCounter, entry.Type)
+
+	entry, ok = findCatalogEntry(catalog, "cbindex_avg_scan_latency")
+	assert.True(t, ok)
+	assert.Equal(t, "indexer:/api/v1/stats", entry.Endpoint)
+}
+
+func TestCatalogListsMetricsOfTheExporter(t *testing.T) {
+	catalog := config.GetDefaultConfig().Collectors.Catalog()
+
+	entry, ok := findCatalogEntry(catalog, "cbexporter_auth_failures_total")
+	assert.True(t, ok)
+	assert.Equal(t, objects.MetricTypeCounter, entry.Type)
+	assert.Equal(t, []string{"endpoint", "status"}, entry.Labels)
+	assert.Equal(t, objects.SelfMetricsCollector, entry.Collector)
+
+	entry, ok = findCatalogEntry(catalog, "cbexporter_client_dns_duration_seconds")
+	assert.True(t, ok)
+	assert.Equal(t, objects.MetricTypeHistogram, entry.Type)
+
+	for _, name := range []string{
+		"cbexporter_credentials_valid",
+		"cbexporter_bucket_degraded",
+		"cbexporter_log_messages_total",
+		"cbexporter_series",
+		"cbexporter_series_dropped",
+		"cbexporter_delta_heartbeat_timestamp_seconds",
+		"cbexporter_delta_series_unchanged",
+		"cbexporter_delta_full",
+		"couchbase_health_status",
+	} {
+		_, ok = findCatalogEntry(catalog, name)
+		assert.True(t, ok, name)
+	}
+}
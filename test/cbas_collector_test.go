@@ -1,6 +1,7 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -167,7 +168,7 @@ func TestCbasCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager)
@@ -189,13 +190,13 @@ func TestCbasCollectReturnsDownIfClientReturnsErrorOnCbas(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	anal := objects.Analytics{}
-	mockClient.EXPECT().Cbas().Times(1).Return(anal, ErrDummy)
+	mockClient.EXPECT().Cbas(gomock.Any()).Times(1).Return(anal, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager)
@@ -226,13 +227,13 @@ func TestCbasCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(Node, nil)
 
 	anal := objects.Analytics{}
-	mockClient.EXPECT().Cbas().Times(1).Return(anal, nil)
+	mockClient.EXPECT().Cbas(gomock.Any()).Times(1).Return(anal, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager)
@@ -256,13 +257,13 @@ func TestCbasCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(node, nil)
 
 	anal := test.GenerateAnalytics()
-	mockClient.EXPECT().Cbas().Times(1).Return(anal, nil)
+	mockClient.EXPECT().Cbas(gomock.Any()).Times(1).Return(anal, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager)
@@ -299,9 +300,74 @@ func TestCbasCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 			}
 			count++
 		case <-time.After(1 * time.Second):
-			if count >= len(defaultConfig.Collectors.Analytics.Metrics)+2 {
+			// the ingestion metrics are only reported on analytics nodes.
+			if count >= len(defaultConfig.Collectors.Analytics.Metrics)-len(objects.CbasNodeMetrics)+2 {
 				return
 			}
 		}
 	}
 }
+
+func TestCbasCollectReportsIngestionOnAnalyticsNodes(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	var ingestion objects.AnalyticsIngestion
+	assert.Nil(t, json.Unmarshal([]byte(`{"links": [
+		{"name": "Local", "scope": "Default", "status": "healthy", "state": [
+			{"timestamp": 1620000000000, "progress": 0.5, "timeLag": 2500, "itemsProcessed": 1200, "seqnoAdvances": 3, "scopes": [
+				{"name": "travel.inventory", "collections": [{"name": "airline"}, {"name": "hotel"}]}
+			]}
+		]},
+		{"name": "remote", "scope": "Default", "status": "stopped", "state": []}
+	]}`), &ingestion))
+
+	var failed objects.StatsRange
+	assert.Nil(t, json.Unmarshal([]byte(`{"data": [{"metric": {}, "values": [[1620000000, "7"]]}]}`), &failed))
+
+	node := test.GenerateNode()
+	node.Services = []string{"kv", "cbas"}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(node, nil)
+	mockClient.EXPECT().Cbas(gomock.Any()).Times(1).Return(objects.Analytics{}, nil)
+	mockClient.EXPECT().AnalyticsIngestion(gomock.Any()).Times(1).Return(ingestion, nil)
+	mockClient.EXPECT().StatsRange(gomock.Any(), objects.CbasFailedRecordsStat).Times(1).Return(failed, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager))
+
+	assert.Equal(t, 1.0, values["cbcbas_up"])
+	assert.Equal(t, 1.0, values["cbcbas_link_connected/Default.Local"])
+	assert.Equal(t, 0.0, values["cbcbas_link_connected/Default.remote"])
+	assert.Equal(t, 1200.0, values["cbcbas_dataset_items_processed_total/travel.inventory.airline/Default.Local"])
+	assert.Equal(t, 1200.0, values["cbcbas_dataset_items_processed_total/travel.inventory.hotel/Default.Local"])
+	assert.Equal(t, 0.5, values["cbcbas_dataset_ingestion_progress/travel.inventory.airline/Default.Local"])
+	assert.Equal(t, 2.5, values["cbcbas_dataset_ingestion_lag_seconds/travel.inventory.hotel/Default.Local"])
+	assert.Equal(t, 7.0, values["cbcbas_failed_records_total"])
+}
+
+func TestCbasCollectReturnsDownIfClientReturnsErrorOnIngestion(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	node.Services = []string{"cbas"}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(node, nil)
+	mockClient.EXPECT().Cbas(gomock.Any()).Times(1).Return(objects.Analytics{}, nil)
+	mockClient.EXPECT().AnalyticsIngestion(gomock.Any()).Times(1).Return(objects.AnalyticsIngestion{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager))
+
+	assert.Equal(t, 0.0, values["cbcbas_up"])
+	assert.NotContains(t, values, "cbcbas_scrape_duration_seconds")
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/cli"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCLIRunsNamedOrDefaultCommand(t *testing.T) {
+	ran := map[string][]string{}
+	run := func(name string) func([]string) int {
+		return func(args []string) int {
+			ran[name] = args
+			return 0
+		}
+	}
+
+	output := &bytes.Buffer{}
+	app := cli.App{
+		Name:    "couchbase-exporter",
+		Default: "serve",
+		Output:  output,
+		Commands: []cli.Command{
+			{Name: "serve", Run: run("serve")},
+			{Name: "version", Run: run("version")},
+		},
+	}
+
+	assert.Equal(t, 0, app.Run([]string{"version", "-x"}))
+	assert.Equal(t, []string{"-x"}, ran["version"])
+
+	assert.Equal(t, 0, app.Run([]string{"-log-level", "debug"}))
+	assert.Equal(t, []string{"-log-level", "debug"}, ran["serve"])
+
+	assert.Equal(t, 2, app.Run([]string{"bogus"}))
+	assert.Contains(t, output.String(), `unknown command "bogus"`)
+}
+
+func TestCLIParseReadsFlagsNotGivenFromEnvironment(t *testing.T) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	level := flags.String("log-level", "", "")
+	cidrs := flags.String("web.allowed-cidrs", "", "")
+	port := flags.String("server-port", "", "")
+
+	t.Setenv("CB_EXPORTER_LOG_LEVEL", "debug")
+	t.Setenv("CB_EXPORTER_WEB_ALLOWED_CIDRS", "10.0.0.0/8")
+	t.Setenv("CB_EXPORTER_SERVER_PORT", "9000")
+
+	assert.Nil(t, cli.Parse(flags, []string{"-server-port", "9091"}))
+	assert.Equal(t, "debug", *level)
+	assert.Equal(t, "10.0.0.0/8", *cidrs)
+	assert.Equal(t, "9091", *port)
+}
+
+func TestCLIParseRejectsInvalidEnvironment(t *testing.T) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.Bool("log-json", true, "")
+
+	t.Setenv("CB_EXPORTER_LOG_JSON", "maybe")
+
+	err := cli.Parse(flags, []string{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "CB_EXPORTER_LOG_JSON")
+}
+
+// repeatedFlag collects the values of a flag that may be given more than once.
+type repeatedFlag []string
+
+func (r *repeatedFlag) String() string {
+	return strings.Join(*r, ",")
+}
+
+func (r *repeatedFlag) Set(value string) error {
+	*r = append(*r, value)
+	return nil
+}
+
+func (r *repeatedFlag) IsRepeatable() bool {
+	return true
+}
+
+func TestCLIParseSplitsEnvironmentOfRepeatableFlags(t *testing.T) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	labels := repeatedFlag{}
+	flags.Var(&labels, "label", "")
+
+	t.Setenv("CB_EXPORTER_LABEL", "env=prod, team=db")
+
+	assert.Nil(t, cli.Parse(flags, []string{}))
+	assert.Equal(t, repeatedFlag{"env=prod", "team=db"}, labels)
+}
+
+func TestCLIParseNamesEnvironmentInUsage(t *testing.T) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.String("web.allowed-cidrs", "", "networks that may request /metrics")
+
+	assert.Nil(t, cli.Parse(flags, []string{}))
+	assert.Nil(t, cli.Parse(flags, []string{}))
+	assert.Equal(t, "networks that may request /metrics [$CB_EXPORTER_WEB_ALLOWED_CIDRS]", flags.Lookup("web.allowed-cidrs").Usage)
+}
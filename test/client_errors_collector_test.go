@@ -0,0 +1,74 @@
+package test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientErrorsCollectReportsEachBucketOnEveryNode(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	var tmpOom objects.StatsRange
+	assert.Nil(t, json.Unmarshal([]byte(`{"data": [
+		{"metric": {"bucket": "default", "nodes": ["node1:8091"]}, "values": [[1620000000, "0"], [1620000010, "12"]]},
+		{"metric": {"bucket": "default", "nodes": ["node2:8091"]}, "values": [[1620000010, "3"]]},
+		{"metric": {"bucket": "travel-sample", "nodes": ["node1:8091"]}, "values": [[1620000010, "0"]]}
+	]}`), &tmpOom))
+
+	var notMyVbucket objects.StatsRange
+	assert.Nil(t, json.Unmarshal([]byte(`{"data": [
+		{"metric": {"bucket": "default", "nodes": ["node1:8091"]}, "values": [[1620000010, "40"]]}
+	]}`), &notMyVbucket))
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVTmpOomErrorsStat).Times(1).Return(tmpOom, nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVNotMyVbucketsStat).Times(1).Return(notMyVbucket, nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVAuthErrorsStat).Times(1).
+		Return(nodeStatsRange(t, map[string]float64{"node1:8091": 5, "node2:8091": 0}), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewClientErrorsCollector(mockClient, defaultConfig.Collectors.ClientErrors, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"cbclient_tmp_oom_errors_total/default/node1:8091":       12,
+		"cbclient_tmp_oom_errors_total/default/node2:8091":       3,
+		"cbclient_tmp_oom_errors_total/travel-sample/node1:8091": 0,
+		"cbclient_not_my_vbucket_total/default/node1:8091":       40,
+		"cbclient_auth_errors_total/node1:8091":                  5,
+		"cbclient_auth_errors_total/node2:8091":                  0,
+		"cbclient_up":                                            1,
+		"cbclient_scrape_duration_seconds":                       values["cbclient_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestClientErrorsCollectReturnsDownWithoutStatsAPI(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), gomock.Any()).Times(1).Return(objects.StatsRange{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewClientErrorsCollector(mockClient, defaultConfig.Collectors.ClientErrors, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbclient_up": 0}, values)
+}
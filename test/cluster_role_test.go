@@ -0,0 +1,86 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterRoleGathererLabelsClusterSeries(t *testing.T) {
+	registry := healthRegistry()
+	registry.MustRegister(prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbexporter_unlabelled"}))
+
+	families := gatherByName(t, util.NewClusterRoleGatherer(registry, objects.ClusterRoleStandby))
+
+	for _, metric := range families["cbtask_xdcr_changes_left"].GetMetric() {
+		assert.Equal(t, "cluster", metric.GetLabel()[0].GetName())
+		assert.Equal(t, objects.ClusterRoleLabel, metric.GetLabel()[1].GetName())
+		assert.Equal(t, objects.ClusterRoleStandby, metric.GetLabel()[1].GetValue())
+	}
+
+	assert.Empty(t, families["cbexporter_unlabelled"].GetMetric()[0].GetLabel())
+}
+
+func TestClusterRoleGathererWithoutRoleLeavesSeries(t *testing.T) {
+	registry := healthRegistry()
+
+	assert.Same(t, registry, util.NewClusterRoleGatherer(registry, ""))
+}
+
+func TestHealthIsSuppressedOnStandbys(t *testing.T) {
+	defaults := objects.ExporterConfig{}
+	defaults.SetDefaults()
+
+	roles := defaults.ClusterRole
+	roles.SuppressOnStandby = true
+
+	// cluster a is the primary and cluster b its standby.
+	primary := prometheus.NewRegistry()
+	standby := prometheus.NewRegistry()
+
+	for _, c := range []struct {
+		registry *prometheus.Registry
+		cluster  string
+	}{{primary, "a"}, {standby, "b"}} {
+		quota := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_quota_user_percent"}, []string{"bucket", "cluster"})
+		quota.WithLabelValues("default", c.cluster).Set(99)
+		c.registry.MustRegister(quota)
+	}
+
+	health, err := util.NewHealthGatherer(prometheus.Gatherers{
+		util.NewClusterRoleGatherer(primary, objects.ClusterRolePrimary),
+		util.NewClusterRoleGatherer(standby, objects.ClusterRoleStandby),
+	}, objects.HealthConfig{Enabled: true, Checks: objects.DefaultHealthChecks()})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]float64{
+		"cluster=a,cluster_role=primary,component=kv,": objects.HealthCritical,
+		"cluster=b,cluster_role=standby,component=kv,": objects.HealthCritical,
+	}, healthStatuses(t, health))
+
+	assert.Equal(t, map[string]float64{
+		"cluster=a,cluster_role=primary,component=kv,": objects.HealthCritical,
+	}, healthStatuses(t, util.NewStandbyGatherer(health, roles)))
+
+	// the suppressed metrics are only dropped when asked to.
+	assert.Len(t, healthStatuses(t, util.NewStandbyGatherer(health, defaults.ClusterRole)), 2)
+
+	// series of the standby other than the suppressed metrics are kept.
+	quota := gatherByName(t, util.NewStandbyGatherer(health, roles))["cbbucketinfo_basic_quota_user_percent"]
+	assert.Len(t, quota.GetMetric(), 2)
+}
+
+func TestClusterRoleIsValidated(t *testing.T) {
+	config := objects.ExporterConfig{}
+	config.SetDefaults()
+	config.Clusters = []objects.ClusterConfig{{Name: "dr", Role: objects.ClusterRoleStandby}}
+
+	assert.Nil(t, config.ValidateClusters())
+	assert.Equal(t, objects.ClusterRoleStandby, config.ForCluster(config.Clusters[0]).ClusterRole.Role)
+
+	config.Clusters[0].Role = "replica"
+	assert.ErrorIs(t, config.ValidateClusters(), objects.ErrInvalidClusterRole)
+}
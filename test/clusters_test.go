@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForClusterOverridesTopLevelSettings(t *testing.T) {
+	exporterConfig := config.GetDefaultConfig()
+	exporterConfig.Ca = "/etc/ca.pem"
+	exporterConfig.ClientCertificate = "/etc/client.pem"
+	exporterConfig.ClientKey = "/etc/client.key"
+	exporterConfig.Buckets = objects.BucketFilter{Exclude: []string{"scratch"}}
+
+	cluster := exporterConfig.ForCluster(objects.ClusterConfig{
+		Name:             "dr",
+		CouchbaseAddress: "dr.example.com",
+		CouchbaseUser:    "dr-reader",
+		Ca:               "/etc/dr-ca.pem",
+	})
+
+	assert.Equal(t, "dr.example.com", cluster.CouchbaseAddress)
+	assert.Equal(t, exporterConfig.CouchbasePort, cluster.CouchbasePort)
+	assert.Equal(t, "dr-reader", cluster.CouchbaseUser)
+	assert.Equal(t, exporterConfig.CouchbasePassword, cluster.CouchbasePassword)
+	assert.Equal(t, "/etc/dr-ca.pem", cluster.Ca)
+	assert.Equal(t, "", cluster.ClientCertificate)
+	assert.Equal(t, "", cluster.ClientKey)
+	assert.Equal(t, exporterConfig.Buckets, cluster.Buckets)
+
+	assert.Equal(t, "localhost", exporterConfig.CouchbaseAddress)
+	assert.Equal(t, "/etc/client.pem", exporterConfig.ClientCertificate)
+}
+
+func TestClusterCollectorEnabled(t *testing.T) {
+	cluster := objects.ClusterConfig{Collectors: map[string]bool{"perNodeBucketStats": false, "Views": true}}
+
+	assert.False(t, cluster.CollectorEnabled("PerNodeBucketStats"))
+	assert.True(t, cluster.CollectorEnabled("views"))
+	assert.True(t, cluster.CollectorEnabled("Node"))
+}
+
+func TestBucketFilterMatcher(t *testing.T) {
+	matches, err := objects.BucketFilter{Include: []string{"app-.*", "travel-sample"}, Exclude: []string{"app-test"}}.Matcher()
+	assert.Nil(t, err)
+
+	assert.True(t, matches("app-orders"))
+	assert.True(t, matches("travel-sample"))
+	assert.False(t, matches("app-test"))
+	assert.False(t, matches("beer-sample"))
+	assert.False(t, matches("travel-sample-copy"))
+
+	_, err = objects.BucketFilter{Include: []string{"("}}.Matcher()
+	assert.ErrorIs(t, err, objects.ErrInvalidBucketFilter)
+}
+
+func TestValidateClusters(t *testing.T) {
+	exporterConfig := config.GetDefaultConfig()
+	assert.Nil(t, exporterConfig.ValidateClusters())
+
+	exporterConfig.Clusters = []objects.ClusterConfig{{Name: "dr"}, {Name: "dr"}}
+	assert.ErrorIs(t, exporterConfig.ValidateClusters(), objects.ErrInvalidCluster)
+
+	exporterConfig.Clusters = []objects.ClusterConfig{{CouchbaseAddress: "dr.example.com"}}
+	assert.ErrorIs(t, exporterConfig.ValidateClusters(), objects.ErrInvalidCluster)
+
+	exporterConfig.Clusters = []objects.ClusterConfig{{Name: "dr", CouchbaseAuth: &objects.AuthConfig{Mode: objects.AuthModeBearer}}}
+	assert.ErrorIs(t, exporterConfig.ValidateClusters(), objects.ErrMissingToken)
+
+	exporterConfig.Clusters = []objects.ClusterConfig{{Name: "dr", Buckets: objects.BucketFilter{Exclude: []string{"["}}}}
+	assert.ErrorIs(t, exporterConfig.ValidateClusters(), objects.ErrInvalidBucketFilter)
+}
+
+func TestBucketFilterClientListsSelectedBuckets(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{{Name: "orders"}, {Name: "scratch"}}, nil)
+
+	unfiltered, err := util.NewBucketFilterClient(mockClient, objects.BucketFilter{})
+	assert.Nil(t, err)
+	assert.Equal(t, util.CbClient(mockClient), unfiltered)
+
+	client, err := util.NewBucketFilterClient(mockClient, objects.BucketFilter{Exclude: []string{"scratch"}})
+	assert.Nil(t, err)
+
+	buckets, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []objects.BucketInfo{{Name: "orders"}}, buckets)
+}
@@ -0,0 +1,70 @@
+package test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// catalogRegistry registers one gauge for every metric in the default catalog.
+func catalogRegistry() (*prometheus.Registry, int) {
+	registry := prometheus.NewRegistry()
+	defaultConfig := config.GetDefaultConfig()
+	catalog := defaultConfig.Collectors.Catalog()
+
+	for _, entry := range catalog {
+		gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: entry.Name, Help: "test"})
+		registry.MustRegister(gauge)
+	}
+
+	return registry, len(catalog)
+}
+
+func TestCompatCouchbaseKeepsNames(t *testing.T) {
+	rules, err := objects.CompatRules(objects.CompatCouchbase)
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestCompatRejectsUnknownScheme(t *testing.T) {
+	_, err := objects.CompatRules("prometheus-couchbase")
+	assert.True(t, errors.Is(err, objects.ErrUnknownCompat))
+}
+
+func TestCompatBlakeleadRenamesEveryMetricUniquely(t *testing.T) {
+	rules, err := objects.CompatRules(objects.CompatBlakelead)
+	assert.Nil(t, err)
+
+	registry, count := catalogRegistry()
+
+	gatherer, err := util.NewRelabelGatherer(registry, rules)
+	assert.Nil(t, err)
+
+	families := gatherByName(t, gatherer)
+
+	assert.Len(t, families, count)
+	assert.Contains(t, families, "cb_node_status")
+	assert.Contains(t, families, "cb_node_ram_total_bytes")
+	assert.Contains(t, families, "cb_node_interesting_stats_cmd_get")
+	assert.Contains(t, families, "cb_cluster_rebalance_success_total")
+	assert.Contains(t, families, "cb_bucket_basic_ops_per_sec")
+	assert.Contains(t, families, "cb_bucket_basic_mem_used_bytes")
+	assert.Contains(t, families, "cb_task_rebalance_progress")
+
+	for name := range families {
+		// collectors added since blakelead/couchbase-exporter keep their names.
+		if strings.HasPrefix(name, "cb_") {
+			continue
+		}
+
+		for _, prefix := range []string{"cbnode_", "cbbucketinfo_", "cbbucketstat_", "cbtask_", "cbquery_", "cbindex_"} {
+			assert.False(t, strings.HasPrefix(name, prefix), name)
+		}
+	}
+}
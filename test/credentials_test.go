@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func exporterGauge(t *testing.T, name string) (float64, bool) {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)[name]
+	if !ok || len(family.GetMetric()) == 0 {
+		return 0, false
+	}
+
+	return family.GetMetric()[0].GetGauge().GetValue(), true
+}
+
+func TestCredentialsCheckReportsInvalidCredentials(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().WhoAmI(gomock.Any()).Times(1).Return(objects.WhoAmI{}, util.ErrUnauthorized)
+
+	collectors.NewCredentialsCheck(mockClient, objects.CredentialsConfig{Check: true}).Check(context.Background())
+
+	valid, ok := exporterGauge(t, "cbexporter_credentials_valid")
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, valid)
+}
+
+func TestCredentialsCheckKeepsResultWhenClusterIsUnreachable(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	gomock.InOrder(
+		mockClient.EXPECT().WhoAmI(gomock.Any()).Times(1).Return(objects.WhoAmI{ID: "exporter"}, nil),
+		mockClient.EXPECT().WhoAmI(gomock.Any()).Times(1).Return(objects.WhoAmI{}, ErrDummy),
+	)
+
+	check := collectors.NewCredentialsCheck(mockClient, objects.CredentialsConfig{Check: true})
+	check.Check(context.Background())
+	check.Check(context.Background())
+
+	valid, ok := exporterGauge(t, "cbexporter_credentials_valid")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, valid)
+}
+
+func TestCredentialsCheckReportsPasswordExpiry(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().WhoAmI(gomock.Any()).Times(1).Return(objects.WhoAmI{
+		ID:                 "exporter",
+		Domain:             "local",
+		PasswordChangeDate: "2021-03-01T00:00:00Z",
+	}, nil)
+
+	check := collectors.NewCredentialsCheck(mockClient, objects.CredentialsConfig{Check: true, PasswordMaxAge: 30, WarnBefore: 14})
+	check.Now = func() time.Time { return time.Date(2021, 3, 25, 0, 0, 0, 0, time.UTC) }
+	check.Check(context.Background())
+
+	valid, ok := exporterGauge(t, "cbexporter_credentials_valid")
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, valid)
+
+	expiry, ok := exporterGauge(t, "cbexporter_credentials_expiry_timestamp_seconds")
+	assert.True(t, ok)
+	assert.Equal(t, float64(time.Date(2021, 3, 31, 0, 0, 0, 0, time.UTC).Unix()), expiry)
+}
+
+func TestCredentialsCheckUsesConfiguredExpiryForExternalUsers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().WhoAmI(gomock.Any()).Times(1).Return(objects.WhoAmI{ID: "exporter", Domain: "external"}, nil)
+
+	collectors.NewCredentialsCheck(mockClient, objects.CredentialsConfig{
+		Check:      true,
+		ExpiresAt:  "2030-01-02T00:00:00Z",
+		WarnBefore: 14,
+	}).Check(context.Background())
+
+	expiry, ok := exporterGauge(t, "cbexporter_credentials_expiry_timestamp_seconds")
+	assert.True(t, ok)
+	assert.Equal(t, float64(time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC).Unix()), expiry)
+}
@@ -1,10 +1,13 @@
 package test
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
 	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,10 +17,12 @@ const (
 
 type simpleWorker struct {
 	Counter int
+	ctx     context.Context
 }
 
-func (w *simpleWorker) DoWork() {
+func (w *simpleWorker) DoWork(ctx context.Context) {
 	w.Counter++
+	w.ctx = ctx
 }
 
 func TestCycleControllerCallsDoWorkEvery100ms(t *testing.T) {
@@ -91,3 +96,70 @@ func TestCycleControllerSubscribeAndUnsubscribeWillNotIncrement(t *testing.T) {
 	assert.Equal(t, 10, worker.Counter)   // was subscribed full time, has 10 for counter
 	assert.Equal(t, 0, workertwo.Counter) // was subscribed no time, has 0 for counter
 }
+
+func TestCycleControllerStopCancelsWorkContext(t *testing.T) {
+	cycle := util.NewCycleController(interval)
+	worker := &simpleWorker{}
+
+	cycle.Subscribe(worker)
+	cycle.Start()
+	time.Sleep(150 * time.Millisecond)
+	cycle.Stop()
+
+	assert.NotNil(t, worker.ctx)
+	assert.ErrorIs(t, worker.ctx.Err(), context.Canceled)
+}
+
+type slowWorker struct{}
+
+func (w *slowWorker) DoWork(ctx context.Context) {
+	time.Sleep(250 * time.Millisecond)
+}
+
+func TestCycleControllerReportsLagAndNextCollection(t *testing.T) {
+	cycle := util.NewCycleController(interval)
+	cycle.Subscribe(collectors.NewCollectorSwitch().Worker("", "slow-worker", &slowWorker{}))
+	cycle.Start()
+	time.Sleep(700 * time.Millisecond)
+	cycle.Stop()
+
+	families := gatherByName(t, prometheus.DefaultGatherer)
+
+	lag := families["cbexporter_cycle_lag_seconds"].GetMetric()[0].GetGauge().GetValue()
+	assert.Greater(t, lag, 0.1)
+
+	var next float64
+
+	for _, metric := range families["cbexporter_collector_next_collection_timestamp_seconds"].GetMetric() {
+		if metric.GetLabel()[1].GetValue() == "slow-worker" {
+			next = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.InDelta(t, float64(time.Now().Unix()), next, 2)
+}
+
+func TestClusterCycleControllersDoNotHoldUpEachOther(t *testing.T) {
+	slow := util.NewClusterCycleController("slow", interval)
+	slow.Subscribe(&slowWorker{})
+
+	fast := util.NewClusterCycleController("fast", interval)
+	worker := &simpleWorker{}
+	fast.Subscribe(worker)
+
+	slow.Start()
+	fast.Start()
+	time.Sleep(1 * time.Second)
+	fast.Stop()
+	slow.Stop()
+
+	assert.Equal(t, 10, worker.Counter)
+
+	lags := map[string]float64{}
+	for _, metric := range gatherByName(t, prometheus.DefaultGatherer)["cbexporter_cycle_lag_seconds"].GetMetric() {
+		lags[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+
+	assert.Greater(t, lags["slow"], 0.1)
+	assert.Less(t, lags["fast"], 0.1)
+}
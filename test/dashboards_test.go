@@ -0,0 +1,51 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/dashboards"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardsGeneratesAllDashboards(t *testing.T) {
+	generated := dashboards.Generate(config.GetDefaultConfig())
+
+	for _, name := range []string{"cluster-overview", "bucket-detail", "per-node-kv", "xdcr"} {
+		dashboard, ok := generated[name]
+		assert.True(t, ok, name)
+		assert.NotEmpty(t, dashboard.Panels, name)
+		assert.NotEmpty(t, dashboard.Templating.List, name)
+	}
+}
+
+func TestDashboardsUseNameOverrides(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+
+	ops := defaultConfig.Collectors.BucketStats.Metrics["Ops"]
+	ops.NameOverride = "operations_per_second"
+	defaultConfig.Collectors.BucketStats.Metrics["Ops"] = ops
+
+	dashboard := dashboards.Generate(defaultConfig)["bucket-detail"]
+
+	assert.True(t, strings.HasPrefix(dashboard.Panels[0].Targets[0].Expr, "cbbucketstat_operations_per_second{"))
+	assert.Equal(t, "label_values(cbbucketstat_operations_per_second, bucket)", dashboard.Templating.List[1].Query)
+}
+
+func TestDashboardsSkipDisabledMetrics(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	before := len(dashboards.Generate(defaultConfig)["xdcr"].Panels)
+
+	paused := defaultConfig.Collectors.Task.Metrics["xdcrPaused"]
+	paused.Enabled = false
+	defaultConfig.Collectors.Task.Metrics["xdcrPaused"] = paused
+
+	after := dashboards.Generate(defaultConfig)["xdcr"].Panels
+
+	assert.Len(t, after, before-1)
+
+	for _, panel := range after {
+		assert.NotContains(t, panel.Targets[0].Expr, "xdcr_paused")
+	}
+}
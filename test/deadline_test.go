@@ -0,0 +1,225 @@
+package test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// collectorTimeouts returns the number of timeouts counted for the collector.
+func collectorTimeouts(t *testing.T, collector string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)["cbexporter_collector_timeout_total"]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "collector" && label.GetValue() == collector {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+// stalledCollector collects one gauge and then waits until it is released.
+type stalledCollector struct {
+	desc    *prometheus.Desc
+	release chan struct{}
+}
+
+func (c *stalledCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *stalledCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+
+	<-c.release
+}
+
+func TestWithDeadlineExposesWhatWasCollected(t *testing.T) {
+	stalled := &stalledCollector{
+		desc:    prometheus.NewDesc("stalled_gauge", "A gauge collected before stalling", nil, nil),
+		release: make(chan struct{}),
+	}
+	defer close(stalled.release)
+
+	before := collectorTimeouts(t, "stalled")
+	values := collectValues(t, collectors.WithDeadline("", "stalled", stalled, 20*time.Millisecond))
+
+	assert.Equal(t, map[string]float64{"stalled_gauge": 1}, values)
+	assert.Equal(t, before+1, collectorTimeouts(t, "stalled"))
+}
+
+func TestWithDeadlineOfZeroReturnsTheCollector(t *testing.T) {
+	stalled := &stalledCollector{}
+
+	assert.Same(t, stalled, collectors.WithDeadline("", "stalled", stalled, 0))
+}
+
+// countingCollector counts its collections, stalling each until it is
+// released.
+type countingCollector struct {
+	stalledCollector
+	collections int32
+}
+
+func (c *countingCollector) Collect(ch chan<- prometheus.Metric) {
+	atomic.AddInt32(&c.collections, 1)
+	c.stalledCollector.Collect(ch)
+}
+
+func TestWithDeadlineSkipsCollectorsStillCollecting(t *testing.T) {
+	stalled := &countingCollector{stalledCollector: stalledCollector{
+		desc:    prometheus.NewDesc("counted_gauge", "A gauge collected before stalling", nil, nil),
+		release: make(chan struct{}),
+	}}
+	collector := collectors.WithDeadline("", "counted", stalled, 20*time.Millisecond)
+
+	assert.Len(t, collectValues(t, collector), 1)
+	assert.Empty(t, collectValues(t, collector))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&stalled.collections))
+
+	close(stalled.release)
+
+	assert.Eventually(t, func() bool {
+		return len(collectValues(t, collector)) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&stalled.collections))
+}
+
+func TestWithDeadlineCancelsTheRequestsOfContextCollectors(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	cancelled := make(chan error, 1)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).DoAndReturn(func(ctx context.Context) ([]objects.Task, error) {
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+			return nil, ctx.Err()
+		case <-time.After(10 * time.Second):
+			cancelled <- nil
+			return nil, nil
+		}
+	})
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	collector := collectors.WithDeadline("", "tasks", collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager),
+		20*time.Millisecond)
+
+	collectValues(t, collector)
+
+	select {
+	case err := <-cancelled:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the request was not cancelled at the deadline")
+	}
+}
+
+type workerFunc func(ctx context.Context)
+
+func (f workerFunc) DoWork(ctx context.Context) {
+	f(ctx)
+}
+
+func TestWorkerWithDeadlineCancelsWork(t *testing.T) {
+	before := collectorTimeouts(t, "blocked")
+
+	worker := collectors.WorkerWithDeadline("", "blocked", workerFunc(func(ctx context.Context) {
+		<-ctx.Done()
+	}), 20*time.Millisecond)
+	worker.DoWork(context.Background())
+
+	assert.Equal(t, before+1, collectorTimeouts(t, "blocked"))
+}
+
+func TestBucketStatsAbandonsRequestInFlightAtDeadline(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("stalled-bucket")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "stalled-bucket").Times(1).DoAndReturn(func(ctx context.Context, _ string) (objects.BucketStats, error) {
+		select {
+		case <-ctx.Done():
+			return objects.BucketStats{}, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return objects.BucketStats{}, nil
+		}
+	})
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+
+	start := time.Now()
+
+	collectors.WorkerWithDeadline("", "stalled", &testCollector, 20*time.Millisecond).DoWork(context.Background())
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestBucketStatsStopsAtDeadline(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), gomock.Any()).Times(0)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	testCollector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCollector.DoWork(ctx)
+
+	values := collectValues(t, &testCollector)
+	assert.Equal(t, 0.0, values["cbbucketstat_up"])
+
+	// the bucket the collection did not get to is still pending.
+	pending := 0.0
+
+	for _, metric := range gatherByName(t, prometheus.DefaultGatherer)["cbexporter_pending_bucket_collections"].GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["collector"] == defaultConfig.Collectors.BucketStats.Name && labels[objects.ClusterLabel] == "dummy-cluster" {
+			pending = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, 1.0, pending)
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func deltaRegistry() (*prometheus.Registry, *prometheus.GaugeVec) {
+	registry := prometheus.NewRegistry()
+
+	items := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_item_count"}, []string{"bucket"})
+	items.WithLabelValues("default").Set(1)
+	items.WithLabelValues("travel-sample").Set(2)
+
+	registry.MustRegister(items)
+
+	return registry, items
+}
+
+func deltaSeries(t *testing.T, gatherer prometheus.Gatherer) (map[string]float64, float64, float64) {
+	families := gatherByName(t, gatherer)
+
+	series := map[string]float64{}
+
+	for _, metric := range families["cbbucketinfo_basic_item_count"].GetMetric() {
+		series[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+
+	assert.Greater(t, families["cbexporter_delta_heartbeat_timestamp_seconds"].GetMetric()[0].GetGauge().GetValue(), 0.0)
+
+	return series,
+		families["cbexporter_delta_series_unchanged"].GetMetric()[0].GetGauge().GetValue(),
+		families["cbexporter_delta_full"].GetMetric()[0].GetGauge().GetValue()
+}
+
+func TestDeltaGathererOnlyReturnsChangedSeries(t *testing.T) {
+	registry, items := deltaRegistry()
+	gatherer := util.NewDeltaGatherer(registry, time.Hour)
+
+	// everything is new to the first scrape.
+	series, unchanged, full := deltaSeries(t, gatherer)
+	assert.Equal(t, map[string]float64{"default": 1, "travel-sample": 2}, series)
+	assert.Equal(t, 0.0, unchanged)
+	assert.Equal(t, 1.0, full)
+
+	series, unchanged, full = deltaSeries(t, gatherer)
+	assert.Empty(t, series)
+	assert.Equal(t, 2.0, unchanged)
+	assert.Equal(t, 0.0, full)
+
+	items.WithLabelValues("default").Set(3)
+	items.WithLabelValues("beer-sample").Set(4)
+
+	series, unchanged, _ = deltaSeries(t, gatherer)
+	assert.Equal(t, map[string]float64{"default": 3, "beer-sample": 4}, series)
+	assert.Equal(t, 1.0, unchanged)
+}
+
+func TestDeltaGathererReturnsEverySeriesEachFullInterval(t *testing.T) {
+	registry, _ := deltaRegistry()
+	gatherer := util.NewDeltaGatherer(registry, time.Nanosecond)
+
+	deltaSeries(t, gatherer)
+	time.Sleep(time.Millisecond)
+
+	series, unchanged, full := deltaSeries(t, gatherer)
+	assert.Len(t, series, 2)
+	assert.Equal(t, 0.0, unchanged)
+	assert.Equal(t, 1.0, full)
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func derivedRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	memory := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_memused_bytes"}, []string{"bucket"})
+	memory.WithLabelValues("default").Set(1000)
+	memory.WithLabelValues("empty").Set(100)
+	memory.WithLabelValues("orphan").Set(50)
+
+	items := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_itemcount"}, []string{"bucket"})
+	items.WithLabelValues("default").Set(10)
+	items.WithLabelValues("empty").Set(0)
+
+	registry.MustRegister(memory, items)
+
+	return registry
+}
+
+func derivedValues(t *testing.T, gatherer prometheus.Gatherer, name string) map[string]float64 {
+	values := map[string]float64{}
+	for _, metric := range gatherByName(t, gatherer)[name].GetMetric() {
+		values[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+
+	return values
+}
+
+func TestDerivedMetricIsEvaluatedPerLabelSet(t *testing.T) {
+	gatherer, err := util.NewDerivedGatherer(derivedRegistry(), []objects.DerivedMetric{
+		{Name: "bytes_per_item", Expr: "cbbucketinfo_basic_memused_bytes / cbbucketinfo_basic_itemcount"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]float64{"default": 100}, derivedValues(t, gatherer, "bytes_per_item"))
+}
+
+func TestDerivedMetricHonoursPrecedenceAndParentheses(t *testing.T) {
+	gatherer, err := util.NewDerivedGatherer(derivedRegistry(), []objects.DerivedMetric{
+		{Name: "precedence", Expr: "cbbucketinfo_basic_itemcount + 2 * 3"},
+		{Name: "parentheses", Expr: "-(cbbucketinfo_basic_itemcount + 2) * 3"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]float64{"default": 16, "empty": 6}, derivedValues(t, gatherer, "precedence"))
+	assert.Equal(t, map[string]float64{"default": -36, "empty": -6}, derivedValues(t, gatherer, "parentheses"))
+}
+
+func TestDerivedMetricRejectsInvalidExpressions(t *testing.T) {
+	for _, metric := range []objects.DerivedMetric{
+		{Name: "bad name", Expr: "cbbucketinfo_basic_itemcount"},
+		{Name: "unbalanced", Expr: "(cbbucketinfo_basic_itemcount + 1"},
+		{Name: "trailing", Expr: "cbbucketinfo_basic_itemcount +"},
+		{Name: "constant", Expr: "1 + 2"},
+		{Name: "operator", Expr: "cbbucketinfo_basic_itemcount % 2"},
+	} {
+		_, err := util.NewDerivedGatherer(derivedRegistry(), []objects.DerivedMetric{metric})
+		assert.True(t, errors.Is(err, util.ErrInvalidDerivedMetric), metric.Name)
+	}
+}
+
+func TestDerivedMetricDoesNotReplaceCollectedMetric(t *testing.T) {
+	gatherer, err := util.NewDerivedGatherer(derivedRegistry(), []objects.DerivedMetric{
+		{Name: "cbbucketinfo_basic_itemcount", Expr: "cbbucketinfo_basic_memused_bytes * 2"},
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]float64{"default": 10, "empty": 0}, derivedValues(t, gatherer, "cbbucketinfo_basic_itemcount"))
+}
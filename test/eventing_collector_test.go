@@ -167,7 +167,7 @@ func TestEventingCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
@@ -190,13 +190,13 @@ func TestEventingCollectReturnsDownIfClientReturnsErrorOnEventing(t *testing.T)
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	eventing := objects.Eventing{}
-	mockClient.EXPECT().Eventing().Times(1).Return(eventing, ErrDummy)
+	mockClient.EXPECT().Eventing(gomock.Any()).Times(1).Return(eventing, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewEventingCollector(mockClient, defaultConfig.Collectors.Eventing, labelManager)
@@ -227,13 +227,13 @@ func TestEventingCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	eventing := objects.Eventing{}
-	mockClient.EXPECT().Eventing().Times(1).Return(eventing, nil)
+	mockClient.EXPECT().Eventing(gomock.Any()).Times(1).Return(eventing, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewEventingCollector(mockClient, defaultConfig.Collectors.Eventing, labelManager)
@@ -257,13 +257,13 @@ func TestEventingCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	eventing := test.GenerateEventing()
-	mockClient.EXPECT().Eventing().Times(1).Return(eventing, nil)
+	mockClient.EXPECT().Eventing(gomock.Any()).Times(1).Return(eventing, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewEventingCollector(mockClient, defaultConfig.Collectors.Eventing, labelManager)
@@ -3,6 +3,7 @@ package test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/objects"
 )
@@ -33,3 +34,86 @@ func TestParsesGoodConfigFile(t *testing.T) {
 		t.Error("Error during parsing of config file.", err)
 	}
 }
+
+func TestPerNodeScopeSelectsEveryNode(t *testing.T) {
+	var config objects.ExporterConfig
+	config.SetDefaults()
+
+	if config.CollectAllNodes() {
+		t.Error("Every node should not be collected by default.")
+	}
+
+	config.SetOrDefaultPerNodeScope(objects.PerNodeScopeAll)
+
+	if !config.CollectAllNodes() {
+		t.Error("Every node should be collected when the scope is all.")
+	}
+
+	config.SetOrDefaultPerNodeScope(objects.PerNodeScopeSelf)
+
+	if config.CollectAllNodes() {
+		t.Error("Only this node should be collected when the scope is self.")
+	}
+
+	config.SetOrDefaultPerNodeScope("everywhere")
+
+	if config.CollectAllNodes() {
+		t.Error("An unknown scope should collect this node.")
+	}
+
+	config.SetOrDefaultClusterMode(true)
+
+	if !config.CollectAllNodes() {
+		t.Error("Every node should be collected in cluster mode.")
+	}
+}
+
+func TestPerNodeScopeAutoDetectsRemoteExporters(t *testing.T) {
+	var config objects.ExporterConfig
+	config.SetDefaults()
+
+	if config.DeploymentMode() != objects.DeploymentSidecar {
+		t.Error("An exporter connecting to localhost should be a sidecar.")
+	}
+
+	config.SetOrDefaultCouchAddress("192.0.2.1")
+
+	if config.DeploymentMode() != objects.DeploymentRemote || !config.CollectAllNodes() {
+		t.Error("An exporter connecting to another host should collect every node.")
+	}
+
+	config.SetOrDefaultNodeName("192.0.2.1:8091")
+
+	if config.CollectAllNodes() {
+		t.Error("An exporter given a node to collect should only collect that node.")
+	}
+
+	config.NodeName = ""
+	config.Sidecar.Enabled = true
+
+	if config.CollectAllNodes() {
+		t.Error("An operator sidecar should only collect its own node.")
+	}
+}
+
+func TestTimeoutsDefaultAndOverride(t *testing.T) {
+	var config objects.ExporterConfig
+	config.SetDefaults()
+
+	if config.RequestTimeoutDuration() != 30*time.Second || config.LabelCacheDuration() != 600*time.Second {
+		t.Errorf("Unexpected default timeouts %s and %s.", config.RequestTimeoutDuration(), config.LabelCacheDuration())
+	}
+
+	config.SetOrDefaultRequestTimeout("5")
+	config.SetOrDefaultLabelCacheTTL("0")
+
+	if config.RequestTimeoutDuration() != 5*time.Second || config.LabelCacheDuration() != 0 {
+		t.Errorf("Unexpected overridden timeouts %s and %s.", config.RequestTimeoutDuration(), config.LabelCacheDuration())
+	}
+
+	config.SetOrDefaultRequestTimeout("-1")
+
+	if config.RequestTimeout != objects.DefaultRequestTimeout {
+		t.Errorf("A negative request timeout should be replaced by the default, not %d.", config.RequestTimeout)
+	}
+}
@@ -0,0 +1,48 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func faultyClient(faults objects.FaultConfig, random float64) util.Client {
+	transport := util.NewFaultTransport(faults, bucketsTransport{})
+	transport.Random = func() float64 { return random }
+
+	return util.NewClientWithTransport("http://localhost", 8091, "Administrator", "password", transport)
+}
+
+func TestFaultTransportInjectsErrors(t *testing.T) {
+	faults := objects.FaultConfig{ErrorRate: 0.5}
+
+	_, err := faultyClient(faults, 0.4).Buckets(context.Background())
+	assert.Contains(t, fmt.Sprint(err), "503")
+
+	_, err = faultyClient(faults, 0.6).Buckets(context.Background())
+	assert.Nil(t, err)
+}
+
+func TestFaultTransportInjectsMalformedResponses(t *testing.T) {
+	_, err := faultyClient(objects.FaultConfig{MalformedRate: 1}, 0).Buckets(context.Background())
+
+	assert.Contains(t, fmt.Sprint(err), "failed to unmarshall")
+}
+
+func TestFaultTransportInjectsLatency(t *testing.T) {
+	client := faultyClient(objects.FaultConfig{LatencyMillis: 5000}, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.Buckets(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyFIPSRestrictsTLS(t *testing.T) {
+	config := &tls.Config{}
+	util.ApplyFIPS(config)
+
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MaxVersion)
+	assert.Equal(t, util.FIPSCipherSuites, config.CipherSuites)
+	assert.Equal(t, util.FIPSCurves, config.CurvePreferences)
+	assert.NotNil(t, config.VerifyConnection)
+}
+
+func TestCheckFIPSCertificateRejectsWeakSignatures(t *testing.T) {
+	for _, algorithm := range []x509.SignatureAlgorithm{x509.MD5WithRSA, x509.SHA1WithRSA, x509.ECDSAWithSHA1} {
+		err := util.CheckFIPSCertificate(&x509.Certificate{SignatureAlgorithm: algorithm})
+		assert.ErrorIs(t, err, util.ErrWeakSignature, algorithm.String())
+	}
+
+	for _, algorithm := range []x509.SignatureAlgorithm{x509.SHA256WithRSA, x509.ECDSAWithSHA384} {
+		assert.Nil(t, util.CheckFIPSCertificate(&x509.Certificate{SignatureAlgorithm: algorithm}), algorithm.String())
+	}
+}
+
+func TestNewTransportIsOnlyRestrictedInFIPSMode(t *testing.T) {
+	defer util.SetFIPS(false)
+
+	config := &tls.Config{}
+
+	util.SetFIPS(false)
+	assert.Nil(t, util.NewTransport(config, 1).TLSClientConfig.CipherSuites)
+
+	util.SetFIPS(true)
+	assert.Equal(t, util.FIPSCipherSuites, util.NewTransport(config, 1).TLSClientConfig.CipherSuites)
+	assert.Nil(t, config.CipherSuites, "the given config is not modified")
+}
+
+func TestFIPSTransportNegotiatesApprovedSuite(t *testing.T) {
+	defer util.SetFIPS(false)
+
+	util.SetFIPS(true)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, uint16(tls.VersionTLS12), r.TLS.Version)
+		assert.Contains(t, util.FIPSCipherSuites, r.TLS.CipherSuite)
+	}))
+	defer server.Close()
+
+	config := server.Client().Transport.(*http.Transport).TLSClientConfig
+	client := &http.Client{Transport: util.NewTransport(config, 1)}
+
+	resp, err := client.Get(server.URL)
+	assert.Nil(t, err)
+
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+func TestFIPSTransportRefusesUnapprovedSuite(t *testing.T) {
+	defer util.SetFIPS(false)
+
+	util.SetFIPS(true)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256},
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	config := server.Client().Transport.(*http.Transport).TLSClientConfig
+	client := &http.Client{Transport: util.NewTransport(config, 1)}
+
+	_, err := client.Get(server.URL)
+	assert.NotNil(t, err)
+}
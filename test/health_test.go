@@ -0,0 +1,97 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func healthRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	quota := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_quota_user_percent"}, []string{"bucket", "cluster"})
+	quota.WithLabelValues("default", "a").Set(50)
+	quota.WithLabelValues("travel-sample", "a").Set(90)
+	quota.WithLabelValues("default", "b").Set(20)
+
+	resident := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketstat_vbuckets_active_resident_items_ratio"}, []string{"bucket", "cluster"})
+	resident.WithLabelValues("default", "a").Set(100)
+	resident.WithLabelValues("default", "b").Set(5)
+
+	changesLeft := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbtask_xdcr_changes_left"}, []string{"cluster"})
+	changesLeft.WithLabelValues("a").Set(0)
+
+	registry.MustRegister(quota, resident, changesLeft)
+
+	return registry
+}
+
+func healthStatuses(t *testing.T, gatherer prometheus.Gatherer) map[string]float64 {
+	family, ok := gatherByName(t, gatherer)[objects.HealthStatusMetric]
+	assert.True(t, ok)
+
+	statuses := map[string]float64{}
+
+	for _, metric := range family.GetMetric() {
+		key := ""
+		for _, label := range metric.GetLabel() {
+			key += label.GetName() + "=" + label.GetValue() + ","
+		}
+
+		statuses[key] = metric.GetGauge().GetValue()
+	}
+
+	return statuses
+}
+
+func TestHealthGathererGradesEachComponentByItsWorstSeries(t *testing.T) {
+	gatherer, err := util.NewHealthGatherer(healthRegistry(), objects.HealthConfig{
+		Enabled: true,
+		Checks:  objects.DefaultHealthChecks(),
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]float64{
+		"cluster=a,component=kv,":   objects.HealthWarning,
+		"cluster=a,component=xdcr,": objects.HealthOK,
+		"cluster=b,component=kv,":   objects.HealthCritical,
+	}, healthStatuses(t, gatherer))
+}
+
+func TestHealthGathererIsOffByDefault(t *testing.T) {
+	defaults := objects.ExporterConfig{}
+	defaults.SetDefaults()
+
+	gatherer, err := util.NewHealthGatherer(healthRegistry(), defaults.Health)
+	assert.Nil(t, err)
+
+	assert.NotContains(t, gatherByName(t, gatherer), objects.HealthStatusMetric)
+}
+
+func TestHealthCheckGrade(t *testing.T) {
+	above := objects.HealthCheck{Warning: 80, Critical: 95}
+	assert.Equal(t, objects.HealthOK, above.Grade(79))
+	assert.Equal(t, objects.HealthWarning, above.Grade(80))
+	assert.Equal(t, objects.HealthCritical, above.Grade(95))
+
+	below := objects.HealthCheck{Warning: 30, Critical: 10, Below: true}
+	assert.Equal(t, objects.HealthOK, below.Grade(30))
+	assert.Equal(t, objects.HealthWarning, below.Grade(29))
+	assert.Equal(t, objects.HealthCritical, below.Grade(9))
+}
+
+func TestHealthGathererRejectsInvalidChecks(t *testing.T) {
+	for _, check := range []objects.HealthCheck{
+		{Metric: "cbindex_ram_percent", Warning: 80, Critical: 95},
+		{Component: "index", Metric: "not a metric", Warning: 80, Critical: 95},
+		{Component: "index", Metric: "cbindex_ram_percent", Warning: 95, Critical: 80},
+		{Component: "kv", Metric: "cbbucketstat_vbuckets_active_resident_items_ratio", Warning: 10, Critical: 30, Below: true},
+	} {
+		_, err := util.NewHealthGatherer(healthRegistry(), objects.HealthConfig{Enabled: true, Checks: []objects.HealthCheck{check}})
+		assert.True(t, errors.Is(err, util.ErrInvalidHealthCheck), check)
+	}
+}
@@ -0,0 +1,61 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostnameNormalizerKeepsHostnamesByDefault(t *testing.T) {
+	normalizer := util.NewHostnameNormalizer(objects.HostnameConfig{})
+
+	assert.Equal(t, "cb-0.cb.default.svc:8091", normalizer.Normalize("cb-0.cb.default.svc:8091"))
+}
+
+func TestHostnameNormalizerStripsPorts(t *testing.T) {
+	normalizer := util.NewHostnameNormalizer(objects.HostnameConfig{StripPort: true})
+
+	assert.Equal(t, "cb-0.cb.default.svc", normalizer.Normalize("cb-0.cb.default.svc:8091"))
+	assert.Equal(t, "::1", normalizer.Normalize("[::1]:8091"))
+	assert.Equal(t, "cb-0", normalizer.Normalize("cb-0"))
+}
+
+func TestHostnameNormalizerShortensHostnames(t *testing.T) {
+	normalizer := util.NewHostnameNormalizer(objects.HostnameConfig{Form: objects.HostnameFormShort})
+
+	assert.Equal(t, "cb-0:8091", normalizer.Normalize("cb-0.cb.default.svc:8091"))
+	assert.Equal(t, "10.0.0.1:8091", normalizer.Normalize("10.0.0.1:8091"))
+}
+
+func TestHostnameNormalizerRelabelsReportedAndNormalizedHostnames(t *testing.T) {
+	normalizer := util.NewHostnameNormalizer(objects.HostnameConfig{
+		StripPort: true,
+		Relabel: map[string]string{
+			"10.0.0.1:8091": "db-1.example.com",
+			"10.0.0.2":      "db-2.example.com",
+		},
+	})
+
+	assert.Equal(t, "db-1.example.com", normalizer.Normalize("10.0.0.1:8091"))
+	assert.Equal(t, "db-2.example.com", normalizer.Normalize("10.0.0.2:8091"))
+	assert.Equal(t, "10.0.0.3", normalizer.Normalize("10.0.0.3:8091"))
+}
+
+func TestLabelManagerNormalizesNodeLabels(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	normalizer := util.NewHostnameNormalizer(objects.HostnameConfig{StripPort: true, Form: objects.HostnameFormShort})
+	manager := util.NewLabelManagerWithHostnames(mockClient, 600*time.Second, normalizer)
+
+	ctx := util.MetricContext{ClusterName: "dummy-cluster", NodeHostname: "cb-0.cb.default.svc:8091"}
+
+	assert.Equal(t, []string{"dummy-cluster", "cb-0"}, manager.GetLabelValues([]string{objects.ClusterLabel, objects.NodeLabel}, ctx))
+}
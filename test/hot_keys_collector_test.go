@@ -0,0 +1,65 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHotKeysCollectExportsTheHottestKeysOfEachBucket(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{{Name: "a"}, {Name: "b"}}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "a").Times(1).Return(objects.BucketStats{
+		HotKeys: []objects.HotKey{
+			{Name: "cold", Ops: 1},
+			{Name: "hottest", Ops: 50},
+			{Name: "warm", Ops: 20},
+		},
+	}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "b").Times(1).Return(objects.BucketStats{}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewHotKeysCollector(mockClient, 2, defaultConfig.Collectors.HotKeys, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"cbhotkeys_key_info/a/hottest/1":    1,
+		"cbhotkeys_key_info/a/warm/2":       1,
+		"cbhotkeys_key_ops/a/hottest":       50,
+		"cbhotkeys_key_ops/a/warm":          20,
+		"cbhotkeys_up":                      1,
+		"cbhotkeys_scrape_duration_seconds": values["cbhotkeys_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestHotKeysCollectReturnsDownIfClientReturnsErrorOnBucketStats(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{{Name: "a"}}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "a").Times(1).Return(objects.BucketStats{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewHotKeysCollector(mockClient, 0, defaultConfig.Collectors.HotKeys, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbhotkeys_up": 0}, values)
+}
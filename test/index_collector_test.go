@@ -167,7 +167,7 @@ func TestIndexCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager)
@@ -189,13 +189,13 @@ func TestIndexCollectReturnsDownIfClientReturnsErrorOnIndex(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	Index := objects.Index{}
-	mockClient.EXPECT().Index().Times(1).Return(Index, ErrDummy)
+	mockClient.EXPECT().Index(gomock.Any()).Times(1).Return(Index, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager)
@@ -226,13 +226,13 @@ func TestIndexCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(2).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(Node, nil)
 
 	Index := objects.Index{}
-	mockClient.EXPECT().Index().Times(1).Return(Index, nil)
+	mockClient.EXPECT().Index(gomock.Any()).Times(1).Return(Index, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager)
@@ -266,13 +266,14 @@ func TestIndexCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Index := test.GenerateIndex()
-	mockClient.EXPECT().Index().Times(1).Return(Index, nil)
+	mockClient.EXPECT().Index(gomock.Any()).Times(1).Return(Index, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(2).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(Node, nil)
+	mockClient.EXPECT().IndexStatus(gomock.Any()).Times(1).Return(objects.IndexStatus{}, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager)
@@ -333,18 +334,21 @@ func TestIndexCollectReturnsOneOfEachMetricWithCorrectValuesWithIndexer(t *testi
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Index := test.GenerateIndex()
-	mockClient.EXPECT().Index().Times(1).Return(Index, nil)
+	mockClient.EXPECT().Index(gomock.Any()).Times(1).Return(Index, nil)
 
 	Node := objects.Node{
 		Services: []string{"index"},
 	}
-	mockClient.EXPECT().GetCurrentNode().Times(2).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(Node, nil)
 
 	Stats := test.GenerateIndexerStats()
-	mockClient.EXPECT().IndexStats().Times(1).Return(Stats, nil)
+	mockClient.EXPECT().IndexStats(gomock.Any()).Times(1).Return(Stats, nil)
+	mockClient.EXPECT().IndexStatus(gomock.Any()).Times(1).Return(objects.IndexStatus{
+		Indexes: []objects.IndexDefinition{{Bucket: "default", IndexName: "idx", StorageMode: "plasma"}},
+	}, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager)
@@ -370,11 +374,23 @@ func TestIndexCollectReturnsOneOfEachMetricWithCorrectValuesWithIndexer(t *testi
 				gauge, err := test.GetGaugeValue(m)
 				assert.Nil(t, err)
 				assert.True(t, gauge > 0, fqName)
+			case "cbindex_storage_info":
+				gauge, err := test.GetGaugeValue(m)
+				assert.Nil(t, err)
+				assert.Equal(t, 1.0, gauge, fqName)
+			case "cbindex_duplicate_indexes":
+				gauge, err := test.GetGaugeValue(m)
+				assert.Nil(t, err)
+				assert.Equal(t, 0.0, gauge, fqName)
 			default:
 				key := test.GetKeyFromFQName(defaultConfig.Collectors.Index, fqName)
 				name := defaultConfig.Collectors.Index.Metrics[key].Name
 
-				if !contains(defaultConfig.Collectors.Index.Metrics[key].Labels, "keyspace") {
+				if stat, ok := objects.IndexerMetrics[name]; ok {
+					gauge, err := test.GetGaugeValue(m)
+					assert.Nil(t, err)
+					assert.Equal(t, Stats[objects.IndexerStats][stat], gauge, fqName)
+				} else if !contains(defaultConfig.Collectors.Index.Metrics[key].Labels, "keyspace") {
 					sampleName := "index_" + name
 
 					gauge, err := test.GetGaugeValue(m)
@@ -405,3 +421,49 @@ func TestIndexCollectReturnsOneOfEachMetricWithCorrectValuesWithIndexer(t *testi
 		}
 	}
 }
+
+func TestIndexCollectReportsStorageModesAndDuplicateIndexes(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Index(gomock.Any()).Times(1).Return(test.GenerateIndex(), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().IndexStatus(gomock.Any()).Times(1).Return(objects.IndexStatus{
+		Indexes: []objects.IndexDefinition{
+			{
+				Bucket: "travel", Scope: "inventory", Collection: "airline", IndexName: "by_name", StorageMode: "plasma",
+				Definition: "CREATE INDEX `by_name` ON `travel`.`inventory`.`airline`(`name`) WITH { \"defer_build\":true }",
+			},
+			{
+				Bucket: "travel", Scope: "inventory", Collection: "airline", IndexName: "by_name", StorageMode: "plasma", ReplicaID: 1,
+				Definition: "CREATE INDEX `by_name` ON `travel`.`inventory`.`airline`(`name`) WITH { \"num_replica\":1 }",
+			},
+			{
+				Bucket: "travel", Scope: "inventory", Collection: "airline", IndexName: "name_again", StorageMode: "plasma",
+				Definition: "CREATE INDEX `name_again` ON `travel`.`inventory`.`airline`(`name`)",
+			},
+			{
+				Bucket: "travel", Scope: "inventory", Collection: "airline", IndexName: "by_country", StorageMode: "plasma",
+				Definition: "CREATE INDEX `by_country` ON `travel`.`inventory`.`airline`(`country`)",
+			},
+			{
+				Bucket: "legacy", IndexName: "#primary", StorageMode: "forestdb",
+				Definition: "CREATE PRIMARY INDEX `#primary` ON `legacy`",
+			},
+		},
+	}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager))
+
+	assert.Equal(t, 1.0, values["cbindex_storage_info/by_name/travel:inventory:airline/plasma"])
+	assert.Equal(t, 1.0, values["cbindex_storage_info/name_again/travel:inventory:airline/plasma"])
+	assert.Equal(t, 1.0, values["cbindex_storage_info/#primary/legacy/forestdb"])
+	assert.Equal(t, 1.0, values["cbindex_duplicate_indexes/travel:inventory:airline"])
+	assert.Equal(t, 0.0, values["cbindex_duplicate_indexes/legacy"])
+	assert.Equal(t, 1.0, values["cbindex_up"])
+}
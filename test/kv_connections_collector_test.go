@@ -0,0 +1,84 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// nodeStatsRange returns a stats range with a series for each node, whose
+// latest value is the value given for the node.
+func nodeStatsRange(t *testing.T, values map[string]float64) objects.StatsRange {
+	data := []string{}
+	for node, value := range values {
+		data = append(data, fmt.Sprintf(`{"metric": {"nodes": [%q]}, "values": [[1620000000, "0"], [1620000010, "%v"]]}`, node, value))
+	}
+
+	var stats objects.StatsRange
+	assert.Nil(t, json.Unmarshal([]byte(`{"data": [`+strings.Join(data, ", ")+`]}`), &stats))
+
+	return stats
+}
+
+func TestKVConnectionsCollectReportsEveryNode(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVCurrConnectionsStat).Times(1).
+		Return(nodeStatsRange(t, map[string]float64{"node1:8091": 120, "node2:8091": 80}), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVRejectedConnsStat).Times(1).
+		Return(nodeStatsRange(t, map[string]float64{"node1:8091": 3, "node2:8091": 0}), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVConnStructuresStat).Times(1).
+		Return(nodeStatsRange(t, map[string]float64{"node1:8091": 150, "node2:8091": 100}), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), objects.KVTotalConnectionsStat).Times(1).
+		Return(nodeStatsRange(t, map[string]float64{"node1:8091": 5000, "node2:8091": 4000}), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewKVConnectionsCollector(mockClient, defaultConfig.Collectors.KVConnections, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"cbkv_connections/node1:8091":                120,
+		"cbkv_connections/node2:8091":                80,
+		"cbkv_rejected_connections_total/node1:8091": 3,
+		"cbkv_rejected_connections_total/node2:8091": 0,
+		"cbkv_connection_structures/node1:8091":      150,
+		"cbkv_connection_structures/node2:8091":      100,
+		"cbkv_connections_total/node1:8091":          5000,
+		"cbkv_connections_total/node2:8091":          4000,
+		"cbkv_up":                                    1,
+		"cbkv_scrape_duration_seconds":               values["cbkv_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestKVConnectionsCollectReturnsDownWithoutStatsAPI(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().NodeStatsRange(gomock.Any(), gomock.Any()).Times(1).Return(objects.StatsRange{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewKVConnectionsCollector(mockClient, defaultConfig.Collectors.KVConnections, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbkv_up": 0}, values)
+}
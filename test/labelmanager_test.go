@@ -1,6 +1,7 @@
 package test
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
@@ -19,10 +20,10 @@ func TestMetricsConcurrency(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(15).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(15).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(15).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(15).Return(node, nil)
 	manager := util.NewLabelManager(mockClient, 1*time.Second)
 
 	var wg sync.WaitGroup
@@ -31,7 +32,7 @@ func TestMetricsConcurrency(t *testing.T) {
 		defer wg.Done()
 
 		for start := time.Now(); time.Since(start) < 15*time.Second; {
-			ctx, err := manager.GetMetricContext("a", "b")
+			ctx, err := manager.GetMetricContext(context.Background(), "a", "b")
 
 			assert.Nil(t, err)
 			assert.Equal(t, "dummy-cluster", ctx.ClusterName)
@@ -49,14 +50,14 @@ func TestLabelManagerCallsClusterNameOnce(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	manager := util.NewLabelManager(mockClient, 600*time.Second)
 
-	ctx, err := manager.GetMetricContext("a", "b")
+	ctx, err := manager.GetMetricContext(context.Background(), "a", "b")
 
 	assert.Nil(t, err)
 	assert.Equal(t, "dummy-cluster", ctx.ClusterName)
@@ -68,17 +69,17 @@ func TestLabelManagerCallsClusterNameOnceEvenOnSubsequentRequests(t *testing.T)
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	manager := util.NewLabelManager(mockClient, 600*time.Second)
 
-	ctx, err := manager.GetMetricContext("a", "b")
+	ctx, err := manager.GetMetricContext(context.Background(), "a", "b")
 	assert.Nil(t, err)
 
-	ctx2, err := manager.GetMetricContext("x", "d")
+	ctx2, err := manager.GetMetricContext(context.Background(), "x", "d")
 
 	assert.Nil(t, err)
 	assert.Equal(t, "dummy-cluster", ctx.ClusterName)
@@ -92,11 +93,11 @@ func TestLabelManagerReturnsErrorIfClientErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("", ErrDummy)
 
 	manager := util.NewLabelManager(mockClient, 600*time.Second)
 
-	_, err := manager.GetMetricContext("a", "b")
+	_, err := manager.GetMetricContext(context.Background(), "a", "b")
 	assert.NotNil(t, err)
 }
 
@@ -189,3 +190,55 @@ func TestLabelManagerSplitsValueOnColonAndRetursFirstValueAsLabel(t *testing.T)
 	assert.Contains(t, labelValues, "new")
 	assert.Contains(t, labelValues, "foobarbaz")
 }
+
+func TestLabelManagerLooksUpUUIDsOnceWhenLabelled(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	bucket := test.GenerateBucket("wawa-bucket")
+	bucket.UUID = "5b0c5f6e4b7a4c1e9c6a1d2e3f405162"
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterUUID(gomock.Any()).Times(1).Return("a0c6c1d1e0c1a4e4a37fba9a3b1a8d7e", nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{bucket}, nil)
+
+	manager := util.NewLabelManager(mockClient, 600*time.Second)
+	ctx := util.MetricContext{BucketName: "wawa-bucket"}
+	labels := []string{objects.ClusterUUIDLabel, objects.BucketUUIDLabel}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, []string{"a0c6c1d1e0c1a4e4a37fba9a3b1a8d7e", "5b0c5f6e4b7a4c1e9c6a1d2e3f405162"}, manager.GetLabelValues(labels, ctx))
+	}
+}
+
+func TestLabelManagerCachesMissingBucketUUIDs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+
+	manager := util.NewLabelManager(mockClient, 600*time.Second)
+	ctx := util.MetricContext{BucketName: "deleted-bucket"}
+	labels := []string{objects.BucketUUIDLabel}
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, []string{""}, manager.GetLabelValues(labels, ctx))
+	}
+}
+
+func TestLabelManagerPrefersUUIDsFromCTX(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	manager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	ctx := util.MetricContext{ClusterUUID: "cluster-uuid", BucketName: "wawa-bucket", BucketUUID: "bucket-uuid"}
+	labels := []string{objects.ClusterUUIDLabel, objects.BucketUUIDLabel}
+
+	assert.Equal(t, []string{"cluster-uuid", "bucket-uuid"}, manager.GetLabelValues(labels, ctx))
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/handlers"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func dummyExporterInfo(gatherer prometheus.Gatherer) *handlers.ExporterInfo {
+	return &handlers.ExporterInfo{
+		Version:    "1.0.0",
+		Collectors: []string{"node", "Audit"},
+		Targets:    []handlers.Target{{URL: "http://localhost:8091", Auth: objects.AuthModeBasic, User: "Administrator"}},
+		Catalog:    []objects.CatalogEntry{{Name: "cbnode_healthy", Collector: "node"}},
+		Scrapes:    util.NewScrapeStatusGatherer(gatherer),
+	}
+}
+
+func serveLanding(handler http.Handler, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+
+	return rec
+}
+
+func TestLandingPageDescribesExporter(t *testing.T) {
+	info := dummyExporterInfo(prometheus.NewRegistry())
+
+	rec := serveLanding(handlers.Landing(info), "/")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `<a href="metrics">`)
+	assert.Contains(t, rec.Body.String(), "<li>Audit</li>")
+	assert.Contains(t, rec.Body.String(), "<td>http://localhost:8091</td>")
+	assert.Contains(t, rec.Body.String(), "The metrics have not been scraped yet.")
+
+	assert.Equal(t, http.StatusNotFound, serveLanding(handlers.Landing(info), "/missing").Code)
+}
+
+func TestLandingPageReportsLastScrape(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	up := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbnode_up"}, []string{objects.ClusterLabel})
+	up.WithLabelValues("a").Set(1)
+	up.WithLabelValues("b").Set(0)
+	registry.MustRegister(up)
+
+	info := dummyExporterInfo(registry)
+
+	_, err := info.Scrapes.Gather()
+	assert.Nil(t, err)
+
+	rec := serveLanding(handlers.Landing(info), "/")
+	assert.Contains(t, rec.Body.String(), "<tr><td>cbnode_up</td><td>0</td></tr>")
+
+	var status struct {
+		LastScrape util.ScrapeStatus      `json:"lastScrape"`
+		Metrics    []objects.CatalogEntry `json:"metrics"`
+	}
+
+	rec = serveLanding(handlers.Debug(info), "/debug")
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, map[string]float64{"cbnode_up": 0}, status.LastScrape.Up)
+	assert.Equal(t, "cbnode_healthy", status.Metrics[0].Name)
+}
+
+func TestHealthzRespondsOK(t *testing.T) {
+	assert.Equal(t, http.StatusOK, serveLanding(handlers.Healthz(), "/healthz").Code)
+}
+
+func TestSnapshotHoldsTopologyAndSeriesOfEachCluster(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	healthy := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbnode_healthy"}, []string{objects.ClusterLabel, objects.NodeLabel})
+	healthy.WithLabelValues("a", "node1").Set(1)
+	healthy.WithLabelValues("a", "node2").Set(0)
+
+	quota := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_quota_user_percent"}, []string{objects.ClusterLabel, objects.BucketLabel})
+	quota.WithLabelValues("a", "default").Set(50)
+	quota.WithLabelValues("b", "travel-sample").Set(math.NaN())
+
+	registry.MustRegister(healthy, quota, prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbexporter_unlabelled"}))
+
+	info := dummyExporterInfo(registry)
+
+	var snapshot struct {
+		Version    string                       `json:"version"`
+		LastScrape *util.ScrapeStatus           `json:"lastScrape"`
+		Clusters   map[string]util.ClusterState `json:"clusters"`
+	}
+
+	// the metrics are gathered for the snapshot if they have not been scraped.
+	rec := serveLanding(handlers.Snapshot(info), handlers.SnapshotPath)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Nil(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+
+	assert.Equal(t, "1.0.0", snapshot.Version)
+	assert.NotNil(t, snapshot.LastScrape)
+	assert.Len(t, snapshot.Clusters, 2)
+
+	a := snapshot.Clusters["a"]
+	assert.Equal(t, []string{"node1", "node2"}, a.Nodes)
+	assert.Equal(t, []string{"default"}, a.Buckets)
+	assert.Len(t, a.Metrics["cbnode_healthy"], 2)
+	assert.Equal(t, map[string]string{"bucket": "default"}, a.Metrics["cbbucketinfo_basic_quota_user_percent"][0].Labels)
+	assert.Equal(t, 50.0, *a.Metrics["cbbucketinfo_basic_quota_user_percent"][0].Value)
+	assert.NotContains(t, a.Metrics, "cbexporter_unlabelled")
+
+	assert.Nil(t, snapshot.Clusters["b"].Metrics["cbbucketinfo_basic_quota_user_percent"][0].Value)
+
+	rec = httptest.NewRecorder()
+	handlers.Snapshot(info).ServeHTTP(rec, httptest.NewRequest("POST", handlers.SnapshotPath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
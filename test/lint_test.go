@@ -0,0 +1,80 @@
+package test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintingRegistererRejectsBadNamesWhenStrict(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registerer := util.NewLintingRegisterer(registry, objects.MetricLintStrict)
+
+	err := registerer.Register(prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbtest_itemCount"}))
+
+	assert.ErrorIs(t, err, util.ErrMetricLint)
+	assert.Contains(t, fmt.Sprint(err), "cbtest_itemCount")
+	assert.Contains(t, fmt.Sprint(err), "snake_case")
+	assert.Contains(t, fmt.Sprint(err), "no help text")
+
+	assert.Nil(t, registerer.Register(prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cbtest_item_count",
+		Help: "The number of items.",
+	})))
+
+	families, err := registry.Gather()
+	assert.Nil(t, err)
+	assert.Len(t, families, 1)
+}
+
+func TestLintingRegistererOnlyWarns(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	assert.Nil(t, util.NewLintingRegisterer(registry, objects.MetricLintWarn).Register(
+		prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbtest_itemCount"})))
+	assert.Same(t, registry, util.NewLintingRegisterer(registry, objects.MetricLintOff))
+}
+
+func TestDefaultMetricsPassLint(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	bucketStats := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	perNode := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+
+	for _, collector := range []prometheus.Collector{
+		&bucketStats,
+		&perNode,
+		collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager),
+		collectors.NewBucketInfoCollector(mockClient, defaultConfig.Collectors.BucketInfo, labelManager),
+		collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager),
+		collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager),
+		collectors.NewIndexCollector(mockClient, defaultConfig.Collectors.Index, labelManager),
+		collectors.NewFTSCollector(mockClient, defaultConfig.Collectors.Search, labelManager),
+		collectors.NewCbasCollector(mockClient, defaultConfig.Collectors.Analytics, labelManager),
+		collectors.NewEventingCollector(mockClient, defaultConfig.Collectors.Eventing, labelManager),
+		collectors.NewSlowQueriesCollector(mockClient, nil, defaultConfig.Collectors.SlowQueries, labelManager),
+		collectors.NewPreparedCollector(mockClient, defaultConfig.Collectors.Prepared, labelManager),
+		collectors.NewHotKeysCollector(mockClient, 0, defaultConfig.Collectors.HotKeys, labelManager),
+		collectors.NewKVConnectionsCollector(mockClient, defaultConfig.Collectors.KVConnections, labelManager),
+		collectors.NewClientErrorsCollector(mockClient, defaultConfig.Collectors.ClientErrors, labelManager),
+		collectors.NewServiceProbesCollector(mockClient, nil, labelManager),
+		collectors.NewSecurityCollector(mockClient, defaultConfig.Collectors.Security, labelManager),
+	} {
+		assert.Empty(t, util.LintCollector(collector))
+	}
+}
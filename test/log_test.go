@@ -0,0 +1,62 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// logMessages returns the value of the exporter's own log counter name for the
+// given level and class.
+func logMessages(t *testing.T, name, level, class string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)[name]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["level"] == level && labels["class"] == class {
+			return metric.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestLogThrottlesRepeatedErrors(t *testing.T) {
+	const class = "unable to reach node %s in log throttle test"
+
+	log.SetThrottle(time.Hour)
+	defer log.SetThrottle(0)
+
+	log.Error(class, "node1")
+	log.Error(class, "node1")
+	log.Error(class, "node1")
+	log.Error(class, "node2")
+	log.Warn(class, "node1")
+
+	assert.Equal(t, 4.0, logMessages(t, "cbexporter_log_messages_total", "error", class))
+	assert.Equal(t, 2.0, logMessages(t, "cbexporter_log_messages_throttled_total", "error", class))
+	assert.Equal(t, 1.0, logMessages(t, "cbexporter_log_messages_total", "warn", class))
+	assert.Equal(t, 0.0, logMessages(t, "cbexporter_log_messages_throttled_total", "warn", class))
+}
+
+func TestLogThrottleDisabled(t *testing.T) {
+	const class = "unable to reach node %s in disabled log throttle test"
+
+	log.SetThrottle(0)
+
+	log.Error(class, "node1")
+	log.Error(class, "node1")
+
+	assert.Equal(t, 2.0, logMessages(t, "cbexporter_log_messages_total", "error", class))
+	assert.Equal(t, 0.0, logMessages(t, "cbexporter_log_messages_throttled_total", "error", class))
+}
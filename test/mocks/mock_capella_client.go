@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/util/capella.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	objects "github.com/couchbase/couchbase-exporter/pkg/objects"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockCapellaClient is a mock of CapellaClient interface.
+type MockCapellaClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockCapellaClientMockRecorder
+}
+
+// MockCapellaClientMockRecorder is the mock recorder for MockCapellaClient.
+type MockCapellaClientMockRecorder struct {
+	mock *MockCapellaClient
+}
+
+// NewMockCapellaClient creates a new mock instance.
+func NewMockCapellaClient(ctrl *gomock.Controller) *MockCapellaClient {
+	mock := &MockCapellaClient{ctrl: ctrl}
+	mock.recorder = &MockCapellaClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCapellaClient) EXPECT() *MockCapellaClientMockRecorder {
+	return m.recorder
+}
+
+// Buckets mocks base method.
+func (m *MockCapellaClient) Buckets(projectID, clusterID string) ([]objects.CapellaBucket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Buckets", projectID, clusterID)
+	ret0, _ := ret[0].([]objects.CapellaBucket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Buckets indicates an expected call of Buckets.
+func (mr *MockCapellaClientMockRecorder) Buckets(projectID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buckets", reflect.TypeOf((*MockCapellaClient)(nil).Buckets), projectID, clusterID)
+}
+
+// Cluster mocks base method.
+func (m *MockCapellaClient) Cluster(projectID, clusterID string) (objects.CapellaCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Cluster", projectID, clusterID)
+	ret0, _ := ret[0].(objects.CapellaCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Cluster indicates an expected call of Cluster.
+func (mr *MockCapellaClientMockRecorder) Cluster(projectID, clusterID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cluster", reflect.TypeOf((*MockCapellaClient)(nil).Cluster), projectID, clusterID)
+}
@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	context "context"
 	reflect "reflect"
 
 	objects "github.com/couchbase/couchbase-exporter/pkg/objects"
@@ -34,288 +35,603 @@ func (m *MockCbClient) EXPECT() *MockCbClientMockRecorder {
 	return m.recorder
 }
 
+// AnalyticsIngestion mocks base method.
+func (m *MockCbClient) AnalyticsIngestion(arg0 context.Context) (objects.AnalyticsIngestion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnalyticsIngestion", arg0)
+	ret0, _ := ret[0].(objects.AnalyticsIngestion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnalyticsIngestion indicates an expected call of AnalyticsIngestion.
+func (mr *MockCbClientMockRecorder) AnalyticsIngestion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnalyticsIngestion", reflect.TypeOf((*MockCbClient)(nil).AnalyticsIngestion), arg0)
+}
+
+// AuditSettings mocks base method.
+func (m *MockCbClient) AuditSettings(arg0 context.Context) (objects.AuditSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditSettings", arg0)
+	ret0, _ := ret[0].(objects.AuditSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditSettings indicates an expected call of AuditSettings.
+func (mr *MockCbClientMockRecorder) AuditSettings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditSettings", reflect.TypeOf((*MockCbClient)(nil).AuditSettings), arg0)
+}
+
+// AutoCompaction mocks base method.
+func (m *MockCbClient) AutoCompaction(arg0 context.Context) (objects.AutoCompaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutoCompaction", arg0)
+	ret0, _ := ret[0].(objects.AutoCompaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AutoCompaction indicates an expected call of AutoCompaction.
+func (mr *MockCbClientMockRecorder) AutoCompaction(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoCompaction", reflect.TypeOf((*MockCbClient)(nil).AutoCompaction), arg0)
+}
+
+// BackupRepositories mocks base method.
+func (m *MockCbClient) BackupRepositories(arg0 context.Context) ([]objects.BackupRepository, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupRepositories", arg0)
+	ret0, _ := ret[0].([]objects.BackupRepository)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackupRepositories indicates an expected call of BackupRepositories.
+func (mr *MockCbClientMockRecorder) BackupRepositories(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupRepositories", reflect.TypeOf((*MockCbClient)(nil).BackupRepositories), arg0)
+}
+
+// BackupRepositoryInfo mocks base method.
+func (m *MockCbClient) BackupRepositoryInfo(arg0 context.Context, arg1 string) (objects.BackupRepositoryInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupRepositoryInfo", arg0, arg1)
+	ret0, _ := ret[0].(objects.BackupRepositoryInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackupRepositoryInfo indicates an expected call of BackupRepositoryInfo.
+func (mr *MockCbClientMockRecorder) BackupRepositoryInfo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupRepositoryInfo", reflect.TypeOf((*MockCbClient)(nil).BackupRepositoryInfo), arg0, arg1)
+}
+
+// BackupTaskHistory mocks base method.
+func (m *MockCbClient) BackupTaskHistory(arg0 context.Context, arg1 string) ([]objects.BackupTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupTaskHistory", arg0, arg1)
+	ret0, _ := ret[0].([]objects.BackupTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackupTaskHistory indicates an expected call of BackupTaskHistory.
+func (mr *MockCbClientMockRecorder) BackupTaskHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupTaskHistory", reflect.TypeOf((*MockCbClient)(nil).BackupTaskHistory), arg0, arg1)
+}
+
 // BucketNodes mocks base method.
-func (m *MockCbClient) BucketNodes(arg0 string) ([]interface{}, error) {
+func (m *MockCbClient) BucketNodes(arg0 context.Context, arg1 string) ([]interface{}, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BucketNodes", arg0)
+	ret := m.ctrl.Call(m, "BucketNodes", arg0, arg1)
 	ret0, _ := ret[0].([]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // BucketNodes indicates an expected call of BucketNodes.
-func (mr *MockCbClientMockRecorder) BucketNodes(arg0 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) BucketNodes(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketNodes", reflect.TypeOf((*MockCbClient)(nil).BucketNodes), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketNodes", reflect.TypeOf((*MockCbClient)(nil).BucketNodes), arg0, arg1)
 }
 
 // BucketPerNodeStats mocks base method.
-func (m *MockCbClient) BucketPerNodeStats(arg0, arg1 string) (objects.BucketStats, error) {
+func (m *MockCbClient) BucketPerNodeStats(arg0 context.Context, arg1, arg2 string) (objects.BucketStats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BucketPerNodeStats", arg0, arg1)
+	ret := m.ctrl.Call(m, "BucketPerNodeStats", arg0, arg1, arg2)
 	ret0, _ := ret[0].(objects.BucketStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // BucketPerNodeStats indicates an expected call of BucketPerNodeStats.
-func (mr *MockCbClientMockRecorder) BucketPerNodeStats(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) BucketPerNodeStats(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketPerNodeStats", reflect.TypeOf((*MockCbClient)(nil).BucketPerNodeStats), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketPerNodeStats", reflect.TypeOf((*MockCbClient)(nil).BucketPerNodeStats), arg0, arg1, arg2)
 }
 
 // BucketStats mocks base method.
-func (m *MockCbClient) BucketStats(arg0 string) (objects.BucketStats, error) {
+func (m *MockCbClient) BucketStats(arg0 context.Context, arg1 string) (objects.BucketStats, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BucketStats", arg0)
+	ret := m.ctrl.Call(m, "BucketStats", arg0, arg1)
 	ret0, _ := ret[0].(objects.BucketStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // BucketStats indicates an expected call of BucketStats.
-func (mr *MockCbClientMockRecorder) BucketStats(arg0 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) BucketStats(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketStats", reflect.TypeOf((*MockCbClient)(nil).BucketStats), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BucketStats", reflect.TypeOf((*MockCbClient)(nil).BucketStats), arg0, arg1)
 }
 
 // Buckets mocks base method.
-func (m *MockCbClient) Buckets() ([]objects.BucketInfo, error) {
+func (m *MockCbClient) Buckets(arg0 context.Context) ([]objects.BucketInfo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Buckets")
+	ret := m.ctrl.Call(m, "Buckets", arg0)
 	ret0, _ := ret[0].([]objects.BucketInfo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Buckets indicates an expected call of Buckets.
-func (mr *MockCbClientMockRecorder) Buckets() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Buckets(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buckets", reflect.TypeOf((*MockCbClient)(nil).Buckets))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Buckets", reflect.TypeOf((*MockCbClient)(nil).Buckets), arg0)
 }
 
 // Cbas mocks base method.
-func (m *MockCbClient) Cbas() (objects.Analytics, error) {
+func (m *MockCbClient) Cbas(arg0 context.Context) (objects.Analytics, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Cbas")
+	ret := m.ctrl.Call(m, "Cbas", arg0)
 	ret0, _ := ret[0].(objects.Analytics)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Cbas indicates an expected call of Cbas.
-func (mr *MockCbClientMockRecorder) Cbas() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Cbas(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cbas", reflect.TypeOf((*MockCbClient)(nil).Cbas))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Cbas", reflect.TypeOf((*MockCbClient)(nil).Cbas), arg0)
 }
 
 // ClusterName mocks base method.
-func (m *MockCbClient) ClusterName() (string, error) {
+func (m *MockCbClient) ClusterName(arg0 context.Context) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ClusterName")
+	ret := m.ctrl.Call(m, "ClusterName", arg0)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ClusterName indicates an expected call of ClusterName.
-func (mr *MockCbClientMockRecorder) ClusterName() *gomock.Call {
+func (mr *MockCbClientMockRecorder) ClusterName(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterName", reflect.TypeOf((*MockCbClient)(nil).ClusterName), arg0)
+}
+
+// ClusterUUID mocks base method.
+func (m *MockCbClient) ClusterUUID(arg0 context.Context) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClusterUUID", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClusterUUID indicates an expected call of ClusterUUID.
+func (mr *MockCbClientMockRecorder) ClusterUUID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterUUID", reflect.TypeOf((*MockCbClient)(nil).ClusterUUID), arg0)
+}
+
+// CompletedRequests mocks base method.
+func (m *MockCbClient) CompletedRequests(arg0 context.Context) ([]objects.CompletedRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletedRequests", arg0)
+	ret0, _ := ret[0].([]objects.CompletedRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompletedRequests indicates an expected call of CompletedRequests.
+func (mr *MockCbClientMockRecorder) CompletedRequests(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletedRequests", reflect.TypeOf((*MockCbClient)(nil).CompletedRequests), arg0)
+}
+
+// DesignDocInfo mocks base method.
+func (m *MockCbClient) DesignDocInfo(arg0 context.Context, arg1, arg2 string) (objects.DesignDocInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DesignDocInfo", arg0, arg1, arg2)
+	ret0, _ := ret[0].(objects.DesignDocInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DesignDocInfo indicates an expected call of DesignDocInfo.
+func (mr *MockCbClientMockRecorder) DesignDocInfo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DesignDocInfo", reflect.TypeOf((*MockCbClient)(nil).DesignDocInfo), arg0, arg1, arg2)
+}
+
+// DesignDocs mocks base method.
+func (m *MockCbClient) DesignDocs(arg0 context.Context, arg1 string) (objects.DesignDocs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DesignDocs", arg0, arg1)
+	ret0, _ := ret[0].(objects.DesignDocs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DesignDocs indicates an expected call of DesignDocs.
+func (mr *MockCbClientMockRecorder) DesignDocs(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClusterName", reflect.TypeOf((*MockCbClient)(nil).ClusterName))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DesignDocs", reflect.TypeOf((*MockCbClient)(nil).DesignDocs), arg0, arg1)
+}
+
+// EncryptionAtRestSettings mocks base method.
+func (m *MockCbClient) EncryptionAtRestSettings(arg0 context.Context) (objects.EncryptionAtRestSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncryptionAtRestSettings", arg0)
+	ret0, _ := ret[0].(objects.EncryptionAtRestSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EncryptionAtRestSettings indicates an expected call of EncryptionAtRestSettings.
+func (mr *MockCbClientMockRecorder) EncryptionAtRestSettings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncryptionAtRestSettings", reflect.TypeOf((*MockCbClient)(nil).EncryptionAtRestSettings), arg0)
 }
 
 // Eventing mocks base method.
-func (m *MockCbClient) Eventing() (objects.Eventing, error) {
+func (m *MockCbClient) Eventing(arg0 context.Context) (objects.Eventing, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Eventing")
+	ret := m.ctrl.Call(m, "Eventing", arg0)
 	ret0, _ := ret[0].(objects.Eventing)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Eventing indicates an expected call of Eventing.
-func (mr *MockCbClientMockRecorder) Eventing() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Eventing(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Eventing", reflect.TypeOf((*MockCbClient)(nil).Eventing))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Eventing", reflect.TypeOf((*MockCbClient)(nil).Eventing), arg0)
+}
+
+// Events mocks base method.
+func (m *MockCbClient) Events(arg0 context.Context) (objects.SystemEvents, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Events", arg0)
+	ret0, _ := ret[0].(objects.SystemEvents)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Events indicates an expected call of Events.
+func (mr *MockCbClientMockRecorder) Events(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Events", reflect.TypeOf((*MockCbClient)(nil).Events), arg0)
 }
 
 // Fts mocks base method.
-func (m *MockCbClient) Fts() (objects.FTS, error) {
+func (m *MockCbClient) Fts(arg0 context.Context) (objects.FTS, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Fts")
+	ret := m.ctrl.Call(m, "Fts", arg0)
 	ret0, _ := ret[0].(objects.FTS)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Fts indicates an expected call of Fts.
-func (mr *MockCbClientMockRecorder) Fts() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Fts(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fts", reflect.TypeOf((*MockCbClient)(nil).Fts))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fts", reflect.TypeOf((*MockCbClient)(nil).Fts), arg0)
 }
 
 // Get mocks base method.
-func (m *MockCbClient) Get(arg0 string, arg1 interface{}) error {
+func (m *MockCbClient) Get(arg0 context.Context, arg1 string, arg2 interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret := m.ctrl.Call(m, "Get", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockCbClientMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) Get(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCbClient)(nil).Get), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCbClient)(nil).Get), arg0, arg1, arg2)
 }
 
 // GetCurrentNode mocks base method.
-func (m *MockCbClient) GetCurrentNode() (objects.Node, error) {
+func (m *MockCbClient) GetCurrentNode(arg0 context.Context) (objects.Node, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCurrentNode")
+	ret := m.ctrl.Call(m, "GetCurrentNode", arg0)
 	ret0, _ := ret[0].(objects.Node)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetCurrentNode indicates an expected call of GetCurrentNode.
-func (mr *MockCbClientMockRecorder) GetCurrentNode() *gomock.Call {
+func (mr *MockCbClientMockRecorder) GetCurrentNode(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentNode", reflect.TypeOf((*MockCbClient)(nil).GetCurrentNode))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCurrentNode", reflect.TypeOf((*MockCbClient)(nil).GetCurrentNode), arg0)
 }
 
 // Index mocks base method.
-func (m *MockCbClient) Index() (objects.Index, error) {
+func (m *MockCbClient) Index(arg0 context.Context) (objects.Index, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Index")
+	ret := m.ctrl.Call(m, "Index", arg0)
 	ret0, _ := ret[0].(objects.Index)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Index indicates an expected call of Index.
-func (mr *MockCbClientMockRecorder) Index() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Index(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockCbClient)(nil).Index))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Index", reflect.TypeOf((*MockCbClient)(nil).Index), arg0)
 }
 
 // IndexNode mocks base method.
-func (m *MockCbClient) IndexNode(arg0 string) (objects.Index, error) {
+func (m *MockCbClient) IndexNode(arg0 context.Context, arg1 string) (objects.Index, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IndexNode", arg0)
+	ret := m.ctrl.Call(m, "IndexNode", arg0, arg1)
 	ret0, _ := ret[0].(objects.Index)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IndexNode indicates an expected call of IndexNode.
-func (mr *MockCbClientMockRecorder) IndexNode(arg0 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) IndexNode(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexNode", reflect.TypeOf((*MockCbClient)(nil).IndexNode), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexNode", reflect.TypeOf((*MockCbClient)(nil).IndexNode), arg0, arg1)
 }
 
 // IndexStats mocks base method.
-func (m *MockCbClient) IndexStats() (map[string]map[string]interface{}, error) {
+func (m *MockCbClient) IndexStats(arg0 context.Context) (map[string]map[string]interface{}, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "IndexStats")
+	ret := m.ctrl.Call(m, "IndexStats", arg0)
 	ret0, _ := ret[0].(map[string]map[string]interface{})
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // IndexStats indicates an expected call of IndexStats.
-func (mr *MockCbClientMockRecorder) IndexStats() *gomock.Call {
+func (mr *MockCbClientMockRecorder) IndexStats(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexStats", reflect.TypeOf((*MockCbClient)(nil).IndexStats), arg0)
+}
+
+// IndexStatus mocks base method.
+func (m *MockCbClient) IndexStatus(arg0 context.Context) (objects.IndexStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IndexStatus", arg0)
+	ret0, _ := ret[0].(objects.IndexStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IndexStatus indicates an expected call of IndexStatus.
+func (mr *MockCbClientMockRecorder) IndexStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexStatus", reflect.TypeOf((*MockCbClient)(nil).IndexStatus), arg0)
+}
+
+// NodeStatsRange mocks base method.
+func (m *MockCbClient) NodeStatsRange(arg0 context.Context, arg1 string) (objects.StatsRange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeStatsRange", arg0, arg1)
+	ret0, _ := ret[0].(objects.StatsRange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeStatsRange indicates an expected call of NodeStatsRange.
+func (mr *MockCbClientMockRecorder) NodeStatsRange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeStatsRange", reflect.TypeOf((*MockCbClient)(nil).NodeStatsRange), arg0, arg1)
+}
+
+// NodeTime mocks base method.
+func (m *MockCbClient) NodeTime(ctx context.Context, hostname string) (objects.NodeTime, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeTime", ctx, hostname)
+	ret0, _ := ret[0].(objects.NodeTime)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeTime indicates an expected call of NodeTime.
+func (mr *MockCbClientMockRecorder) NodeTime(ctx, hostname interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IndexStats", reflect.TypeOf((*MockCbClient)(nil).IndexStats))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeTime", reflect.TypeOf((*MockCbClient)(nil).NodeTime), ctx, hostname)
 }
 
 // Nodes mocks base method.
-func (m *MockCbClient) Nodes() (objects.Nodes, error) {
+func (m *MockCbClient) Nodes(arg0 context.Context) (objects.Nodes, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Nodes")
+	ret := m.ctrl.Call(m, "Nodes", arg0)
 	ret0, _ := ret[0].(objects.Nodes)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Nodes indicates an expected call of Nodes.
-func (mr *MockCbClientMockRecorder) Nodes() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Nodes(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nodes", reflect.TypeOf((*MockCbClient)(nil).Nodes))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Nodes", reflect.TypeOf((*MockCbClient)(nil).Nodes), arg0)
 }
 
 // NodesNodes mocks base method.
-func (m *MockCbClient) NodesNodes() (objects.Nodes, error) {
+func (m *MockCbClient) NodesNodes(arg0 context.Context) (objects.Nodes, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "NodesNodes")
+	ret := m.ctrl.Call(m, "NodesNodes", arg0)
 	ret0, _ := ret[0].(objects.Nodes)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // NodesNodes indicates an expected call of NodesNodes.
-func (mr *MockCbClientMockRecorder) NodesNodes() *gomock.Call {
+func (mr *MockCbClientMockRecorder) NodesNodes(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodesNodes", reflect.TypeOf((*MockCbClient)(nil).NodesNodes))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodesNodes", reflect.TypeOf((*MockCbClient)(nil).NodesNodes), arg0)
+}
+
+// Pools mocks base method.
+func (m *MockCbClient) Pools(arg0 context.Context) (objects.Pools, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pools", arg0)
+	ret0, _ := ret[0].(objects.Pools)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Pools indicates an expected call of Pools.
+func (mr *MockCbClientMockRecorder) Pools(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pools", reflect.TypeOf((*MockCbClient)(nil).Pools), arg0)
+}
+
+// Prepareds mocks base method.
+func (m *MockCbClient) Prepareds(arg0 context.Context) ([]objects.Prepared, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prepareds", arg0)
+	ret0, _ := ret[0].([]objects.Prepared)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Prepareds indicates an expected call of Prepareds.
+func (mr *MockCbClientMockRecorder) Prepareds(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prepareds", reflect.TypeOf((*MockCbClient)(nil).Prepareds), arg0)
 }
 
 // Query mocks base method.
-func (m *MockCbClient) Query() (objects.Query, error) {
+func (m *MockCbClient) Query(arg0 context.Context) (objects.Query, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Query")
+	ret := m.ctrl.Call(m, "Query", arg0)
 	ret0, _ := ret[0].(objects.Query)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Query indicates an expected call of Query.
-func (mr *MockCbClientMockRecorder) Query() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Query(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockCbClient)(nil).Query))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockCbClient)(nil).Query), arg0)
 }
 
 // QueryNode mocks base method.
-func (m *MockCbClient) QueryNode(arg0 string) (objects.Query, error) {
+func (m *MockCbClient) QueryNode(arg0 context.Context, arg1 string) (objects.Query, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "QueryNode", arg0)
+	ret := m.ctrl.Call(m, "QueryNode", arg0, arg1)
 	ret0, _ := ret[0].(objects.Query)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // QueryNode indicates an expected call of QueryNode.
-func (mr *MockCbClientMockRecorder) QueryNode(arg0 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) QueryNode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryNode", reflect.TypeOf((*MockCbClient)(nil).QueryNode), arg0, arg1)
+}
+
+// QueryVitals mocks base method.
+func (m *MockCbClient) QueryVitals(arg0 context.Context) (objects.QueryVitals, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueryVitals", arg0)
+	ret0, _ := ret[0].(objects.QueryVitals)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QueryVitals indicates an expected call of QueryVitals.
+func (mr *MockCbClientMockRecorder) QueryVitals(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryNode", reflect.TypeOf((*MockCbClient)(nil).QueryNode), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueryVitals", reflect.TypeOf((*MockCbClient)(nil).QueryVitals), arg0)
+}
+
+// SecuritySettings mocks base method.
+func (m *MockCbClient) SecuritySettings(arg0 context.Context) (objects.SecuritySettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecuritySettings", arg0)
+	ret0, _ := ret[0].(objects.SecuritySettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SecuritySettings indicates an expected call of SecuritySettings.
+func (mr *MockCbClientMockRecorder) SecuritySettings(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecuritySettings", reflect.TypeOf((*MockCbClient)(nil).SecuritySettings), arg0)
+}
+
+// ServerGroups mocks base method.
+func (m *MockCbClient) ServerGroups(arg0 context.Context) (objects.ServerGroups, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServerGroups", arg0)
+	ret0, _ := ret[0].(objects.ServerGroups)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServerGroups indicates an expected call of ServerGroups.
+func (mr *MockCbClientMockRecorder) ServerGroups(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServerGroups", reflect.TypeOf((*MockCbClient)(nil).ServerGroups), arg0)
 }
 
 // Servers mocks base method.
-func (m *MockCbClient) Servers(arg0 string) (objects.Servers, error) {
+func (m *MockCbClient) Servers(arg0 context.Context, arg1 string) (objects.Servers, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Servers", arg0)
+	ret := m.ctrl.Call(m, "Servers", arg0, arg1)
 	ret0, _ := ret[0].(objects.Servers)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Servers indicates an expected call of Servers.
-func (mr *MockCbClientMockRecorder) Servers(arg0 interface{}) *gomock.Call {
+func (mr *MockCbClientMockRecorder) Servers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Servers", reflect.TypeOf((*MockCbClient)(nil).Servers), arg0, arg1)
+}
+
+// StatsRange mocks base method.
+func (m *MockCbClient) StatsRange(arg0 context.Context, arg1 string) (objects.StatsRange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatsRange", arg0, arg1)
+	ret0, _ := ret[0].(objects.StatsRange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatsRange indicates an expected call of StatsRange.
+func (mr *MockCbClientMockRecorder) StatsRange(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Servers", reflect.TypeOf((*MockCbClient)(nil).Servers), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatsRange", reflect.TypeOf((*MockCbClient)(nil).StatsRange), arg0, arg1)
 }
 
 // Tasks mocks base method.
-func (m *MockCbClient) Tasks() ([]objects.Task, error) {
+func (m *MockCbClient) Tasks(arg0 context.Context) ([]objects.Task, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Tasks")
+	ret := m.ctrl.Call(m, "Tasks", arg0)
 	ret0, _ := ret[0].([]objects.Task)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Tasks indicates an expected call of Tasks.
-func (mr *MockCbClientMockRecorder) Tasks() *gomock.Call {
+func (mr *MockCbClientMockRecorder) Tasks(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tasks", reflect.TypeOf((*MockCbClient)(nil).Tasks))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tasks", reflect.TypeOf((*MockCbClient)(nil).Tasks), arg0)
 }
 
 // URL mocks base method.
@@ -331,3 +647,33 @@ func (mr *MockCbClientMockRecorder) URL(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "URL", reflect.TypeOf((*MockCbClient)(nil).URL), arg0)
 }
+
+// WhoAmI mocks base method.
+func (m *MockCbClient) WhoAmI(arg0 context.Context) (objects.WhoAmI, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WhoAmI", arg0)
+	ret0, _ := ret[0].(objects.WhoAmI)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WhoAmI indicates an expected call of WhoAmI.
+func (mr *MockCbClientMockRecorder) WhoAmI(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WhoAmI", reflect.TypeOf((*MockCbClient)(nil).WhoAmI), arg0)
+}
+
+// XdcrStats mocks base method.
+func (m *MockCbClient) XdcrStats(ctx context.Context, bucket string) (objects.XdcrStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "XdcrStats", ctx, bucket)
+	ret0, _ := ret[0].(objects.XdcrStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// XdcrStats indicates an expected call of XdcrStats.
+func (mr *MockCbClientMockRecorder) XdcrStats(ctx, bucket interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "XdcrStats", reflect.TypeOf((*MockCbClient)(nil).XdcrStats), ctx, bucket)
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func namingRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	cpuIdle := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketstat_cpu_idle_ms", Help: "CPU idle"}, []string{"bucket"})
+	cpuIdle.WithLabelValues("wawa-bucket").Set(2500)
+
+	failover := prometheus.NewCounter(prometheus.CounterOpts{Name: "cbnode_failover", Help: "Failovers"})
+	failover.Add(3)
+
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbnode_up", Help: "Up"})
+	up.Set(1)
+
+	registry.MustRegister(cpuIdle, failover, up)
+
+	return registry
+}
+
+func TestNamingGathererCorrectsNames(t *testing.T) {
+	gatherer, err := util.NewNamingGatherer(namingRegistry(), objects.MetricNamesCorrected)
+	assert.Nil(t, err)
+
+	families := gatherByName(t, gatherer)
+
+	assert.NotContains(t, families, "cbbucketstat_cpu_idle_ms")
+	assert.NotContains(t, families, "cbnode_failover")
+	assert.Contains(t, families, "cbnode_up")
+
+	assert.Equal(t, 2.5, families["cbbucketstat_cpu_idle_seconds"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, "wawa-bucket", families["cbbucketstat_cpu_idle_seconds"].GetMetric()[0].GetLabel()[0].GetValue())
+	assert.Equal(t, 3.0, families["cbnode_failover_total"].GetMetric()[0].GetCounter().GetValue())
+}
+
+func TestNamingGathererExportsBothNames(t *testing.T) {
+	gatherer, err := util.NewNamingGatherer(namingRegistry(), objects.MetricNamesBoth)
+	assert.Nil(t, err)
+
+	families := gatherByName(t, gatherer)
+
+	assert.Equal(t, 2500.0, families["cbbucketstat_cpu_idle_ms"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, "CPU idle, deprecated and renamed to cbbucketstat_cpu_idle_seconds", families["cbbucketstat_cpu_idle_ms"].GetHelp())
+	assert.Equal(t, 2.5, families["cbbucketstat_cpu_idle_seconds"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, "CPU idle", families["cbbucketstat_cpu_idle_seconds"].GetHelp())
+	assert.Equal(t, 3.0, families["cbnode_failover"].GetMetric()[0].GetCounter().GetValue())
+	assert.Equal(t, 3.0, families["cbnode_failover_total"].GetMetric()[0].GetCounter().GetValue())
+	assert.Equal(t, "Up", families["cbnode_up"].GetHelp())
+}
+
+func TestNamingGathererKeepsLegacyNames(t *testing.T) {
+	registry := namingRegistry()
+
+	gatherer, err := util.NewNamingGatherer(registry, objects.MetricNamesLegacy)
+	assert.Nil(t, err)
+	assert.Equal(t, prometheus.Gatherer(registry), gatherer)
+
+	_, err = util.NewNamingGatherer(registry, "v3")
+	assert.ErrorIs(t, err, objects.ErrUnknownMetricNames)
+}
@@ -0,0 +1,247 @@
+package test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientRequestsAreCancelledWithContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+
+	defer server.Close()
+	defer close(release)
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	client := util.NewClient("http://"+u.Hostname(), port, "Administrator", "password", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.Buckets(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+type countingTransport struct {
+	requests       int
+	users          []string
+	authorizations []string
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests++
+
+	user, _, _ := req.BasicAuth()
+	t.users = append(t.users, user)
+	t.authorizations = append(t.authorizations, req.Header.Get("Authorization"))
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader("[]")),
+		Request:    req,
+	}, nil
+}
+
+func TestClientUsesInjectedTransport(t *testing.T) {
+	transport := &countingTransport{}
+	client := util.NewClientWithTransport("http://localhost", 8091, "Administrator", "password", transport)
+
+	_, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	_, err = client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, transport.requests)
+	assert.Equal(t, []string{"Administrator", "Administrator"}, transport.users)
+}
+
+func TestNewTransportPoolsConnectionsPerHost(t *testing.T) {
+	transport := util.NewTransport(nil, 4)
+
+	assert.Equal(t, 4, transport.MaxIdleConnsPerHost)
+}
+
+func TestClientSendsBearerTokenFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("first\n"), 0600))
+
+	transport := &countingTransport{}
+	auth := objects.AuthConfig{Mode: objects.AuthModeBearer, TokenFile: path}
+	client := util.NewClientWithAuth("http://localhost", 8091, auth, "Administrator", "password", transport)
+
+	_, err = client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("second"), 0600))
+
+	_, err = client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"Bearer first", "Bearer second"}, transport.authorizations)
+	assert.Equal(t, []string{"", ""}, transport.users)
+}
+
+func TestClientSendsNoCredentialsWithCertificateAuth(t *testing.T) {
+	transport := &countingTransport{}
+	auth := objects.AuthConfig{Mode: objects.AuthModeCertificate}
+	client := util.NewClientWithAuth("https://localhost", 18091, auth, "Administrator", "password", transport)
+
+	_, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{""}, transport.authorizations)
+}
+
+func TestClientSendsSPNEGOTokenForHost(t *testing.T) {
+	transport := &countingTransport{}
+	auth := objects.AuthConfig{Mode: objects.AuthModeSPNEGO, SPNEGOCommand: []string{"echo", "token"}}
+	client := util.NewClientWithAuth("http://localhost", 8091, auth, "Administrator", "password", transport)
+
+	_, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"Negotiate token HTTP@localhost"}, transport.authorizations)
+	assert.Equal(t, []string{""}, transport.users)
+}
+
+func TestClientFailsWithoutSPNEGOToken(t *testing.T) {
+	transport := &countingTransport{}
+	auth := objects.AuthConfig{Mode: objects.AuthModeSPNEGO, SPNEGOCommand: []string{"false"}}
+	client := util.NewClientWithAuth("http://localhost", 8091, auth, "Administrator", "password", transport)
+
+	_, err := client.Buckets(context.Background())
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, transport.requests)
+}
+
+type headerAuthenticator struct{}
+
+func (headerAuthenticator) Authenticate(req *http.Request) error {
+	req.Header.Set("Authorization", "Custom "+req.URL.Hostname())
+
+	return nil
+}
+
+func TestClientUsesPluggedAuthenticator(t *testing.T) {
+	transport := &countingTransport{}
+	client := util.NewClientWithAuthenticator("http://localhost", 8091, headerAuthenticator{}, transport)
+
+	_, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"Custom localhost"}, transport.authorizations)
+}
+
+func TestClientMiddlewareSeesAuthenticatedRequests(t *testing.T) {
+	transport := &countingTransport{}
+	client := util.NewClientWithTransport("http://localhost", 8091, "Administrator", "password", transport)
+
+	var order []string
+
+	header := func(name string) util.Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return util.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				user, _, _ := req.BasicAuth()
+				order = append(order, name+":"+user)
+				req.Header.Set("X-Proxy-"+name, "set")
+
+				return next.RoundTrip(req)
+			})
+		}
+	}
+
+	wrapped := client.WithMiddleware(header("First"), header("Second"))
+
+	_, err := wrapped.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"First:Administrator", "Second:Administrator"}, order)
+	assert.Equal(t, 1, transport.requests)
+
+	// the client the middleware was added to is left as it was.
+	_, err = client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Len(t, order, 2)
+	assert.Equal(t, 2, transport.requests)
+}
+
+func TestClientUsesAuthenticatorFunc(t *testing.T) {
+	transport := &countingTransport{}
+	authenticator := util.AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Signed")
+
+		return nil
+	})
+	client := util.NewClientWithAuthenticator("http://localhost", 8091, authenticator, transport)
+
+	_, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"Signed"}, transport.authorizations)
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	assert.Nil(t, objects.AuthConfig{}.Validate())
+	assert.Nil(t, objects.AuthConfig{Mode: objects.AuthModeCertificate}.Validate())
+	assert.Nil(t, objects.AuthConfig{Mode: objects.AuthModeBearer, Token: "token"}.Validate())
+	assert.ErrorIs(t, objects.AuthConfig{Mode: objects.AuthModeBearer}.Validate(), objects.ErrMissingToken)
+	assert.Nil(t, objects.AuthConfig{Mode: objects.AuthModeSPNEGO, SPNEGOCommand: []string{"kinit-token"}}.Validate())
+	assert.ErrorIs(t, objects.AuthConfig{Mode: objects.AuthModeSPNEGO}.Validate(), objects.ErrMissingCommand)
+	assert.ErrorIs(t, objects.AuthConfig{Mode: "ldap"}.Validate(), objects.ErrUnknownAuthMode)
+}
+
+func TestClientReadsNodeTimeFromTheDateOfItsResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/pools", r.URL.Path)
+		w.Header().Set("Date", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	}))
+
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	client := util.NewClient("http://localhost", port, "Administrator", "password", nil)
+
+	nodeTime, err := client.NodeTime(context.Background(), net.JoinHostPort(u.Hostname(), "8091"))
+	assert.Nil(t, err)
+	assert.InDelta(t, time.Hour.Seconds(), nodeTime.Skew().Seconds(), 1)
+}
@@ -167,7 +167,7 @@ func TestNodeCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager)
@@ -189,13 +189,13 @@ func TestNodeCollectReturnsDownIfClientReturnsErrorOnNode(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	Nodes := objects.Nodes{}
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, ErrDummy)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager)
@@ -226,13 +226,13 @@ func TestNodeCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
 
 	Nodes := objects.Nodes{}
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager)
@@ -256,13 +256,16 @@ func TestNodeCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), gomock.Any()).AnyTimes().Return(test.GenerateNodeTime(0), nil)
+	mockClient.EXPECT().ServerGroups(gomock.Any()).Times(1).Return(test.GenerateServerGroups("Group 1", []objects.Node{Node}), nil)
+	mockClient.EXPECT().Pools(gomock.Any()).Times(1).Return(test.GeneratePools(), nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager)
@@ -305,3 +308,149 @@ func TestNodeCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 		}
 	}
 }
+
+func TestNodeCollectLabelsNodesWithTheirServerGroup(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	nodes := test.GenerateNodes("dummy-cluster", []objects.Node{node})
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(nodes, nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), gomock.Any()).AnyTimes().Return(test.GenerateNodeTime(0), nil)
+	mockClient.EXPECT().ServerGroups(gomock.Any()).Times(1).Return(test.GenerateServerGroups("rack-a", []objects.Node{node}), nil)
+	mockClient.EXPECT().Pools(gomock.Any()).Times(1).Return(test.GeneratePools(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager))
+
+	assert.Equal(t, 1.0, values["cbnode_server_group_info/localhost/rack-a"])
+	assert.Equal(t, 1.0, values["cbnode_cluster_info/a0c6c1d1e0c1a4e4a37fba9a3b1a8d7e/7.1/enterprise/7.2.0-5325"])
+}
+
+func TestNodeCollectLeavesOutSystemStatsMissingFromOlderServers(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	node.SystemStats[objects.CPUStolenRate] = 12.5
+
+	delete(node.SystemStats, objects.Allocstall)
+	delete(node.SystemStats, objects.MemLimit)
+
+	nodes := test.GenerateNodes("dummy-cluster", []objects.Node{node})
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(nodes, nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), gomock.Any()).AnyTimes().Return(test.GenerateNodeTime(0), nil)
+	mockClient.EXPECT().ServerGroups(gomock.Any()).Times(1).Return(test.GenerateServerGroups("rack-a", []objects.Node{node}), nil)
+	mockClient.EXPECT().Pools(gomock.Any()).Times(1).Return(test.GeneratePools(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager))
+
+	assert.Equal(t, 12.5, values["cbnode_systemstats_cpu_stolen_rate/localhost"])
+	assert.Contains(t, values, "cbnode_systemstats_mem_free/localhost")
+	assert.NotContains(t, values, "cbnode_systemstats_allocstall/localhost")
+	assert.NotContains(t, values, "cbnode_systemstats_mem_limit/localhost")
+}
+
+func TestNodeCollectStaysUpWithoutServerGroups(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	nodes := test.GenerateNodes("dummy-cluster", []objects.Node{node})
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(nodes, nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), gomock.Any()).AnyTimes().Return(test.GenerateNodeTime(0), nil)
+	mockClient.EXPECT().ServerGroups(gomock.Any()).Times(1).Return(objects.ServerGroups{}, ErrDummy)
+	mockClient.EXPECT().Pools(gomock.Any()).Times(1).Return(test.GeneratePools(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager))
+
+	assert.Equal(t, 1.0, values["cbnode_up"])
+	assert.NotContains(t, values, "cbnode_server_group_info/localhost/")
+	assert.Contains(t, values, "cbnode_healthy/localhost")
+}
+
+func TestNodeCollectReportsVersionsOfAClusterPartWayThroughAnUpgrade(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	upgraded := test.GenerateNode()
+	upgraded.Version = "7.2.0-5325-enterprise"
+	upgraded.ClusterCompatibility = 0x70002
+
+	old := test.GenerateNode()
+	old.Hostname = "old-node"
+
+	nodes := test.GenerateNodes("dummy-cluster", []objects.Node{upgraded, old})
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(upgraded, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(nodes, nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), gomock.Any()).AnyTimes().Return(test.GenerateNodeTime(0), nil)
+	mockClient.EXPECT().ServerGroups(gomock.Any()).Times(1).Return(objects.ServerGroups{}, ErrDummy)
+	mockClient.EXPECT().Pools(gomock.Any()).Times(1).Return(test.GeneratePools(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager))
+
+	assert.Equal(t, 1.0, values["cbnode_version_info/localhost/7.2.0-5325"])
+	assert.Equal(t, 1.0, values["cbnode_version_info/old-node/7.1.1-3175"])
+	assert.Equal(t, 1.0, values["cbnode_cluster_info/a0c6c1d1e0c1a4e4a37fba9a3b1a8d7e/7.1/enterprise/7.2.0-5325"])
+}
+
+func TestNodeCollectReportsTheClockSkewOfEachNode(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	ahead := test.GenerateNode()
+
+	behind := test.GenerateNode()
+	behind.Hostname = "behind-node:8091"
+
+	down := test.GenerateNode()
+	down.Hostname = "down-node:8091"
+
+	nodes := test.GenerateNodes("dummy-cluster", []objects.Node{ahead, behind, down})
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(ahead, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(nodes, nil)
+	mockClient.EXPECT().ServerGroups(gomock.Any()).Times(1).Return(objects.ServerGroups{}, ErrDummy)
+	mockClient.EXPECT().Pools(gomock.Any()).Times(1).Return(test.GeneratePools(), nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), ahead.Hostname).Times(1).Return(test.GenerateNodeTime(3*time.Second), nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), behind.Hostname).Times(1).Return(test.GenerateNodeTime(-90*time.Second), nil)
+	mockClient.EXPECT().NodeTime(gomock.Any(), down.Hostname).Times(1).Return(objects.NodeTime{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewNodesCollector(mockClient, defaultConfig.Collectors.Node, labelManager))
+
+	assert.Equal(t, 1.0, values["cbnode_up"])
+	assert.Equal(t, 3.0, values["cbnode_clock_skew_seconds/"+ahead.Hostname])
+	assert.Equal(t, -90.0, values["cbnode_clock_skew_seconds/behind-node:8091"])
+	assert.NotContains(t, values, "cbnode_clock_skew_seconds/down-node:8091")
+}
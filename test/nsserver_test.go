@@ -0,0 +1,144 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	nsserver "github.com/couchbase/couchbase-exporter/pkg/test"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeClusterCollector(server *nsserver.Server) collectors.PerNodeBucketStatsCollector {
+	client := server.CouchbaseClient()
+	labelManager := util.NewLabelManager(client, 600*time.Second)
+
+	collector := collectors.NewPerNodeBucketStatsCollector(client, config.GetDefaultConfig().Collectors.PerNodeBucketStats, labelManager)
+	collector.SetClusterMode(true)
+
+	return collector
+}
+
+func TestFakeServerFollowsTopologyChanges(t *testing.T) {
+	server := nsserver.NewServer()
+	defer server.Close()
+
+	client := server.CouchbaseClient()
+
+	server.AddBucket(objects.BucketInfo{Name: "first"})
+	server.AddBucket(objects.BucketInfo{Name: "second"})
+	server.RemoveBucket("first")
+
+	buckets, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []objects.BucketInfo{{Name: "second"}}, buckets)
+
+	server.AddNode(objects.Node{Hostname: "node2:8091"})
+
+	nodes, err := client.Nodes(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, nodes.Nodes, 2)
+	assert.False(t, nodes.Balanced)
+
+	node, err := client.GetCurrentNode(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, nsserver.DefaultNode, node.Hostname)
+}
+
+func TestPerNodeBucketStatsCollectsEveryNodeOfFakeServer(t *testing.T) {
+	server := nsserver.NewServer()
+	defer server.Close()
+
+	server.AddNode(objects.Node{Hostname: "node2:8091"})
+	server.AddBucket(objects.BucketInfo{Name: "fake-bucket"})
+	server.SetNodeSamples("fake-bucket", nsserver.DefaultNode, map[string]interface{}{"avg_active_timestamp_drift": []float64{1}})
+	server.SetNodeSamples("fake-bucket", "node2:8091", map[string]interface{}{"avg_active_timestamp_drift": []float64{2}})
+
+	collector := fakeClusterCollector(server)
+	collector.CollectMetrics(context.Background())
+
+	values := collectValues(t, &collector)
+
+	assert.Equal(t, 1.0, values["cbpernodebucket_avg_active_timestamp_drift/fake-bucket/"+nsserver.DefaultNode])
+	assert.Equal(t, 2.0, values["cbpernodebucket_avg_active_timestamp_drift/fake-bucket/node2:8091"])
+}
+
+func TestPerNodeBucketStatsReturnsDownWhenFakeServerFails(t *testing.T) {
+	server := nsserver.NewServer()
+	defer server.Close()
+
+	server.AddBucket(objects.BucketInfo{Name: "fake-bucket"})
+	server.Fail("/pools/default/buckets", http.StatusInternalServerError)
+
+	mockSetter := mocks.NewMockSetter()
+
+	collector := fakeClusterCollector(server)
+	collector.Setter = &mockSetter
+	collector.CollectMetrics(context.Background())
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, nsserver.DefaultClusterName))
+
+	server.Fail("/pools/default/buckets", 0)
+	collector.CollectMetrics(context.Background())
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, nsserver.DefaultClusterName))
+}
+
+// rebalanceWaitValue returns the value of the named rebalance wait metric of
+// the fake cluster.
+func rebalanceWaitValue(t *testing.T, name string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)[name]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == objects.ClusterLabel && label.GetValue() == nsserver.DefaultClusterName {
+				if metric.Counter != nil {
+					return metric.GetCounter().GetValue()
+				}
+
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestPerNodeBucketStatsWaitsForFakeServerRebalance(t *testing.T) {
+	server := nsserver.NewServer()
+	defer server.Close()
+
+	server.AddBucket(objects.BucketInfo{Name: "fake-bucket"})
+	server.StartRebalance(50)
+
+	retries := rebalanceWaitValue(t, "cbexporter_rebalance_wait_retries_total")
+
+	collector := fakeClusterCollector(server)
+	collector.SetWaitForRebalance(true)
+	collector.CollectMetrics(context.Background())
+	collector.CollectMetrics(context.Background())
+
+	assert.Equal(t, 0, server.Requests("/pools/default/buckets"))
+	assert.Equal(t, retries+2, rebalanceWaitValue(t, "cbexporter_rebalance_wait_retries_total"))
+	assert.True(t, rebalanceWaitValue(t, "cbexporter_rebalance_wait_seconds") > 0)
+
+	tasks, err := server.CouchbaseClient().Tasks(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "running", tasks[0].Status)
+
+	server.FinishRebalance()
+	collector.CollectMetrics(context.Background())
+
+	assert.Equal(t, 1, server.Requests("/pools/default/buckets"))
+	assert.Equal(t, 0.0, rebalanceWaitValue(t, "cbexporter_rebalance_wait_seconds"))
+}
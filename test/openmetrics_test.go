@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+const openMetricsAccept = "application/openmetrics-text; version=0.0.1,text/plain;version=0.0.4;q=0.5"
+
+func scrapeMetrics(t *testing.T, gatherer prometheus.Gatherer, accept string) (string, string) {
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	rec := httptest.NewRecorder()
+	util.NewMetricsHandler(gatherer).ServeHTTP(rec, req)
+
+	body, err := ioutil.ReadAll(rec.Body)
+	assert.Nil(t, err)
+
+	return rec.Header().Get("Content-Type"), string(body)
+}
+
+func dummyCounterRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "cb_dummy_total", Help: "dummy counter"}, []string{"bucket"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "cb_gauge", Help: "dummy gauge"})
+	registry.MustRegister(counter, gauge)
+
+	counter.WithLabelValues("a").Add(1)
+	counter.WithLabelValues("b").Add(2)
+	gauge.Set(42)
+
+	return registry
+}
+
+func TestMetricsHandlerServesTextByDefault(t *testing.T) {
+	contentType, body := scrapeMetrics(t, dummyCounterRegistry(), "")
+
+	assert.Equal(t, string(expfmt.FmtText), contentType)
+	assert.Contains(t, body, "cb_dummy_total{bucket=\"a\"} 1\n")
+	assert.NotContains(t, body, "_created")
+	assert.NotContains(t, body, "# EOF")
+}
+
+// createdCounterVec is a counter the exporter reports about itself.  It is
+// registered with the default registry, so it can only be created once.
+var createdCounterVec = objects.NewExporterCounterVec(prometheus.CounterOpts{
+	Name: "cbexporter_test_created_total",
+	Help: "dummy exporter counter",
+}, []string{"bucket"})
+
+func TestMetricsHandlerServesOpenMetricsWithCreatedTimestamps(t *testing.T) {
+	before := float64(time.Now().UnixNano()) / float64(time.Second)
+
+	createdCounterVec.WithLabelValues("a").Add(1)
+
+	after := float64(time.Now().UnixNano()) / float64(time.Second)
+
+	contentType, body := scrapeMetrics(t, prometheus.DefaultGatherer, openMetricsAccept)
+
+	assert.Equal(t, string(expfmt.FmtOpenMetrics), contentType)
+	assert.Regexp(t, regexp.MustCompile(`# HELP cbexporter_test_created dummy exporter counter\n`+
+		`# TYPE cbexporter_test_created counter\n`+
+		`cbexporter_test_created_total\{bucket="a"\} \S+\ncbexporter_test_created_created\{bucket="a"\} \S+\n`), body)
+
+	created := regexp.MustCompile(`cbexporter_test_created_created\{bucket="a"\} (\S+)`).FindStringSubmatch(body)
+	if assert.Len(t, created, 2) {
+		seconds, err := strconv.ParseFloat(created[1], 64)
+		assert.Nil(t, err)
+		assert.LessOrEqual(t, before, seconds)
+		assert.GreaterOrEqual(t, after, seconds)
+	}
+}
+
+func TestMetricsHandlerOmitsCreatedTimestampsOfServerCounters(t *testing.T) {
+	contentType, body := scrapeMetrics(t, dummyCounterRegistry(), openMetricsAccept)
+
+	assert.Equal(t, string(expfmt.FmtOpenMetrics), contentType)
+	assert.Equal(t, "# HELP cb_dummy dummy counter\n# TYPE cb_dummy counter\n"+
+		"cb_dummy_total{bucket=\"a\"} 1.0\ncb_dummy_total{bucket=\"b\"} 2.0\n"+
+		"# HELP cb_gauge dummy gauge\n# TYPE cb_gauge gauge\ncb_gauge 42.0\n# EOF\n", body)
+}
+
+func TestMetricsHandlerKeepsCreatedTimestampsAcrossScrapes(t *testing.T) {
+	createdCounterVec.WithLabelValues("b").Add(1)
+
+	handler := util.NewMetricsHandler(prometheus.DefaultGatherer)
+
+	scrape := func() string {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.Header.Set("Accept", openMetricsAccept)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return regexp.MustCompile(`cbexporter_test_created_created\{bucket="b"\} (\S+)`).FindStringSubmatch(rec.Body.String())[1]
+	}
+
+	first := scrape()
+
+	time.Sleep(10 * time.Millisecond)
+	createdCounterVec.WithLabelValues("b").Add(1)
+
+	assert.Equal(t, first, scrape())
+}
+
+func TestPerNodeBucketStatsAttachesExemplarsToUnparseableSamples(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("exemplar-bucket")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(test.GenerateServers(), nil)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, undefinedDriftStats()).Return(nil).Times(1)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.SetExemplars(true)
+	testCollector.CollectMetrics(context.Background())
+
+	_, body := scrapeMetrics(t, prometheus.DefaultGatherer, openMetricsAccept)
+
+	// the labels of an exemplar are written in no particular order.
+	exemplar := regexp.MustCompile(`cbexporter_unparseable_samples_total\{stat="avg_active_timestamp_drift"\} \S+ # \{([^}]*)\} 1\.0`).FindStringSubmatch(body)
+	if assert.Len(t, exemplar, 2) {
+		assert.ElementsMatch(t, []string{`bucket="exemplar-bucket"`, `node="` + Node.Hostname + `"`}, strings.Split(exemplar[1], ","))
+	}
+}
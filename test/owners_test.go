@@ -0,0 +1,85 @@
+package test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func ownerRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	items := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_itemcount"}, []string{"bucket"})
+	items.WithLabelValues("orders").Set(1)
+	items.WithLabelValues("unowned").Set(2)
+
+	nodes := prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbnode_healthy"})
+	nodes.Set(1)
+
+	registry.MustRegister(items, nodes)
+
+	return registry
+}
+
+// ownerLabels returns the labels of each series of a metric keyed by the
+// series' bucket.
+func ownerLabels(t *testing.T, gatherer prometheus.Gatherer, name string) map[string]map[string]string {
+	series := map[string]map[string]string{}
+
+	for _, metric := range gatherByName(t, gatherer)[name].GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		series[labels["bucket"]] = labels
+	}
+
+	return series
+}
+
+func writeOwners(t *testing.T, path, contents string, modTime time.Time) {
+	assert.Nil(t, ioutil.WriteFile(path, []byte(contents), 0o600))
+	assert.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestOwnerLabelsAreAddedToBucketMetrics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.json")
+	writeOwners(t, path, `{"orders": {"team": "payments", "bucket": "ignored"}}`, time.Now())
+
+	_, err := util.NewOwnerLabelGatherer(ownerRegistry(), path)
+	assert.True(t, errors.Is(err, util.ErrInvalidOwnerLabels))
+
+	writeOwners(t, path, `{"orders": {"team": "payments"}}`, time.Now())
+
+	gatherer, err := util.NewOwnerLabelGatherer(ownerRegistry(), path)
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string]map[string]string{
+		"orders":  {"bucket": "orders", "team": "payments"},
+		"unowned": {"bucket": "unowned"},
+	}, ownerLabels(t, gatherer, "cbbucketinfo_basic_itemcount"))
+	assert.Equal(t, map[string]map[string]string{"": {}}, ownerLabels(t, gatherer, "cbnode_healthy"))
+}
+
+func TestOwnerLabelsAreReloadedWhenTheFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "owners.json")
+	start := time.Now().Add(-time.Hour)
+	writeOwners(t, path, `{"orders": {"team": "payments"}}`, start)
+
+	gatherer, err := util.NewOwnerLabelGatherer(ownerRegistry(), path)
+	assert.Nil(t, err)
+
+	writeOwners(t, path, `{"orders": {"team": "checkout"}}`, start.Add(time.Minute))
+	assert.Equal(t, "checkout", ownerLabels(t, gatherer, "cbbucketinfo_basic_itemcount")["orders"]["team"])
+
+	writeOwners(t, path, `{"orders": `, start.Add(2*time.Minute))
+	assert.Equal(t, "checkout", ownerLabels(t, gatherer, "cbbucketinfo_basic_itemcount")["orders"]["team"])
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	testutils "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func getCollectorEnabled(t *testing.T, cluster, collector string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	assert.Nil(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "cbexporter_collector_enabled" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["cluster"] == cluster && labels["collector"] == collector {
+				return metric.GetGauge().GetValue()
+			}
+		}
+	}
+
+	return -1
+}
+
+func TestProbePermissionsDisablesForbiddenCollectors(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	forbidden := fmt.Errorf("failed to Get: %w", util.ErrForbidden)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().Nodes(gomock.Any()).Return(objects.Nodes{}, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Return([]objects.BucketInfo{
+		testutils.GenerateBucket("wawa-bucket"),
+		testutils.GenerateBucket("other-bucket"),
+	}, nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Return(objects.BucketStats{}, forbidden)
+	mockClient.EXPECT().DesignDocs(gomock.Any(), "wawa-bucket").Return(objects.DesignDocs{}, nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Return([]objects.Task{}, nil)
+	mockClient.EXPECT().Query(gomock.Any()).Return(objects.Query{}, forbidden)
+	mockClient.EXPECT().Index(gomock.Any()).Return(objects.Index{}, nil)
+	mockClient.EXPECT().Fts(gomock.Any()).Return(objects.FTS{}, nil)
+	mockClient.EXPECT().Cbas(gomock.Any()).Return(objects.Analytics{}, fmt.Errorf("service not running"))
+	mockClient.EXPECT().Eventing(gomock.Any()).Return(objects.Eventing{}, nil)
+	mockClient.EXPECT().AuditSettings(gomock.Any()).Return(objects.AuditSettings{}, forbidden)
+	mockClient.EXPECT().SecuritySettings(gomock.Any()).Return(objects.SecuritySettings{}, forbidden)
+
+	c := defaultConfig.Collectors
+	permissions := collectors.ProbePermissions(mockClient, "exporter", "probed", &c, time.Minute)
+
+	assert.True(t, permissions.Enabled(c.Node))
+	assert.True(t, permissions.Enabled(c.BucketInfo))
+	assert.True(t, permissions.Enabled(c.Analytics))
+	assert.False(t, permissions.Enabled(c.Query))
+	assert.False(t, permissions.Enabled(c.BucketStats))
+	assert.False(t, permissions.Enabled(c.PerNodeBucketStats))
+	assert.False(t, permissions.Enabled(c.Audit))
+	assert.False(t, permissions.Enabled(c.Security))
+	assert.True(t, permissions.Enabled(c.Views))
+	assert.True(t, permissions.Enabled(c.Rollup))
+
+	assert.Equal(t, 1.0, getCollectorEnabled(t, "probed", c.Node.Name))
+	assert.Equal(t, 0.0, getCollectorEnabled(t, "probed", c.Query.Name))
+	assert.Equal(t, 0.0, getCollectorEnabled(t, "probed", c.BucketStats.Name))
+	assert.Equal(t, -1.0, getCollectorEnabled(t, "", c.Query.Name))
+}
+
+func TestProbePermissionsGivesUpAtTimeout(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	// every request blocks until the probes give up.
+	blocked := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().Nodes(gomock.Any()).DoAndReturn(func(ctx context.Context) (objects.Nodes, error) {
+		return objects.Nodes{}, blocked(ctx)
+	})
+	mockClient.EXPECT().Buckets(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]objects.BucketInfo, error) {
+		return nil, blocked(ctx)
+	})
+	mockClient.EXPECT().Tasks(gomock.Any()).Return([]objects.Task{}, nil)
+	mockClient.EXPECT().Query(gomock.Any()).Return(objects.Query{}, nil)
+	mockClient.EXPECT().Index(gomock.Any()).Return(objects.Index{}, nil)
+	mockClient.EXPECT().Fts(gomock.Any()).Return(objects.FTS{}, nil)
+	mockClient.EXPECT().Cbas(gomock.Any()).Return(objects.Analytics{}, nil)
+	mockClient.EXPECT().Eventing(gomock.Any()).Return(objects.Eventing{}, nil)
+	mockClient.EXPECT().AuditSettings(gomock.Any()).Return(objects.AuditSettings{}, nil)
+	mockClient.EXPECT().SecuritySettings(gomock.Any()).Return(objects.SecuritySettings{}, nil)
+
+	c := defaultConfig.Collectors
+	start := time.Now()
+	permissions := collectors.ProbePermissions(mockClient, "exporter", "", &c, 50*time.Millisecond)
+
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.True(t, permissions.Enabled(c.Node))
+	assert.True(t, permissions.Enabled(c.BucketStats))
+	assert.True(t, permissions.Enabled(c.Views))
+}
+
+func TestCollectorPermissionsAllowUnprobedCollectors(t *testing.T) {
+	permissions := collectors.CollectorPermissions{}
+
+	assert.True(t, permissions.Enabled(nil))
+	assert.True(t, permissions.Enabled(objects.GetQueryCollectorDefaultConfig()))
+}
@@ -1,6 +1,9 @@
 package test
 
 import (
+	"context"
+	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/couchbase/couchbase-exporter/test/mocks"
 	test "github.com/couchbase/couchbase-exporter/test/utils"
 	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,15 +33,15 @@ func TestPerNodeBucketStatsReturnsDownIfCantGetCurrentNode(t *testing.T) {
 	mockSetter := mocks.NewMockSetter()
 	Node := test.GenerateNode()
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, ErrDummy)
 
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "cluster"))
 }
@@ -51,13 +55,13 @@ func TestPerNodeBucketStatsReturnsDownIfCantGetClusterName(t *testing.T) {
 	mockClient := mocks.NewMockCbClient(mockCtrl)
 	mockSetter := mocks.NewMockSetter()
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "cluster"))
 }
@@ -73,16 +77,16 @@ func TestPerNodeBucketStatsReturnsDownIfCantGetClusterBalanceStatus(t *testing.T
 	Node := test.GenerateNode()
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "dummy-cluster"))
 }
@@ -98,18 +102,18 @@ func TestPerNodeBucketStatsReturnsDownIfCantGetBuckets(t *testing.T) {
 	Node := test.GenerateNode()
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, ErrDummy)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "dummy-cluster"))
 }
@@ -125,23 +129,23 @@ func TestPerNodeBucketStatsReturnsDownIfCantGetBucketStats(t *testing.T) {
 	Node := test.GenerateNode()
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	buckets := []objects.BucketInfo{test.GenerateBucket("wawa-bucket")}
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).Times(1).Return(ErrDummy)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(ErrDummy)
 
 	servers := test.GenerateServers()
-	mockClient.EXPECT().Servers(gomock.Any()).Times(1).Return(servers, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(servers, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "dummy-cluster"))
 }
@@ -167,33 +171,33 @@ func TestPerNodeBucketStatsReturnsUp(t *testing.T) {
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 	stats := objects.PerNodeBucketStats{
 		Op: struct {
-			Samples      map[string]interface{} "json:\"samples\""
-			SamplesCount int                    "json:\"samplesCount\""
-			IsPersistent bool                   "json:\"isPersistent\""
-			LastTStamp   int64                  "json:\"lastTStamp\""
-			Interval     int                    "json:\"interval\""
+			Samples      objects.Samples "json:\"samples\""
+			SamplesCount int             "json:\"samplesCount\""
+			IsPersistent bool            "json:\"isPersistent\""
+			LastTStamp   int64           "json:\"lastTStamp\""
+			Interval     int             "json:\"interval\""
 		}{
 			Samples: test.GenerateBucketStatSamples(),
 		},
 	}
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	buckets := []objects.BucketInfo{test.GenerateBucket("wawa-bucket")}
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).SetArg(1, stats).Return(nil).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, decodePerNodeBucketStats(t, stats)).Return(nil).Times(1)
 
 	servers := test.GenerateServers()
-	mockClient.EXPECT().Servers(gomock.Any()).Times(1).Return(servers, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(servers, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, "dummy-cluster"))
 	assert.True(t, mockSetter.TestMetricGreaterThanOrEqual(metricPrefix+objects.DefaultScrapeDurationMetric, 0, "dummy-cluster"))
@@ -213,40 +217,40 @@ func TestPerNodeBucketStatsReturnsCorrectValues(t *testing.T) {
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 	stats := objects.PerNodeBucketStats{
 		Op: struct {
-			Samples      map[string]interface{} "json:\"samples\""
-			SamplesCount int                    "json:\"samplesCount\""
-			IsPersistent bool                   "json:\"isPersistent\""
-			LastTStamp   int64                  "json:\"lastTStamp\""
-			Interval     int                    "json:\"interval\""
+			Samples      objects.Samples "json:\"samples\""
+			SamplesCount int             "json:\"samplesCount\""
+			IsPersistent bool            "json:\"isPersistent\""
+			LastTStamp   int64           "json:\"lastTStamp\""
+			Interval     int             "json:\"interval\""
 		}{
 			Samples: test.GenerateBucketStatSamples(),
 		},
 	}
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
 
 	buckets := []objects.BucketInfo{test.GenerateBucket("wawa-bucket")}
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).SetArg(1, stats).Return(nil).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, decodePerNodeBucketStats(t, stats)).Return(nil).Times(1)
 
 	servers := test.GenerateServers()
-	mockClient.EXPECT().Servers(gomock.Any()).Times(1).Return(servers, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(servers, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, "dummy-cluster"))
 	assert.True(t, mockSetter.TestMetricGreaterThanOrEqual(metricPrefix+objects.DefaultScrapeDurationMetric, 0, "dummy-cluster"))
 
 	for _, value := range defaultConfig.Collectors.PerNodeBucketStats.Metrics {
-		sample, ok := stats.Op.Samples[value.Name].([]float64)
-		if sample == nil || !ok {
+		sample := stats.Op.Samples[value.Name]
+		if sample == nil {
 			log.Info("%s does not have a matching sample.", value.Name)
 			continue
 		}
@@ -281,44 +285,44 @@ func TestPerNodeBucketStatsReturnsCorrectValuesWithAutoCompactionBoolean(t *test
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 	stats := objects.PerNodeBucketStats{
 		Op: struct {
-			Samples      map[string]interface{} "json:\"samples\""
-			SamplesCount int                    "json:\"samplesCount\""
-			IsPersistent bool                   "json:\"isPersistent\""
-			LastTStamp   int64                  "json:\"lastTStamp\""
-			Interval     int                    "json:\"interval\""
+			Samples      objects.Samples "json:\"samples\""
+			SamplesCount int             "json:\"samplesCount\""
+			IsPersistent bool            "json:\"isPersistent\""
+			LastTStamp   int64           "json:\"lastTStamp\""
+			Interval     int             "json:\"interval\""
 		}{
 			Samples: test.GenerateBucketStatSamples(),
 		},
 	}
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
 	singleBucket.AutoCompactionSettings = true
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).SetArg(1, stats).Return(nil).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, decodePerNodeBucketStats(t, stats)).Return(nil).Times(1)
 
 	servers := test.GenerateServers()
-	mockClient.EXPECT().Servers(gomock.Any()).Times(1).Return(servers, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(servers, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, lblManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, "dummy-cluster"))
 	assert.True(t, mockSetter.TestMetricGreaterThanOrEqual(metricPrefix+objects.DefaultScrapeDurationMetric, 0, "dummy-cluster"))
 
 	for _, value := range defaultConfig.Collectors.PerNodeBucketStats.Metrics {
-		sample, ok := stats.Op.Samples[value.Name].([]float64)
-		if sample == nil || !ok {
+		sample := stats.Op.Samples[value.Name]
+		if sample == nil {
 			log.Info("%s does not have a matching sample.", value.Name)
 			continue
 		}
@@ -353,19 +357,19 @@ func TestPerNodeBucketStatsReturnsCorrectValuesWithAutoCompactionObject(t *testi
 	Nodes := test.GenerateNodes("dummy-cluster", []objects.Node{Node})
 	stats := objects.PerNodeBucketStats{
 		Op: struct {
-			Samples      map[string]interface{} "json:\"samples\""
-			SamplesCount int                    "json:\"samplesCount\""
-			IsPersistent bool                   "json:\"isPersistent\""
-			LastTStamp   int64                  "json:\"lastTStamp\""
-			Interval     int                    "json:\"interval\""
+			Samples      objects.Samples "json:\"samples\""
+			SamplesCount int             "json:\"samplesCount\""
+			IsPersistent bool            "json:\"isPersistent\""
+			LastTStamp   int64           "json:\"lastTStamp\""
+			Interval     int             "json:\"interval\""
 		}{
 			Samples: test.GenerateBucketStatSamples(),
 		},
 	}
 
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
-	mockClient.EXPECT().Nodes().Times(1).Return(Nodes, nil)
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(Nodes, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
@@ -381,26 +385,26 @@ func TestPerNodeBucketStatsReturnsCorrectValuesWithAutoCompactionObject(t *testi
 		},
 	}
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).SetArg(1, stats).Return(nil).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, decodePerNodeBucketStats(t, stats)).Return(nil).Times(1)
 
 	servers := test.GenerateServers()
-	mockClient.EXPECT().Servers(gomock.Any()).Times(1).Return(servers, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(servers, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, lblManager)
 	testCollector.Setter = &mockSetter
 
-	testCollector.CollectMetrics()
+	testCollector.CollectMetrics(context.Background())
 
 	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, "dummy-cluster"))
 	assert.True(t, mockSetter.TestMetricGreaterThanOrEqual(metricPrefix+objects.DefaultScrapeDurationMetric, 0, "dummy-cluster"))
 
 	for _, value := range defaultConfig.Collectors.PerNodeBucketStats.Metrics {
-		sample, ok := stats.Op.Samples[value.Name].([]float64)
-		if sample == nil || !ok {
+		sample := stats.Op.Samples[value.Name]
+		if sample == nil {
 			log.Info("%s does not have a matching sample.", value.Name)
 			continue
 		}
@@ -420,3 +424,400 @@ func TestPerNodeBucketStatsReturnsCorrectValuesWithAutoCompactionObject(t *testi
 		)
 	}
 }
+
+func clusterModeServers() objects.Servers {
+	return objects.Servers{
+		Servers: []objects.Server{
+			{Hostname: "node1:8091", Stats: map[string]string{"uri": "/pools/default/buckets/wawa-bucket/nodes/node1%3A8091/stats"}},
+			{Hostname: "node2:8091", Stats: map[string]string{"uri": "/pools/default/buckets/wawa-bucket/nodes/node2%3A8091/stats"}},
+		},
+	}
+}
+
+func driftStats(drift float64) objects.PerNodeBucketStats {
+	var stats objects.PerNodeBucketStats
+	stats.Op.Samples = objects.Samples{"avg_active_timestamp_drift": {0, drift}}
+
+	return stats
+}
+
+func TestPerNodeBucketStatsClusterModeCollectsEveryNode(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), "wawa-bucket").Times(1).Return(clusterModeServers(), nil)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/wawa-bucket/nodes/node1%3A8091/stats", gomock.Any()).SetArg(2, driftStats(1)).Return(nil).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/wawa-bucket/nodes/node2%3A8091/stats", gomock.Any()).SetArg(2, driftStats(2)).Return(nil).Times(1)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.SetClusterMode(true)
+	testCollector.CollectMetrics(context.Background())
+
+	values := collectValues(t, &testCollector)
+
+	assert.Equal(t, 1.0, values["cbpernodebucket_avg_active_timestamp_drift/wawa-bucket/node1:8091"])
+	assert.Equal(t, 2.0, values["cbpernodebucket_avg_active_timestamp_drift/wawa-bucket/node2:8091"])
+}
+
+func TestPerNodeBucketStatsClusterModeReturnsDownButKeepsOtherNodes(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockSetter := mocks.NewMockSetter()
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), "wawa-bucket").Times(1).Return(clusterModeServers(), nil)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/wawa-bucket/nodes/node1%3A8091/stats", gomock.Any()).Return(ErrDummy).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/wawa-bucket/nodes/node2%3A8091/stats", gomock.Any()).SetArg(2, driftStats(2)).Return(nil).Times(1)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.Setter = &mockSetter
+	testCollector.SetClusterMode(true)
+	testCollector.CollectMetrics(context.Background())
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "dummy-cluster"))
+	assert.True(t, mockSetter.TestMetric("cbpernodebucket_avg_active_timestamp_drift", 2, "wawa-bucket", "node2:8091", "dummy-cluster"))
+}
+
+func TestPerNodeBucketStatsCollectsTheNamedNode(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	Node := test.GenerateNode()
+	remote := test.GenerateNode()
+	remote.Hostname = "node2:8091"
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node, remote}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), "wawa-bucket").Times(1).Return(clusterModeServers(), nil)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/wawa-bucket/nodes/node2%3A8091/stats", gomock.Any()).SetArg(2, driftStats(2)).Return(nil).Times(1)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.SetNodeName("node2")
+	testCollector.CollectMetrics(context.Background())
+
+	values := collectValues(t, &testCollector)
+
+	assert.Equal(t, 2.0, values["cbpernodebucket_avg_active_timestamp_drift/wawa-bucket/node2:8091"])
+	assert.NotContains(t, values, "cbpernodebucket_avg_active_timestamp_drift/wawa-bucket/"+Node.Hostname)
+}
+
+func TestPerNodeBucketStatsReturnsDownIfTheNamedNodeIsMissing(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockSetter := mocks.NewMockSetter()
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.Setter = &mockSetter
+	testCollector.SetNodeName("node2")
+	testCollector.CollectMetrics(context.Background())
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "dummy-cluster"))
+}
+
+func nodeResolutionFailures(t *testing.T, bucket string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)["cbexporter_node_resolution_failures_total"]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == objects.BucketLabel && label.GetValue() == bucket {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func TestPerNodeBucketStatsBacksOffWhenTheNodeIsNotAServerOfTheBucket(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockSetter := mocks.NewMockSetter()
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(6).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(6).Return([]objects.BucketInfo{test.GenerateBucket("unserved-bucket")}, nil)
+	// the node is looked for in the first, third and sixth collections.
+	mockClient.EXPECT().Servers(gomock.Any(), "unserved-bucket").Times(3).Return(clusterModeServers(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.Setter = &mockSetter
+
+	before := nodeResolutionFailures(t, "unserved-bucket")
+
+	for i := 0; i < 6; i++ {
+		testCollector.CollectMetrics(context.Background())
+	}
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 0, "dummy-cluster"))
+	assert.Equal(t, before+3, nodeResolutionFailures(t, "unserved-bucket"))
+}
+
+func unbalancedNodes(node objects.Node) objects.Nodes {
+	return objects.Nodes{
+		Name:            "dummy-cluster",
+		Nodes:           []objects.Node{node},
+		Balanced:        false,
+		RebalanceStatus: "running",
+	}
+}
+
+func TestPerNodeBucketStatsCollectsDuringRebalance(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockSetter := mocks.NewMockSetter()
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(unbalancedNodes(Node), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(1).Return(test.GenerateServers(), nil)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, driftStats(1)).Return(nil).Times(1)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.Setter = &mockSetter
+	testCollector.CollectMetrics(context.Background())
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+"cluster_balanced", 0, "dummy-cluster"))
+	assert.True(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, "dummy-cluster"))
+}
+
+func TestPerNodeBucketStatsWaitsForRebalanceWhenAsked(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockSetter := mocks.NewMockSetter()
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(unbalancedNodes(Node), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.Setter = &mockSetter
+	testCollector.SetWaitForRebalance(true)
+	testCollector.CollectMetrics(context.Background())
+
+	assert.True(t, mockSetter.TestMetric(metricPrefix+"cluster_balanced", 0, "dummy-cluster"))
+	assert.False(t, mockSetter.TestMetric(metricPrefix+objects.DefaultUptimeMetric, 1, "dummy-cluster"))
+}
+
+func undefinedDriftStats() objects.PerNodeBucketStats {
+	var stats objects.PerNodeBucketStats
+	_ = json.Unmarshal([]byte(`{"op": {"samples": {"avg_active_timestamp_drift": [1, "undefined"]}}}`), &stats)
+
+	return stats
+}
+
+// decodePerNodeBucketStats returns stats as the client would decode them,
+// leaving stats itself as it is when the collector releases the samples.
+func decodePerNodeBucketStats(t *testing.T, stats objects.PerNodeBucketStats) objects.PerNodeBucketStats {
+	body, err := json.Marshal(stats)
+	assert.Nil(t, err)
+
+	var decoded objects.PerNodeBucketStats
+	assert.Nil(t, json.Unmarshal(body, &decoded))
+
+	return decoded
+}
+
+func unparseableSamples(t *testing.T, stat string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)["cbexporter_unparseable_samples_total"]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "stat" && label.GetValue() == stat {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func collectPerNodeDrift(t *testing.T, mode string, stats ...objects.PerNodeBucketStats) map[string]float64 {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(len(stats)).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(len(stats)).Return([]objects.BucketInfo{test.GenerateBucket("wawa-bucket")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), gomock.Any()).Times(len(stats)).Return(test.GenerateServers(), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.SetUndefinedSamples(mode)
+
+	for _, s := range stats {
+		mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(2, s).Return(nil).Times(1)
+		testCollector.CollectMetrics(context.Background())
+	}
+
+	return collectValues(t, &testCollector)
+}
+
+func TestPerNodeBucketStatsSkipsUndefinedSamples(t *testing.T) {
+	before := unparseableSamples(t, "avg_active_timestamp_drift")
+	values := collectPerNodeDrift(t, objects.UndefinedSamplesSkip, driftStats(1), undefinedDriftStats())
+
+	for key := range values {
+		assert.NotContains(t, key, "cbpernodebucket_avg_active_timestamp_drift")
+	}
+
+	assert.Equal(t, before+1, unparseableSamples(t, "avg_active_timestamp_drift"))
+}
+
+func TestPerNodeBucketStatsExportsUndefinedSamples(t *testing.T) {
+	values := collectPerNodeDrift(t, objects.UndefinedSamplesNaN, undefinedDriftStats())
+	assert.True(t, math.IsNaN(values["cbpernodebucket_avg_active_timestamp_drift/wawa-bucket/"+test.GenerateNode().Hostname]))
+
+	values = collectPerNodeDrift(t, objects.UndefinedSamplesZero, driftStats(1), undefinedDriftStats())
+	assert.Equal(t, 0.0, values["cbpernodebucket_avg_active_timestamp_drift/wawa-bucket/"+test.GenerateNode().Hostname])
+}
+
+func deadNodeServers(bucket string) objects.Servers {
+	return objects.Servers{
+		Servers: []objects.Server{
+			{Hostname: "dead-node:8091", Stats: map[string]string{"uri": "/pools/default/buckets/" + bucket + "/nodes/dead-node%3A8091/stats"}},
+			{Hostname: "live-node:8091", Stats: map[string]string{"uri": "/pools/default/buckets/" + bucket + "/nodes/live-node%3A8091/stats"}},
+		},
+	}
+}
+
+// getDriftStats answers every request with new stats, as the samples are
+// released after each collection.
+func getDriftStats(drift float64) func(context.Context, string, interface{}) error {
+	return func(_ context.Context, _ string, v interface{}) error {
+		*v.(*objects.PerNodeBucketStats) = driftStats(drift)
+		return nil
+	}
+}
+
+func TestPerNodeBucketStatsClusterModeSkipsUnreachableNodes(t *testing.T) {
+	t.Parallel()
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	Node := test.GenerateNode()
+
+	mockClient.EXPECT().ClusterName(gomock.Any()).AnyTimes().Return("dummy-cluster", nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(2).Return(test.GenerateNodes("dummy-cluster", []objects.Node{Node}), nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).AnyTimes().Return(Node, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(2).Return([]objects.BucketInfo{test.GenerateBucket("bucket-a"), test.GenerateBucket("bucket-b")}, nil)
+	mockClient.EXPECT().Servers(gomock.Any(), "bucket-a").Times(2).Return(deadNodeServers("bucket-a"), nil)
+	mockClient.EXPECT().Servers(gomock.Any(), "bucket-b").Times(2).Return(deadNodeServers("bucket-b"), nil)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/bucket-a/nodes/dead-node%3A8091/stats", gomock.Any()).Return(ErrDummy).Times(1)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/bucket-a/nodes/live-node%3A8091/stats", gomock.Any()).DoAndReturn(getDriftStats(1)).Times(2)
+	mockClient.EXPECT().Get(gomock.Any(), "/pools/default/buckets/bucket-b/nodes/live-node%3A8091/stats", gomock.Any()).DoAndReturn(getDriftStats(2)).Times(2)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	testCollector := collectors.NewPerNodeBucketStatsCollector(mockClient, defaultConfig.Collectors.PerNodeBucketStats, labelManager)
+	testCollector.SetClusterMode(true)
+	testCollector.CollectMetrics(context.Background())
+	testCollector.CollectMetrics(context.Background())
+
+	values := collectValues(t, &testCollector)
+
+	assert.Equal(t, 0.0, values["cbpernodebucket_up"])
+	assert.Equal(t, 2.0, values["cbpernodebucket_avg_active_timestamp_drift/bucket-b/live-node:8091"])
+
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)["cbexporter_node_skipped"]
+	assert.True(t, ok)
+
+	skipped := map[string]float64{}
+	for _, metric := range family.GetMetric() {
+		skipped[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, 1.0, skipped["dead-node:8091"])
+}
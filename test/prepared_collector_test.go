@@ -0,0 +1,63 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreparedCollectCountsPlanCacheChanges(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+
+	gomock.InOrder(
+		mockClient.EXPECT().Prepareds(gomock.Any()).Return([]objects.Prepared{
+			{Name: "p1", Node: "node1:8091", Uses: 10, PlanPreparedTime: "t1"},
+			{Name: "p2", Node: "node1:8091", Uses: 5, PlanPreparedTime: "t1"},
+			{Name: "p1", Node: "node2:8091", Uses: 3, PlanPreparedTime: "t1"},
+		}, nil),
+		mockClient.EXPECT().Prepareds(gomock.Any()).Return([]objects.Prepared{
+			// used again, replanned after an index change, and newly prepared.
+			{Name: "p1", Node: "node1:8091", Uses: 14, PlanPreparedTime: "t1"},
+			{Name: "p2", Node: "node1:8091", Uses: 7, PlanPreparedTime: "t2"},
+			{Name: "p3", Node: "node1:8091", Uses: 1, PlanPreparedTime: "t2"},
+		}, nil),
+	)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	collector := collectors.NewPreparedCollector(mockClient, defaultConfig.Collectors.Prepared, labelManager)
+
+	values := collectValues(t, collector)
+	assert.Equal(t, 2.0, values["cbprepared_statements/node1:8091"])
+	assert.Equal(t, 1.0, values["cbprepared_statements/node2:8091"])
+	assert.Equal(t, 0.0, values["cbprepared_cache_hits_total/node1:8091"])
+	assert.Equal(t, 0.0, values["cbprepared_cache_misses_total/node1:8091"])
+
+	values = collectValues(t, collector)
+	assert.Equal(t, map[string]float64{
+		"cbprepared_statements/node1:8091":          3,
+		"cbprepared_cache_hits_total/node1:8091":    7,
+		"cbprepared_cache_misses_total/node1:8091":  2,
+		"cbprepared_invalidations_total/node1:8091": 1,
+		"cbprepared_statements/node2:8091":          0,
+		"cbprepared_cache_hits_total/node2:8091":    0,
+		"cbprepared_cache_misses_total/node2:8091":  0,
+		"cbprepared_invalidations_total/node2:8091": 0,
+		"cbprepared_up":                      1,
+		"cbprepared_scrape_duration_seconds": values["cbprepared_scrape_duration_seconds"],
+	}, values)
+}
@@ -168,7 +168,7 @@ func TestQueryCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager)
@@ -190,13 +190,13 @@ func TestQueryCollectReturnsDownIfClientReturnsErrorOnQuery(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Query := objects.Query{}
-	mockClient.EXPECT().Query().Times(1).Return(Query, ErrDummy)
+	mockClient.EXPECT().Query(gomock.Any()).Times(1).Return(Query, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager)
@@ -227,13 +227,13 @@ func TestQueryCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Query := objects.Query{}
-	mockClient.EXPECT().Query().Times(1).Return(Query, nil)
+	mockClient.EXPECT().Query(gomock.Any()).Times(1).Return(Query, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager)
@@ -257,13 +257,21 @@ func TestQueryCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	Node.Services = []string{objects.QueryService}
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(Node, nil)
 
 	Query := test.GenerateQuery()
-	mockClient.EXPECT().Query().Times(1).Return(Query, nil)
+	mockClient.EXPECT().Query(gomock.Any()).Times(1).Return(Query, nil)
+
+	vitals := objects.QueryVitals{}
+	for _, vital := range objects.QueryVitalsMetrics {
+		vitals[vital] = test.GetRandomFloat64(0, 99999)
+	}
+
+	mockClient.EXPECT().QueryVitals(gomock.Any()).Times(1).Return(vitals, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager)
@@ -294,7 +302,13 @@ func TestQueryCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 				sampleName := "query_" + name
 
 				gauge, err := test.GetGaugeValue(m)
-				testValue := test.Last(Query.Op.Samples[sampleName])
+
+				var testValue float64
+				if vital, ok := objects.QueryVitalsMetrics[name]; ok {
+					testValue = vitals[vital].(float64)
+				} else {
+					testValue = test.Last(Query.Op.Samples[sampleName])
+				}
 
 				assert.Equal(t, testValue, gauge)
 				assert.Nil(t, err)
@@ -308,3 +322,69 @@ func TestQueryCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 		}
 	}
 }
+
+func sampleAge(t *testing.T, collector string) (float64, bool) {
+	for _, metric := range gatherByName(t, prometheus.DefaultGatherer)["cbexporter_sample_age_seconds"].GetMetric() {
+		if metric.GetLabel()[0].GetValue() == collector {
+			return metric.GetGauge().GetValue(), true
+		}
+	}
+
+	return 0, false
+}
+
+func TestQueryCollectReportsSampleAge(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(test.GenerateNode(), nil)
+
+	newest := time.Now().Add(-30 * time.Second)
+
+	query := objects.Query{}
+	query.Op.Samples = map[string][]float64{"timestamp": {float64(newest.Add(-time.Minute).UnixMilli()), float64(newest.UnixMilli())}}
+	mockClient.EXPECT().Query(gomock.Any()).Times(1).Return(query, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	collectValues(t, collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager))
+
+	age, ok := sampleAge(t, defaultConfig.Collectors.Query.Name)
+	assert.True(t, ok)
+	assert.InDelta(t, 30, age, 5)
+}
+
+func TestQueryCollectReportsVitalsOfTheQueryService(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	node.Services = []string{"kv", objects.QueryService}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(2).Return(node, nil)
+	mockClient.EXPECT().Query(gomock.Any()).Times(1).Return(objects.Query{}, nil)
+	mockClient.EXPECT().QueryVitals(gomock.Any()).Times(1).Return(objects.QueryVitals{
+		"cpu.user.percent":        12.5,
+		"memory.usage":            1048576.0,
+		"request_time.mean":       "1.5ms",
+		"request.completed.count": 42.0,
+		"uptime":                  "1h2m3s",
+	}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewQueryCollector(mockClient, defaultConfig.Collectors.Query, labelManager))
+
+	assert.Equal(t, 12.5, values["cbquery_vitals_cpu_user_percent/localhost"])
+	assert.Equal(t, 1048576.0, values["cbquery_vitals_memory_usage_bytes/localhost"])
+	assert.Equal(t, 0.0015, values["cbquery_vitals_request_time_mean_seconds/localhost"])
+	assert.Equal(t, 42.0, values["cbquery_vitals_requests_completed/localhost"])
+	assert.NotContains(t, values, "cbquery_vitals_request_quota_used_hwm_bytes/localhost")
+	assert.Equal(t, 1.0, values["cbquery_up"])
+}
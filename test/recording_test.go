@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+type bucketsTransport struct{}
+
+func (bucketsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`[{"name": "recorded-bucket"}]`)),
+		Request:    req,
+	}, nil
+}
+
+func TestRecordedResponsesAreReplayed(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder := util.NewRecordingTransport(dir, time.Hour, bucketsTransport{})
+	client := util.NewClientWithTransport("http://cluster.example.com", 8091, "Administrator", "secret", recorder)
+
+	buckets, err := client.Buckets(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "recorded-bucket", buckets[0].Name)
+
+	files, err := filepath.Glob(filepath.Join(dir, "cycle-000000", "*.json"))
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+
+	recorded, err := os.ReadFile(files[0])
+	assert.Nil(t, err)
+	assert.Contains(t, string(recorded), "/pools/default/buckets")
+	assert.NotContains(t, string(recorded), "secret")
+
+	replay, err := util.NewReplayTransport(dir, time.Hour)
+	assert.Nil(t, err)
+
+	// the recording is replayed whatever the address of the cluster.
+	client = util.NewClientWithTransport("http://localhost", 8091, "Administrator", "password", replay)
+
+	buckets, err = client.Buckets(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, []objects.BucketInfo{{Name: "recorded-bucket"}}, buckets)
+
+	_, err = client.Tasks(context.Background())
+	assert.NotNil(t, err)
+}
+
+func TestReplayTransportNeedsRecording(t *testing.T) {
+	_, err := util.NewReplayTransport(t.TempDir(), time.Hour)
+
+	assert.ErrorIs(t, err, util.ErrNoRecordings)
+}
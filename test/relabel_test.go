@@ -0,0 +1,98 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func relabelRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	itemCount := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_itemcount"}, []string{"bucket"})
+	itemCount.WithLabelValues("wawa-bucket").Set(10)
+
+	memUsed := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketinfo_basic_memused_bytes"}, []string{"bucket"})
+	memUsed.WithLabelValues("wawa-bucket").Set(20)
+
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "cbnode_up"})
+	up.Set(1)
+
+	registry.MustRegister(itemCount, memUsed, up)
+
+	return registry
+}
+
+func gatherByName(t *testing.T, gatherer prometheus.Gatherer) map[string]*dto.MetricFamily {
+	families, err := gatherer.Gather()
+	assert.Nil(t, err)
+
+	byName := map[string]*dto.MetricFamily{}
+	for _, family := range families {
+		byName[family.GetName()] = family
+	}
+
+	return byName
+}
+
+func TestRelabelGathererRenamesDropsAndLabels(t *testing.T) {
+	gatherer, err := util.NewRelabelGatherer(relabelRegistry(), []objects.RelabelRule{
+		{Match: "cbbucketinfo_basic_(.*)", Rename: "couchbase_bucket_$1"},
+		{Match: "couchbase_bucket_.*", Labels: map[string]string{"source": "couchbase", "bucket": "renamed"}},
+		{Match: "cbnode_up", Drop: true},
+	})
+	assert.Nil(t, err)
+
+	families := gatherByName(t, gatherer)
+
+	assert.NotContains(t, families, "cbnode_up")
+	assert.NotContains(t, families, "cbbucketinfo_basic_itemcount")
+	assert.Contains(t, families, "couchbase_bucket_memused_bytes")
+
+	itemCount := families["couchbase_bucket_itemcount"]
+	assert.NotNil(t, itemCount)
+	assert.Equal(t, 10.0, itemCount.GetMetric()[0].GetGauge().GetValue())
+
+	labels := map[string]string{}
+	for _, label := range itemCount.GetMetric()[0].GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+
+	assert.Equal(t, map[string]string{"bucket": "renamed", "source": "couchbase"}, labels)
+}
+
+func TestRelabelGathererMatchesWholeNames(t *testing.T) {
+	gatherer, err := util.NewRelabelGatherer(relabelRegistry(), []objects.RelabelRule{
+		{Match: "cbnode", Drop: true},
+	})
+	assert.Nil(t, err)
+
+	assert.Contains(t, gatherByName(t, gatherer), "cbnode_up")
+}
+
+func TestRelabelGathererMergesFamiliesRenamedToTheSameName(t *testing.T) {
+	gatherer, err := util.NewRelabelGatherer(relabelRegistry(), []objects.RelabelRule{
+		{Match: "cbbucketinfo_basic_.*", Rename: "couchbase_bucket_basic"},
+	})
+	assert.Nil(t, err)
+
+	families := gatherByName(t, gatherer)
+
+	assert.Len(t, families["couchbase_bucket_basic"].GetMetric(), 2)
+}
+
+func TestNewRelabelGathererRejectsInvalidRules(t *testing.T) {
+	for _, rule := range []objects.RelabelRule{
+		{Match: "cb(node"},
+		{Match: "cbnode_up", Rename: "not a name"},
+		{Match: "cbnode_up", Labels: map[string]string{"not a label": "x"}},
+	} {
+		_, err := util.NewRelabelGatherer(relabelRegistry(), []objects.RelabelRule{rule})
+		assert.True(t, errors.Is(err, util.ErrInvalidRelabelRule), rule.Match)
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRequestIDClient(t *testing.T, handler http.HandlerFunc) (util.Client, func()) {
+	server := httptest.NewServer(handler)
+
+	u, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	port, err := strconv.Atoi(u.Port())
+	assert.Nil(t, err)
+
+	client := util.NewClientWithTransport("http://"+u.Hostname(), port, "Administrator", "password",
+		util.NewRequestIDTransport(http.DefaultTransport))
+
+	return client, server.Close
+}
+
+func TestClientIdentifiesEveryRequest(t *testing.T) {
+	ids := []string{}
+	agents := []string{}
+
+	client, closeServer := newRequestIDClient(t, func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(util.RequestIDHeader))
+		agents = append(agents, r.Header.Get("User-Agent"))
+
+		fmt.Fprint(w, "[]")
+	})
+
+	defer closeServer()
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Buckets(context.Background())
+		assert.Nil(t, err)
+	}
+
+	assert.Len(t, ids, 2)
+	assert.Len(t, ids[0], 16)
+	assert.NotEqual(t, ids[0], ids[1])
+
+	for _, agent := range agents {
+		assert.True(t, strings.HasPrefix(agent, "couchbase-exporter/"), agent)
+	}
+}
+
+func TestClientQuotesRequestIDOfFailedRequest(t *testing.T) {
+	var id string
+
+	client, closeServer := newRequestIDClient(t, func(w http.ResponseWriter, r *http.Request) {
+		id = r.Header.Get(util.RequestIDHeader)
+
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	defer closeServer()
+
+	_, err := client.Buckets(context.Background())
+
+	assert.NotEmpty(t, id)
+	assert.Contains(t, fmt.Sprint(err), "500")
+	assert.Contains(t, fmt.Sprint(err), util.RequestIDHeader+" "+id)
+}
+
+func TestRequestIDTransportKeepsAnIDAlreadySet(t *testing.T) {
+	var id string
+
+	transport := util.NewRequestIDTransport(util.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		id = req.Header.Get(util.RequestIDHeader)
+
+		return nil, ErrDummy
+	}))
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost:8091/pools", nil)
+	assert.Nil(t, err)
+
+	req.Header.Set(util.RequestIDHeader, "scrape-42")
+
+	_, err = transport.RoundTrip(req)
+
+	assert.Equal(t, "scrape-42", id)
+	assert.ErrorIs(t, err, ErrDummy)
+	assert.Contains(t, fmt.Sprint(err), "scrape-42")
+}
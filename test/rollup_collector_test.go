@@ -0,0 +1,126 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func dataNode(status, membership string) objects.Node {
+	return objects.Node{Status: status, ClusterMembership: membership}
+}
+
+func TestBucketFailuresTolerated(t *testing.T) {
+	healthy := dataNode("healthy", "active")
+
+	tests := []struct {
+		name       string
+		bucketType string
+		replicas   int
+		nodes      []objects.Node
+		expected   int
+	}{
+		{"limited by replicas", objects.CouchbaseBucketType, 1, []objects.Node{healthy, healthy, healthy}, 1},
+		{"limited by nodes", objects.CouchbaseBucketType, 3, []objects.Node{healthy, healthy}, 1},
+		{"unhealthy node", objects.CouchbaseBucketType, 2, []objects.Node{healthy, healthy, dataNode("unhealthy", "active")}, 1},
+		{"failed over node", objects.EphemeralBucketType, 1, []objects.Node{healthy, healthy, dataNode("healthy", "inactiveFailed")}, 0},
+		{"no replicas", objects.CouchbaseBucketType, 0, []objects.Node{healthy, healthy}, 0},
+		{"memcached", objects.MemcachedBucketType, 1, []objects.Node{healthy, healthy}, 0},
+		{"every node down", objects.CouchbaseBucketType, 1, []objects.Node{dataNode("unhealthy", "active")}, 0},
+	}
+
+	for _, tt := range tests {
+		bucket := objects.BucketInfo{BucketType: tt.bucketType, ReplicaNumber: tt.replicas, Nodes: tt.nodes}
+		assert.Equal(t, tt.expected, bucket.FailuresTolerated(), tt.name)
+	}
+}
+
+func TestRollupCollectSumsEveryBucket(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	first := test.GenerateBucket("first")
+	first.Quota.RAM = 100
+	first.ReplicaNumber = 2
+	first.Nodes = []objects.Node{dataNode("healthy", "active"), dataNode("healthy", "active"), dataNode("unhealthy", "active")}
+	second := test.GenerateBucket("second")
+	second.Quota.RAM = 200
+	second.ReplicaNumber = 2
+	second.Nodes = []objects.Node{dataNode("healthy", "active"), dataNode("healthy", "active"), dataNode("healthy", "active")}
+
+	nodes := objects.Nodes{}
+	nodes.StorageTotals.Hdd.QuotaTotal = 5000
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{first, second}, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(nodes, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewRollupCollector(mockClient, defaultConfig.Collectors.Rollup, labelManager))
+
+	sum := func(stat string) float64 {
+		return first.BucketBasicStats[stat] + second.BucketBasicStats[stat]
+	}
+
+	assert.Equal(t, map[string]float64{
+		"cbcluster_buckets":                 2,
+		"cbcluster_ops":                     sum(objects.OpsPerSec),
+		"cbcluster_items":                   sum(objects.ItemCount),
+		"cbcluster_ram_used_bytes":          sum(objects.MemUsed),
+		"cbcluster_ram_quota_bytes":         300,
+		"cbcluster_disk_used_bytes":         sum(objects.DiskUsed),
+		"cbcluster_disk_quota_bytes":        5000,
+		"cbcluster_failures_tolerated":      1,
+		"cbcluster_up":                      1,
+		"cbcluster_scrape_duration_seconds": values["cbcluster_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestRollupCollectToleratesMissingStorageTotals(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{}, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewRollupCollector(mockClient, defaultConfig.Collectors.Rollup, labelManager))
+
+	assert.Equal(t, 1.0, values["cbcluster_up"])
+	assert.Equal(t, 0.0, values["cbcluster_buckets"])
+	assert.NotContains(t, values, "cbcluster_disk_quota_bytes")
+}
+
+func TestRollupCollectReturnsDownIfClientReturnsErrorOnBuckets(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(nil, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewRollupCollector(mockClient, defaultConfig.Collectors.Rollup, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbcluster_up": 0}, values)
+}
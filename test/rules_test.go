@@ -0,0 +1,87 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func findRule(ruleFile rules.RuleFile, alert string) (rules.Rule, bool) {
+	for _, group := range ruleFile.Groups {
+		for _, rule := range group.Rules {
+			if rule.Alert == alert {
+				return rule, true
+			}
+		}
+	}
+
+	return rules.Rule{}, false
+}
+
+func TestRulesGeneratesAllRules(t *testing.T) {
+	generated := rules.Generate(config.GetDefaultConfig(), rules.DefaultThresholds())
+
+	for _, alert := range []string{
+		"CouchbaseNodeDown",
+		"CouchbaseResidentRatioLow",
+		"CouchbaseDiskQueueGrowing",
+		"CouchbaseOOMErrors",
+		"CouchbaseTemporaryOOMErrors",
+		"CouchbaseRebalanceStuck",
+		"CouchbaseXdcrErrors",
+		"CouchbaseXdcrCheckpointsFailing",
+		"CouchbaseNoFailureTolerance",
+		"CouchbaseEncryptionDisabled",
+	} {
+		_, ok := findRule(generated, alert)
+		assert.True(t, ok, alert)
+	}
+}
+
+func TestRulesUseThresholds(t *testing.T) {
+	thresholds := rules.DefaultThresholds()
+	thresholds.ResidentRatio = 25
+	thresholds.RebalanceStuckFor = time.Hour
+
+	generated := rules.Generate(config.GetDefaultConfig(), thresholds)
+
+	rule, _ := findRule(generated, "CouchbaseResidentRatioLow")
+	assert.Equal(t, "cbbucketstat_vbuckets_active_resident_items_ratio < 25", rule.Expr)
+
+	rule, _ = findRule(generated, "CouchbaseRebalanceStuck")
+	assert.Contains(t, rule.Expr, "changes(cbtask_rebalance_progress[1h]) == 0")
+}
+
+func TestRulesUseNameOverridesAndSkipDisabledMetrics(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+
+	queue := defaultConfig.Collectors.BucketStats.Metrics["DiskWriteQueue"]
+	queue.NameOverride = "disk_queue_items"
+	defaultConfig.Collectors.BucketStats.Metrics["DiskWriteQueue"] = queue
+
+	oom := defaultConfig.Collectors.BucketStats.Metrics["EpOomErrors"]
+	oom.Enabled = false
+	defaultConfig.Collectors.BucketStats.Metrics["EpOomErrors"] = oom
+
+	generated := rules.Generate(defaultConfig, rules.DefaultThresholds())
+
+	rule, ok := findRule(generated, "CouchbaseDiskQueueGrowing")
+	assert.True(t, ok)
+	assert.Contains(t, rule.Expr, "deriv(cbbucketstat_disk_queue_items[10m])")
+
+	_, ok = findRule(generated, "CouchbaseOOMErrors")
+	assert.False(t, ok)
+}
+
+func TestRulesPrometheusRuleFormat(t *testing.T) {
+	generated := rules.Generate(config.GetDefaultConfig(), rules.DefaultThresholds())
+
+	out, err := rules.Marshal(generated.AsPrometheusRule("couchbase"))
+	assert.Nil(t, err)
+	assert.Contains(t, string(out), "kind: PrometheusRule")
+	assert.Contains(t, string(out), "apiVersion: monitoring.coreos.com/v1")
+	assert.Contains(t, string(out), "  name: couchbase")
+}
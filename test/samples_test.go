@@ -0,0 +1,154 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSamplesDecodeAnythingCouchbaseServerReturns(t *testing.T) {
+	var stats objects.PerNodeBucketStats
+
+	err := json.Unmarshal([]byte(`{"hostname": "node1:8091", "op": {"samples": {
+		"numbers": [1, 2.5, 3e2],
+		"undefined": [1, "undefined"],
+		"strings": ["4"],
+		"holes": [5, null],
+		"nested": [6, [7], {"eight": 8}],
+		"scalar": 9,
+		"empty": [],
+		"missing": null
+	}, "samplesCount": 3}}`), &stats)
+	assert.Nil(t, err)
+
+	samples := stats.Op.Samples
+	assert.Equal(t, []float64{1, 2.5, 300}, samples["numbers"])
+	assert.Equal(t, 1.0, samples["undefined"][0])
+	assert.True(t, math.IsNaN(samples["undefined"][1]))
+	assert.Equal(t, []float64{4}, samples["strings"])
+	assert.Equal(t, []float64{5, 0}, samples["holes"])
+	assert.Len(t, samples["nested"], 3)
+	assert.True(t, math.IsNaN(samples["nested"][2]))
+	assert.Equal(t, []float64{9}, samples["scalar"])
+	assert.Equal(t, []float64{}, samples["empty"])
+	assert.Nil(t, samples["missing"])
+	assert.Contains(t, samples, "missing")
+	assert.Equal(t, 3, stats.Op.SamplesCount)
+
+	samples.Release()
+	assert.Empty(t, samples)
+}
+
+func TestSamplesEncodeUndefinedSamples(t *testing.T) {
+	body, err := json.Marshal(objects.Samples{"drift": {1, math.NaN()}, "missing": nil})
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"drift": [1, "undefined"], "missing": null}`, string(body))
+}
+
+func TestSamplesMustBeAnObject(t *testing.T) {
+	var samples objects.Samples
+
+	err := json.Unmarshal([]byte(`[1, 2]`), &samples)
+	assert.True(t, errors.Is(err, objects.ErrInvalidSamples))
+}
+
+func TestPerNodeBucketStatsDecodeFromAStream(t *testing.T) {
+	var stats objects.PerNodeBucketStats
+
+	decoder := json.NewDecoder(strings.NewReader(`{"hostname": "node1:8091", "op": {"samples": {
+		"numbers": [1, 2.5, 3e2],
+		"undefined": [1, "undefined"],
+		"nested": [6, [7], {"eight": 8}],
+		"scalar": 9,
+		"missing": null
+	}, "samplesCount": 3, "interval": 1000, "unknown": {"a": [1]}}, "unknown": true}`))
+
+	assert.Nil(t, stats.DecodeJSON(decoder))
+
+	samples := stats.Op.Samples
+	assert.Equal(t, "node1:8091", stats.HostName)
+	assert.Equal(t, []float64{1, 2.5, 300}, samples["numbers"])
+	assert.True(t, math.IsNaN(samples["undefined"][1]))
+	assert.Len(t, samples["nested"], 3)
+	assert.True(t, math.IsNaN(samples["nested"][1]))
+	assert.Equal(t, []float64{9}, samples["scalar"])
+	assert.Nil(t, samples["missing"])
+	assert.Equal(t, 3, stats.Op.SamplesCount)
+	assert.Equal(t, 1000, stats.Op.Interval)
+}
+
+func TestPerNodeBucketStatsMustBeAnObject(t *testing.T) {
+	var stats objects.PerNodeBucketStats
+
+	err := stats.DecodeJSON(json.NewDecoder(strings.NewReader(`[1, 2]`)))
+	assert.True(t, errors.Is(err, objects.ErrInvalidObject))
+}
+
+// perNodeStatsResponse is a response with a minute of samples of as many
+// stats as a bucket has on a node.
+func perNodeStatsResponse() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(`{"hostname": "node1:8091", "op": {"samples": {`)
+
+	for stat := 0; stat < 300; stat++ {
+		if stat > 0 {
+			buf.WriteString(",")
+		}
+
+		fmt.Fprintf(&buf, `"stat_%d": [`, stat)
+
+		for i := 0; i < 60; i++ {
+			if i > 0 {
+				buf.WriteString(",")
+			}
+
+			fmt.Fprintf(&buf, "%d.5", stat*i)
+		}
+
+		buf.WriteString("]")
+	}
+
+	buf.WriteString(`}, "samplesCount": 60, "isPersistent": true, "lastTStamp": 1, "interval": 1000}}`)
+
+	return buf.Bytes()
+}
+
+func BenchmarkPerNodeBucketStatsDecode(b *testing.B) {
+	response := perNodeStatsResponse()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var stats objects.PerNodeBucketStats
+		if err := stats.DecodeJSON(json.NewDecoder(bytes.NewReader(response))); err != nil {
+			b.Fatal(err)
+		}
+
+		stats.Op.Samples.Release()
+	}
+}
+
+// BenchmarkPerNodeBucketStatsDecodeMap is the baseline the samples are
+// compared with, decoding them into interface values.
+func BenchmarkPerNodeBucketStatsDecodeMap(b *testing.B) {
+	response := perNodeStatsResponse()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var stats map[string]interface{}
+		if err := json.NewDecoder(bytes.NewReader(response)).Decode(&stats); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecurityCollectReportsEncryptionSettings(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	encrypted := test.GenerateNode()
+	encrypted.NodeEncryption = true
+
+	other := test.GenerateNode()
+	other.Hostname = "other-node"
+	other.NodeEncryption = true
+
+	keyID := 3
+	disabledKeyID := -1
+
+	secret := test.GenerateBucket("secret-bucket")
+	secret.EncryptionAtRestKeyID = &keyID
+
+	plain := test.GenerateBucket("plain-bucket")
+	plain.EncryptionAtRestKeyID = &disabledKeyID
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(encrypted, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{encrypted, other}), nil)
+	mockClient.EXPECT().SecuritySettings(gomock.Any()).Times(1).Return(objects.SecuritySettings{ClusterEncryptionLevel: "strict"}, nil)
+	mockClient.EXPECT().EncryptionAtRestSettings(gomock.Any()).Times(1).Return(objects.EncryptionAtRestSettings{
+		"config": {EncryptionMethod: "nodeSecretManager", EncryptionKeyID: -1},
+		"log":    {EncryptionMethod: "disabled", EncryptionKeyID: -1},
+	}, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{
+		secret,
+		plain,
+		test.GenerateBucket("old-bucket"),
+	}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewSecurityCollector(mockClient, defaultConfig.Collectors.Security, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"cbsecurity_node_encryption_enabled/" + encrypted.Hostname:   1,
+		"cbsecurity_node_encryption_enabled/other-node":              1,
+		"cbsecurity_cluster_encryption_enabled":                      1,
+		"cbsecurity_cluster_encryption_level_info/strict":            1,
+		"cbsecurity_encryption_at_rest_enabled/config":               1,
+		"cbsecurity_encryption_at_rest_enabled/log":                  0,
+		"cbsecurity_bucket_encryption_at_rest_enabled/secret-bucket": 1,
+		"cbsecurity_bucket_encryption_at_rest_enabled/plain-bucket":  0,
+		"cbsecurity_up":                      1,
+		"cbsecurity_scrape_duration_seconds": values["cbsecurity_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestSecurityCollectReportsNoEncryptionLevelUnlessEveryNodeEncrypts(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	encrypted := test.GenerateNode()
+	encrypted.NodeEncryption = true
+
+	other := test.GenerateNode()
+	other.Hostname = "other-node"
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(encrypted, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(test.GenerateNodes("dummy-cluster", []objects.Node{encrypted, other}), nil)
+	mockClient.EXPECT().SecuritySettings(gomock.Any()).Times(1).Return(objects.SecuritySettings{ClusterEncryptionLevel: "control"}, nil)
+	mockClient.EXPECT().EncryptionAtRestSettings(gomock.Any()).Times(1).Return(objects.EncryptionAtRestSettings{}, ErrDummy)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{test.GenerateBucket("old-bucket")}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewSecurityCollector(mockClient, defaultConfig.Collectors.Security, labelManager))
+
+	assert.Equal(t, 1.0, values["cbsecurity_up"])
+	assert.Equal(t, 0.0, values["cbsecurity_node_encryption_enabled/other-node"])
+	assert.Equal(t, 0.0, values["cbsecurity_cluster_encryption_enabled"])
+	assert.Equal(t, 1.0, values["cbsecurity_cluster_encryption_level_info/none"])
+	assert.NotContains(t, values, "cbsecurity_cluster_encryption_level_info/control")
+	assert.NotContains(t, values, "cbsecurity_bucket_encryption_at_rest_enabled/old-bucket")
+}
+
+func TestSecurityCollectReturnsDownIfClientReturnsErrorOnSettings(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, nil)
+	mockClient.EXPECT().SecuritySettings(gomock.Any()).Times(1).Return(objects.SecuritySettings{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewSecurityCollector(mockClient, defaultConfig.Collectors.Security, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbsecurity_up": 0}, values)
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func unixSocketClient(path string) *http.Client {
+	return &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", path)
+			},
+		},
+	}
+}
+
+func TestServerListensOnUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "socket")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "metrics.sock")
+
+	// a socket left behind by a previous run is replaced.
+	assert.Nil(t, ioutil.WriteFile(path, nil, 0600))
+
+	server := &util.Server{}
+	server.Start(util.UnixScheme+path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	client := unixSocketClient(path)
+
+	var resp *http.Response
+
+	assert.Eventually(t, func() bool {
+		resp, err = client.Get("http://exporter/metrics")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "ok", string(body))
+}
+
+func TestListenersDefaultToServerAddressAndPort(t *testing.T) {
+	config := objects.ExporterConfig{}
+	config.SetDefaults()
+
+	assert.Equal(t, []string{"0.0.0.0:9091"}, config.Listeners())
+
+	config.SetOrDefaultListenAddresses([]string{"127.0.0.1:9091", "unix:///tmp/metrics.sock"})
+	assert.Equal(t, []string{"127.0.0.1:9091", "unix:///tmp/metrics.sock"}, config.Listeners())
+}
+
+func TestServeAllNotifiesSystemdWhenListening(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notify")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	notify, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: filepath.Join(dir, "notify.sock"), Net: "unixgram"})
+	assert.Nil(t, err)
+
+	defer notify.Close()
+
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(dir, "notify.sock"))
+
+	path := filepath.Join(dir, "metrics.sock")
+
+	go util.ServeAll([]string{util.UnixScheme + path}, http.NotFoundHandler(), "", "")
+
+	assert.Nil(t, notify.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	buf := make([]byte, 256)
+	n, err := notify.Read(buf)
+	assert.Nil(t, err)
+	assert.Equal(t, "READY=1\nSTATUS=Serving metrics on unix://"+path, string(buf[:n]))
+
+	// the socket is listened on by the time systemd is told.
+	conn, err := net.Dial("unix", path)
+	assert.Nil(t, err)
+	conn.Close()
+}
+
+func TestNotifySystemdDoesNothingOutsideSystemd(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+
+	assert.Nil(t, util.NotifySystemd("READY=1"))
+}
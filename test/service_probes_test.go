@@ -0,0 +1,86 @@
+package test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func listenerPort(t *testing.T, listener net.Listener) int {
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	assert.Nil(t, err)
+
+	p, err := strconv.Atoi(port)
+	assert.Nil(t, err)
+
+	return p
+}
+
+func TestServiceProbesReportWhetherEachPortAnswers(t *testing.T) {
+	kv, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	defer kv.Close()
+
+	// a service that refuses requests is still answering.
+	query := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer query.Close()
+
+	queryURL, err := url.Parse(query.URL)
+	assert.Nil(t, err)
+
+	queryPort, err := strconv.Atoi(queryURL.Port())
+	assert.Nil(t, err)
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+
+	closedPort := listenerPort(t, closed)
+	closed.Close()
+
+	prober := &util.ServiceProber{
+		Ports: map[string]objects.ServicePort{
+			"kv":    {Port: listenerPort(t, kv)},
+			"n1ql":  {Port: queryPort, HTTP: true},
+			"index": {Port: closedPort, HTTP: true},
+		},
+		Timeout: time.Second,
+		Client:  &http.Client{},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	node := test.GenerateNode()
+	node.Hostname = "127.0.0.1:8091"
+	node.Services = []string{"kv", "n1ql", "index", "backup"}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(node, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{Nodes: []objects.Node{node}}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewServiceProbesCollector(mockClient, prober, labelManager))
+
+	assert.Equal(t, map[string]float64{
+		"couchbase_service_up/127.0.0.1:8091/kv":    1,
+		"couchbase_service_up/127.0.0.1:8091/n1ql":  1,
+		"couchbase_service_up/127.0.0.1:8091/index": 0,
+	}, values)
+}
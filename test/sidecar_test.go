@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func sidecarConfig(t *testing.T) *objects.ExporterConfig {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "username"), []byte("operator-admin\n"), 0o600))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "password"), []byte("s3cret\n"), 0o600))
+
+	config := new(objects.ExporterConfig)
+	config.SetDefaults()
+	config.Sidecar.SecretDir = dir
+
+	return config
+}
+
+func TestSidecarIsDisabledByDefault(t *testing.T) {
+	t.Setenv("COUCHBASE_OPERATOR_USER", "")
+
+	config := sidecarConfig(t)
+	config.SetOrDefaultSidecar(false)
+
+	assert.False(t, config.Sidecar.Enabled)
+	assert.Equal(t, "localhost", config.CouchbaseAddress)
+	assert.Equal(t, "Administrator", config.CouchbaseUser)
+}
+
+func TestSidecarFillsInDefaultConnectionSettings(t *testing.T) {
+	t.Setenv("COUCHBASE_OPERATOR_USER", "")
+
+	hostname, err := os.Hostname()
+	assert.Nil(t, err)
+
+	config := sidecarConfig(t)
+	config.Ca = "/etc/ca.pem"
+	config.SetOrDefaultSidecar(true)
+
+	assert.True(t, config.Sidecar.Enabled)
+	assert.Equal(t, hostname, config.CouchbaseAddress)
+	assert.Equal(t, 18091, config.CouchbasePort)
+	assert.Equal(t, "operator-admin", config.CouchbaseUser)
+	assert.Equal(t, "s3cret", config.CouchbasePassword)
+}
+
+func TestSidecarKeepsExplicitSettings(t *testing.T) {
+	t.Setenv("COUCHBASE_OPERATOR_USER", "")
+
+	config := sidecarConfig(t)
+	config.SetOrDefaultCouchAddress("cb-0000.cb.default.svc")
+	config.SetOrDefaultCouchPort("9000")
+	config.SetOrDefaultCouchUser("exporter")
+	config.SetOrDefaultCouchPassword("hunter2")
+	config.SetOrDefaultSidecar(true)
+
+	assert.Equal(t, "cb-0000.cb.default.svc", config.CouchbaseAddress)
+	assert.Equal(t, 9000, config.CouchbasePort)
+	assert.Equal(t, "exporter", config.CouchbaseUser)
+	assert.Equal(t, "hunter2", config.CouchbasePassword)
+}
+
+func TestSidecarIsDetectedFromOperatorEnvironment(t *testing.T) {
+	t.Setenv("COUCHBASE_OPERATOR_USER", "operator-admin")
+
+	config := sidecarConfig(t)
+	config.SetOrDefaultSidecar(false)
+
+	assert.True(t, config.Sidecar.Enabled)
+}
+
+func TestWaitForNodeInitWaitsUntilNodeIsHealthy(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	gomock.InOrder(
+		mockClient.EXPECT().GetCurrentNode(gomock.Any()).Return(objects.Node{}, ErrDummy),
+		mockClient.EXPECT().GetCurrentNode(gomock.Any()).Return(objects.Node{Hostname: "localhost:8091", Status: "warmup"}, nil),
+		mockClient.EXPECT().GetCurrentNode(gomock.Any()).Return(objects.Node{Hostname: "localhost:8091", Status: "healthy"}, nil),
+	)
+
+	assert.Nil(t, util.WaitForNodeInit(context.Background(), mockClient, time.Millisecond, 5))
+}
+
+func TestWaitForNodeInitGivesUp(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(3).Return(objects.Node{Hostname: "localhost:8091", Status: "warmup"}, nil)
+
+	err := util.WaitForNodeInit(context.Background(), mockClient, time.Millisecond, 2)
+
+	assert.True(t, util.IsRetryFailure(err))
+	assert.Contains(t, err.Error(), "node localhost:8091 is warmup")
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeStatementReplacesLiterals(t *testing.T) {
+	assert.Equal(t,
+		"SELECT * FROM `travel-sample` WHERE type = ? AND id IN [?] AND price > ?",
+		objects.NormalizeStatement("SELECT *  FROM `travel-sample`\n WHERE type = \"hotel\" AND id IN [1, 2, 3] AND price > 9.5"))
+	assert.Equal(t,
+		objects.NormalizeStatement("SELECT name FROM b WHERE city = 'Paris'"),
+		objects.NormalizeStatement("SELECT name FROM b WHERE city = 'Rome'"))
+	assert.Equal(t, "SELECT a1 FROM b WHERE c = $1", objects.NormalizeStatement("SELECT a1 FROM b WHERE c = $1"))
+}
+
+func TestSlowQueriesCollectCountsRequestsByFingerprint(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().CompletedRequests(gomock.Any()).Times(1).Return([]objects.CompletedRequest{
+		{Statement: "SELECT * FROM b WHERE id = 1", Elapsed: 1.5},
+		{Statement: "SELECT * FROM b WHERE id = 2", Elapsed: 4},
+		{Statement: "SELECT * FROM b WHERE id = 3", Elapsed: 120},
+		{Statement: "DELETE FROM b WHERE id = 'x'", Elapsed: 2},
+	}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewSlowQueriesCollector(mockClient, []float64{5, 2}, defaultConfig.Collectors.SlowQueries, labelManager))
+
+	selectFingerprint := objects.Fingerprint("SELECT * FROM b WHERE id = ?")
+	deleteFingerprint := objects.Fingerprint("DELETE FROM b WHERE id = ?")
+
+	assert.Equal(t, map[string]float64{
+		"cbslowquery_requests/" + selectFingerprint + "/2":                                  1,
+		"cbslowquery_requests/" + selectFingerprint + "/5":                                  2,
+		"cbslowquery_requests/" + selectFingerprint + "/+Inf":                               3,
+		"cbslowquery_elapsed_seconds/" + selectFingerprint:                                  125.5,
+		"cbslowquery_statement_info/" + selectFingerprint + "/SELECT * FROM b WHERE id = ?": 1,
+		"cbslowquery_requests/" + deleteFingerprint + "/2":                                  1,
+		"cbslowquery_requests/" + deleteFingerprint + "/5":                                  1,
+		"cbslowquery_requests/" + deleteFingerprint + "/+Inf":                               1,
+		"cbslowquery_elapsed_seconds/" + deleteFingerprint:                                  2,
+		"cbslowquery_statement_info/" + deleteFingerprint + "/DELETE FROM b WHERE id = ?":   1,
+		"cbslowquery_up":                      1,
+		"cbslowquery_scrape_duration_seconds": values["cbslowquery_scrape_duration_seconds"],
+	}, values)
+}
+
+func TestSlowQueriesCollectReturnsDownIfQueryFails(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().CompletedRequests(gomock.Any()).Times(1).Return(nil, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewSlowQueriesCollector(mockClient, nil, defaultConfig.Collectors.SlowQueries, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbslowquery_up": 0}, values)
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotRoundTripsRestoredValues(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	samples := []objects.MetricSample{
+		{Name: "avg_bg_wait_time", Labels: map[string]string{"bucket": "wawa-bucket", "cluster": "dummy-cluster"}, Value: 0.5},
+		{Name: "not_a_configured_metric", Labels: map[string]string{"bucket": "wawa-bucket"}, Value: 2},
+	}
+
+	original := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	original.Restore(samples)
+
+	path := filepath.Join(dir, "snapshot.json")
+	assert.Nil(t, collectors.NewSnapshotWriter(path, time.Minute, &original).Write())
+
+	restored := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+	assert.Nil(t, collectors.NewSnapshotWriter(path, time.Minute, &restored).Restore())
+
+	assert.Equal(t, samples[:1], restored.Snapshot())
+}
+
+func TestSnapshotKeepsTheSamplesOfEachCluster(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+
+	newCollector := func(cluster string) *collectors.BucketStatsCollector {
+		collector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+		collector.SetCluster(cluster)
+
+		return &collector
+	}
+
+	sample := func(cluster string, value float64) []objects.MetricSample {
+		return []objects.MetricSample{
+			{Name: "avg_bg_wait_time", Labels: map[string]string{"bucket": "wawa-bucket", "cluster": cluster}, Value: value},
+		}
+	}
+
+	top, other := newCollector(""), newCollector("other")
+	top.Restore(sample("top-cluster", 1))
+	other.Restore(sample("other-cluster", 2))
+
+	path := filepath.Join(dir, "snapshot.json")
+	assert.Nil(t, collectors.NewSnapshotWriter(path, time.Minute, top, other).Write())
+
+	restoredTop, restoredOther := newCollector(""), newCollector("other")
+	assert.Nil(t, collectors.NewSnapshotWriter(path, time.Minute, restoredTop, restoredOther).Restore())
+
+	assert.Equal(t, sample("top-cluster", 1), restoredTop.Snapshot())
+	assert.Equal(t, sample("other-cluster", 2), restoredOther.Snapshot())
+
+	stale := map[string]float64{}
+
+	for _, metric := range gatherByName(t, prometheus.DefaultGatherer)["cbexporter_snapshot_stale"].GetMetric() {
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["collector"] == defaultConfig.Collectors.BucketStats.Name {
+			stale[labels["cluster"]] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, map[string]float64{"": 1, "other": 1}, stale)
+}
+
+func TestSnapshotRestoreRejectsOldSnapshots(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	dir, err := ioutil.TempDir("", "snapshot")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.json")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(`{"timestamp": 1, "collectors": {}}`), 0600))
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	collector := collectors.NewBucketStatsCollector(mockClient, defaultConfig.Collectors.BucketStats, labelManager)
+
+	err = collectors.NewSnapshotWriter(path, time.Minute, &collector).Restore()
+	assert.ErrorIs(t, err, collectors.ErrSnapshotTooOld)
+	assert.Empty(t, collector.Snapshot())
+}
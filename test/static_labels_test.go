@@ -0,0 +1,64 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStaticLabel(t *testing.T) {
+	name, value, err := util.ParseStaticLabel("environment=prod=eu")
+	assert.Nil(t, err)
+	assert.Equal(t, "environment", name)
+	assert.Equal(t, "prod=eu", value)
+
+	for _, pair := range []string{"environment", "2fast=yes", "__name__=x"} {
+		_, _, err := util.ParseStaticLabel(pair)
+		assert.True(t, errors.Is(err, util.ErrInvalidStaticLabel), pair)
+	}
+}
+
+func TestStaticLabelGathererLabelsEveryMetric(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	plain := prometheus.NewGauge(prometheus.GaugeOpts{Name: "plain"})
+	plain.Set(1)
+
+	labelled := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "labelled"}, []string{"team", "bucket"})
+	labelled.WithLabelValues("storage", "wawa-bucket").Set(2)
+
+	registry.MustRegister(plain, labelled)
+
+	gatherer := util.NewStaticLabelGatherer(registry, map[string]string{"environment": "prod", "team": "platform"})
+
+	families, err := gatherer.Gather()
+	assert.Nil(t, err)
+
+	labels := map[string]map[string]string{}
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			names := []string{}
+			labels[family.GetName()] = map[string]string{}
+
+			for _, label := range metric.GetLabel() {
+				names = append(names, label.GetName())
+				labels[family.GetName()][label.GetName()] = label.GetValue()
+			}
+
+			assert.IsIncreasing(t, names, family.GetName())
+		}
+	}
+
+	assert.Equal(t, map[string]string{"environment": "prod", "team": "platform"}, labels["plain"])
+	assert.Equal(t, map[string]string{"environment": "prod", "team": "storage", "bucket": "wawa-bucket"}, labels["labelled"])
+}
+
+func TestStaticLabelGathererIsNoopWithoutLabels(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	assert.Equal(t, prometheus.Gatherer(registry), util.NewStaticLabelGatherer(registry, nil))
+}
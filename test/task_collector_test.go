@@ -1,6 +1,7 @@
 package test
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
@@ -167,7 +168,7 @@ func TestTaskCollectReturnsDownIfClientReturnsError(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", ErrDummy)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager)
@@ -189,13 +190,13 @@ func TestTaskCollectReturnsDownIfClientReturnsErrorOnTask(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Tasks := []objects.Task{}
-	mockClient.EXPECT().Tasks().Times(1).Return(Tasks, ErrDummy)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(Tasks, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager)
@@ -217,16 +218,16 @@ func TestTaskCollectReturnsDownIfClientReturnsErrorOnBuckets(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Tasks := []objects.Task{}
-	mockClient.EXPECT().Tasks().Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(Tasks, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, ErrDummy)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, ErrDummy)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager)
@@ -256,16 +257,16 @@ func TestTaskCollectReturnsUpWithNoErrors(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Tasks := []objects.Task{}
-	mockClient.EXPECT().Tasks().Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(Tasks, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager)
@@ -289,17 +290,21 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	Tasks := test.GenerateTasks()
-	mockClient.EXPECT().Tasks().Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, nil)
+
+	Xdcr := test.GenerateXdcrStats(Tasks)
+	mockClient.EXPECT().XdcrStats(gomock.Any(), "Foo").Times(1).Return(Xdcr, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	buckets = append(buckets, test.GenerateBucket("wawa-bucket"))
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
 
 	testCollector := collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager)
@@ -328,7 +333,7 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 				name := defaultConfig.Collectors.Task.Metrics[key].Name
 
 				gauge, err := test.GetGaugeValue(m)
-				testValue := test.GetTaskTestValue(key, name, Tasks)
+				testValue := test.GetTaskTestValue(key, name, Tasks, Xdcr)
 
 				assert.Equal(t, testValue, gauge)
 				assert.Nil(t, err)
@@ -338,7 +343,9 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValues(t *testing.T) {
 		case <-time.After(1 * time.Second):
 			log.Debug("%v", count)
 
-			if count >= len(defaultConfig.Collectors.Task.Metrics)+2 {
+			// the bucket has no auto-compaction thresholds or purge interval
+			// and no compaction has finished yet.
+			if count >= len(defaultConfig.Collectors.Task.Metrics)-2 {
 				return
 			}
 		}
@@ -352,10 +359,14 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutoCompactionObj
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Tasks := test.GenerateTasks()
-	mockClient.EXPECT().Tasks().Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, nil)
+
+	Xdcr := test.GenerateXdcrStats(Tasks)
+	mockClient.EXPECT().XdcrStats(gomock.Any(), "Foo").Times(1).Return(Xdcr, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
@@ -370,11 +381,12 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutoCompactionObj
 			"size":       "undefined",
 		},
 	}
+	singleBucket.PurgeInterval = 3
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
@@ -399,12 +411,20 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutoCompactionObj
 				gauge, err := test.GetGaugeValue(m)
 				assert.Nil(t, err)
 				assert.True(t, gauge > 0, fqName)
+			case "cbtask_compaction_docs_fragmentation_threshold", "cbtask_compaction_views_fragmentation_threshold":
+				gauge, err := test.GetGaugeValue(m)
+				assert.Nil(t, err)
+				assert.Equal(t, 30.0, gauge, fqName)
+			case "cbtask_compaction_metadata_purge_interval_seconds":
+				gauge, err := test.GetGaugeValue(m)
+				assert.Nil(t, err)
+				assert.Equal(t, 3*86400.0, gauge, fqName)
 			default:
 				key := test.GetKeyFromFQName(defaultConfig.Collectors.Task, fqName)
 				name := defaultConfig.Collectors.Task.Metrics[key].Name
 
 				gauge, err := test.GetGaugeValue(m)
-				testValue := test.GetTaskTestValue(key, name, Tasks)
+				testValue := test.GetTaskTestValue(key, name, Tasks, Xdcr)
 
 				assert.Equal(t, testValue, gauge)
 				assert.Nil(t, err)
@@ -414,7 +434,8 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutoCompactionObj
 		case <-time.After(1 * time.Second):
 			log.Debug("%v", count)
 
-			if count >= len(defaultConfig.Collectors.Task.Metrics)+2 {
+			// no compaction has finished yet.
+			if count >= len(defaultConfig.Collectors.Task.Metrics)+1 {
 				return
 			}
 		}
@@ -428,19 +449,23 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutocompactionBoo
 	defer mockCtrl.Finish()
 
 	mockClient := mocks.NewMockCbClient(mockCtrl)
-	mockClient.EXPECT().ClusterName().Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
 
 	Tasks := test.GenerateTasks()
-	mockClient.EXPECT().Tasks().Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(Tasks, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{}, nil)
+
+	Xdcr := test.GenerateXdcrStats(Tasks)
+	mockClient.EXPECT().XdcrStats(gomock.Any(), "Foo").Times(1).Return(Xdcr, nil)
 
 	buckets := make([]objects.BucketInfo, 0)
 	singleBucket := test.GenerateBucket("wawa-bucket")
 	singleBucket.AutoCompactionSettings = true
 	buckets = append(buckets, singleBucket)
-	mockClient.EXPECT().Buckets().Times(1).Return(buckets, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return(buckets, nil)
 
 	Node := test.GenerateNode()
-	mockClient.EXPECT().GetCurrentNode().Times(1).Return(Node, nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(Node, nil)
 
 	lblManager := util.NewLabelManager(mockClient, 600*time.Second)
 
@@ -470,7 +495,7 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutocompactionBoo
 				name := defaultConfig.Collectors.Task.Metrics[key].Name
 
 				gauge, err := test.GetGaugeValue(m)
-				testValue := test.GetTaskTestValue(key, name, Tasks)
+				testValue := test.GetTaskTestValue(key, name, Tasks, Xdcr)
 
 				assert.Equal(t, testValue, gauge)
 				assert.Nil(t, err)
@@ -480,9 +505,203 @@ func TestTaskCollectReturnsOneOfEachMetricWithCorrectValuesWithAutocompactionBoo
 		case <-time.After(1 * time.Second):
 			log.Debug("%v", count)
 
-			if count >= len(defaultConfig.Collectors.Task.Metrics)+2 {
+			// the bucket has no auto-compaction thresholds or purge interval
+			// and no compaction has finished yet.
+			if count >= len(defaultConfig.Collectors.Task.Metrics)-2 {
 				return
 			}
 		}
 	}
 }
+
+func TestTaskCollectReportsIndexRebalanceStageOnceStarted(t *testing.T) {
+	var tasks []objects.Task
+
+	assert.Nil(t, json.Unmarshal([]byte(`[{
+		"type": "rebalance",
+		"status": "running",
+		"progress": 40,
+		"stageInfo": {
+			"data": {"totalProgress": 100, "perNodeProgress": {"ns_1@node1": 1}, "startTime": "2022-01-01T00:00:00.000Z", "completedTime": "2022-01-01T00:01:00.000Z", "timeTaken": 60000},
+			"index": {"totalProgress": 12.5, "perNodeProgress": {"ns_1@node1": 0.125}, "startTime": "2022-01-01T00:01:00.000Z", "completedTime": false},
+			"search": {"totalProgress": 0, "startTime": false, "completedTime": false}
+		}
+	}]`), &tasks))
+
+	assert.True(t, tasks[0].StageInfo.Index.Started())
+	assert.False(t, tasks[0].StageInfo.Search.Started())
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(tasks, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager))
+
+	assert.Equal(t, 40.0, values["cbtask_rebalance_progress"])
+	assert.Equal(t, 12.5, values["cbtask_index_rebalance_progress"])
+}
+
+func TestTaskCollectReportsWarmupProgress(t *testing.T) {
+	var tasks []objects.Task
+
+	assert.Nil(t, json.Unmarshal([]byte(`[{
+		"type": "warming_up",
+		"bucket": "wawa-bucket",
+		"node": "ns_1@node1",
+		"status": "running",
+		"recommendedRefreshPeriod": 2,
+		"stats": {
+			"ep_warmup_thread": "running",
+			"ep_warmup_state": "loading data",
+			"ep_warmup_estimated_key_count": "1000",
+			"ep_warmup_estimated_value_count": "1000",
+			"ep_warmup_key_count": "1000",
+			"ep_warmup_value_count": "250"
+		}
+	}, {
+		"type": "warming_up",
+		"bucket": "wawa-bucket",
+		"node": "ns_1@node2",
+		"status": "running",
+		"stats": {
+			"ep_warmup_state": "estimating database item count",
+			"ep_warmup_estimated_value_count": "unknown"
+		}
+	}]`), &tasks))
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(tasks, nil)
+	mockClient.EXPECT().Nodes(gomock.Any()).Times(1).Return(objects.Nodes{
+		Nodes: []objects.Node{{Hostname: "node1:8091", OtpNode: "ns_1@node1"}},
+	}, nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager))
+
+	assert.Equal(t, 1.0, values["cbtask_warmup_state/wawa-bucket/node1:8091/loading data"])
+	assert.Equal(t, 1000.0, values["cbtask_warmup_estimated_items/wawa-bucket/node1:8091"])
+	assert.Equal(t, 250.0, values["cbtask_warmup_items/wawa-bucket/node1:8091"])
+
+	// a node missing from the cluster's nodes is labelled by its host.
+	assert.Equal(t, 1.0, values["cbtask_warmup_state/wawa-bucket/node2/estimating database item count"])
+	assert.NotContains(t, values, "cbtask_warmup_estimated_items/wawa-bucket/node2")
+}
+
+func TestTaskCollectReportsXdcrStatsPerReplication(t *testing.T) {
+	var tasks []objects.Task
+
+	assert.Nil(t, json.Unmarshal([]byte(`[
+		{"type": "xdcr", "id": "a1b2/src/dst", "source": "src", "target": "/remoteClusters/a1b2/buckets/dst", "pauseRequested": true, "errors": ["pipeline failed"], "changesLeft": 3},
+		{"type": "xdcr", "id": "c3d4/other/dst", "source": "other", "target": "/remoteClusters/c3d4/buckets/dst", "changesLeft": 0}
+	]`), &tasks))
+
+	var stats objects.XdcrStats
+	assert.Nil(t, json.Unmarshal([]byte(`{"op": {"samples": {
+		"replications/a1b2/src/dst/docs_failed_cr_source": [1, 4],
+		"replications/a1b2/src/dst/docs_filtered": [10, 12],
+		"replications/a1b2/src/dst/num_checkpoints": [6, 7],
+		"replications/a1b2/src/dst/num_failedckpts": [0, 2]
+	}}}`), &stats))
+
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return(tasks, nil)
+	mockClient.EXPECT().XdcrStats(gomock.Any(), "src").Times(1).Return(stats, nil)
+	mockClient.EXPECT().XdcrStats(gomock.Any(), "other").Times(1).Return(objects.XdcrStats{}, ErrDummy)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{}, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager))
+
+	src := "/src//remoteClusters/a1b2/buckets/dst"
+	other := "/other//remoteClusters/c3d4/buckets/dst"
+
+	assert.Equal(t, 1.0, values["cbtask_up"])
+	assert.Equal(t, 1.0, values["cbtask_xdcr_paused"+src])
+	assert.Equal(t, 1.0, values["cbtask_xdcr_errors"+src])
+	assert.Equal(t, 4.0, values["cbtask_xdcr_docs_failed_cr_source"+src])
+	assert.Equal(t, 12.0, values["cbtask_xdcr_docs_filtered"+src])
+	assert.Equal(t, 7.0, values["cbtask_xdcr_checkpoints"+src])
+	assert.Equal(t, 2.0, values["cbtask_xdcr_failed_checkpoints"+src])
+	assert.Equal(t, 0.0, values["cbtask_xdcr_paused"+other])
+	assert.NotContains(t, values, "cbtask_xdcr_docs_filtered"+other)
+}
+
+func TestTaskCollectReportsCompactionStateAndThresholds(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	own := test.GenerateBucket("own")
+	own.AutoCompactionSettings = map[string]interface{}{
+		"databaseFragmentationThreshold": map[string]interface{}{"percentage": 40, "size": "undefined"},
+		"viewFragmentationThreshold":     map[string]interface{}{"percentage": "undefined", "size": "undefined"},
+	}
+	own.PurgeInterval = 0.5
+
+	shared := test.GenerateBucket("shared")
+	shared.AutoCompactionSettings = false
+
+	var defaults objects.AutoCompaction
+	assert.Nil(t, json.Unmarshal([]byte(`{
+		"databaseFragmentationThreshold": {"percentage": 30, "size": "undefined"},
+		"viewFragmentationThreshold": {"percentage": 25, "size": "undefined"}
+	}`), &defaults))
+
+	defaults.PurgeInterval = 3
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	gomock.InOrder(
+		mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return([]objects.Task{{Type: "bucket_compaction", Bucket: "own", Progress: 50}}, nil),
+		mockClient.EXPECT().Tasks(gomock.Any()).Times(1).Return([]objects.Task{}, nil),
+	)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(2).Return([]objects.BucketInfo{own, shared}, nil)
+	mockClient.EXPECT().AutoCompaction(gomock.Any()).Times(2).Return(defaults, nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	collector := collectors.NewTaskCollector(mockClient, defaultConfig.Collectors.Task, labelManager)
+
+	values := collectValues(t, collector)
+
+	assert.Equal(t, 50.0, values["cbtask_compacting_progress/own"])
+	assert.Equal(t, 1.0, values["cbtask_compaction_running/own"])
+	assert.Equal(t, 0.0, values["cbtask_compaction_running/shared"])
+	assert.NotContains(t, values, "cbtask_compaction_last_duration_seconds/own")
+	assert.Equal(t, 40.0, values["cbtask_compaction_docs_fragmentation_threshold/own"])
+	assert.NotContains(t, values, "cbtask_compaction_views_fragmentation_threshold/own")
+	assert.Equal(t, 30.0, values["cbtask_compaction_docs_fragmentation_threshold/shared"])
+	assert.Equal(t, 25.0, values["cbtask_compaction_views_fragmentation_threshold/shared"])
+	assert.Equal(t, 43200.0, values["cbtask_compaction_metadata_purge_interval_seconds/own"])
+	assert.Equal(t, 3*86400.0, values["cbtask_compaction_metadata_purge_interval_seconds/shared"])
+
+	values = collectValues(t, collector)
+
+	assert.Equal(t, 0.0, values["cbtask_compaction_running/own"])
+	assert.True(t, values["cbtask_compaction_last_duration_seconds/own"] > 0)
+	assert.NotContains(t, values, "cbtask_compaction_last_duration_seconds/shared")
+}
@@ -0,0 +1,69 @@
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextfileWriterRejectsNonPromExtension(t *testing.T) {
+	_, err := util.NewTextfileWriter("/tmp/couchbase.txt", prometheus.NewRegistry())
+
+	assert.ErrorIs(t, err, util.ErrTextfileExtension)
+}
+
+func TestTextfileWriterWritesGatheredMetrics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "textfile")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	registry := prometheus.NewRegistry()
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "cb_dummy", Help: "dummy gauge"})
+	registry.MustRegister(gauge)
+	gauge.Set(42)
+
+	path := filepath.Join(dir, "couchbase.prom")
+	writer, err := util.NewTextfileWriter(path, registry)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Write())
+
+	contents, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "# HELP cb_dummy dummy gauge\n# TYPE cb_dummy gauge\ncb_dummy 42\n", string(contents))
+
+	files, err := ioutil.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestTextfileWriterOmitsGoAndProcessFamilies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "textfile")
+	assert.Nil(t, err)
+
+	defer os.RemoveAll(dir)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewGoCollector())
+	registry.MustRegister(prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "cb_dummy", Help: "dummy gauge"})
+	registry.MustRegister(gauge)
+	gauge.Set(42)
+
+	path := filepath.Join(dir, "couchbase.prom")
+	writer, err := util.NewTextfileWriter(path, registry)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Write())
+
+	contents, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(contents), "go_")
+	assert.NotContains(t, string(contents), "process_")
+	assert.Contains(t, string(contents), "cb_dummy 42\n")
+}
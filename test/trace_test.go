@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+// clientCounter returns the value of the exporter's client counter name with
+// the label set to value.
+func clientCounter(t *testing.T, name, label, value string) float64 {
+	family, ok := gatherByName(t, prometheus.DefaultGatherer)[name]
+	if !ok {
+		return 0
+	}
+
+	for _, metric := range family.GetMetric() {
+		for _, pair := range metric.GetLabel() {
+			if pair.GetName() == label && pair.GetValue() == value {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+
+	return 0
+}
+
+func get(t *testing.T, client *http.Client, url string) {
+	resp, err := client.Get(url)
+	assert.Nil(t, err)
+
+	_, _ = ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+}
+
+func TestTraceTransportCountsReusedConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	newConns := clientCounter(t, "cbexporter_client_connections_total", "reused", "false")
+	reusedConns := clientCounter(t, "cbexporter_client_connections_total", "reused", "true")
+	requests := clientCounter(t, "cbexporter_client_requests_total", "protocol", "HTTP/1.1")
+
+	client := &http.Client{Transport: util.NewTraceTransport(util.NewTransport(nil, 10))}
+	get(t, client, server.URL)
+	get(t, client, server.URL)
+
+	assert.Equal(t, newConns+1, clientCounter(t, "cbexporter_client_connections_total", "reused", "false"))
+	assert.Equal(t, reusedConns+1, clientCounter(t, "cbexporter_client_connections_total", "reused", "true"))
+	assert.Equal(t, requests+2, clientCounter(t, "cbexporter_client_requests_total", "protocol", "HTTP/1.1"))
+}
+
+func TestTransportUsesHTTP2OverTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+
+	defer server.Close()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	requests := clientCounter(t, "cbexporter_client_requests_total", "protocol", "HTTP/2.0")
+
+	client := &http.Client{Transport: util.NewTraceTransport(util.NewTransport(&tls.Config{RootCAs: pool}, 10))}
+	get(t, client, server.URL)
+
+	assert.Equal(t, requests+1, clientCounter(t, "cbexporter_client_requests_total", "protocol", "HTTP/2.0"))
+}
+
+func TestRecycleConnectionsDialsAgain(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	pool := util.NewTransport(nil, 10)
+	client := &http.Client{Transport: util.NewTraceTransport(pool)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go util.RecycleConnections(ctx, pool, 20*time.Millisecond)
+
+	newConns := clientCounter(t, "cbexporter_client_connections_total", "reused", "false")
+
+	get(t, client, server.URL)
+	time.Sleep(100 * time.Millisecond)
+	get(t, client, server.URL)
+
+	assert.Equal(t, newConns+2, clientCounter(t, "cbexporter_client_connections_total", "reused", "false"))
+}
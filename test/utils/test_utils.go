@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/couchbase/couchbase-exporter/pkg/objects"
 	"github.com/prometheus/client_golang/prometheus"
@@ -210,6 +211,8 @@ func handleSpecialCases(key string, node objects.Node) float64 {
 		return node.McdMemoryAllocated
 	case "mcdMemoryReserved":
 		return node.McdMemoryReserved
+	case objects.ServerGroupInfo, objects.VersionInfo:
+		return 1
 	default:
 		return 0
 	}
@@ -217,6 +220,10 @@ func handleSpecialCases(key string, node objects.Node) float64 {
 
 func GetNodeTestValue(key string, name string, config objects.CollectorConfig, nodes objects.Nodes) float64 {
 	metric := config.Metrics[key]
+	if key == objects.ClusterInfo {
+		return 1
+	}
+
 	if contains(metric.Labels, "node") {
 		switch {
 		case strings.HasPrefix(name, "systemstats_"):
@@ -247,6 +254,26 @@ func GenerateServers() objects.Servers {
 	}
 }
 
+func GeneratePools() objects.Pools {
+	return objects.Pools{
+		UUID:                  "a0c6c1d1e0c1a4e4a37fba9a3b1a8d7e",
+		IsEnterprise:          true,
+		ImplementationVersion: "7.2.0-5325-enterprise",
+	}
+}
+
+func GenerateServerGroups(name string, nodes []objects.Node) objects.ServerGroups {
+	return objects.ServerGroups{
+		Groups: []objects.ServerGroup{
+			{
+				Name:  name,
+				URI:   "/pools/default/serverGroups/0",
+				Nodes: nodes,
+			},
+		},
+	}
+}
+
 func GenerateNodes(name string, nodes []objects.Node) objects.Nodes {
 	cluster := objects.Nodes{
 		Name:  name,
@@ -277,6 +304,10 @@ func GenerateNode() objects.Node {
 			objects.SwapUsed:           GetRandomFloat64(0, 99999),
 			objects.MemTotal:           GetRandomFloat64(0, 99999),
 			objects.MemFree:            GetRandomFloat64(0, 99999),
+			objects.CPUStolenRate:      GetRandomFloat64(0, 100),
+			objects.CPUCoresAvailable:  GetRandomFloat64(0, 64),
+			objects.MemLimit:           GetRandomFloat64(0, 99999),
+			objects.Allocstall:         GetRandomFloat64(0, 99999),
 		},
 		InterestingStats: map[string]float64{
 			objects.CmdGet:                                 GetRandomFloat64(0, 99999),
@@ -310,8 +341,8 @@ func GenerateNode() objects.Node {
 		ThisNode:             true,
 		OtpCookie:            "",
 		Hostname:             "localhost",
-		ClusterCompatibility: 5,
-		Version:              "",
+		ClusterCompatibility: 0x70001,
+		Version:              "7.1.1-3175-enterprise",
 		Os:                   "",
 		CPUCount:             5,
 		Ports:                &objects.Ports{},
@@ -322,6 +353,18 @@ func GenerateNode() objects.Node {
 	return node
 }
 
+// GenerateNodeTime returns a node time whose skew is exactly the given skew,
+// bearing in mind the reported time is truncated to the second.
+func GenerateNodeTime(skew time.Duration) objects.NodeTime {
+	sent := time.Date(2023, time.March, 14, 9, 26, 53, 0, time.UTC)
+
+	return objects.NodeTime{
+		Time:     sent.Add(skew - time.Second/2),
+		Sent:     sent,
+		Received: sent,
+	}
+}
+
 func GenerateTasks() []objects.Task {
 	tasks := make([]objects.Task, 0)
 	rebalance := objects.Task{
@@ -335,6 +378,11 @@ func GenerateTasks() []objects.Task {
 			},
 		},
 	}
+	rebalance.StageInfo.Index = objects.StageInfo{
+		TotalProgress: GetRandomIntAsFloat64(0, 100),
+		StartTime:     "2022-01-01T00:00:00.000Z",
+		CompletedTime: false,
+	}
 	xdcr := objects.Task{
 		Type:           "xdcr",
 		ChangesLeft:    GetRandomInt64(0, 99999),
@@ -342,6 +390,7 @@ func GenerateTasks() []objects.Task {
 		DocsWritten:    GetRandomInt64(0, 99999),
 		PauseRequested: false,
 		Errors:         make([]interface{}, 5),
+		ID:             "remote-uuid/Foo/Bar",
 		Source:         "Foo",
 		Target:         "Bar",
 		DetailedProgress: struct {
@@ -380,7 +429,17 @@ func GenerateTasks() []objects.Task {
 		Type:     "clusterLogsCollection",
 		Progress: GetRandomIntAsFloat64(0, 100),
 	}
-	tasks = append(tasks, clusterLogCollection, rebalance, xdcr, bucketCompaction)
+	warmup := objects.Task{
+		Type:   "warming_up",
+		Bucket: "wawa-bucket",
+		Node:   "ns_1@wawa-node",
+		Stats: map[string]interface{}{
+			objects.WarmupState:               "loading data",
+			objects.WarmupEstimatedValueCount: fmt.Sprint(GetRandomInt64(1, 99999)),
+			objects.WarmupValueCount:          fmt.Sprint(GetRandomInt64(0, 99999)),
+		},
+	}
+	tasks = append(tasks, clusterLogCollection, rebalance, xdcr, bucketCompaction, warmup)
 
 	return tasks
 }
@@ -397,16 +456,38 @@ func getTask(tasks []objects.Task, taskType string) objects.Task {
 	return task
 }
 
-func GetTaskTestValue(key string, name string, tasks []objects.Task) float64 {
+// GenerateXdcrStats generates the XDCR stats of the replication in tasks.
+func GenerateXdcrStats(tasks []objects.Task) objects.XdcrStats {
+	var stats objects.XdcrStats
+
+	stats.Op.Samples = map[string][]float64{}
+
+	for _, stat := range objects.XdcrStatMetrics {
+		stats.Op.Samples["replications/"+getTask(tasks, "xdcr").ID+"/"+stat] = GetRandomFloatSlice(0, 1000, 5)
+	}
+
+	return stats
+}
+
+func GetTaskTestValue(key string, name string, tasks []objects.Task, xdcrStats objects.XdcrStats) float64 {
+	if stat, ok := objects.XdcrStatMetrics[key]; ok {
+		samples, _ := xdcrStats.Replication(getTask(tasks, "xdcr").ID, stat)
+		return Last(samples)
+	}
+
 	switch key {
 	case "compacting":
 		return getTask(tasks, "bucket_compaction").Progress
+	case "compactionRunning":
+		return boolToFloat64(getTask(tasks, "bucket_compaction").Type != "")
 	case "clusterLogsCollection":
 		return getTask(tasks, "clusterLogsCollection").Progress
 	case "rebalance":
 		return getTask(tasks, "rebalance").Progress
 	case "rebalancePerNode":
 		return getTask(tasks, "rebalance").PerNode["wawa-node"].Progress
+	case "rebalanceIndex":
+		return getTask(tasks, "rebalance").StageInfo.Index.TotalProgress
 	case "xdcrChangesLeft":
 		return float64(getTask(tasks, "xdcr").ChangesLeft)
 	case "xdcrDocsChecked":
@@ -425,6 +506,14 @@ func GetTaskTestValue(key string, name string, tasks []objects.Task) float64 {
 		return float64(getTask(tasks, "xdcr").DetailedProgress.PerNode["wawa-node"].Ingoing.ActiveVBucketsLeft)
 	case "progressReplicaVBucketsLeft":
 		return float64(getTask(tasks, "xdcr").DetailedProgress.PerNode["wawa-node"].Ingoing.ReplicaVBucketsLeft)
+	case "warmupState":
+		return 1
+	case "warmupEstimatedItems":
+		items, _ := getTask(tasks, "warming_up").WarmupStat(objects.WarmupEstimatedValueCount)
+		return items
+	case "warmupItems":
+		items, _ := getTask(tasks, "warming_up").WarmupStat(objects.WarmupValueCount)
+		return items
 	default:
 		return 0
 	}
@@ -440,6 +529,7 @@ func GenerateBucketInfo(name string) objects.BucketInfo {
 		StreamingURI:      "some streaming uri",
 		LocalRandomKeyURI: "some random key uri",
 		Nodes:             []objects.Node{},
+		MaxTTL:            int(GetRandomInt64(1, 86400)),
 		BucketBasicStats: map[string]float64{
 			objects.QuotaPercentUsed:       GetRandomFloat64(1, 99999),
 			objects.OpsPerSec:              GetRandomFloat64(1, 99999),
@@ -584,10 +674,10 @@ func GenerateAnalytics() objects.Analytics {
 	return anal
 }
 
-func GenerateBucketStatSamples() map[string]interface{} {
+func GenerateBucketStatSamples() objects.Samples {
 	samples := GenerateBucketStats().Op.Samples
 
-	realSamples := make(map[string]interface{}, len(samples))
+	realSamples := make(objects.Samples, len(samples))
 
 	for k, v := range samples {
 		realSamples[k] = v
@@ -712,6 +802,9 @@ func GenerateBucketStats() objects.BucketStats {
 				objects.EpDiskqueueDrain:                    GetRandomFloatSlice(0, 1000, 10),
 				objects.EpDiskqueueFill:                     GetRandomFloatSlice(0, 1000, 10),
 				objects.EpDiskqueueItems:                    GetRandomFloatSlice(0, 1000, 10),
+				objects.EpExpiredAccess:                     GetRandomFloatSlice(0, 1000, 10),
+				objects.EpExpiredCompactor:                  GetRandomFloatSlice(0, 1000, 10),
+				objects.EpExpiredPager:                      GetRandomFloatSlice(0, 1000, 10),
 				objects.EpFlusherTodo:                       GetRandomFloatSlice(0, 1000, 10),
 				objects.EpItemCommitFailed:                  GetRandomFloatSlice(0, 1000, 10),
 				objects.EpKvSize:                            GetRandomFloatSlice(0, 1000, 10),
@@ -735,6 +828,7 @@ func GenerateBucketStats() objects.BucketStats {
 				objects.EpReplicaHlcDrift:                   GetRandomFloatSlice(0, 1000, 10),
 				objects.EpReplicaHlcDriftCount:              GetRandomFloatSlice(0, 1000, 10),
 				objects.EpTmpOomErrors:                      GetRandomFloatSlice(0, 1000, 10),
+				objects.EpTotalDelItems:                     GetRandomFloatSlice(0, 1000, 10),
 				objects.EpVbTotal:                           GetRandomFloatSlice(0, 1000, 10),
 				objects.Evictions:                           GetRandomFloatSlice(0, 1000, 10),
 				objects.BucketStatsGetHits:                  GetRandomFloatSlice(0, 1000, 10),
@@ -838,12 +932,17 @@ func GenerateBucket(name string) objects.BucketInfo {
 
 func GenerateIndexerStats() map[string]map[string]interface{} {
 	stats := map[string]map[string]interface{}{
-		"indexer": {},
+		"indexer": {
+			"memory_quota": GetRandomFloat64(0, 1000),
+			"memory_used":  GetRandomFloat64(0, 1000),
+		},
 		"mybucket:keyspace": {
 			objects.IndexDocsIndexed:          GetRandomFloat64(0, 1000),
 			objects.IndexItemsCount:           GetRandomFloat64(0, 1000),
 			objects.IndexFragPercent:          GetRandomFloat64(0, 1000),
 			objects.IndexNumDocsPendingQueued: GetRandomFloat64(0, 1000),
+			objects.IndexNumDocsQueued:        GetRandomFloat64(0, 1000),
+			objects.IndexNumDocsPending:       GetRandomFloat64(0, 1000),
 			objects.IndexNumRequests:          GetRandomFloat64(0, 1000),
 			objects.IndexCacheMisses:          GetRandomFloat64(0, 1000),
 			objects.IndexCacheHits:            GetRandomFloat64(0, 1000),
@@ -0,0 +1,94 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+)
+
+func bound(value float64) *float64 {
+	return &value
+}
+
+func valueGuardRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+
+	items := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketstat_curr_items"}, []string{"bucket"})
+	items.WithLabelValues("default").Set(10)
+	items.WithLabelValues("negative").Set(-5)
+	items.WithLabelValues("overflow").Set(1e19)
+
+	ops := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "cbbucketstat_ops"}, []string{"bucket"})
+	ops.WithLabelValues("negative").Set(-1)
+
+	registry.MustRegister(items, ops)
+
+	return registry
+}
+
+func guardedBuckets(t *testing.T, gatherer prometheus.Gatherer, name string) []string {
+	buckets := []string{}
+	for _, metric := range gatherByName(t, gatherer)[name].GetMetric() {
+		buckets = append(buckets, metric.GetLabel()[0].GetValue())
+	}
+
+	return buckets
+}
+
+func anomalies(t *testing.T, metric, bound string) float64 {
+	for _, m := range gatherByName(t, prometheus.DefaultGatherer)["cbexporter_value_guard_anomalies_total"].GetMetric() {
+		if m.GetLabel()[0].GetValue() == bound && m.GetLabel()[1].GetValue() == metric {
+			return m.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestValueGuardDropsOutOfBoundsSeries(t *testing.T) {
+	gatherer, err := util.NewValueGuardGatherer(valueGuardRegistry(), []objects.ValueGuard{
+		{Match: "cbbucketstat_curr_items", Min: bound(0), Max: bound(1e15), Drop: true},
+	})
+	assert.Nil(t, err)
+
+	minBefore := anomalies(t, "cbbucketstat_curr_items", "min")
+	maxBefore := anomalies(t, "cbbucketstat_curr_items", "max")
+
+	assert.Equal(t, []string{"default"}, guardedBuckets(t, gatherer, "cbbucketstat_curr_items"))
+	assert.Equal(t, []string{"negative"}, guardedBuckets(t, gatherer, "cbbucketstat_ops"))
+
+	assert.Equal(t, minBefore+2, anomalies(t, "cbbucketstat_curr_items", "min"))
+	assert.Equal(t, maxBefore+2, anomalies(t, "cbbucketstat_curr_items", "max"))
+}
+
+func TestValueGuardOnlyCountsWithoutDrop(t *testing.T) {
+	gatherer, err := util.NewValueGuardGatherer(valueGuardRegistry(), []objects.ValueGuard{
+		{Match: "cbbucketstat_.*", Min: bound(0)},
+	})
+	assert.Nil(t, err)
+
+	before := anomalies(t, "cbbucketstat_ops", "min")
+
+	assert.Equal(t, []string{"default", "negative", "overflow"}, guardedBuckets(t, gatherer, "cbbucketstat_curr_items"))
+	assert.Equal(t, before+1, anomalies(t, "cbbucketstat_ops", "min"))
+}
+
+func TestInvalidValueGuardsAreRejected(t *testing.T) {
+	registry := valueGuardRegistry()
+
+	for _, guard := range []objects.ValueGuard{
+		{Match: "cbbucketstat_("},
+		{Match: "cbbucketstat_ops"},
+		{Match: "cbbucketstat_ops", Min: bound(2), Max: bound(1)},
+	} {
+		_, err := util.NewValueGuardGatherer(registry, []objects.ValueGuard{guard})
+		assert.ErrorIs(t, err, util.ErrInvalidValueGuard)
+	}
+
+	gatherer, err := util.NewValueGuardGatherer(registry, nil)
+	assert.Nil(t, err)
+	assert.Same(t, registry, gatherer)
+}
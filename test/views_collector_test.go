@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbase/couchbase-exporter/pkg/collectors"
+	"github.com/couchbase/couchbase-exporter/pkg/config"
+	"github.com/couchbase/couchbase-exporter/pkg/objects"
+	"github.com/couchbase/couchbase-exporter/pkg/util"
+	"github.com/couchbase/couchbase-exporter/test/mocks"
+	test "github.com/couchbase/couchbase-exporter/test/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func generateDesignDocs(t *testing.T, ids ...string) objects.DesignDocs {
+	rows := []string{}
+	for _, id := range ids {
+		rows = append(rows, fmt.Sprintf(`{"doc":{"meta":{"id":%q,"rev":"1-0"}},"controllers":{}}`, id))
+	}
+
+	var ddocs objects.DesignDocs
+	assert.Nil(t, json.Unmarshal([]byte(`{"rows":[`+strings.Join(rows, ",")+`]}`), &ddocs))
+
+	return ddocs
+}
+
+func generateDesignDocInfo(t *testing.T, body string) objects.DesignDocInfo {
+	var info objects.DesignDocInfo
+	assert.Nil(t, json.Unmarshal([]byte(body), &info))
+
+	return info
+}
+
+func TestViewsCollectReportsDesignDocumentMetrics(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	bucket := test.GenerateBucket("wawa-bucket")
+	bucket.BucketType = "membase"
+
+	memcached := test.GenerateBucket("memcached-bucket")
+	memcached.BucketType = "memcached"
+
+	var stats objects.BucketStats
+	stats.Op.Samples = map[string][]float64{
+		"views/abc/accesses": {1, 2, 7},
+		"views/def/accesses": {0, 0, 3},
+	}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{bucket, memcached}, nil)
+	mockClient.EXPECT().DesignDocs(gomock.Any(), "wawa-bucket").Times(1).Return(generateDesignDocs(t, "_design/orders", "_design/dev_users"), nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).Return(stats, nil)
+	mockClient.EXPECT().DesignDocInfo(gomock.Any(), "wawa-bucket", "orders").Times(1).Return(generateDesignDocInfo(t, `{"name":"_design/orders","view_index":{"signature":"abc","disk_size":4096,"data_size":1024,"updater_running":true,
+		"stats":{"update_history":[{"indexing_time":0.5},{"indexing_time":5}]}}}`), nil)
+	mockClient.EXPECT().DesignDocInfo(gomock.Any(), "wawa-bucket", "dev_users").Times(1).Return(generateDesignDocInfo(t, `{"name":"_design/dev_users","view_index":{"signature":"def","disk_size":8192,"data_size":2048,"updater_running":false,
+		"stats":{"update_history":[]}}}`), nil)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewViewsCollector(mockClient, defaultConfig.Collectors.Views, labelManager))
+
+	assert.Equal(t, 7.0, values["cbviews_accesses/wawa-bucket/orders"])
+	assert.Equal(t, 3.0, values["cbviews_accesses/wawa-bucket/dev_users"])
+	assert.Equal(t, 0.5, values["cbviews_last_update_duration_seconds/wawa-bucket/orders"])
+	assert.NotContains(t, values, "cbviews_last_update_duration_seconds/wawa-bucket/dev_users")
+	assert.Equal(t, 4096.0, values["cbviews_disk_size_bytes/wawa-bucket/orders"])
+	assert.Equal(t, 2048.0, values["cbviews_data_size_bytes/wawa-bucket/dev_users"])
+	assert.Equal(t, 1.0, values["cbviews_updater_running/wawa-bucket/orders"])
+	assert.Equal(t, 0.0, values["cbviews_updater_running/wawa-bucket/dev_users"])
+	assert.Equal(t, 1.0, values["cbviews_up"])
+}
+
+func TestViewsCollectReturnsDownIfClientReturnsError(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	bucket := test.GenerateBucket("wawa-bucket")
+	bucket.BucketType = "membase"
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{bucket}, nil)
+	mockClient.EXPECT().DesignDocs(gomock.Any(), "wawa-bucket").Times(1).Return(objects.DesignDocs{}, ErrDummy)
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewViewsCollector(mockClient, defaultConfig.Collectors.Views, labelManager))
+
+	assert.Equal(t, map[string]float64{"cbviews_up": 0}, values)
+}
+
+func TestViewsCollectRequestsDesignDocInfoAndStatsAtOnce(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	bucket := test.GenerateBucket("wawa-bucket")
+	bucket.BucketType = "membase"
+
+	// each request waits for the other two to be in flight, so would time
+	// out if they were made one after another.
+	var inFlight sync.WaitGroup
+
+	inFlight.Add(3)
+
+	together := func() bool {
+		inFlight.Done()
+
+		done := make(chan struct{})
+
+		go func() {
+			inFlight.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return true
+		case <-time.After(time.Second):
+			return false
+		}
+	}
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{bucket}, nil)
+	mockClient.EXPECT().DesignDocs(gomock.Any(), "wawa-bucket").Times(1).Return(generateDesignDocs(t, "_design/orders", "_design/users"), nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).DoAndReturn(func(context.Context, string) (objects.BucketStats, error) {
+		assert.True(t, together(), "bucket stats requested alone")
+
+		return objects.BucketStats{}, nil
+	})
+	mockClient.EXPECT().DesignDocInfo(gomock.Any(), "wawa-bucket", gomock.Any()).Times(2).DoAndReturn(func(_ context.Context, _, name string) (objects.DesignDocInfo, error) {
+		assert.True(t, together(), "info of %s requested alone", name)
+
+		return generateDesignDocInfo(t, `{"name":"_design/`+name+`","view_index":{"disk_size":4096}}`), nil
+	})
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	values := collectValues(t, collectors.NewViewsCollector(mockClient, defaultConfig.Collectors.Views, labelManager))
+
+	assert.Equal(t, 4096.0, values["cbviews_disk_size_bytes/wawa-bucket/orders"])
+	assert.Equal(t, 4096.0, values["cbviews_disk_size_bytes/wawa-bucket/users"])
+	assert.Equal(t, 1.0, values["cbviews_up"])
+}
+
+func TestViewsCollectCancelsRemainingRequestsOnFirstError(t *testing.T) {
+	defaultConfig := config.GetDefaultConfig()
+	mockCtrl := gomock.NewController(t)
+
+	defer mockCtrl.Finish()
+
+	bucket := test.GenerateBucket("wawa-bucket")
+	bucket.BucketType = objects.CouchbaseBucketType
+
+	mockClient := mocks.NewMockCbClient(mockCtrl)
+	mockClient.EXPECT().ClusterName(gomock.Any()).Times(1).Return("dummy-cluster", nil)
+	mockClient.EXPECT().GetCurrentNode(gomock.Any()).Times(1).Return(test.GenerateNode(), nil)
+	mockClient.EXPECT().Buckets(gomock.Any()).Times(1).Return([]objects.BucketInfo{bucket}, nil)
+	mockClient.EXPECT().DesignDocs(gomock.Any(), "wawa-bucket").Times(1).Return(generateDesignDocs(t, "_design/orders", "_design/users"), nil)
+	mockClient.EXPECT().BucketStats(gomock.Any(), "wawa-bucket").Times(1).Return(objects.BucketStats{}, ErrDummy)
+	mockClient.EXPECT().DesignDocInfo(gomock.Any(), "wawa-bucket", gomock.Any()).AnyTimes().DoAndReturn(func(ctx context.Context, _, _ string) (objects.DesignDocInfo, error) {
+		select {
+		case <-ctx.Done():
+			return objects.DesignDocInfo{}, ctx.Err()
+		case <-time.After(10 * time.Second):
+			return objects.DesignDocInfo{}, nil
+		}
+	})
+
+	labelManager := util.NewLabelManager(mockClient, 600*time.Second)
+	start := time.Now()
+	values := collectValues(t, collectors.NewViewsCollector(mockClient, defaultConfig.Collectors.Views, labelManager))
+
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, 0.0, values["cbviews_up"])
+}